@@ -7,25 +7,36 @@ import (
 	"os"
 
 	"github.com/go-redis/redis/v8"
+	pkgmilvus "github.com/lk2023060901/ai-writer-backend/internal/pkg/milvus"
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"google.golang.org/grpc"
+	grpccredentials "google.golang.org/grpc/credentials"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 type Config struct {
-	PostgresDSN       string
-	RedisAddr         string
-	RedisPassword     string
-	MinioEndpoint     string
-	MinioAccessKey    string
-	MinioSecretKey    string
-	MinioBucket       string
-	MinioUseSSL       bool
-	MilvusHost        string
-	MilvusPort        string
+	PostgresDSN      string
+	RedisAddr        string
+	RedisPassword    string
+	MinioEndpoint    string
+	MinioAccessKey   string
+	MinioSecretKey   string
+	MinioBucket      string
+	MinioUseSSL      bool
+	MilvusHost       string
+	MilvusPort       string
+	MilvusUsername   string
+	MilvusPassword   string
+	MilvusAPIKey     string
+	MilvusTLS        bool
+	MilvusCACert     string
+	MilvusClientCert string
+	MilvusClientKey  string
+	MilvusServerName string
 }
 
 func main() {
@@ -94,15 +105,23 @@ func loadConfig() *Config {
 	return &Config{
 		PostgresDSN: getEnv("POSTGRES_DSN",
 			"host=localhost port=5432 user=postgres password=postgres dbname=aiwriter sslmode=disable"),
-		RedisAddr:      getEnv("REDIS_ADDR", "localhost:6379"),
-		RedisPassword:  getEnv("REDIS_PASSWORD", ""),
-		MinioEndpoint:  getEnv("MINIO_ENDPOINT", "localhost:9000"),
-		MinioAccessKey: getEnv("MINIO_ACCESS_KEY", "minioadmin"),
-		MinioSecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
-		MinioBucket:    getEnv("MINIO_BUCKET", "aiwriter"),
-		MinioUseSSL:    getEnv("MINIO_USE_SSL", "false") == "true",
-		MilvusHost:     getEnv("MILVUS_HOST", "localhost"),
-		MilvusPort:     getEnv("MILVUS_PORT", "19530"),
+		RedisAddr:        getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:    getEnv("REDIS_PASSWORD", ""),
+		MinioEndpoint:    getEnv("MINIO_ENDPOINT", "localhost:9000"),
+		MinioAccessKey:   getEnv("MINIO_ACCESS_KEY", "minioadmin"),
+		MinioSecretKey:   getEnv("MINIO_SECRET_KEY", "minioadmin"),
+		MinioBucket:      getEnv("MINIO_BUCKET", "aiwriter"),
+		MinioUseSSL:      getEnv("MINIO_USE_SSL", "false") == "true",
+		MilvusHost:       getEnv("MILVUS_HOST", "localhost"),
+		MilvusPort:       getEnv("MILVUS_PORT", "19530"),
+		MilvusUsername:   getEnv("MILVUS_USERNAME", ""),
+		MilvusPassword:   getEnv("MILVUS_PASSWORD", ""),
+		MilvusAPIKey:     getEnv("MILVUS_API_KEY", ""),
+		MilvusTLS:        getEnv("MILVUS_TLS", "false") == "true",
+		MilvusCACert:     getEnv("MILVUS_TLS_CA_CERT", ""),
+		MilvusClientCert: getEnv("MILVUS_TLS_CERT", ""),
+		MilvusClientKey:  getEnv("MILVUS_TLS_KEY", ""),
+		MilvusServerName: getEnv("MILVUS_TLS_SERVER_NAME", ""),
 	}
 }
 
@@ -172,10 +191,50 @@ func clearRedis(ctx context.Context, cfg *Config) {
 	fmt.Printf("   ✓ 已清理 %d 个 Redis key\n", count)
 }
 
+// milvusClientConfig 构建 Milvus 连接配置（支持用户名密码/API Key 认证和 TLS）
+func milvusClientConfig(cfg *Config) (client.Config, error) {
+	connCfg := &pkgmilvus.Config{
+		Address:    fmt.Sprintf("%s:%s", cfg.MilvusHost, cfg.MilvusPort),
+		Username:   cfg.MilvusUsername,
+		Password:   cfg.MilvusPassword,
+		APIKey:     cfg.MilvusAPIKey,
+		EnableTLS:  cfg.MilvusTLS,
+		CACertFile: cfg.MilvusCACert,
+		CertFile:   cfg.MilvusClientCert,
+		KeyFile:    cfg.MilvusClientKey,
+		ServerName: cfg.MilvusServerName,
+	}
+	if err := connCfg.Validate(); err != nil {
+		return client.Config{}, err
+	}
+
+	clientCfg := client.Config{
+		Address:  connCfg.Address,
+		Username: connCfg.Username,
+		Password: connCfg.Password,
+		APIKey:   connCfg.APIKey,
+	}
+
+	tlsCfg, err := connCfg.TLSConfig()
+	if err != nil {
+		return client.Config{}, err
+	}
+	if tlsCfg != nil {
+		clientCfg.EnableTLSAuth = true
+		clientCfg.DialOptions = append(append([]grpc.DialOption{}, client.DefaultGrpcOpts...),
+			grpc.WithTransportCredentials(grpccredentials.NewTLS(tlsCfg)))
+	}
+	return clientCfg, nil
+}
+
 func clearMilvus(ctx context.Context, cfg *Config) {
-	c, err := client.NewClient(ctx, client.Config{
-		Address: fmt.Sprintf("%s:%s", cfg.MilvusHost, cfg.MilvusPort),
-	})
+	clientCfg, err := milvusClientConfig(cfg)
+	if err != nil {
+		fmt.Printf("   ⚠ Milvus 配置无效: %v\n", err)
+		return
+	}
+
+	c, err := client.NewClient(ctx, clientCfg)
 	if err != nil {
 		fmt.Printf("   ⚠ Milvus 连接失败: %v\n", err)
 		return
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/conf"
+	kbbiz "github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/injector"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+)
+
+var (
+	configFile = flag.String("config", "config.yaml", "config file path")
+	kbID       = flag.String("kb-id", "", "only rebuild the given knowledge base")
+	resume     = flag.Bool("resume", false, "resume from the state file instead of starting over")
+	stateFile  = flag.String("state", "rebuild-vectors.state.json", "progress state file used for resuming")
+)
+
+// rebuildState 断点续传状态（按知识库记录最后完成的文档 ID）
+type rebuildState struct {
+	LastDocumentIDs map[string]string `json:"last_document_ids"`
+	Completed       map[string]bool   `json:"completed"`
+}
+
+func main() {
+	flag.Parse()
+
+	config, err := conf.LoadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	appLog, err := logger.New(&logger.Config{
+		Level:  config.Log.Level,
+		Format: config.Log.Format,
+		Output: "console",
+	})
+	if err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
+	defer appLog.Sync()
+
+	fmt.Println("==========================================")
+	fmt.Println("从 PostgreSQL 分块重建 Milvus 向量")
+	fmt.Print("==========================================\n\n")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	uc, cleanup, err := injector.InitializeDocumentUseCase(config, appLog)
+	if err != nil {
+		log.Fatalf("初始化失败: %v", err)
+	}
+	defer cleanup()
+
+	state := &rebuildState{LastDocumentIDs: map[string]string{}, Completed: map[string]bool{}}
+	if *resume {
+		if state, err = loadState(*stateFile); err != nil {
+			log.Fatalf("读取进度文件失败: %v", err)
+		}
+		fmt.Printf("从进度文件继续: %s\n\n", *stateFile)
+	}
+
+	kbIDs := []string{*kbID}
+	if *kbID == "" {
+		if kbIDs, err = uc.ListKnowledgeBaseIDs(ctx); err != nil {
+			log.Fatalf("列出知识库失败: %v", err)
+		}
+	}
+
+	totalVectors := 0
+	for i, id := range kbIDs {
+		fmt.Printf("%d/%d. 知识库 %s\n", i+1, len(kbIDs), id)
+		if state.Completed[id] {
+			fmt.Print("   ✓ 已完成，跳过\n\n")
+			continue
+		}
+
+		result, err := uc.RebuildVectors(ctx, id, &kbbiz.RebuildVectorsOptions{
+			ResumeAfterDocumentID: state.LastDocumentIDs[id],
+			Progress: func(p kbbiz.RebuildVectorsProgress) {
+				fmt.Printf("   [%d/%d] 文档 %s，累计写入 %d 个向量\n",
+					p.ProcessedDocuments, p.TotalDocuments, p.DocumentID, p.InsertedVectors)
+				state.LastDocumentIDs[id] = p.DocumentID
+				if err := saveState(*stateFile, state); err != nil {
+					fmt.Printf("   ⚠ 保存进度失败: %v\n", err)
+				}
+			},
+		})
+		if err != nil {
+			log.Fatalf("重建知识库 %s 失败（使用 --resume 继续）: %v", id, err)
+		}
+
+		state.Completed[id] = true
+		delete(state.LastDocumentIDs, id)
+		if err := saveState(*stateFile, state); err != nil {
+			fmt.Printf("   ⚠ 保存进度失败: %v\n", err)
+		}
+		totalVectors += result.InsertedVectors
+		fmt.Printf("   ✓ 已处理 %d 个文档（跳过 %d 个），写入 %d 个向量\n\n",
+			result.ProcessedDocuments, result.SkippedDocuments, result.InsertedVectors)
+	}
+
+	fmt.Println("==========================================")
+	fmt.Println("重建完成！")
+	fmt.Println("==========================================")
+	fmt.Printf("\n重建汇总:\n")
+	fmt.Printf("  - 知识库: %d\n", len(kbIDs))
+	fmt.Printf("  - 写入向量: %d\n\n", totalVectors)
+
+	// 全部完成后删除进度文件，下次运行重新开始
+	_ = os.Remove(*stateFile)
+}
+
+// loadState 读取进度文件（不存在时返回空状态）
+func loadState(path string) (*rebuildState, error) {
+	state := &rebuildState{LastDocumentIDs: map[string]string{}, Completed: map[string]bool{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.LastDocumentIDs == nil {
+		state.LastDocumentIDs = map[string]string{}
+	}
+	if state.Completed == nil {
+		state.Completed = map[string]bool{}
+	}
+	return state, nil
+}
+
+// saveState 写入进度文件（先写临时文件再重命名，避免中断时留下损坏的文件）
+func saveState(path string, state *rebuildState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
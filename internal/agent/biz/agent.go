@@ -54,6 +54,7 @@ type AgentRepo interface {
 type OfficialAgentRepo interface {
 	GetByID(ctx context.Context, id string) (*Agent, error)
 	List(ctx context.Context, req *ListAgentsRequest) ([]*Agent, int64, error)
+	CreateIfAbsent(ctx context.Context, agent *Agent) (bool, error) // ID 已存在（含已软删除）时不做任何修改；返回是否新建
 }
 
 // AgentUseCase 智能体业务逻辑
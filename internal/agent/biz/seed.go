@@ -0,0 +1,73 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OfficialAgentSeed 官方智能体种子定义（Key 为稳定标识，决定智能体 ID）
+type OfficialAgentSeed struct {
+	Key              string
+	Name             string
+	Emoji            string
+	Prompt           string
+	Tags             []string
+	KnowledgeBaseIDs []string
+}
+
+// OfficialAgentSeedID 根据种子 Key 生成稳定的官方智能体 ID（重复运行得到相同 ID）
+func OfficialAgentSeedID(key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("ai-writer:official-agent:"+key)).String()
+}
+
+// SeedOfficialAgents 创建缺失的官方智能体，已存在（含已禁用、已删除）的不做修改；返回新建数量
+func (uc *AgentUseCase) SeedOfficialAgents(ctx context.Context, seeds []OfficialAgentSeed) (int, error) {
+	created := 0
+	for _, seed := range seeds {
+		if seed.Key == "" {
+			return created, fmt.Errorf("official agent seed %q: key is required", seed.Name)
+		}
+		if seed.Name == "" {
+			return created, fmt.Errorf("official agent seed %s: %w", seed.Key, ErrAgentNameRequired)
+		}
+		if len(seed.Prompt) < 10 {
+			return created, fmt.Errorf("official agent seed %s: %w", seed.Key, ErrAgentPromptTooShort)
+		}
+
+		id := OfficialAgentSeedID(seed.Key)
+		knowledgeBaseIDs := seed.KnowledgeBaseIDs
+		if knowledgeBaseIDs == nil {
+			knowledgeBaseIDs = []string{}
+		}
+		tags := seed.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		now := time.Now()
+		agent := &Agent{
+			ID:               id,
+			OwnerID:          SystemOwnerID,
+			Name:             seed.Name,
+			Emoji:            seed.Emoji,
+			Prompt:           seed.Prompt,
+			KnowledgeBaseIDs: knowledgeBaseIDs,
+			Tags:             tags,
+			Type:             "agent",
+			IsEnabled:        true,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		inserted, err := uc.officialAgentRepo.CreateIfAbsent(ctx, agent)
+		if err != nil {
+			return created, fmt.Errorf("failed to create official agent %s: %w", seed.Key, err)
+		}
+		if inserted {
+			created++
+		}
+	}
+	return created, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/lk2023060901/ai-writer-backend/internal/agent/biz"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // OfficialAgentPO 官方智能体数据库模型（无 owner_id 字段）
@@ -56,6 +57,30 @@ func (r *OfficialAgentRepo) GetByID(ctx context.Context, id string) (*biz.Agent,
 	return r.toAgent(&po), nil
 }
 
+// CreateIfAbsent 创建官方智能体（ID 冲突时忽略，软删除的行仍占用 ID），返回是否实际写入
+func (r *OfficialAgentRepo) CreateIfAbsent(ctx context.Context, agent *biz.Agent) (bool, error) {
+	po := &OfficialAgentPO{
+		ID:               agent.ID,
+		Name:             agent.Name,
+		Emoji:            agent.Emoji,
+		Prompt:           agent.Prompt,
+		KnowledgeBaseIDs: agent.KnowledgeBaseIDs,
+		Tags:             agent.Tags,
+		Type:             agent.Type,
+		IsEnabled:        agent.IsEnabled,
+		CreatedAt:        agent.CreatedAt,
+		UpdatedAt:        agent.UpdatedAt,
+	}
+
+	result := r.db.WithContext(ctx).GetDB().
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).
+		Create(po)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // List 获取官方智能体列表（分页、过滤）
 func (r *OfficialAgentRepo) List(ctx context.Context, req *biz.ListAgentsRequest) ([]*biz.Agent, int64, error) {
 	var pos []OfficialAgentPO
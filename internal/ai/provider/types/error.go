@@ -20,8 +20,33 @@ const (
 	// 5xx 服务器错误
 	ErrorTypeAPI        ErrorType = "api_error"        // 500 - 内部服务器错误
 	ErrorTypeOverloaded ErrorType = "overloaded_error" // 529 - API 临时过载
+
+	// 非 HTTP 错误
+	ErrorTypeTimeout ErrorType = "timeout_error" // 等待服务商响应超时
 )
 
+// ErrorTypeFromStatus 按 HTTP 状态码推断错误类型
+func ErrorTypeFromStatus(statusCode int) ErrorType {
+	switch {
+	case statusCode == 401:
+		return ErrorTypeAuthentication
+	case statusCode == 403:
+		return ErrorTypePermission
+	case statusCode == 404:
+		return ErrorTypeNotFound
+	case statusCode == 413:
+		return ErrorTypeRequestTooLarge
+	case statusCode == 429:
+		return ErrorTypeRateLimit
+	case statusCode == 503 || statusCode == 529:
+		return ErrorTypeOverloaded
+	case statusCode >= 500:
+		return ErrorTypeAPI
+	default:
+		return ErrorTypeInvalidRequest
+	}
+}
+
 // ProviderError Provider 错误
 type ProviderError struct {
 	Type       ErrorType // 错误类型
@@ -77,7 +102,7 @@ func (e *ProviderError) IsRateLimitError() bool {
 // IsRetryable 判断错误是否可重试
 func (e *ProviderError) IsRetryable() bool {
 	switch e.Type {
-	case ErrorTypeRateLimit, ErrorTypeAPI, ErrorTypeOverloaded:
+	case ErrorTypeRateLimit, ErrorTypeAPI, ErrorTypeOverloaded, ErrorTypeTimeout:
 		return true
 	default:
 		return false
@@ -94,6 +119,16 @@ func NewProviderError(provider, message string, err error) *ProviderError {
 	}
 }
 
+// NewStatusError 根据服务商返回的非 2xx 响应创建错误（message 为响应体）
+func NewStatusError(provider string, statusCode int, message string) *ProviderError {
+	return &ProviderError{
+		Type:       ErrorTypeFromStatus(statusCode),
+		Provider:   provider,
+		StatusCode: statusCode,
+		Message:    message,
+	}
+}
+
 // NewRateLimitError 创建速率限制错误
 func NewRateLimitError(provider string, rateLimitInfo *RateLimitInfo) *ProviderError {
 	return &ProviderError{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	agentbiz "github.com/lk2023060901/ai-writer-backend/internal/agent/biz"
 	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
 
 	"github.com/google/uuid"
@@ -13,6 +14,8 @@ import (
 // AssistantRepo defines the repository interface for assistant data operations
 type AssistantRepo interface {
 	Create(ctx context.Context, assistant *types.Assistant) error
+	// CreateIfAbsent creates the assistant unless a row with the same ID exists (including soft-deleted ones); reports whether it was created
+	CreateIfAbsent(ctx context.Context, assistant *types.Assistant) (bool, error)
 	GetByID(ctx context.Context, id, userID string) (*types.Assistant, error)
 	List(ctx context.Context, userID string, filter *types.AssistantFilter) ([]*types.Assistant, error)
 	Update(ctx context.Context, assistant *types.Assistant) error
@@ -81,9 +84,12 @@ func (uc *AssistantUseCase) CreateAssistant(ctx context.Context, userID string,
 	return assistant, nil
 }
 
-// GetAssistant retrieves an assistant by ID
+// GetAssistant retrieves an assistant by ID (the user's own or an official one)
 func (uc *AssistantUseCase) GetAssistant(ctx context.Context, id, userID string) (*types.Assistant, error) {
 	assistant, err := uc.repo.GetByID(ctx, id, userID)
+	if err != nil && userID != agentbiz.SystemOwnerID {
+		assistant, err = uc.repo.GetByID(ctx, id, agentbiz.SystemOwnerID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assistant: %w", err)
 	}
@@ -91,14 +97,22 @@ func (uc *AssistantUseCase) GetAssistant(ctx context.Context, id, userID string)
 	return assistant, nil
 }
 
-// ListAssistants lists user's assistants with optional filtering
+// ListAssistants lists official assistants followed by the user's own, with optional filtering
 func (uc *AssistantUseCase) ListAssistants(ctx context.Context, userID string, filter *types.AssistantFilter) ([]*types.Assistant, error) {
-	assistants, err := uc.repo.List(ctx, userID, filter)
+	assistants, err := uc.repo.List(ctx, agentbiz.SystemOwnerID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list official assistants: %w", err)
+	}
+	if userID == agentbiz.SystemOwnerID {
+		return assistants, nil
+	}
+
+	own, err := uc.repo.List(ctx, userID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list assistants: %w", err)
 	}
 
-	return assistants, nil
+	return append(assistants, own...), nil
 }
 
 // UpdateAssistant updates an existing assistant
@@ -15,6 +15,8 @@ type MessageRepo interface {
 	Create(ctx context.Context, message *types.Message) error
 	GetByID(ctx context.Context, id string) (*types.Message, error)
 	ListByTopic(ctx context.Context, topicID string, limit, offset int) ([]*types.Message, error)
+	// ListRecentByTopic returns the newest limit messages of a topic, newest first
+	ListRecentByTopic(ctx context.Context, topicID string, limit int) ([]*types.Message, error)
 	CountByTopic(ctx context.Context, topicID string) (int64, error)
 	DeleteByTopic(ctx context.Context, topicID string) error
 }
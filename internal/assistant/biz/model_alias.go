@@ -0,0 +1,108 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+var (
+	// ErrModelAliasNotMapped is returned when a requested alias has no concrete model
+	ErrModelAliasNotMapped = errors.New("model alias is not mapped")
+	// ErrModelAliasNotFound is returned when an alias does not exist
+	ErrModelAliasNotFound = errors.New("model alias not found")
+)
+
+// ModelAliasRepo defines the repository interface for model alias operations
+type ModelAliasRepo interface {
+	GetByAlias(ctx context.Context, alias string) (*types.ModelAlias, error) // returns ErrModelAliasNotFound if missing
+	List(ctx context.Context) ([]*types.ModelAlias, error)
+	Upsert(ctx context.Context, alias *types.ModelAlias) error
+	Delete(ctx context.Context, alias string) error
+}
+
+// ModelAliasUseCase contains business logic for model alias operations
+type ModelAliasUseCase struct {
+	repo ModelAliasRepo
+}
+
+// NewModelAliasUseCase creates a new model alias use case
+func NewModelAliasUseCase(repo ModelAliasRepo) *ModelAliasUseCase {
+	return &ModelAliasUseCase{
+		repo: repo,
+	}
+}
+
+// ResolveModel resolves a requested model name to a concrete model ID.
+// Known aliases resolve to their current target; names that are not in the alias
+// table are returned unchanged, including provider-native names such as
+// "claude-3-5-sonnet-latest".
+func (uc *ModelAliasUseCase) ResolveModel(ctx context.Context, model string) (string, error) {
+	alias, err := uc.repo.GetByAlias(ctx, model)
+	if err != nil {
+		if !errors.Is(err, ErrModelAliasNotFound) {
+			return "", fmt.Errorf("failed to resolve model alias: %w", err)
+		}
+		return model, nil
+	}
+
+	if alias.Model == "" {
+		return "", fmt.Errorf("%w: %q has no mapping, ask an admin to configure it", ErrModelAliasNotMapped, model)
+	}
+
+	return alias.Model, nil
+}
+
+// ListAliases lists all model aliases
+func (uc *ModelAliasUseCase) ListAliases(ctx context.Context) ([]*types.ModelAlias, error) {
+	aliases, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// SetAlias creates or updates an alias mapping (empty model leaves the alias unmapped)
+func (uc *ModelAliasUseCase) SetAlias(ctx context.Context, alias, model, description string) (*types.ModelAlias, error) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return nil, fmt.Errorf("alias is required")
+	}
+	model = strings.TrimSpace(model)
+	if model == alias {
+		return nil, fmt.Errorf("alias cannot point to itself")
+	}
+
+	now := time.Now()
+	modelAlias := &types.ModelAlias{
+		Alias:       alias,
+		Model:       model,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if existing, err := uc.repo.GetByAlias(ctx, alias); err == nil {
+		modelAlias.CreatedAt = existing.CreatedAt
+	} else if !errors.Is(err, ErrModelAliasNotFound) {
+		return nil, fmt.Errorf("failed to get model alias: %w", err)
+	}
+
+	if err := uc.repo.Upsert(ctx, modelAlias); err != nil {
+		return nil, fmt.Errorf("failed to save model alias: %w", err)
+	}
+
+	return modelAlias, nil
+}
+
+// DeleteAlias deletes an alias mapping
+func (uc *ModelAliasUseCase) DeleteAlias(ctx context.Context, alias string) error {
+	if err := uc.repo.Delete(ctx, alias); err != nil {
+		return fmt.Errorf("failed to delete model alias: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+type fakeModelAliasRepo struct {
+	aliases map[string]*types.ModelAlias
+}
+
+func (r *fakeModelAliasRepo) GetByAlias(ctx context.Context, alias string) (*types.ModelAlias, error) {
+	if a, ok := r.aliases[alias]; ok {
+		return a, nil
+	}
+	return nil, ErrModelAliasNotFound
+}
+
+func (r *fakeModelAliasRepo) List(ctx context.Context) ([]*types.ModelAlias, error) {
+	var list []*types.ModelAlias
+	for _, a := range r.aliases {
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+func (r *fakeModelAliasRepo) Upsert(ctx context.Context, alias *types.ModelAlias) error {
+	r.aliases[alias.Alias] = alias
+	return nil
+}
+
+func (r *fakeModelAliasRepo) Delete(ctx context.Context, alias string) error {
+	if _, ok := r.aliases[alias]; !ok {
+		return ErrModelAliasNotFound
+	}
+	delete(r.aliases, alias)
+	return nil
+}
+
+func TestModelAliasUseCase_ResolveModel(t *testing.T) {
+	ctx := context.Background()
+	uc := NewModelAliasUseCase(&fakeModelAliasRepo{aliases: map[string]*types.ModelAlias{}})
+
+	if _, err := uc.SetAlias(ctx, "claude-sonnet-latest", "claude-3-5-sonnet-20241022", ""); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	if _, err := uc.SetAlias(ctx, "gpt-retired", "", "deprecated"); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		model     string
+		want      string
+		wantErrIs error
+	}{
+		{name: "alias resolves", model: "claude-sonnet-latest", want: "claude-3-5-sonnet-20241022"},
+		{name: "concrete model passes through", model: "gpt-4o", want: "gpt-4o"},
+		{name: "mapped alias with empty target", model: "gpt-retired", wantErrIs: ErrModelAliasNotMapped},
+		{name: "unmapped provider-native latest name passes through", model: "claude-3-5-sonnet-latest", want: "claude-3-5-sonnet-latest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uc.ResolveModel(ctx, tt.model)
+			if tt.wantErrIs != nil {
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Fatalf("Expected %v, got %v", tt.wantErrIs, err)
+				}
+				if !strings.Contains(err.Error(), tt.model) {
+					t.Errorf("Expected error to name the alias, got %q", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	// 管理员更新别名后立即生效
+	if _, err := uc.SetAlias(ctx, "claude-sonnet-latest", "claude-sonnet-4-20250514", ""); err != nil {
+		t.Fatalf("SetAlias failed: %v", err)
+	}
+	got, err := uc.ResolveModel(ctx, "claude-sonnet-latest")
+	if err != nil || got != "claude-sonnet-4-20250514" {
+		t.Errorf("Expected updated alias target, got %s (%v)", got, err)
+	}
+}
@@ -0,0 +1,70 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	agentbiz "github.com/lk2023060901/ai-writer-backend/internal/agent/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+
+	"github.com/google/uuid"
+)
+
+// AssistantSeed defines an official assistant; Key is a stable identifier that determines the assistant ID
+type AssistantSeed struct {
+	Key              string
+	Name             string
+	Emoji            string
+	Prompt           string
+	Type             string // "assistant" (default) or "translate"
+	Tags             []string
+	KnowledgeBaseIDs []string
+}
+
+// AssistantSeedID derives a stable assistant ID from a seed key so re-runs resolve to the same row
+func AssistantSeedID(key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("ai-writer:official-assistant:"+key)).String()
+}
+
+// SeedOfficialAssistants creates missing official assistants (owned by agentbiz.SystemOwnerID) and leaves
+// existing ones untouched, including ones an operator deleted. It returns the number of assistants created.
+func (uc *AssistantUseCase) SeedOfficialAssistants(ctx context.Context, seeds []AssistantSeed) (int, error) {
+	created := 0
+	for _, seed := range seeds {
+		if seed.Key == "" {
+			return created, fmt.Errorf("assistant seed %q: key is required", seed.Name)
+		}
+		if seed.Name == "" {
+			return created, fmt.Errorf("assistant seed %s: name is required", seed.Key)
+		}
+
+		id := AssistantSeedID(seed.Key)
+		assistantType := seed.Type
+		if assistantType == "" {
+			assistantType = "assistant"
+		}
+
+		now := time.Now()
+		assistant := &types.Assistant{
+			ID:               id,
+			UserID:           agentbiz.SystemOwnerID,
+			Name:             seed.Name,
+			Emoji:            seed.Emoji,
+			Prompt:           seed.Prompt,
+			Type:             assistantType,
+			Tags:             seed.Tags,
+			KnowledgeBaseIDs: seed.KnowledgeBaseIDs,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		inserted, err := uc.repo.CreateIfAbsent(ctx, assistant)
+		if err != nil {
+			return created, fmt.Errorf("failed to create official assistant %s: %w", seed.Key, err)
+		}
+		if inserted {
+			created++
+		}
+	}
+	return created, nil
+}
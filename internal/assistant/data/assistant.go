@@ -8,6 +8,7 @@ import (
 	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AssistantRepo implements the assistant repository using GORM
@@ -29,6 +30,17 @@ func (r *AssistantRepo) Create(ctx context.Context, assistant *types.Assistant)
 	return nil
 }
 
+// CreateIfAbsent creates the assistant, ignoring ID conflicts (soft-deleted rows still hold their ID)
+func (r *AssistantRepo) CreateIfAbsent(ctx context.Context, assistant *types.Assistant) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).
+		Create(r.toModel(assistant))
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create assistant: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // GetByID retrieves an assistant by ID and user ID
 func (r *AssistantRepo) GetByID(ctx context.Context, id, userID string) (*types.Assistant, error) {
 	var model models.Assistant
@@ -0,0 +1,140 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/models"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+)
+
+// MessageRepo implements the message repository using database wrapper
+type MessageRepo struct {
+	db *database.DB
+}
+
+// NewMessageRepo creates a new message repository
+func NewMessageRepo(db *database.DB) *MessageRepo {
+	return &MessageRepo{db: db}
+}
+
+// Create creates a new message
+func (r *MessageRepo) Create(ctx context.Context, message *types.Message) error {
+	model := r.toModel(message)
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a message by ID
+func (r *MessageRepo) GetByID(ctx context.Context, id string) (*types.Message, error) {
+	var model models.Message
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error; err != nil {
+		if database.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("message not found")
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	return r.toDomain(&model), nil
+}
+
+// ListByTopic lists messages of a topic in chronological order with pagination
+func (r *MessageRepo) ListByTopic(ctx context.Context, topicID string, limit, offset int) ([]*types.Message, error) {
+	var modelList []models.Message
+	if err := r.db.WithContext(ctx).
+		Where("topic_id = ?", topicID).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&modelList).Error; err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	return r.toDomainList(modelList), nil
+}
+
+// ListRecentByTopic returns the newest limit messages of a topic, newest first
+func (r *MessageRepo) ListRecentByTopic(ctx context.Context, topicID string, limit int) ([]*types.Message, error) {
+	var modelList []models.Message
+	if err := r.db.WithContext(ctx).
+		Where("topic_id = ?", topicID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&modelList).Error; err != nil {
+		return nil, fmt.Errorf("failed to list recent messages: %w", err)
+	}
+
+	return r.toDomainList(modelList), nil
+}
+
+// CountByTopic counts messages of a topic
+func (r *MessageRepo) CountByTopic(ctx context.Context, topicID string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("topic_id = ?", topicID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count messages: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteByTopic deletes all messages of a topic
+func (r *MessageRepo) DeleteByTopic(ctx context.Context, topicID string) error {
+	if err := r.db.WithContext(ctx).
+		Where("topic_id = ?", topicID).
+		Delete(&models.Message{}).Error; err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+	return nil
+}
+
+// toModel converts domain message to GORM model
+func (r *MessageRepo) toModel(message *types.Message) *models.Message {
+	blocks := make(models.ContentBlocks, len(message.ContentBlocks))
+	for i, block := range message.ContentBlocks {
+		blocks[i] = models.ContentBlock(block)
+	}
+
+	return &models.Message{
+		ID:            message.ID,
+		TopicID:       message.TopicID,
+		Role:          message.Role,
+		ContentBlocks: blocks,
+		TokenCount:    message.TokenCount,
+		Provider:      message.Provider,
+		Model:         message.Model,
+		CreatedAt:     message.CreatedAt,
+	}
+}
+
+// toDomain converts GORM model to domain message
+func (r *MessageRepo) toDomain(model *models.Message) *types.Message {
+	blocks := make([]types.ContentBlock, len(model.ContentBlocks))
+	for i, block := range model.ContentBlocks {
+		blocks[i] = types.ContentBlock(block)
+	}
+
+	return &types.Message{
+		ID:            model.ID,
+		TopicID:       model.TopicID,
+		Role:          model.Role,
+		ContentBlocks: blocks,
+		TokenCount:    model.TokenCount,
+		Provider:      model.Provider,
+		Model:         model.Model,
+		CreatedAt:     model.CreatedAt,
+	}
+}
+
+// toDomainList converts GORM models to domain messages
+func (r *MessageRepo) toDomainList(modelList []models.Message) []*types.Message {
+	messages := make([]*types.Message, 0, len(modelList))
+	for i := range modelList {
+		messages = append(messages, r.toDomain(&modelList[i]))
+	}
+	return messages
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/models"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+	"gorm.io/gorm/clause"
+)
+
+// ModelAliasRepo implements the model alias repository using database wrapper
+type ModelAliasRepo struct {
+	db *database.DB
+}
+
+// NewModelAliasRepo creates a new model alias repository
+func NewModelAliasRepo(db *database.DB) *ModelAliasRepo {
+	return &ModelAliasRepo{db: db}
+}
+
+// GetByAlias retrieves an alias mapping
+func (r *ModelAliasRepo) GetByAlias(ctx context.Context, alias string) (*types.ModelAlias, error) {
+	var model models.ModelAlias
+	if err := r.db.WithContext(ctx).Where("alias = ?", alias).First(&model).Error; err != nil {
+		if database.IsRecordNotFoundError(err) {
+			return nil, biz.ErrModelAliasNotFound
+		}
+		return nil, fmt.Errorf("failed to get model alias: %w", err)
+	}
+
+	return r.toDomain(&model), nil
+}
+
+// List lists all alias mappings
+func (r *ModelAliasRepo) List(ctx context.Context) ([]*types.ModelAlias, error) {
+	var modelList []models.ModelAlias
+	if err := r.db.WithContext(ctx).Order("alias ASC").Find(&modelList).Error; err != nil {
+		return nil, fmt.Errorf("failed to list model aliases: %w", err)
+	}
+
+	aliases := make([]*types.ModelAlias, 0, len(modelList))
+	for _, model := range modelList {
+		aliases = append(aliases, r.toDomain(&model))
+	}
+
+	return aliases, nil
+}
+
+// Upsert creates or updates an alias mapping
+func (r *ModelAliasRepo) Upsert(ctx context.Context, alias *types.ModelAlias) error {
+	model := &models.ModelAlias{
+		Alias:       alias.Alias,
+		Model:       alias.Model,
+		Description: alias.Description,
+		CreatedAt:   alias.CreatedAt,
+		UpdatedAt:   alias.UpdatedAt,
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "alias"}},
+		DoUpdates: clause.AssignmentColumns([]string{"model", "description", "updated_at"}),
+	}).Create(model).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert model alias: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes an alias mapping
+func (r *ModelAliasRepo) Delete(ctx context.Context, alias string) error {
+	result := r.db.WithContext(ctx).Where("alias = ?", alias).Delete(&models.ModelAlias{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete model alias: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return biz.ErrModelAliasNotFound
+	}
+	return nil
+}
+
+// toDomain converts GORM model to domain model alias
+func (r *ModelAliasRepo) toDomain(model *models.ModelAlias) *types.ModelAlias {
+	return &types.ModelAlias{
+		Alias:       model.Alias,
+		Model:       model.Model,
+		Description: model.Description,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
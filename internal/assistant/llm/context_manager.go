@@ -0,0 +1,198 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	assistantbiz "github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+// ErrTopicNotAccessible 会话不存在或不属于当前用户
+var ErrTopicNotAccessible = errors.New("topic not found")
+
+// messageTokenOverhead 每条消息的固定 token 开销（角色、分隔符等）
+const messageTokenOverhead = 4
+
+// MessageContextManager 基于消息仓储的上下文管理器：从会话中加载最近的历史消息
+type MessageContextManager struct {
+	messageRepo assistantbiz.MessageRepo
+	topicRepo   assistantbiz.TopicRepo
+	maxMessages int
+	tokenBudget int
+}
+
+// NewMessageContextManager 创建上下文管理器（cfg 为 nil 时使用默认历史限制）
+func NewMessageContextManager(messageRepo assistantbiz.MessageRepo, topicRepo assistantbiz.TopicRepo, cfg *OrchestratorConfig) *MessageContextManager {
+	if cfg == nil {
+		cfg = DefaultOrchestratorConfig()
+	}
+	return &MessageContextManager{
+		messageRepo: messageRepo,
+		topicRepo:   topicRepo,
+		maxMessages: cfg.HistoryMaxMessages,
+		tokenBudget: cfg.HistoryTokenBudget,
+	}
+}
+
+// GetHistory 实现 ContextManager 接口
+// 加载最近 limit 条消息，按 user/assistant 交替整理后裁剪到 token 预算内
+func (m *MessageContextManager) GetHistory(ctx context.Context, userID, topicID string, limit int) ([]Message, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	topic, err := m.topicRepo.GetByID(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic: %w", err)
+	}
+	if topic == nil || topic.UserID != userID {
+		return nil, ErrTopicNotAccessible
+	}
+
+	// 一次查询取最新的 limit 条（倒序），再反转为时间正序
+	records, err := m.messageRepo.ListRecentByTopic(ctx, topicID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	slices.Reverse(records)
+
+	return trimToTokenBudget(toHistoryMessages(records), m.tokenBudget), nil
+}
+
+// SaveMessage 实现 ContextManager 接口（仅保存文本内容）
+func (m *MessageContextManager) SaveMessage(ctx context.Context, topicID string, role string, content []ContentBlock) error {
+	if role != "user" && role != "assistant" {
+		return fmt.Errorf("invalid role: %s", role)
+	}
+
+	var blocks []types.ContentBlock
+	for _, block := range content {
+		if block.Type == "text" && block.Text != "" {
+			blocks = append(blocks, types.ContentBlock{Type: "text", Text: block.Text})
+		}
+	}
+	if len(blocks) == 0 {
+		return fmt.Errorf("message has no text content")
+	}
+
+	message := &types.Message{
+		ID:            uuid.New().String(),
+		TopicID:       topicID,
+		Role:          role,
+		ContentBlocks: blocks,
+		CreatedAt:     time.Now(),
+	}
+	if err := m.messageRepo.Create(ctx, message); err != nil {
+		return fmt.Errorf("failed to save message: %w", err)
+	}
+	return nil
+}
+
+// BuildContext 实现 ContextManager 接口：历史消息 + 当前用户消息
+func (m *MessageContextManager) BuildContext(ctx context.Context, userID, topicID string, newMessage string, contentBlocks []types.MessageContentBlock) ([]Message, error) {
+	history, err := m.GetHistory(ctx, userID, topicID, m.maxMessages)
+	if err != nil {
+		return nil, err
+	}
+	return append(history, Message{
+		Role:    "user",
+		Content: convertContentBlocks(newMessage, contentBlocks),
+	}), nil
+}
+
+// toHistoryMessages 将存储的消息转换为服务商消息
+// 只保留文本内容；同一问题的多个服务商回复只保留第一条，没有回复的提问由后续提问替换
+func toHistoryMessages(records []*types.Message) []Message {
+	var messages []Message
+	for _, record := range records {
+		if record.Role != "user" && record.Role != "assistant" {
+			continue
+		}
+
+		var blocks []ContentBlock
+		for _, block := range record.ContentBlocks {
+			if block.Type == "text" && block.Text != "" {
+				blocks = append(blocks, ContentBlock{Type: "text", Text: block.Text})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		message := Message{Role: record.Role, Content: blocks}
+		if last := len(messages) - 1; last >= 0 && messages[last].Role == record.Role {
+			if record.Role == "user" {
+				messages[last] = message
+			}
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// trimToTokenBudget 从最早的消息开始丢弃直到不超过预算（budget <= 0 不限制）
+// 裁剪后的历史总是以 user 消息开头
+func trimToTokenBudget(messages []Message, budget int) []Message {
+	start := 0
+	if budget > 0 {
+		used := 0
+		start = len(messages)
+		for i := len(messages) - 1; i >= 0; i-- {
+			used += estimateMessageTokens(messages[i])
+			if used > budget {
+				break
+			}
+			start = i
+		}
+	}
+
+	for start < len(messages) && messages[start].Role != "user" {
+		start++
+	}
+	return messages[start:]
+}
+
+// trimCurrentMessage 去掉历史末尾与当前提问相同的 user 消息（服务层会在调用编排器前保存用户消息）
+func trimCurrentMessage(history []Message, current string) []Message {
+	last := len(history) - 1
+	if last < 0 || history[last].Role != "user" {
+		return history
+	}
+	var text string
+	for _, block := range history[last].Content {
+		text += block.Text
+	}
+	if text != current {
+		return history
+	}
+	return history[:last]
+}
+
+// estimateMessageTokens 估算消息的 token 数
+func estimateMessageTokens(message Message) int {
+	tokens := messageTokenOverhead
+	for _, block := range message.Content {
+		tokens += estimateTokens(block.Text)
+	}
+	return tokens
+}
+
+// estimateTokens 粗略估算文本 token 数：CJK 字符按 1 个 token，其余按 4 个字符 1 个 token
+func estimateTokens(text string) int {
+	var cjk, other int
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
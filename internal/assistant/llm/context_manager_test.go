@@ -0,0 +1,233 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"go.uber.org/zap"
+)
+
+type fakeTopicRepo struct {
+	topics map[string]*types.Topic
+}
+
+func (r *fakeTopicRepo) Create(ctx context.Context, topic *types.Topic) error { return nil }
+
+func (r *fakeTopicRepo) GetByID(ctx context.Context, id string) (*types.Topic, error) {
+	topic, ok := r.topics[id]
+	if !ok {
+		return nil, errors.New("topic not found")
+	}
+	return topic, nil
+}
+
+func (r *fakeTopicRepo) ListByAssistant(ctx context.Context, assistantID string) ([]*types.Topic, error) {
+	return nil, nil
+}
+
+func (r *fakeTopicRepo) ListByUserID(ctx context.Context, userID string) ([]*types.Topic, error) {
+	return nil, nil
+}
+
+func (r *fakeTopicRepo) Update(ctx context.Context, topic *types.Topic) error { return nil }
+
+func (r *fakeTopicRepo) Delete(ctx context.Context, id string) error { return nil }
+
+func (r *fakeTopicRepo) DeleteByAssistant(ctx context.Context, assistantID string) error { return nil }
+
+// fakeMessageRepo 按插入顺序保存消息，ListByTopic 按时间正序分页，ListRecentByTopic 按时间倒序取最新
+type fakeMessageRepo struct {
+	messages        []*types.Message
+	listRecentCalls int
+}
+
+func (r *fakeMessageRepo) add(topicID, role, text string) {
+	r.messages = append(r.messages, &types.Message{
+		ID:            text,
+		TopicID:       topicID,
+		Role:          role,
+		ContentBlocks: []types.ContentBlock{{Type: "text", Text: text}},
+		CreatedAt:     time.Unix(int64(len(r.messages)), 0),
+	})
+}
+
+func (r *fakeMessageRepo) Create(ctx context.Context, message *types.Message) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *fakeMessageRepo) GetByID(ctx context.Context, id string) (*types.Message, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeMessageRepo) ListByTopic(ctx context.Context, topicID string, limit, offset int) ([]*types.Message, error) {
+	var matched []*types.Message
+	for _, m := range r.messages {
+		if m.TopicID == topicID {
+			matched = append(matched, m)
+		}
+	}
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[offset:end], nil
+}
+
+func (r *fakeMessageRepo) ListRecentByTopic(ctx context.Context, topicID string, limit int) ([]*types.Message, error) {
+	r.listRecentCalls++
+	var matched []*types.Message
+	for _, m := range r.messages {
+		if m.TopicID == topicID {
+			matched = append(matched, m)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].CreatedAt.After(matched[j].CreatedAt) })
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+func (r *fakeMessageRepo) CountByTopic(ctx context.Context, topicID string) (int64, error) {
+	var count int64
+	for _, m := range r.messages {
+		if m.TopicID == topicID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeMessageRepo) DeleteByTopic(ctx context.Context, topicID string) error { return nil }
+
+func newTestContextManager(cfg *OrchestratorConfig) (*MessageContextManager, *fakeMessageRepo) {
+	topics := &fakeTopicRepo{topics: map[string]*types.Topic{"t1": {ID: "t1", UserID: "u1"}}}
+	messages := &fakeMessageRepo{}
+	return NewMessageContextManager(messages, topics, cfg), messages
+}
+
+func messageTexts(messages []Message) []string {
+	texts := make([]string, len(messages))
+	for i, m := range messages {
+		var text strings.Builder
+		for _, block := range m.Content {
+			text.WriteString(block.Text)
+		}
+		texts[i] = m.Role + ":" + text.String()
+	}
+	return texts
+}
+
+func TestChatStreamMulti_PrependsTopicHistory(t *testing.T) {
+	manager, messages := newTestContextManager(nil)
+	messages.add("t1", "user", "什么是 RAG？")
+	messages.add("t1", "assistant", "检索增强生成。")
+	messages.add("t1", "assistant", "另一个服务商的回答") // 同一问题的多服务商回复只保留第一条
+	messages.add("t1", "user", "举个例子")           // 服务层已保存的当前提问
+
+	provider := &fakeProvider{name: "openai", tokens: []string{"ok"}}
+	o := NewOrchestrator(&fakeProviderFactory{providers: map[string]Provider{"p1": provider}},
+		manager, nil, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+		UserID:    "u1",
+		TopicID:   "t1",
+		Message:   "举个例子",
+		Providers: []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStreamMulti failed: %v", err)
+	}
+	collectResponses(ch)
+
+	req := provider.lastRequest()
+	if req == nil {
+		t.Fatal("Expected provider to be called")
+	}
+	want := []string{"user:什么是 RAG？", "assistant:检索增强生成。", "user:举个例子"}
+	if got := messageTexts(req.Messages); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected messages %v, got %v", want, got)
+	}
+}
+
+func TestMessageContextManager_GetHistory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("last messages within limit", func(t *testing.T) {
+		manager, messages := newTestContextManager(nil)
+		for _, text := range []string{"q1", "a1", "q2", "a2", "q3", "a3"} {
+			role := "user"
+			if strings.HasPrefix(text, "a") {
+				role = "assistant"
+			}
+			messages.add("t1", role, text)
+		}
+
+		// 截取后以 assistant 开头的历史会去掉开头的 assistant 消息
+		history, err := manager.GetHistory(ctx, "u1", "t1", 3)
+		if err != nil {
+			t.Fatalf("GetHistory failed: %v", err)
+		}
+		if got := strings.Join(messageTexts(history), "|"); got != "user:q3|assistant:a3" {
+			t.Errorf("Unexpected history %s", got)
+		}
+	})
+
+	t.Run("newest messages in chronological order", func(t *testing.T) {
+		manager, messages := newTestContextManager(nil)
+		for _, text := range []string{"q1", "a1", "q2", "a2", "q3", "a3"} {
+			role := "user"
+			if strings.HasPrefix(text, "a") {
+				role = "assistant"
+			}
+			messages.add("t1", role, text)
+		}
+
+		history, err := manager.GetHistory(ctx, "u1", "t1", 4)
+		if err != nil {
+			t.Fatalf("GetHistory failed: %v", err)
+		}
+		if got := strings.Join(messageTexts(history), "|"); got != "user:q2|assistant:a2|user:q3|assistant:a3" {
+			t.Errorf("Unexpected history %s", got)
+		}
+		if messages.listRecentCalls != 1 {
+			t.Errorf("Expected a single newest-N query, got %d", messages.listRecentCalls)
+		}
+	})
+
+	t.Run("token budget drops oldest turns", func(t *testing.T) {
+		cfg := DefaultOrchestratorConfig()
+		cfg.HistoryTokenBudget = 2*messageTokenOverhead + 8
+		manager, messages := newTestContextManager(cfg)
+		messages.add("t1", "user", strings.Repeat("很长的问题", 10))
+		messages.add("t1", "assistant", strings.Repeat("很长的回答", 10))
+		messages.add("t1", "user", "短问题")
+		messages.add("t1", "assistant", "短回答")
+
+		history, err := manager.GetHistory(ctx, "u1", "t1", 20)
+		if err != nil {
+			t.Fatalf("GetHistory failed: %v", err)
+		}
+		if got := strings.Join(messageTexts(history), "|"); got != "user:短问题|assistant:短回答" {
+			t.Errorf("Unexpected history %s", got)
+		}
+	})
+
+	t.Run("topic of another user", func(t *testing.T) {
+		manager, messages := newTestContextManager(nil)
+		messages.add("t1", "user", "私密问题")
+
+		if _, err := manager.GetHistory(ctx, "u2", "t1", 20); !errors.Is(err, ErrTopicNotAccessible) {
+			t.Errorf("Expected ErrTopicNotAccessible, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	aitypes "github.com/lk2023060901/ai-writer-backend/internal/ai/provider/types"
+	assistantbiz "github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+// requestValidationErrors 由本服务校验产生的错误，错误信息只包含用户输入，可以原样返回给客户端
+// 这些错误重试或降级到其他服务商都无济于事
+var requestValidationErrors = []error{
+	ErrInvalidProviderOptions,
+	ErrInvalidResponseFormat,
+	ErrResponseFormatUnsupported,
+	ErrInvalidStructuredOutput,
+	ErrTooManyProviders,
+	ErrTopicNotAccessible,
+	assistantbiz.ErrModelAliasNotMapped,
+}
+
+// ErrorTypeFileProcessing 聊天附件处理失败（本服务产生，不可重试，换服务商也无济于事）
+const ErrorTypeFileProcessing aitypes.ErrorType = "file_processing_error"
+
+// fileValidationErrors 附件校验错误，错误原因可以附加在客户端提示后（不含附件地址）
+var fileValidationErrors = []error{
+	ErrUnsupportedFileType,
+	ErrFileTooLarge,
+	ErrFileURLNotAllowed,
+}
+
+// clientErrorMessages 各错误类型返回给客户端的提示（不包含服务商原始响应）
+var clientErrorMessages = map[aitypes.ErrorType]string{
+	aitypes.ErrorTypeInvalidRequest:  "The AI provider rejected the request as invalid.",
+	aitypes.ErrorTypeAuthentication:  "The AI provider rejected the configured credentials. Please contact the administrator.",
+	aitypes.ErrorTypePermission:      "The configured credentials are not allowed to use this model. Please contact the administrator.",
+	aitypes.ErrorTypeNotFound:        "The requested model is not available from this provider.",
+	aitypes.ErrorTypeRequestTooLarge: "The request is too large for this model.",
+	aitypes.ErrorTypeRateLimit:       "The AI provider is rate limiting requests. Please try again later.",
+	aitypes.ErrorTypeAPI:             "The AI provider failed to process the request. Please try again later.",
+	aitypes.ErrorTypeOverloaded:      "The AI provider is temporarily overloaded. Please try again later.",
+	aitypes.ErrorTypeTimeout:         "The AI provider did not respond in time. Please try again.",
+	ErrorTypeFileProcessing:          "The attached file could not be processed.",
+}
+
+// DefaultErrorHandler 默认错误处理器：对服务商错误分类，给出可重试判断与对客户端安全的错误信息
+type DefaultErrorHandler struct{}
+
+// NewErrorHandler 创建错误处理器
+func NewErrorHandler() *DefaultErrorHandler {
+	return &DefaultErrorHandler{}
+}
+
+// ClassifyError 按错误类型分类（服务商返回的 HTTP 错误、超时、本服务的请求校验与附件处理错误，其余视为 api_error）
+func ClassifyError(err error) aitypes.ErrorType {
+	if errors.Is(err, ErrFileProcessing) {
+		return ErrorTypeFileProcessing
+	}
+
+	var providerErr *aitypes.ProviderError
+	if errors.As(err, &providerErr) {
+		if providerErr.Type != "" {
+			return providerErr.Type
+		}
+		return aitypes.ErrorTypeFromStatus(providerErr.StatusCode)
+	}
+
+	if isRequestValidationError(err) {
+		return aitypes.ErrorTypeInvalidRequest
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return aitypes.ErrorTypeTimeout
+	}
+
+	return aitypes.ErrorTypeAPI
+}
+
+// HandleError 实现 ErrorHandler 接口：生成 error 事件，原始错误只记录在服务端日志
+func (h *DefaultErrorHandler) HandleError(err error, provider string) *types.ChatResponse {
+	errorType := ClassifyError(err)
+
+	return &types.ChatResponse{
+		Provider:  provider,
+		EventType: "error",
+		Error:     ClientErrorMessage(err),
+		Metadata: map[string]interface{}{
+			"error_type": string(errorType),
+			"retryable":  h.IsRetryable(err),
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// ClientErrorMessage 返回可以下发给客户端的错误提示（本服务的校验错误原样返回，其余按类型给出固定提示）
+func ClientErrorMessage(err error) string {
+	if isRequestValidationError(err) {
+		return err.Error()
+	}
+
+	errorType := ClassifyError(err)
+	if errorType == ErrorTypeFileProcessing {
+		for _, target := range fileValidationErrors {
+			if errors.Is(err, target) {
+				return fmt.Sprintf("%s (%s)", clientErrorMessages[errorType], target.Error())
+			}
+		}
+	}
+	return clientErrorMessages[errorType]
+}
+
+// IsRetryable 实现 ErrorHandler 接口（限流、服务商内部错误、过载与超时可重试）
+func (h *DefaultErrorHandler) IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || isRequestValidationError(err) {
+		return false
+	}
+
+	switch ClassifyError(err) {
+	case aitypes.ErrorTypeRateLimit, aitypes.ErrorTypeAPI, aitypes.ErrorTypeOverloaded, aitypes.ErrorTypeTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// ShouldFallback 实现 ErrorHandler 接口：服务商侧的问题（凭证、可用性）可以降级到其他服务商，请求本身的问题不行
+func (h *DefaultErrorHandler) ShouldFallback(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	switch ClassifyError(err) {
+	case aitypes.ErrorTypeInvalidRequest, aitypes.ErrorTypeRequestTooLarge, ErrorTypeFileProcessing:
+		return false
+	default:
+		return true
+	}
+}
+
+// isRequestValidationError 是否为本服务的请求校验错误
+func isRequestValidationError(err error) bool {
+	for _, target := range requestValidationErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	aitypes "github.com/lk2023060901/ai-writer-backend/internal/ai/provider/types"
+	assistantbiz "github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"go.uber.org/zap"
+)
+
+// fakeFailingProvider 前几次调用依次返回 errs 中的错误，之后正常输出
+type fakeFailingProvider struct {
+	fakeProvider
+	errs []error
+}
+
+func (p *fakeFailingProvider) ChatStream(ctx context.Context, req *ChatRequest) (<-chan StreamEvent, error) {
+	p.mu.Lock()
+	attempt := len(p.requests)
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	if attempt < len(p.errs) {
+		return nil, p.errs[attempt]
+	}
+	eventChan := make(chan StreamEvent, len(p.tokens)+1)
+	eventChan <- StreamEvent{Type: EventStart}
+	for i, token := range p.tokens {
+		eventChan <- StreamEvent{Type: EventToken, Content: token, Index: i}
+	}
+	close(eventChan)
+	return eventChan, nil
+}
+
+func (p *fakeFailingProvider) calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.requests)
+}
+
+func runChatWithErrorHandler(t *testing.T, provider Provider) []*types.ChatResponse {
+	t.Helper()
+	cfg := DefaultOrchestratorConfig()
+	cfg.ProviderRetryBackoff = 0
+	o := NewOrchestrator(&fakeProviderFactory{providers: map[string]Provider{"p1": provider}},
+		nil, nil, nil, NewErrorHandler(), nil, nil, nil, nil, cfg, zap.NewNop())
+
+	ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+		Message:   "hello",
+		Providers: []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStreamMulti failed: %v", err)
+	}
+	return collectResponses(ch)
+}
+
+func TestChatStreamMulti_TranslatesProviderAuthError(t *testing.T) {
+	rawErr := fmt.Errorf("POST http://10.0.0.12:8080/v1/chat/completions: %w",
+		aitypes.NewStatusError("openai", 401, `{"error":{"message":"Incorrect API key provided: sk-live-abc123"}}`))
+	provider := &fakeFailingProvider{fakeProvider: fakeProvider{name: "openai"}, errs: []error{rawErr}}
+
+	responses := runChatWithErrorHandler(t, provider)
+
+	errs := responsesOfType(responses, "error")
+	if len(errs) != 1 {
+		t.Fatalf("Expected one error event, got %d", len(errs))
+	}
+	event := errs[0]
+	for _, secret := range []string{"sk-live-abc123", "10.0.0.12", "/v1/chat/completions"} {
+		if strings.Contains(event.Error, secret) {
+			t.Errorf("Error message leaks %q: %s", secret, event.Error)
+		}
+	}
+	if event.Error != clientErrorMessages[aitypes.ErrorTypeAuthentication] {
+		t.Errorf("Expected authentication message, got %q", event.Error)
+	}
+	if event.Metadata["error_type"] != string(aitypes.ErrorTypeAuthentication) || event.Metadata["retryable"] != false {
+		t.Errorf("Unexpected error metadata %v", event.Metadata)
+	}
+	if event.SessionID == "" || event.Model != "gpt-4o" || event.Provider != "p1" {
+		t.Errorf("Expected error event to carry session and provider, got %+v", event)
+	}
+	if provider.calls() != 1 {
+		t.Errorf("Expected auth errors not to be retried, got %d calls", provider.calls())
+	}
+}
+
+func TestChatStreamMulti_RetriesRetryableProviderError(t *testing.T) {
+	provider := &fakeFailingProvider{
+		fakeProvider: fakeProvider{name: "openai", tokens: []string{"ok"}},
+		errs:         []error{aitypes.NewStatusError("openai", 429, "rate limited")},
+	}
+
+	responses := runChatWithErrorHandler(t, provider)
+
+	if errs := responsesOfType(responses, "error"); len(errs) != 0 {
+		t.Fatalf("Expected retry to succeed, got error %q", errs[0].Error)
+	}
+	if done := responsesOfType(responses, "done"); len(done) != 1 || done[0].Content != "ok" {
+		t.Errorf("Expected done event after retry, got %+v", done)
+	}
+	if provider.calls() != 2 {
+		t.Errorf("Expected 2 calls, got %d", provider.calls())
+	}
+}
+
+func TestDefaultErrorHandler_Classification(t *testing.T) {
+	h := NewErrorHandler()
+
+	tests := []struct {
+		name          string
+		err           error
+		wantType      aitypes.ErrorType
+		wantRetryable bool
+		wantFallback  bool
+	}{
+		{name: "rate limit", err: aitypes.NewStatusError("openai", 429, ""), wantType: aitypes.ErrorTypeRateLimit, wantRetryable: true, wantFallback: true},
+		{name: "overloaded", err: aitypes.NewStatusError("anthropic", 529, ""), wantType: aitypes.ErrorTypeOverloaded, wantRetryable: true, wantFallback: true},
+		{name: "bad request", err: aitypes.NewStatusError("openai", 400, ""), wantType: aitypes.ErrorTypeInvalidRequest},
+		{name: "timeout", err: fmt.Errorf("failed to send request: %w", context.DeadlineExceeded), wantType: aitypes.ErrorTypeTimeout, wantRetryable: true, wantFallback: true},
+		{name: "canceled", err: context.Canceled, wantType: aitypes.ErrorTypeAPI},
+		{name: "invalid options", err: fmt.Errorf("%w: temperature out of range", ErrInvalidProviderOptions), wantType: aitypes.ErrorTypeInvalidRequest},
+		{name: "topic not accessible", err: fmt.Errorf("failed to build messages: %w", ErrTopicNotAccessible), wantType: aitypes.ErrorTypeInvalidRequest},
+		{name: "unmapped alias", err: fmt.Errorf("%w: %q", assistantbiz.ErrModelAliasNotMapped, "gpt-latest"), wantType: aitypes.ErrorTypeInvalidRequest},
+		{name: "file download failed", err: fmt.Errorf("%w: a.pdf: failed to download file: status 500", ErrFileProcessing), wantType: ErrorTypeFileProcessing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.wantType {
+				t.Errorf("ClassifyError: expected %s, got %s", tt.wantType, got)
+			}
+			if got := h.IsRetryable(tt.err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable: expected %v, got %v", tt.wantRetryable, got)
+			}
+			if got := h.ShouldFallback(tt.err); got != tt.wantFallback {
+				t.Errorf("ShouldFallback: expected %v, got %v", tt.wantFallback, got)
+			}
+		})
+	}
+
+	// 本服务的校验错误原样返回，便于用户修正请求
+	validationErr := fmt.Errorf("%w: temperature out of range", ErrInvalidProviderOptions)
+	if resp := h.HandleError(validationErr, "p1"); resp.Error != validationErr.Error() {
+		t.Errorf("Expected validation error to be passed through, got %q", resp.Error)
+	}
+
+	// 附件错误只返回固定提示与校验原因，不暴露附件地址
+	fileErr := fmt.Errorf("%w: a.pdf: %w: host 10.0.0.5:8080", ErrFileProcessing, ErrFileURLNotAllowed)
+	resp := h.HandleError(fileErr, "p1")
+	if strings.Contains(resp.Error, "10.0.0.5") || !strings.Contains(resp.Error, ErrFileURLNotAllowed.Error()) {
+		t.Errorf("Unexpected file error message %q", resp.Error)
+	}
+	if resp.Metadata["error_type"] != string(ErrorTypeFileProcessing) || resp.Metadata["retryable"] != false {
+		t.Errorf("Unexpected file error metadata %v", resp.Metadata)
+	}
+}
@@ -0,0 +1,242 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+)
+
+var (
+	// ErrUnsupportedFileType 附件类型不支持文本提取
+	ErrUnsupportedFileType = errors.New("unsupported file type")
+	// ErrFileTooLarge 附件超过大小上限
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrFileURLNotAllowed 附件地址不在允许的范围内
+	ErrFileURLNotAllowed = errors.New("file url not allowed")
+	// ErrFileProcessing 聊天附件处理失败（下载或提取文本出错），客户端按 file_processing_error 处理
+	ErrFileProcessing = errors.New("file processing failed")
+)
+
+// fileFetchTimeout 下载附件的超时时间
+const fileFetchTimeout = 60 * time.Second
+
+// truncatedFileSuffix 附件文本被截断时追加的提示
+const truncatedFileSuffix = "\n……（附件内容过长，已截断）"
+
+// DocumentFileProcessor 附件处理器：下载聊天附件并通过知识库的文档处理器提取文本
+type DocumentFileProcessor struct {
+	processor    biz.DocumentProcessor
+	httpClient   *http.Client
+	maxSize      int64
+	maxChars     int
+	allowedHosts map[string]bool
+}
+
+// NewDocumentFileProcessor 创建附件处理器（cfg 为 nil 时使用默认限制）
+// 只下载 FileAllowedHosts 中的主机，未配置时拒绝所有附件地址
+func NewDocumentFileProcessor(processor biz.DocumentProcessor, cfg *OrchestratorConfig) *DocumentFileProcessor {
+	if cfg == nil {
+		cfg = DefaultOrchestratorConfig()
+	}
+
+	allowedHosts := make(map[string]bool, len(cfg.FileAllowedHosts))
+	for _, host := range cfg.FileAllowedHosts {
+		allowedHosts[strings.ToLower(host)] = true
+	}
+
+	p := &DocumentFileProcessor{
+		processor:    processor,
+		maxSize:      cfg.FileMaxSize,
+		maxChars:     cfg.FileContentMaxChars,
+		allowedHosts: allowedHosts,
+	}
+	p.httpClient = &http.Client{
+		Timeout: fileFetchTimeout,
+		// 重定向的每一跳都重新校验地址，避免经允许的主机跳转到内网地址
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return p.checkURL(req.URL)
+		},
+	}
+	return p
+}
+
+// ProcessFile 实现 FileProcessor 接口：校验地址与类型，下载后提取文本并按上限截断
+func (p *DocumentFileProcessor) ProcessFile(ctx context.Context, fileURL string) (*ProcessedFile, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFileURLNotAllowed, fileURL)
+	}
+	if err := p.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	fileType := strings.ToLower(strings.TrimPrefix(path.Ext(u.Path), "."))
+	if !p.supportsFileType(fileType) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFileType, fileType)
+	}
+
+	data, err := p.download(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := p.processor.ExtractText(ctx, data, fileType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract text: %w", err)
+	}
+
+	return &ProcessedFile{
+		URL:      fileURL,
+		MimeType: contentTypeForFileType(fileType),
+		Size:     int64(len(data)),
+		Content:  truncateRunes(strings.TrimSpace(content), p.maxChars),
+	}, nil
+}
+
+// SupportedMimeTypes 实现 FileProcessor 接口（文档处理器未声明支持的类型时返回空）
+func (p *DocumentFileProcessor) SupportedMimeTypes() []string {
+	aware, ok := p.processor.(biz.FileTypeAwareProcessor)
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var mimeTypes []string
+	for fileType := range aware.SupportedFileTypes() {
+		mimeType := contentTypeForFileType(fileType)
+		if !seen[mimeType] {
+			seen[mimeType] = true
+			mimeTypes = append(mimeTypes, mimeType)
+		}
+	}
+	sort.Strings(mimeTypes)
+	return mimeTypes
+}
+
+// checkURL 校验附件地址：仅允许 http/https，且主机必须在允许列表中
+func (p *DocumentFileProcessor) checkURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s", ErrFileURLNotAllowed, u.Redacted())
+	}
+	if !p.allowedHosts[strings.ToLower(u.Host)] {
+		return fmt.Errorf("%w: host %s", ErrFileURLNotAllowed, u.Host)
+	}
+	return nil
+}
+
+// supportsFileType 文档处理器声明了支持的类型时按其校验，否则交给 ExtractText 判断
+func (p *DocumentFileProcessor) supportsFileType(fileType string) bool {
+	if fileType == "" {
+		return false
+	}
+	aware, ok := p.processor.(biz.FileTypeAwareProcessor)
+	if !ok {
+		return true
+	}
+	_, supported := aware.SupportedFileTypes()[fileType]
+	return supported
+}
+
+// download 下载附件，超过大小上限时中止
+func (p *DocumentFileProcessor) download(ctx context.Context, fileURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+	if p.maxSize > 0 && resp.ContentLength > p.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, max %d", ErrFileTooLarge, resp.ContentLength, p.maxSize)
+	}
+
+	reader := io.Reader(resp.Body)
+	if p.maxSize > 0 {
+		reader = io.LimitReader(resp.Body, p.maxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if p.maxSize > 0 && int64(len(data)) > p.maxSize {
+		return nil, fmt.Errorf("%w: max %d bytes", ErrFileTooLarge, p.maxSize)
+	}
+	return data, nil
+}
+
+// processFileBlocks 将 file 内容块替换为提取出的文本（未配置文件处理器时原样返回）
+func (o *DefaultOrchestrator) processFileBlocks(ctx context.Context, blocks []types.MessageContentBlock) ([]types.MessageContentBlock, error) {
+	if o.fileProcessor == nil {
+		return blocks, nil
+	}
+
+	processed := make([]types.MessageContentBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Type != "file" {
+			processed = append(processed, block)
+			continue
+		}
+
+		name := block.FileName
+		if name == "" {
+			name = path.Base(block.FileURL)
+		}
+		file, err := o.fileProcessor.ProcessFile(ctx, block.FileURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFileProcessing, name, err)
+		}
+		processed = append(processed, types.MessageContentBlock{
+			Type: "text",
+			Text: fmt.Sprintf("以下是附件《%s》的内容：\n\n%s", name, file.Content),
+		})
+	}
+	return processed, nil
+}
+
+// contentTypeForFileType 按扩展名返回 MIME 类型
+func contentTypeForFileType(fileType string) string {
+	switch fileType {
+	case "txt":
+		return "text/plain"
+	case "md":
+		return "text/markdown"
+	}
+	if mimeType := mime.TypeByExtension("." + fileType); mimeType != "" {
+		mimeType, _, _ = strings.Cut(mimeType, ";")
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// truncateRunes 按字符数截断文本（maxChars <= 0 不截断）
+func truncateRunes(text string, maxChars int) string {
+	if maxChars <= 0 {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	return string(runes[:maxChars]) + truncatedFileSuffix
+}
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/processor"
+	"go.uber.org/zap"
+)
+
+// serveFiles 提供测试附件下载（路径 -> 内容）
+func serveFiles(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChatStreamMulti_InjectsAttachedFileContent(t *testing.T) {
+	server := serveFiles(t, map[string]string{"/files/notes.txt": "第三季度营收增长 12%。"})
+	serverURL, _ := url.Parse(server.URL)
+	cfg := DefaultOrchestratorConfig()
+	cfg.FileAllowedHosts = []string{serverURL.Host}
+	fileProcessor := NewDocumentFileProcessor(processor.NewDocumentProcessor(), cfg)
+
+	provider := &fakeProvider{name: "openai", tokens: []string{"ok"}}
+	o := NewOrchestrator(&fakeProviderFactory{providers: map[string]Provider{"p1": provider}},
+		nil, nil, fileProcessor, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+		Message: "总结一下附件",
+		ContentBlocks: []types.MessageContentBlock{
+			{Type: "file", FileURL: server.URL + "/files/notes.txt", FileName: "notes.txt"},
+		},
+		Providers: []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStreamMulti failed: %v", err)
+	}
+	collectResponses(ch)
+
+	req := provider.lastRequest()
+	if req == nil || len(req.Messages) != 1 {
+		t.Fatalf("Expected a single user message, got %+v", req)
+	}
+	blocks := req.Messages[0].Content
+	if len(blocks) != 2 || blocks[1].Type != "text" {
+		t.Fatalf("Expected question and file text blocks, got %+v", blocks)
+	}
+	if !strings.Contains(blocks[1].Text, "notes.txt") || !strings.Contains(blocks[1].Text, "第三季度营收增长 12%。") {
+		t.Errorf("Expected file content to be injected, got %q", blocks[1].Text)
+	}
+}
+
+func TestChatStreamMulti_RejectsUnprocessableFile(t *testing.T) {
+	cfg := DefaultOrchestratorConfig()
+	cfg.FileAllowedHosts = []string{"example.com"}
+	fileProcessor := NewDocumentFileProcessor(processor.NewDocumentProcessor(), cfg)
+	o := NewOrchestrator(&fakeProviderFactory{providers: map[string]Provider{}},
+		nil, nil, fileProcessor, nil, nil, nil, nil, nil, nil, zap.NewNop())
+
+	_, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+		Message:       "看看这个",
+		ContentBlocks: []types.MessageContentBlock{{Type: "file", FileURL: "http://example.com/run.exe"}},
+		Providers:     []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+	})
+	if !errors.Is(err, ErrUnsupportedFileType) || !errors.Is(err, ErrFileProcessing) {
+		t.Errorf("Expected ErrUnsupportedFileType wrapped in ErrFileProcessing, got %v", err)
+	}
+	if ClassifyError(err) != ErrorTypeFileProcessing || NewErrorHandler().IsRetryable(err) {
+		t.Errorf("Expected non-retryable file processing error, got %s", ClassifyError(err))
+	}
+}
+
+func TestDocumentFileProcessor_ProcessFile(t *testing.T) {
+	server := serveFiles(t, map[string]string{
+		"/long.md":  strings.Repeat("字", 30),
+		"/big.txt":  strings.Repeat("a", 128),
+		"/small.md": "# 标题",
+	})
+	serverURL, _ := url.Parse(server.URL)
+	ctx := context.Background()
+
+	cfg := DefaultOrchestratorConfig()
+	cfg.FileContentMaxChars = 10
+	cfg.FileMaxSize = 100
+	cfg.FileAllowedHosts = []string{serverURL.Host}
+	p := NewDocumentFileProcessor(processor.NewDocumentProcessor(), cfg)
+
+	t.Run("content truncated to budget", func(t *testing.T) {
+		file, err := p.ProcessFile(ctx, server.URL+"/long.md")
+		if err != nil {
+			t.Fatalf("ProcessFile failed: %v", err)
+		}
+		if file.Content != strings.Repeat("字", 10)+truncatedFileSuffix || file.MimeType != "text/markdown" {
+			t.Errorf("Unexpected processed file %+v", file)
+		}
+	})
+
+	t.Run("file over size limit", func(t *testing.T) {
+		if _, err := p.ProcessFile(ctx, server.URL+"/big.txt"); !errors.Is(err, ErrFileTooLarge) {
+			t.Errorf("Expected ErrFileTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("host allowlist", func(t *testing.T) {
+		cfg := DefaultOrchestratorConfig()
+		cfg.FileAllowedHosts = []string{"files.internal:9000"}
+		restricted := NewDocumentFileProcessor(processor.NewDocumentProcessor(), cfg)
+		if _, err := restricted.ProcessFile(ctx, server.URL+"/small.md"); !errors.Is(err, ErrFileURLNotAllowed) {
+			t.Errorf("Expected ErrFileURLNotAllowed, got %v", err)
+		}
+
+		cfg.FileAllowedHosts = []string{serverURL.Host}
+		allowed := NewDocumentFileProcessor(processor.NewDocumentProcessor(), cfg)
+		if _, err := allowed.ProcessFile(ctx, server.URL+"/small.md"); err != nil {
+			t.Errorf("Expected allowed host to succeed, got %v", err)
+		}
+	})
+
+	t.Run("empty allowlist denies all", func(t *testing.T) {
+		denyAll := NewDocumentFileProcessor(processor.NewDocumentProcessor(), nil)
+		if _, err := denyAll.ProcessFile(ctx, server.URL+"/small.md"); !errors.Is(err, ErrFileURLNotAllowed) {
+			t.Errorf("Expected ErrFileURLNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("redirect to disallowed host", func(t *testing.T) {
+		internal := serveFiles(t, map[string]string{"/secret.md": "内网数据"})
+		redirector := httptest.NewServer(http.RedirectHandler(internal.URL+"/secret.md", http.StatusFound))
+		t.Cleanup(redirector.Close)
+		redirectorURL, _ := url.Parse(redirector.URL)
+
+		cfg := DefaultOrchestratorConfig()
+		cfg.FileAllowedHosts = []string{redirectorURL.Host}
+		p := NewDocumentFileProcessor(processor.NewDocumentProcessor(), cfg)
+		if _, err := p.ProcessFile(ctx, redirector.URL+"/file.md"); !errors.Is(err, ErrFileURLNotAllowed) {
+			t.Errorf("Expected redirect to be rejected with ErrFileURLNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("non-http scheme", func(t *testing.T) {
+		if _, err := p.ProcessFile(ctx, "file:///etc/passwd.txt"); !errors.Is(err, ErrFileURLNotAllowed) {
+			t.Errorf("Expected ErrFileURLNotAllowed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// validateJSONSchema 按 JSON Schema 子集校验已解析的 JSON 值
+// 支持：type（单个或数组）、enum、properties、required、additionalProperties（false）、items、minItems、maxItems
+func validateJSONSchema(value interface{}, schema map[string]interface{}, path string) error {
+	if path == "" {
+		path = "$"
+	}
+
+	if typeSpec, ok := schema["type"]; ok {
+		if err := checkSchemaType(value, typeSpec, path); err != nil {
+			return err
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !enumContains(enum, value) {
+		return fmt.Errorf("%s: value %v is not one of %v", path, value, enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return validateSchemaObject(v, schema, path)
+	case []interface{}:
+		return validateSchemaArray(v, schema, path)
+	}
+	return nil
+}
+
+// validateSchemaObject 校验对象的 required、properties 与 additionalProperties
+func validateSchemaObject(obj map[string]interface{}, schema map[string]interface{}, path string) error {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, exists := obj[key]; !exists {
+				return fmt.Errorf("%s: missing required property %q", path, key)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		propSchema, known := properties[key].(map[string]interface{})
+		if !known {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				return fmt.Errorf("%s: unexpected property %q", path, key)
+			}
+			continue
+		}
+		if err := validateJSONSchema(obj[key], propSchema, path+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSchemaArray 校验数组长度与元素
+func validateSchemaArray(arr []interface{}, schema map[string]interface{}, path string) error {
+	if minItems, ok := schema["minItems"].(float64); ok && float64(len(arr)) < minItems {
+		return fmt.Errorf("%s: expected at least %d items, got %d", path, int(minItems), len(arr))
+	}
+	if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(arr)) > maxItems {
+		return fmt.Errorf("%s: expected at most %d items, got %d", path, int(maxItems), len(arr))
+	}
+
+	itemSchema, ok := schema["items"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for i, item := range arr {
+		if err := validateJSONSchema(item, itemSchema, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchemaType 校验 type 关键字
+func checkSchemaType(value interface{}, typeSpec interface{}, path string) error {
+	var allowed []string
+	switch t := typeSpec.(type) {
+	case string:
+		allowed = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				allowed = append(allowed, s)
+			}
+		}
+	}
+
+	for _, typeName := range allowed {
+		if matchesSchemaType(value, typeName) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: expected type %s, got %s", path, strings.Join(allowed, "|"), jsonTypeName(value))
+}
+
+// matchesSchemaType 值是否为指定的 JSON Schema 类型
+func matchesSchemaType(value interface{}, typeName string) bool {
+	switch typeName {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+// jsonTypeName 返回 JSON 值的类型名（用于错误信息）
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// enumContains enum 中是否包含该值（按 JSON 语义比较标量）
+func enumContains(enum []interface{}, value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	for _, candidate := range enum {
+		switch candidate.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -24,15 +24,25 @@ func (ka *KnowledgeAdapter) SearchDocuments(
 	kbID, userID, query string,
 	topK int,
 ) ([]*KnowledgeSearchResult, error) {
+	results, _, err := ka.SearchDocumentsWithTemplate(ctx, kbID, userID, query, topK)
+	return results, err
+}
+
+// SearchDocumentsWithTemplate 实现 KnowledgeTemplateSearcher 接口，同时返回知识库自定义的注入模板
+func (ka *KnowledgeAdapter) SearchDocumentsWithTemplate(
+	ctx context.Context,
+	kbID, userID, query string,
+	topK int,
+) ([]*KnowledgeSearchResult, string, error) {
 	// 调用 knowledge 模块的搜索功能
-	results, err := ka.docUseCase.SearchDocuments(ctx, kbID, userID, query, topK)
+	outcome, err := ka.docUseCase.SearchDocumentsWithOutcome(ctx, kbID, userID, query, topK)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// 转换 biz.SearchResult 到 llm.KnowledgeSearchResult
-	converted := make([]*KnowledgeSearchResult, len(results))
-	for i, result := range results {
+	converted := make([]*KnowledgeSearchResult, len(outcome.Results))
+	for i, result := range outcome.Results {
 		converted[i] = &KnowledgeSearchResult{
 			DocumentID: result.DocumentID,
 			Content:    result.Content,
@@ -41,5 +51,9 @@ func (ka *KnowledgeAdapter) SearchDocuments(
 		}
 	}
 
-	return converted, nil
+	var knowledgeTemplate string
+	if outcome.KnowledgeTemplate != nil {
+		knowledgeTemplate = *outcome.KnowledgeTemplate
+	}
+	return converted, knowledgeTemplate, nil
 }
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// knowledgeTemplateData 知识库注入模板数据
+type knowledgeTemplateData struct {
+	Query   string
+	Results []knowledgeTemplateResult
+}
+
+// knowledgeTemplateResult 模板中的单条搜索结果
+type knowledgeTemplateResult struct {
+	Index      int // 从 1 开始
+	Score      float32
+	FileName   string
+	DocumentID string
+	Content    string
+	Metadata   map[string]interface{}
+}
+
+// parseKnowledgeTemplate 解析知识库注入模板（为空时使用默认模板）
+func parseKnowledgeTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultKnowledgeTemplate
+	}
+
+	tmpl, err := template.New("knowledge").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid knowledge template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderKnowledgeContext 使用模板渲染知识库搜索结果
+func renderKnowledgeContext(tmpl *template.Template, query string, results []*KnowledgeSearchResult) (string, error) {
+	data := knowledgeTemplateData{
+		Query:   query,
+		Results: make([]knowledgeTemplateResult, len(results)),
+	}
+	for i, result := range results {
+		fileName := "未知文档"
+		if result.Metadata != nil {
+			if name, ok := result.Metadata["file_name"].(string); ok {
+				fileName = name
+			}
+		}
+		data.Results[i] = knowledgeTemplateResult{
+			Index:      i + 1,
+			Score:      result.Score,
+			FileName:   fileName,
+			DocumentID: result.DocumentID,
+			Content:    result.Content,
+			Metadata:   result.Metadata,
+		}
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("failed to render knowledge template: %w", err)
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+var testKnowledgeResults = []*KnowledgeSearchResult{
+	{DocumentID: "doc-1", Content: "Go is a compiled language.", Score: 0.91, Metadata: map[string]interface{}{"file_name": "go.md"}},
+	{DocumentID: "doc-2", Content: "Rust has no GC.", Score: 0.5},
+}
+
+func TestRenderKnowledgeContext_DefaultTemplateMatchesLegacyFormat(t *testing.T) {
+	tmpl, err := parseKnowledgeTemplate("")
+	if err != nil {
+		t.Fatalf("parseKnowledgeTemplate failed: %v", err)
+	}
+
+	got, err := renderKnowledgeContext(tmpl, "query", testKnowledgeResults)
+	if err != nil {
+		t.Fatalf("renderKnowledgeContext failed: %v", err)
+	}
+
+	want := "以下是知识库中的相关内容：\n\n" +
+		"1. [相似度: 0.91] 来自文档: go.md\nGo is a compiled language.\n\n" +
+		"2. [相似度: 0.50] 来自文档: 未知文档\nRust has no GC.\n\n"
+	if got != want {
+		t.Errorf("Default template output mismatch:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestChatStreamMulti_CustomKnowledgeTemplate(t *testing.T) {
+	customTemplate := `Context for "{{.Query}}":
+{{range .Results}}[{{.Index}}] {{.FileName}} ({{printf "%.1f" .Score}}): {{.Content}}
+{{end}}`
+	wantText := "Context for \"what is go\":\n" +
+		"[1] go.md (0.9): Go is a compiled language.\n" +
+		"[2] 未知文档 (0.5): Rust has no GC.\n"
+
+	tests := []struct {
+		name         string
+		role         string
+		systemPrompt string
+		wantSystem   string
+		wantMessages int
+	}{
+		{name: "user message", role: KnowledgeContextRoleUser, systemPrompt: "Be brief.", wantSystem: "Be brief.", wantMessages: 2},
+		{name: "system message", role: KnowledgeContextRoleSystem, systemPrompt: "Be brief.", wantSystem: "Be brief.\n\n" + wantText, wantMessages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{name: "openai", tokens: []string{"ok"}}
+			cfg := DefaultOrchestratorConfig()
+			cfg.KnowledgeTemplate = customTemplate
+			cfg.KnowledgeContextRole = tt.role
+			searcher := &fakeKnowledgeSearcher{results: testKnowledgeResults}
+			o := newTestOrchestrator(cfg, map[string]Provider{"p1": provider}, searcher, nil)
+
+			ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+				Message:         "what is go",
+				KnowledgeBaseID: "kb-1",
+				SystemPrompt:    tt.systemPrompt,
+				Providers:       []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+			})
+			if err != nil {
+				t.Fatalf("ChatStreamMulti failed: %v", err)
+			}
+			collectResponses(ch)
+
+			req := provider.lastRequest()
+			if req == nil {
+				t.Fatal("Expected provider to be called")
+			}
+			if req.SystemPrompt != tt.wantSystem {
+				t.Errorf("System prompt mismatch:\ngot:  %q\nwant: %q", req.SystemPrompt, tt.wantSystem)
+			}
+			if len(req.Messages) != tt.wantMessages {
+				t.Fatalf("Expected %d messages, got %d", tt.wantMessages, len(req.Messages))
+			}
+			if tt.role == KnowledgeContextRoleUser {
+				knowledge := req.Messages[1]
+				if knowledge.Role != "user" || knowledge.Content[0].Text != wantText {
+					t.Errorf("Knowledge message mismatch: role=%s text=%q", knowledge.Role, knowledge.Content[0].Text)
+				}
+			}
+		})
+	}
+}
+
+// fakeTemplateKnowledgeSearcher 返回知识库自定义注入模板的搜索器
+type fakeTemplateKnowledgeSearcher struct {
+	fakeKnowledgeSearcher
+	template string
+}
+
+func (s *fakeTemplateKnowledgeSearcher) SearchDocumentsWithTemplate(ctx context.Context, kbID, userID, query string, topK int) ([]*KnowledgeSearchResult, string, error) {
+	return s.results, s.template, s.err
+}
+
+func TestChatStreamMulti_KnowledgeBaseTemplate(t *testing.T) {
+	globalTemplate := "global:{{range .Results}} {{.DocumentID}}{{end}}"
+
+	tests := []struct {
+		name       string
+		kbTemplate string
+		want       string
+	}{
+		{name: "knowledge base template", kbTemplate: "kb:{{range .Results}} {{.Index}}{{end}}", want: "kb: 1 2"},
+		{name: "empty falls back to global", kbTemplate: "", want: "global: doc-1 doc-2"},
+		{name: "invalid falls back to global", kbTemplate: "{{range .Results}", want: "global: doc-1 doc-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{name: "openai", tokens: []string{"ok"}}
+			cfg := DefaultOrchestratorConfig()
+			cfg.KnowledgeTemplate = globalTemplate
+			searcher := &fakeTemplateKnowledgeSearcher{
+				fakeKnowledgeSearcher: fakeKnowledgeSearcher{results: testKnowledgeResults},
+				template:              tt.kbTemplate,
+			}
+			o := newTestOrchestrator(cfg, map[string]Provider{"p1": provider}, searcher, nil)
+
+			ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+				Message:         "what is go",
+				KnowledgeBaseID: "kb-1",
+				Providers:       []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+			})
+			if err != nil {
+				t.Fatalf("ChatStreamMulti failed: %v", err)
+			}
+			collectResponses(ch)
+
+			req := provider.lastRequest()
+			if req == nil || len(req.Messages) != 2 {
+				t.Fatalf("Expected user and knowledge messages, got %+v", req)
+			}
+			if got := req.Messages[1].Content[0].Text; got != tt.want {
+				t.Errorf("Knowledge text mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKnowledgeTemplate_Invalid(t *testing.T) {
+	if _, err := parseKnowledgeTemplate("{{range .Results}"); err == nil || !strings.Contains(err.Error(), "invalid knowledge template") {
+		t.Fatalf("Expected invalid template error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsNamespace 指标名前缀
+const metricsNamespace = "ai_writer_llm"
+
+// metricLabels 指标标签：服务商类型与解析后的模型名
+// 不使用服务商记录 UUID 与请求中的原始模型名（别名），避免标签基数随配置和用户输入增长
+type metricLabels struct {
+	provider string
+	model    string
+}
+
+// PrometheusMetricsCollector 基于 Prometheus 的指标收集器（按服务商类型/模型打标签）
+type PrometheusMetricsCollector struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	tokens   *prometheus.CounterVec
+}
+
+// NewPrometheusMetricsCollector 创建指标收集器并注册到 registerer（nil 时使用默认注册表）
+// 指标已注册时复用已有的收集器，便于重复初始化
+func NewPrometheusMetricsCollector(registerer prometheus.Registerer) (*PrometheusMetricsCollector, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	requests, err := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "requests_total",
+		Help:      "Total number of chat requests sent to LLM providers.",
+	}, []string{"provider", "model"}))
+	if err != nil {
+		return nil, err
+	}
+
+	latency, err := registerCollector(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "request_duration_seconds",
+		Help:      "Duration of LLM chat requests until the stream completes.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"provider", "model"}))
+	if err != nil {
+		return nil, err
+	}
+
+	errorCount, err := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "errors_total",
+		Help:      "Total number of failed LLM chat requests.",
+	}, []string{"provider", "model", "type"}))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "tokens_total",
+		Help:      "Total number of tokens consumed by LLM chat requests.",
+	}, []string{"provider", "model", "direction"}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrometheusMetricsCollector{
+		requests: requests,
+		latency:  latency,
+		errors:   errorCount,
+		tokens:   tokens,
+	}, nil
+}
+
+// registerCollector 注册指标，已注册时返回已有的实例
+func registerCollector[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return collector, err
+	}
+	return collector, nil
+}
+
+// RecordRequest 记录请求
+func (m *PrometheusMetricsCollector) RecordRequest(provider, model string) {
+	m.requests.WithLabelValues(provider, model).Inc()
+}
+
+// RecordLatency 记录延迟（秒）
+func (m *PrometheusMetricsCollector) RecordLatency(provider, model string, duration float64) {
+	m.latency.WithLabelValues(provider, model).Observe(duration)
+}
+
+// RecordTokens 记录 token 使用
+func (m *PrometheusMetricsCollector) RecordTokens(provider, model string, inputTokens, outputTokens int) {
+	if inputTokens > 0 {
+		m.tokens.WithLabelValues(provider, model, "input").Add(float64(inputTokens))
+	}
+	if outputTokens > 0 {
+		m.tokens.WithLabelValues(provider, model, "output").Add(float64(outputTokens))
+	}
+}
+
+// RecordError 记录错误
+func (m *PrometheusMetricsCollector) RecordError(provider, model string, errType string) {
+	m.errors.WithLabelValues(provider, model, errType).Inc()
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+// fakeTypedProvider 复用同一实现、按数据库服务商类型区分的服务商（如 OpenAI 兼容的 siliconflow）
+type fakeTypedProvider struct {
+	fakeProvider
+	providerType string
+}
+
+func (p *fakeTypedProvider) ProviderType() string {
+	return p.providerType
+}
+
+func TestChatStreamMulti_RecordsMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewPrometheusMetricsCollector(registry)
+	if err != nil {
+		t.Fatalf("NewPrometheusMetricsCollector failed: %v", err)
+	}
+
+	provider := &fakeTypedProvider{fakeProvider: fakeProvider{name: "openai", tokens: []string{"你", "好", "！"}}, providerType: "siliconflow"}
+	resolver := &fakeModelResolver{aliases: map[string]string{"fast": "Qwen/Qwen2.5-7B-Instruct"}}
+	o := NewOrchestrator(&fakeProviderFactory{providers: map[string]Provider{"3f2b6c1e-provider-uuid": provider}},
+		nil, nil, nil, nil, metrics, nil, resolver, nil, nil, zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+			Message:   "hello",
+			Providers: []types.ProviderConfig{{Provider: "3f2b6c1e-provider-uuid", Model: "fast"}},
+		})
+		if err != nil {
+			t.Fatalf("ChatStreamMulti failed: %v", err)
+		}
+		collectResponses(ch)
+	}
+
+	// 按服务商类型与解析后的模型打标签，不使用服务商 UUID 与别名
+	if got := testutil.ToFloat64(metrics.requests.WithLabelValues("siliconflow", "Qwen/Qwen2.5-7B-Instruct")); got != 2 {
+		t.Errorf("Expected 2 requests, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.requests); got != 1 {
+		t.Errorf("Expected one request series, got %d", got)
+	}
+	if got := testutil.ToFloat64(metrics.tokens.WithLabelValues("siliconflow", "Qwen/Qwen2.5-7B-Instruct", "output")); got != 6 {
+		t.Errorf("Expected 6 output tokens, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.latency); got != 1 {
+		t.Errorf("Expected one latency series, got %d", got)
+	}
+	if got := testutil.CollectAndCount(metrics.errors); got != 0 {
+		t.Errorf("Expected no error series, got %d", got)
+	}
+
+	metrics.RecordError("openai", "gpt-4o", "stream_error")
+	if got := testutil.ToFloat64(metrics.errors.WithLabelValues("openai", "gpt-4o", "stream_error")); got != 1 {
+		t.Errorf("Expected 1 error, got %v", got)
+	}
+}
+
+func TestProviderTypeOf(t *testing.T) {
+	if got := ProviderTypeOf(&fakeProvider{name: "anthropic"}); got != "anthropic" {
+		t.Errorf("Expected Name() fallback, got %q", got)
+	}
+	if got := ProviderTypeOf(&fakeTypedProvider{fakeProvider: fakeProvider{name: "openai"}, providerType: "siliconflow"}); got != "siliconflow" {
+		t.Errorf("Expected provider type, got %q", got)
+	}
+}
+
+func TestNewPrometheusMetricsCollector_ReusesRegisteredMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	first, err := NewPrometheusMetricsCollector(registry)
+	if err != nil {
+		t.Fatalf("first NewPrometheusMetricsCollector failed: %v", err)
+	}
+	second, err := NewPrometheusMetricsCollector(registry)
+	if err != nil {
+		t.Fatalf("second NewPrometheusMetricsCollector failed: %v", err)
+	}
+
+	first.RecordRequest("openai", "gpt-4o")
+	if got := testutil.ToFloat64(second.requests.WithLabelValues("openai", "gpt-4o")); got != 1 {
+		t.Errorf("Expected collectors to share registered metrics, got %v", got)
+	}
+}
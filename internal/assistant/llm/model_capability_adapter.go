@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+)
+
+// ModelCapabilityAdapter 适配器：从 knowledge 模块同步的模型信息中读取模型能力
+type ModelCapabilityAdapter struct {
+	aiModelUseCase *biz.AIModelUseCase
+}
+
+// NewModelCapabilityAdapter 创建模型能力适配器
+func NewModelCapabilityAdapter(aiModelUseCase *biz.AIModelUseCase) *ModelCapabilityAdapter {
+	return &ModelCapabilityAdapter{
+		aiModelUseCase: aiModelUseCase,
+	}
+}
+
+// SupportsStream 实现 ModelCapabilityChecker 接口
+// 未同步的模型返回 true（保持默认的流式调用）
+func (a *ModelCapabilityAdapter) SupportsStream(ctx context.Context, providerID, model string) (bool, error) {
+	models, err := a.aiModelUseCase.ListAIModelsByProviderID(ctx, providerID)
+	if err != nil {
+		return true, fmt.Errorf("failed to list provider models: %w", err)
+	}
+
+	for _, m := range models {
+		if m.ModelName == model {
+			return m.SupportsStream, nil
+		}
+	}
+
+	return true, nil
+}
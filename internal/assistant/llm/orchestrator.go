@@ -3,8 +3,11 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
@@ -12,11 +15,22 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrTooManyProviders 请求的服务商数量超过上限
+var ErrTooManyProviders = errors.New("too many providers in request")
+
+// ErrInvalidProviderOptions 服务商特定选项校验失败（reject 策略）
+var ErrInvalidProviderOptions = errors.New("invalid provider options")
+
 // KnowledgeSearcher 知识库搜索接口
 type KnowledgeSearcher interface {
 	SearchDocuments(ctx context.Context, kbID, userID, query string, topK int) ([]*KnowledgeSearchResult, error)
 }
 
+// KnowledgeTemplateSearcher 可选接口：搜索时同时返回知识库自定义的注入模板（为空时使用全局模板）
+type KnowledgeTemplateSearcher interface {
+	SearchDocumentsWithTemplate(ctx context.Context, kbID, userID, query string, topK int) ([]*KnowledgeSearchResult, string, error)
+}
+
 // KnowledgeSearchResult 知识库搜索结果
 type KnowledgeSearchResult struct {
 	DocumentID string
@@ -25,6 +39,16 @@ type KnowledgeSearchResult struct {
 	Metadata   map[string]interface{}
 }
 
+// ModelResolver 模型别名解析接口（将稳定别名解析为具体模型 ID）
+type ModelResolver interface {
+	ResolveModel(ctx context.Context, model string) (string, error)
+}
+
+// ModelCapabilityChecker 模型能力查询接口（能力来源于同步的模型信息）
+type ModelCapabilityChecker interface {
+	SupportsStream(ctx context.Context, providerID, model string) (bool, error)
+}
+
 // DefaultOrchestrator 默认的多服务商编排器实现
 type DefaultOrchestrator struct {
 	providerFactory   ProviderFactory
@@ -34,6 +58,10 @@ type DefaultOrchestrator struct {
 	errorHandler      ErrorHandler
 	metricsCollector  MetricsCollector
 	knowledgeSearcher KnowledgeSearcher
+	modelResolver     ModelResolver
+	modelCapabilities ModelCapabilityChecker
+	config            *OrchestratorConfig
+	knowledgeTemplate *template.Template
 	mu                sync.RWMutex
 	logger            *zap.Logger
 }
@@ -47,8 +75,21 @@ func NewOrchestrator(
 	errorHandler ErrorHandler,
 	metricsCollector MetricsCollector,
 	knowledgeSearcher KnowledgeSearcher,
+	modelResolver ModelResolver,
+	modelCapabilities ModelCapabilityChecker,
+	cfg *OrchestratorConfig,
 	logger *zap.Logger,
 ) *DefaultOrchestrator {
+	if cfg == nil {
+		cfg = DefaultOrchestratorConfig()
+	}
+
+	knowledgeTemplate, err := parseKnowledgeTemplate(cfg.KnowledgeTemplate)
+	if err != nil {
+		logger.Error("Invalid knowledge template, using default", zap.Error(err))
+		knowledgeTemplate, _ = parseKnowledgeTemplate(DefaultKnowledgeTemplate)
+	}
+
 	return &DefaultOrchestrator{
 		providerFactory:   providerFactory,
 		contextManager:    contextManager,
@@ -57,6 +98,10 @@ func NewOrchestrator(
 		errorHandler:      errorHandler,
 		metricsCollector:  metricsCollector,
 		knowledgeSearcher: knowledgeSearcher,
+		modelResolver:     modelResolver,
+		modelCapabilities: modelCapabilities,
+		config:            cfg,
+		knowledgeTemplate: knowledgeTemplate,
 		logger:            logger,
 	}
 }
@@ -81,6 +126,14 @@ func (o *DefaultOrchestrator) GetProvider(providerType string) (Provider, error)
 
 // ChatStreamMulti 并发调用多个服务商
 func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.ChatRequest) (<-chan *types.ChatResponse, error) {
+	// 0. 限制服务商数量（在并发调用前拒绝）
+	if limit := o.config.MaxProvidersPerRequest; limit > 0 && len(req.Providers) > limit {
+		return nil, fmt.Errorf("%w: got %d, max %d", ErrTooManyProviders, len(req.Providers), limit)
+	}
+	if err := validateResponseFormat(req.ResponseFormat); err != nil {
+		return nil, err
+	}
+
 	// 1. 构建上下文（获取历史消息）
 	messages, err := o.buildMessages(ctx, req)
 	if err != nil {
@@ -88,6 +141,8 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 	}
 
 	// 2. 处理知识库搜索（如果提供了 KnowledgeBaseID）
+	var warnings []*types.ChatResponse
+	systemPrompt := req.SystemPrompt
 	if req.KnowledgeBaseID != "" && o.knowledgeSearcher != nil {
 		// 记录知识库搜索开始
 		logger.Info("开始知识库向量搜索",
@@ -96,10 +151,28 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 			zap.String("query", req.Message))
 
 		// 使用请求中的 UserID
-		searchResults, err := o.knowledgeSearcher.SearchDocuments(ctx, req.KnowledgeBaseID, req.UserID, req.Message, 5)
+		searchResults, kbTemplate, err := o.searchKnowledge(ctx, req)
 		if err != nil {
 			logger.Warn("知识库搜索失败", zap.Error(err))
-			o.logger.Warn("Knowledge base search failed", zap.Error(err))
+			o.logger.Warn("Knowledge base search failed",
+				zap.String("policy", o.config.KnowledgeSearchErrorPolicy),
+				zap.Error(err))
+
+			switch o.config.KnowledgeSearchErrorPolicy {
+			case KnowledgeErrorPolicyFail:
+				return nil, fmt.Errorf("knowledge search failed: %w", err)
+			case KnowledgeErrorPolicyWarn:
+				warnings = append(warnings, &types.ChatResponse{
+					EventType: "warning",
+					Content:   "knowledge base context unavailable, answering without it",
+					Error:     "knowledge search failed", // 原始错误（可能含向量库地址）只记录日志
+					Metadata: map[string]interface{}{
+						"code":              "knowledge_search_failed",
+						"knowledge_base_id": req.KnowledgeBaseID,
+					},
+					Timestamp: time.Now(),
+				})
+			}
 		} else {
 			// 记录知识库搜索结果
 			searchResultsJSON, _ := json.Marshal(searchResults)
@@ -109,7 +182,7 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 				zap.String("search_results", string(searchResultsJSON)))
 
 			// 将搜索结果添加到消息中
-			messages = o.appendKnowledgeResults(messages, searchResults)
+			messages, systemPrompt = o.appendKnowledgeResults(messages, systemPrompt, req.Message, o.resolveKnowledgeTemplate(kbTemplate), searchResults)
 		}
 	}
 
@@ -131,6 +204,12 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 	var wg sync.WaitGroup
 	sessionID := generateSessionID()
 
+	// 先发送请求级警告事件（如知识库上下文不可用）
+	for _, warning := range warnings {
+		warning.SessionID = sessionID
+		outputChan <- warning
+	}
+
 	for _, providerConfig := range req.Providers {
 		wg.Add(1)
 
@@ -155,21 +234,50 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 				zap.String("provider_id", pc.Provider),
 				zap.String("provider_name", provider.Name()))
 
+			// 校验服务商特定选项（在发起请求前，按数据库中的服务商类型选择 schema）
+			providerOptions, err := o.checkProviderOptions(ProviderTypeOf(provider), pc.Options)
+			if err != nil {
+				o.sendErrorResponse(outputChan, sessionID, pc.Provider, pc.Model, err)
+				return
+			}
+
+			// 解析模型别名
+			model, err := o.resolveModel(ctx, pc.Model)
+			if err != nil {
+				o.logger.Warn("Failed to resolve model",
+					zap.String("provider_id", pc.Provider),
+					zap.String("model", pc.Model),
+					zap.Error(err))
+				o.sendErrorResponse(outputChan, sessionID, pc.Provider, pc.Model, err)
+				return
+			}
+
+			// 结构化输出需要服务商支持对应格式
+			if err := checkResponseFormatSupport(provider, model, req.ResponseFormat); err != nil {
+				o.sendErrorResponse(outputChan, sessionID, pc.Provider, pc.Model, err)
+				return
+			}
+
+			// 查询模型是否支持流式输出
+			stream := o.supportsStream(ctx, pc.Provider, model)
+
 			// 记录请求
+			labels := metricLabels{provider: ProviderTypeOf(provider), model: model}
 			if o.metricsCollector != nil {
-				o.metricsCollector.RecordRequest(pc.Provider, pc.Model)
+				o.metricsCollector.RecordRequest(labels.provider, labels.model)
 			}
 			startTime := time.Now()
 
 			// 构建请求
 			llmReq := &ChatRequest{
 				Messages:        messages,
-				Model:           pc.Model,
+				Model:           model,
 				Temperature:     pc.Temperature,
 				MaxTokens:       pc.MaxTokens,
-				SystemPrompt:    req.SystemPrompt,
-				Stream:          true,
-				ProviderOptions: pc.Options,
+				SystemPrompt:    systemPrompt,
+				Stream:          stream,
+				ProviderOptions: providerOptions,
+				ResponseFormat:  req.ResponseFormat,
 			}
 
 			// 记录发送给 AI 服务商的完整请求数据
@@ -180,12 +288,13 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 				zap.String("session_id", sessionID),
 				zap.String("request_data", string(llmReqJSON)))
 
-			// 调用服务商流式 API
+			// 调用服务商流式 API（不支持流式的模型回退为非流式调用）
 			o.logger.Info("Calling provider ChatStream",
 				zap.String("provider_id", pc.Provider),
-				zap.String("model", pc.Model))
+				zap.String("model", pc.Model),
+				zap.Bool("stream", stream))
 
-			streamChan, err := provider.ChatStream(ctx, llmReq)
+			streamChan, err := o.startChatWithRetry(ctx, provider, llmReq)
 			if err != nil {
 				o.logger.Error("Provider ChatStream failed",
 					zap.String("provider_id", pc.Provider),
@@ -193,7 +302,7 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 					zap.Error(err))
 				o.sendErrorResponse(outputChan, sessionID, pc.Provider, pc.Model, err)
 				if o.metricsCollector != nil {
-					o.metricsCollector.RecordError(pc.Provider, pc.Model, "stream_error")
+					o.metricsCollector.RecordError(labels.provider, labels.model, "stream_error")
 				}
 				return
 			}
@@ -203,7 +312,7 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 				zap.String("model", pc.Model))
 
 			// 转发流式事件
-			o.forwardStreamEvents(ctx, streamChan, outputChan, sessionID, pc.Provider, pc.Model, startTime)
+			o.forwardStreamEvents(ctx, streamChan, outputChan, sessionID, pc.Provider, pc.Model, labels, startTime)
 
 		}(providerConfig)
 	}
@@ -218,24 +327,136 @@ func (o *DefaultOrchestrator) ChatStreamMulti(ctx context.Context, req *types.Ch
 	return outputChan, nil
 }
 
+// resolveModel 解析模型别名（未配置解析器时原样返回）
+func (o *DefaultOrchestrator) resolveModel(ctx context.Context, model string) (string, error) {
+	if o.modelResolver == nil {
+		return model, nil
+	}
+
+	resolved, err := o.modelResolver.ResolveModel(ctx, model)
+	if err != nil {
+		return "", err
+	}
+	if resolved != model {
+		o.logger.Info("Resolved model alias",
+			zap.String("alias", model),
+			zap.String("model", resolved))
+	}
+	return resolved, nil
+}
+
+// supportsStream 查询模型是否支持流式输出（未配置或查询失败时默认流式）
+func (o *DefaultOrchestrator) supportsStream(ctx context.Context, providerID, model string) bool {
+	if o.modelCapabilities == nil {
+		return true
+	}
+
+	supported, err := o.modelCapabilities.SupportsStream(ctx, providerID, model)
+	if err != nil {
+		o.logger.Warn("Failed to check stream capability, assuming streaming",
+			zap.String("provider_id", providerID),
+			zap.String("model", model),
+			zap.Error(err))
+		return true
+	}
+	return supported
+}
+
+// chatWithoutStream 非流式调用服务商，并将完整结果转换为流式事件（保持客户端接口一致）
+func (o *DefaultOrchestrator) chatWithoutStream(ctx context.Context, provider Provider, req *ChatRequest) (<-chan StreamEvent, error) {
+	completer, ok := provider.(CompletionProvider)
+	if !ok {
+		o.logger.Warn("Provider does not support non-streaming calls, using ChatStream",
+			zap.String("provider", provider.Name()),
+			zap.String("model", req.Model))
+		return provider.ChatStream(ctx, req)
+	}
+
+	completion, err := completer.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := splitContent(completion.Content, o.config.NonStreamChunkSize)
+	eventChan := make(chan StreamEvent, len(chunks)+1)
+	eventChan <- StreamEvent{Type: EventStart}
+	for i, chunk := range chunks {
+		eventChan <- StreamEvent{Type: EventToken, Content: chunk, Index: i}
+	}
+	close(eventChan)
+
+	return eventChan, nil
+}
+
+// splitContent 按字符数拆分内容（size <= 0 时不拆分）
+func splitContent(content string, size int) []string {
+	if content == "" {
+		return nil
+	}
+
+	runes := []rune(content)
+	if size <= 0 || len(runes) <= size {
+		return []string{content}
+	}
+
+	chunks := make([]string, 0, (len(runes)+size-1)/size)
+	for start := 0; start < len(runes); start += size {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[start:end]))
+	}
+	return chunks
+}
+
+// checkProviderOptions 按配置的策略校验服务商选项
+// reject: 存在未知/非法选项时返回错误；warn: 记录警告后原样透传
+func (o *DefaultOrchestrator) checkProviderOptions(providerType string, options map[string]interface{}) (map[string]interface{}, error) {
+	problems := ValidateProviderOptions(providerType, options)
+	if len(problems) == 0 {
+		return options, nil
+	}
+
+	if o.config.ProviderOptionPolicy == OptionPolicyWarn {
+		for _, problem := range problems {
+			o.logger.Warn("Invalid provider option, passing through",
+				zap.String("provider_type", providerType),
+				zap.String("option", problem.Key),
+				zap.String("reason", problem.Reason))
+		}
+		return options, nil
+	}
+
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Error()
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidProviderOptions, strings.Join(messages, "; "))
+}
+
 // buildMessages 构建完整的消息列表
 func (o *DefaultOrchestrator) buildMessages(ctx context.Context, req *types.ChatRequest) ([]Message, error) {
 	var messages []Message
 
 	// 1. 获取历史消息（如果有 TopicID）
-	if req.TopicID != "" && o.contextManager != nil {
-		history, err := o.contextManager.GetHistory(req.TopicID, 20) // 最多 20 条历史
+	if req.TopicID != "" && o.contextManager != nil && o.config.HistoryMaxMessages > 0 {
+		history, err := o.contextManager.GetHistory(ctx, req.UserID, req.TopicID, o.config.HistoryMaxMessages)
 		if err != nil {
 			o.logger.Warn("Failed to get history", zap.Error(err))
 		} else {
-			messages = append(messages, history...)
+			messages = append(messages, trimCurrentMessage(history, req.Message)...)
 		}
 	}
 
 	// 2. 构建当前用户消息
+	content, err := o.buildContentBlocks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
 	userMessage := Message{
 		Role:    "user",
-		Content: o.buildContentBlocks(req),
+		Content: content,
 	}
 
 	messages = append(messages, userMessage)
@@ -244,19 +465,29 @@ func (o *DefaultOrchestrator) buildMessages(ctx context.Context, req *types.Chat
 }
 
 // buildContentBlocks 构建内容块
-func (o *DefaultOrchestrator) buildContentBlocks(req *types.ChatRequest) []ContentBlock {
+// 配置了文件处理器时，附件先提取为文本再注入
+func (o *DefaultOrchestrator) buildContentBlocks(ctx context.Context, req *types.ChatRequest) ([]ContentBlock, error) {
+	contentBlocks, err := o.processFileBlocks(ctx, req.ContentBlocks)
+	if err != nil {
+		return nil, err
+	}
+	return convertContentBlocks(req.Message, contentBlocks), nil
+}
+
+// convertContentBlocks 将用户消息文本与多模态内容块转换为服务商内容块
+func convertContentBlocks(message string, contentBlocks []types.MessageContentBlock) []ContentBlock {
 	var blocks []ContentBlock
 
 	// 1. 添加文本消息
-	if req.Message != "" {
+	if message != "" {
 		blocks = append(blocks, ContentBlock{
 			Type: "text",
-			Text: req.Message,
+			Text: message,
 		})
 	}
 
 	// 2. 添加多模态内容块
-	for _, cb := range req.ContentBlocks {
+	for _, cb := range contentBlocks {
 		switch cb.Type {
 		case "text":
 			blocks = append(blocks, ContentBlock{
@@ -274,7 +505,7 @@ func (o *DefaultOrchestrator) buildContentBlocks(req *types.ChatRequest) []Conte
 			})
 
 		case "file":
-			// TODO: 处理文件上传
+			// 未配置文件处理器时原样交给服务商
 			blocks = append(blocks, ContentBlock{
 				Type:         "file",
 				FileURL:      cb.FileURL,
@@ -286,26 +517,47 @@ func (o *DefaultOrchestrator) buildContentBlocks(req *types.ChatRequest) []Conte
 	return blocks
 }
 
-// appendKnowledgeResults 将知识库搜索结果添加到消息中
-func (o *DefaultOrchestrator) appendKnowledgeResults(messages []Message, results []*KnowledgeSearchResult) []Message {
+// searchKnowledge 搜索知识库，搜索器支持时同时返回知识库自定义的注入模板
+func (o *DefaultOrchestrator) searchKnowledge(ctx context.Context, req *types.ChatRequest) ([]*KnowledgeSearchResult, string, error) {
+	if searcher, ok := o.knowledgeSearcher.(KnowledgeTemplateSearcher); ok {
+		return searcher.SearchDocumentsWithTemplate(ctx, req.KnowledgeBaseID, req.UserID, req.Message, 5)
+	}
+	results, err := o.knowledgeSearcher.SearchDocuments(ctx, req.KnowledgeBaseID, req.UserID, req.Message, 5)
+	return results, "", err
+}
+
+// resolveKnowledgeTemplate 优先使用知识库自定义模板，为空或解析失败时回退到全局模板
+func (o *DefaultOrchestrator) resolveKnowledgeTemplate(text string) *template.Template {
+	if strings.TrimSpace(text) == "" {
+		return o.knowledgeTemplate
+	}
+	tmpl, err := parseKnowledgeTemplate(text)
+	if err != nil {
+		o.logger.Warn("Invalid knowledge base template, using global template", zap.Error(err))
+		return o.knowledgeTemplate
+	}
+	return tmpl
+}
+
+// appendKnowledgeResults 按模板渲染知识库搜索结果，并作为 user 消息追加或合并到系统提示词
+func (o *DefaultOrchestrator) appendKnowledgeResults(messages []Message, systemPrompt, query string, tmpl *template.Template, results []*KnowledgeSearchResult) ([]Message, string) {
 	if len(results) == 0 {
-		return messages
+		return messages, systemPrompt
 	}
 
-	// 构建知识库搜索结果文本
-	knowledgeText := "以下是知识库中的相关内容：\n\n"
-	for i, result := range results {
-		fileName := "未知文档"
-		if result.Metadata != nil {
-			if name, ok := result.Metadata["file_name"].(string); ok {
-				fileName = name
-			}
+	knowledgeText, err := renderKnowledgeContext(tmpl, query, results)
+	if err != nil {
+		o.logger.Warn("Failed to render knowledge template", zap.Error(err))
+		return messages, systemPrompt
+	}
+
+	if o.config.KnowledgeContextRole == KnowledgeContextRoleSystem {
+		if systemPrompt == "" {
+			return messages, knowledgeText
 		}
-		knowledgeText += fmt.Sprintf("%d. [相似度: %.2f] 来自文档: %s\n%s\n\n",
-			i+1, result.Score, fileName, result.Content)
+		return messages, systemPrompt + "\n\n" + knowledgeText
 	}
 
-	// 添加系统消息
 	knowledgeMessage := Message{
 		Role: "user",
 		Content: []ContentBlock{
@@ -316,7 +568,7 @@ func (o *DefaultOrchestrator) appendKnowledgeResults(messages []Message, results
 		},
 	}
 
-	return append(messages, knowledgeMessage)
+	return append(messages, knowledgeMessage), systemPrompt
 }
 
 // appendSearchResults 将搜索结果添加到消息中
@@ -351,6 +603,7 @@ func (o *DefaultOrchestrator) forwardStreamEvents(
 	streamChan <-chan StreamEvent,
 	outputChan chan<- *types.ChatResponse,
 	sessionID, provider, model string,
+	labels metricLabels,
 	startTime time.Time,
 ) {
 	var tokenCount int
@@ -376,7 +629,8 @@ func (o *DefaultOrchestrator) forwardStreamEvents(
 
 				duration := time.Since(startTime).Seconds()
 				if o.metricsCollector != nil {
-					o.metricsCollector.RecordLatency(provider, model, duration)
+					o.metricsCollector.RecordLatency(labels.provider, labels.model, duration)
+					o.metricsCollector.RecordTokens(labels.provider, labels.model, 0, tokenCount)
 				}
 
 				// 记录 AI 服务商的完整流式响应（汇总）
@@ -448,24 +702,30 @@ func (o *DefaultOrchestrator) forwardStreamEvents(
 					Timestamp: time.Now(),
 				}
 
-			case EventError:
-				if o.metricsCollector != nil {
-					o.metricsCollector.RecordError(provider, model, "stream_error")
-				}
+			case EventReasoning:
+				// 推理过程单独转发，不计入回答内容与 token 数
 				outputChan <- &types.ChatResponse{
 					SessionID: sessionID,
 					Provider:  provider,
 					Model:     model,
-					EventType: "error",
-					Error:     event.Error.Error(),
+					EventType: "reasoning",
+					Content:   event.Content,
+					Index:     event.Index,
 					Timestamp: time.Now(),
 				}
+
+			case EventError:
+				if o.metricsCollector != nil {
+					o.metricsCollector.RecordError(labels.provider, labels.model, "stream_error")
+				}
+				o.sendErrorResponse(outputChan, sessionID, provider, model, event.Error)
 				return
 
 			case EventDone:
 				// 服务商发送的完成事件
 				if o.metricsCollector != nil {
-					o.metricsCollector.RecordTokens(provider, model, 0, tokenCount)
+					o.metricsCollector.RecordLatency(labels.provider, labels.model, time.Since(startTime).Seconds())
+					o.metricsCollector.RecordTokens(labels.provider, labels.model, 0, tokenCount)
 				}
 				return
 			}
@@ -473,7 +733,43 @@ func (o *DefaultOrchestrator) forwardStreamEvents(
 	}
 }
 
+// startChat 发起服务商调用
+// 结构化输出需要完整结果校验后再下发，不走流式；不支持流式的模型回退为非流式调用
+func (o *DefaultOrchestrator) startChat(ctx context.Context, provider Provider, req *ChatRequest) (<-chan StreamEvent, error) {
+	switch {
+	case requiresStructuredOutput(req.ResponseFormat):
+		return o.chatStructured(ctx, provider, req)
+	case req.Stream:
+		return provider.ChatStream(ctx, req)
+	default:
+		return o.chatWithoutStream(ctx, provider, req)
+	}
+}
+
+// startChatWithRetry 发起服务商调用，错误处理器判定可重试时按退避间隔重试（仅重试尚未开始输出的调用）
+func (o *DefaultOrchestrator) startChatWithRetry(ctx context.Context, provider Provider, req *ChatRequest) (<-chan StreamEvent, error) {
+	for attempt := 0; ; attempt++ {
+		streamChan, err := o.startChat(ctx, provider, req)
+		if err == nil || o.errorHandler == nil || attempt >= o.config.ProviderMaxRetries || !o.errorHandler.IsRetryable(err) {
+			return streamChan, err
+		}
+
+		o.logger.Warn("Provider call failed, retrying",
+			zap.String("provider", provider.Name()),
+			zap.String("model", req.Model),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(o.config.ProviderRetryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
+
 // sendErrorResponse 发送错误响应
+// 配置了错误处理器时只下发分类后的安全提示，原始错误（可能含服务商响应、内部地址）仅记录日志
 func (o *DefaultOrchestrator) sendErrorResponse(
 	outputChan chan<- *types.ChatResponse,
 	sessionID, provider, model string,
@@ -481,6 +777,14 @@ func (o *DefaultOrchestrator) sendErrorResponse(
 ) {
 	o.logger.Error("Provider error", zap.String("provider", provider), zap.Error(err))
 
+	if o.errorHandler != nil {
+		response := o.errorHandler.HandleError(err, provider)
+		response.SessionID = sessionID
+		response.Model = model
+		outputChan <- response
+		return
+	}
+
 	outputChan <- &types.ChatResponse{
 		SessionID: sessionID,
 		Provider:  provider,
@@ -0,0 +1,89 @@
+package llm
+
+import "time"
+
+// 知识库搜索失败时的处理策略
+const (
+	KnowledgeErrorPolicyProceed = "proceed" // 不带知识库上下文继续（默认）
+	KnowledgeErrorPolicyFail    = "fail"    // 整个请求失败
+	KnowledgeErrorPolicyWarn    = "warn"    // 继续，并向客户端发送 warning 事件
+)
+
+// 知识库上下文注入位置
+const (
+	KnowledgeContextRoleUser   = "user"   // 作为独立的 user 消息（默认）
+	KnowledgeContextRoleSystem = "system" // 合并到系统提示词
+)
+
+// DefaultMaxProvidersPerRequest 单次多服务商请求默认允许的最大服务商数
+const DefaultMaxProvidersPerRequest = 5
+
+// DefaultStructuredOutputMaxRepairs 结构化输出校验失败时默认的修复重试次数
+const DefaultStructuredOutputMaxRepairs = 2
+
+// 会话历史默认加载限制
+const (
+	DefaultHistoryMaxMessages = 20   // 最多加载的历史消息条数
+	DefaultHistoryTokenBudget = 8000 // 历史消息的 token 预算（估算值）
+)
+
+// 聊天附件默认限制
+const (
+	DefaultFileMaxSize         = 10 << 20 // 附件大小上限（10MB）
+	DefaultFileContentMaxChars = 20000    // 注入提示词的附件文本字符数上限
+)
+
+// 服务商调用失败默认重试策略
+const (
+	DefaultProviderMaxRetries   = 1
+	DefaultProviderRetryBackoff = 500 * time.Millisecond
+)
+
+// DefaultKnowledgeTemplate 默认知识库注入模板
+// 可用字段：.Query，.Results（每项含 .Index .Score .FileName .DocumentID .Content .Metadata）
+const DefaultKnowledgeTemplate = `以下是知识库中的相关内容：
+
+{{range .Results}}{{.Index}}. [相似度: {{printf "%.2f" .Score}}] 来自文档: {{.FileName}}
+{{.Content}}
+
+{{end}}`
+
+// OrchestratorConfig 编排器配置
+type OrchestratorConfig struct {
+	ProviderOptionPolicy       string // reject, warn
+	KnowledgeSearchErrorPolicy string // proceed, fail, warn
+	KnowledgeTemplate          string // text/template 格式的知识库注入模板
+	KnowledgeContextRole       string // user, system
+	NonStreamChunkSize         int    // 非流式回退时每个 token 事件的字符数（0 表示整段作为一个事件）
+	MaxProvidersPerRequest     int    // 单次请求允许的最大服务商数（<= 0 表示不限制）
+	StructuredOutputMaxRepairs int    // 结构化输出不合法时的修复重试次数（<= 0 表示不重试）
+	HistoryMaxMessages         int    // 携带的会话历史消息条数上限（<= 0 表示不携带历史）
+	HistoryTokenBudget         int    // 会话历史的 token 预算，超出时丢弃最早的消息（<= 0 表示不限制）
+
+	// 聊天附件
+	FileMaxSize         int64    // 附件大小上限（字节，<= 0 表示不限制）
+	FileContentMaxChars int      // 注入提示词的附件文本字符数上限（<= 0 表示不截断）
+	FileAllowedHosts    []string // 允许下载附件的主机（host[:port]，为空时拒绝所有附件地址）
+
+	// 服务商调用失败重试（仅在错误处理器判定可重试且尚未开始输出时）
+	ProviderMaxRetries   int           // 最多重试次数（<= 0 表示不重试）
+	ProviderRetryBackoff time.Duration // 第 n 次重试前等待 n 倍退避间隔
+}
+
+// DefaultOrchestratorConfig 默认编排器配置
+func DefaultOrchestratorConfig() *OrchestratorConfig {
+	return &OrchestratorConfig{
+		ProviderOptionPolicy:       OptionPolicyReject,
+		KnowledgeSearchErrorPolicy: KnowledgeErrorPolicyProceed,
+		KnowledgeTemplate:          DefaultKnowledgeTemplate,
+		KnowledgeContextRole:       KnowledgeContextRoleUser,
+		MaxProvidersPerRequest:     DefaultMaxProvidersPerRequest,
+		StructuredOutputMaxRepairs: DefaultStructuredOutputMaxRepairs,
+		HistoryMaxMessages:         DefaultHistoryMaxMessages,
+		HistoryTokenBudget:         DefaultHistoryTokenBudget,
+		FileMaxSize:                DefaultFileMaxSize,
+		FileContentMaxChars:        DefaultFileContentMaxChars,
+		ProviderMaxRetries:         DefaultProviderMaxRetries,
+		ProviderRetryBackoff:       DefaultProviderRetryBackoff,
+	}
+}
@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"go.uber.org/zap"
+)
+
+// 测试用服务商实现（仅供 llm 包内的单元测试使用）
+
+type fakeProvider struct {
+	name      string
+	tokens    []string
+	reasoning []string // 在回答 token 之前下发的推理内容
+
+	mu       sync.Mutex
+	requests []*ChatRequest
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) ChatStream(ctx context.Context, req *ChatRequest) (<-chan StreamEvent, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	eventChan := make(chan StreamEvent, len(p.reasoning)+len(p.tokens)+2)
+	eventChan <- StreamEvent{Type: EventStart}
+	for i, content := range p.reasoning {
+		eventChan <- StreamEvent{Type: EventReasoning, Content: content, Index: i}
+	}
+	for i, token := range p.tokens {
+		eventChan <- StreamEvent{Type: EventToken, Content: token, Index: i}
+	}
+	close(eventChan)
+	return eventChan, nil
+}
+
+func (p *fakeProvider) ValidateConfig() error { return nil }
+
+func (p *fakeProvider) SupportedModels() []string { return nil }
+
+func (p *fakeProvider) SupportsMultimodal() bool { return false }
+
+func (p *fakeProvider) lastRequest() *ChatRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.requests) == 0 {
+		return nil
+	}
+	return p.requests[len(p.requests)-1]
+}
+
+type fakeProviderFactory struct {
+	providers map[string]Provider // providerID -> provider
+}
+
+func (f *fakeProviderFactory) CreateProvider(config ProviderConfig) (Provider, error) {
+	provider, ok := f.providers[config.Provider]
+	if !ok {
+		return nil, fmt.Errorf("provider not found: %s", config.Provider)
+	}
+	return provider, nil
+}
+
+// newTestOrchestrator 创建使用 fake 服务商的编排器
+func newTestOrchestrator(cfg *OrchestratorConfig, providers map[string]Provider, knowledgeSearcher KnowledgeSearcher, modelResolver ModelResolver) *DefaultOrchestrator {
+	return NewOrchestrator(
+		&fakeProviderFactory{providers: providers},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		knowledgeSearcher,
+		modelResolver,
+		nil,
+		cfg,
+		zap.NewNop(),
+	)
+}
+
+// collectResponses 读取所有响应（带超时保护）
+func collectResponses(ch <-chan *types.ChatResponse) []*types.ChatResponse {
+	var responses []*types.ChatResponse
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case resp, ok := <-ch:
+			if !ok {
+				return responses
+			}
+			responses = append(responses, resp)
+		case <-timeout:
+			return responses
+		}
+	}
+}
+
+// responsesOfType 过滤指定事件类型的响应
+func responsesOfType(responses []*types.ChatResponse, eventType string) []*types.ChatResponse {
+	var filtered []*types.ChatResponse
+	for _, resp := range responses {
+		if resp.EventType == eventType {
+			filtered = append(filtered, resp)
+		}
+	}
+	return filtered
+}
+
+type fakeKnowledgeSearcher struct {
+	results []*KnowledgeSearchResult
+	err     error
+}
+
+func (s *fakeKnowledgeSearcher) SearchDocuments(ctx context.Context, kbID, userID, query string, topK int) ([]*KnowledgeSearchResult, error) {
+	return s.results, s.err
+}
+
+// fakeCompletionProvider 同时支持流式与非流式调用的服务商
+type fakeCompletionProvider struct {
+	fakeProvider
+	completion string
+
+	completions int
+}
+
+func (p *fakeCompletionProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatCompletion, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.completions++
+	p.mu.Unlock()
+
+	return &ChatCompletion{Content: p.completion, FinishReason: "stop"}, nil
+}
+
+type fakeModelCapabilities struct {
+	nonStreaming map[string]bool // model -> 不支持流式
+}
+
+func (c *fakeModelCapabilities) SupportsStream(ctx context.Context, providerID, model string) (bool, error) {
+	return !c.nonStreaming[model], nil
+}
+
+// fakeStructuredProvider 支持结构化输出的服务商，按顺序返回预设回复（最后一条重复使用）
+type fakeStructuredProvider struct {
+	fakeProvider
+	replies []string
+}
+
+func (p *fakeStructuredProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatCompletion, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reply := p.replies[len(p.replies)-1]
+	if len(p.requests) < len(p.replies) {
+		reply = p.replies[len(p.requests)]
+	}
+	p.requests = append(p.requests, req)
+	return &ChatCompletion{Content: reply, FinishReason: "stop"}, nil
+}
+
+func (p *fakeStructuredProvider) SupportsResponseFormat(model, formatType string) bool {
+	return true
+}
+
+func (p *fakeStructuredProvider) calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.requests)
+}
@@ -0,0 +1,294 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	assistantbiz "github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"go.uber.org/zap"
+)
+
+type fakeModelResolver struct {
+	aliases map[string]string
+}
+
+func (r *fakeModelResolver) ResolveModel(ctx context.Context, model string) (string, error) {
+	if target, ok := r.aliases[model]; ok {
+		if target == "" {
+			return "", fmt.Errorf("%w: %q", assistantbiz.ErrModelAliasNotMapped, model)
+		}
+		return target, nil
+	}
+	return model, nil
+}
+
+func TestChatStreamMulti_ResolvesModelAlias(t *testing.T) {
+	resolver := &fakeModelResolver{aliases: map[string]string{
+		"claude-sonnet-latest": "claude-sonnet-4-20250514",
+		"claude-old-latest":    "",
+	}}
+
+	t.Run("alias resolved", func(t *testing.T) {
+		provider := &fakeProvider{name: "anthropic", tokens: []string{"ok"}}
+		o := newTestOrchestrator(nil, map[string]Provider{"p1": provider}, nil, resolver)
+
+		ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+			Message:   "hello",
+			Providers: []types.ProviderConfig{{Provider: "p1", Model: "claude-sonnet-latest"}},
+		})
+		if err != nil {
+			t.Fatalf("ChatStreamMulti failed: %v", err)
+		}
+		responses := collectResponses(ch)
+
+		if errs := responsesOfType(responses, "error"); len(errs) != 0 {
+			t.Fatalf("Unexpected error: %s", errs[0].Error)
+		}
+		req := provider.lastRequest()
+		if req == nil || req.Model != "claude-sonnet-4-20250514" {
+			t.Fatalf("Expected provider to receive resolved model, got %+v", req)
+		}
+	})
+
+	t.Run("unmapped alias", func(t *testing.T) {
+		provider := &fakeProvider{name: "anthropic", tokens: []string{"ok"}}
+		o := newTestOrchestrator(nil, map[string]Provider{"p1": provider}, nil, resolver)
+		o.errorHandler = NewErrorHandler()
+
+		ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+			Message:   "hello",
+			Providers: []types.ProviderConfig{{Provider: "p1", Model: "claude-old-latest"}},
+		})
+		if err != nil {
+			t.Fatalf("ChatStreamMulti failed: %v", err)
+		}
+		responses := collectResponses(ch)
+
+		errs := responsesOfType(responses, "error")
+		if len(errs) != 1 || !strings.Contains(errs[0].Error, "claude-old-latest") {
+			t.Fatalf("Expected clear unmapped alias error, got %v", errs)
+		}
+		if errs[0].Metadata["error_type"] != "invalid_request_error" || errs[0].Metadata["retryable"] != false {
+			t.Errorf("Expected non-retryable invalid request, got %v", errs[0].Metadata)
+		}
+		if provider.lastRequest() != nil {
+			t.Error("Expected provider not to be called for unmapped alias")
+		}
+	})
+}
+
+func TestChatStreamMulti_KnowledgeSearchErrorPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "proceed", policy: KnowledgeErrorPolicyProceed},
+		{name: "fail", policy: KnowledgeErrorPolicyFail, wantErr: true},
+		{name: "warn", policy: KnowledgeErrorPolicyWarn, wantWarning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{name: "openai", tokens: []string{"ok"}}
+			cfg := DefaultOrchestratorConfig()
+			cfg.KnowledgeSearchErrorPolicy = tt.policy
+			searcher := &fakeKnowledgeSearcher{err: fmt.Errorf("milvus unavailable")}
+			o := newTestOrchestrator(cfg, map[string]Provider{"p1": provider}, searcher, nil)
+
+			ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+				Message:         "hello",
+				KnowledgeBaseID: "kb-1",
+				Providers:       []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+			})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "knowledge search failed") {
+					t.Fatalf("Expected knowledge search error, got %v", err)
+				}
+				if provider.lastRequest() != nil {
+					t.Error("Expected provider not to be called")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ChatStreamMulti failed: %v", err)
+			}
+			responses := collectResponses(ch)
+
+			warnings := responsesOfType(responses, "warning")
+			if tt.wantWarning {
+				if len(warnings) != 1 || warnings[0].Metadata["code"] != "knowledge_search_failed" {
+					t.Fatalf("Expected one knowledge warning event, got %v", warnings)
+				}
+				if strings.Contains(warnings[0].Error, "milvus") {
+					t.Errorf("Expected warning not to expose the raw search error, got %q", warnings[0].Error)
+				}
+				if responses[0].EventType != "warning" {
+					t.Errorf("Expected warning to be sent first, got %s", responses[0].EventType)
+				}
+			} else if len(warnings) != 0 {
+				t.Errorf("Expected no warning events, got %d", len(warnings))
+			}
+
+			req := provider.lastRequest()
+			if req == nil {
+				t.Fatal("Expected provider to be called")
+			}
+			if len(req.Messages) != 1 {
+				t.Errorf("Expected only the user message without knowledge context, got %d messages", len(req.Messages))
+			}
+		})
+	}
+}
+
+func TestChatStreamMulti_NonStreamingModelFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		chunkSize  int
+		wantTokens []string
+		wantChat   bool
+	}{
+		{name: "streaming model uses ChatStream", model: "gpt-4o", wantTokens: []string{"streamed"}},
+		{name: "non-streaming model single token", model: "o1-preview", wantTokens: []string{"你好，世界"}, wantChat: true},
+		{name: "non-streaming model chunked", model: "o1-preview", chunkSize: 2, wantTokens: []string{"你好", "，世", "界"}, wantChat: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeCompletionProvider{
+				fakeProvider: fakeProvider{name: "openai", tokens: []string{"streamed"}},
+				completion:   "你好，世界",
+			}
+			cfg := DefaultOrchestratorConfig()
+			cfg.NonStreamChunkSize = tt.chunkSize
+			capabilities := &fakeModelCapabilities{nonStreaming: map[string]bool{"o1-preview": true}}
+			o := NewOrchestrator(&fakeProviderFactory{providers: map[string]Provider{"p1": provider}},
+				nil, nil, nil, nil, nil, nil, nil, capabilities, cfg, zap.NewNop())
+
+			ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+				Message:   "hello",
+				Providers: []types.ProviderConfig{{Provider: "p1", Model: tt.model}},
+			})
+			if err != nil {
+				t.Fatalf("ChatStreamMulti failed: %v", err)
+			}
+			responses := collectResponses(ch)
+
+			if errs := responsesOfType(responses, "error"); len(errs) != 0 {
+				t.Fatalf("Unexpected error: %s", errs[0].Error)
+			}
+			if len(responsesOfType(responses, "start")) != 1 {
+				t.Errorf("Expected one start event")
+			}
+			tokens := responsesOfType(responses, "token")
+			if len(tokens) != len(tt.wantTokens) {
+				t.Fatalf("Expected %d token events, got %d", len(tt.wantTokens), len(tokens))
+			}
+			for i, want := range tt.wantTokens {
+				if tokens[i].Content != want {
+					t.Errorf("Token %d: expected %q, got %q", i, want, tokens[i].Content)
+				}
+			}
+			done := responsesOfType(responses, "done")
+			if len(done) != 1 || done[0].Content != strings.Join(tt.wantTokens, "") {
+				t.Fatalf("Expected done event with full content, got %v", done)
+			}
+
+			if (provider.completions == 1) != tt.wantChat {
+				t.Errorf("Expected non-streaming call: %v, got %d calls", tt.wantChat, provider.completions)
+			}
+			if req := provider.lastRequest(); req == nil || req.Stream == tt.wantChat {
+				t.Errorf("Expected request Stream=%v, got %+v", !tt.wantChat, req)
+			}
+		})
+	}
+}
+
+func TestChatStreamMulti_MaxProvidersPerRequest(t *testing.T) {
+	providers := map[string]Provider{}
+	for i := 1; i <= 3; i++ {
+		providers[fmt.Sprintf("p%d", i)] = &fakeProvider{name: "openai", tokens: []string{"ok"}}
+	}
+	cfg := DefaultOrchestratorConfig()
+	cfg.MaxProvidersPerRequest = 2
+	o := newTestOrchestrator(cfg, providers, nil, nil)
+
+	t.Run("exceeds cap", func(t *testing.T) {
+		_, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+			Message: "hello",
+			Providers: []types.ProviderConfig{
+				{Provider: "p1", Model: "gpt-4o"},
+				{Provider: "p2", Model: "gpt-4o"},
+				{Provider: "p3", Model: "gpt-4o"},
+			},
+		})
+		if !errors.Is(err, ErrTooManyProviders) {
+			t.Fatalf("Expected ErrTooManyProviders, got %v", err)
+		}
+		for id, provider := range providers {
+			if provider.(*fakeProvider).lastRequest() != nil {
+				t.Errorf("Expected provider %s not to be called", id)
+			}
+		}
+	})
+
+	t.Run("within cap", func(t *testing.T) {
+		ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+			Message: "hello",
+			Providers: []types.ProviderConfig{
+				{Provider: "p1", Model: "gpt-4o"},
+				{Provider: "p2", Model: "gpt-4o"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("ChatStreamMulti failed: %v", err)
+		}
+		responses := collectResponses(ch)
+		if done := responsesOfType(responses, "done"); len(done) != 2 {
+			t.Fatalf("Expected 2 done events, got %d", len(done))
+		}
+	})
+}
+
+func TestChatStreamMulti_ForwardsReasoningSeparately(t *testing.T) {
+	provider := &fakeProvider{
+		name:      "deepseek",
+		reasoning: []string{"先想一想，", "答案是 2。"},
+		tokens:    []string{"1+1", " 等于 2。"},
+	}
+	o := newTestOrchestrator(nil, map[string]Provider{"p1": provider}, nil, nil)
+
+	ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+		Message:   "1+1=?",
+		Providers: []types.ProviderConfig{{Provider: "p1", Model: "deepseek-reasoner"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStreamMulti failed: %v", err)
+	}
+	responses := collectResponses(ch)
+
+	var reasoning, answer strings.Builder
+	for _, resp := range responsesOfType(responses, "reasoning") {
+		reasoning.WriteString(resp.Content)
+	}
+	for _, resp := range responsesOfType(responses, "token") {
+		answer.WriteString(resp.Content)
+	}
+	if reasoning.String() != "先想一想，答案是 2。" {
+		t.Errorf("Expected reasoning events, got %q", reasoning.String())
+	}
+	if answer.String() != "1+1 等于 2。" {
+		t.Errorf("Expected answer tokens without reasoning, got %q", answer.String())
+	}
+
+	done := responsesOfType(responses, "done")
+	if len(done) != 1 || done[0].Content != "1+1 等于 2。" || done[0].TokenCount == nil || *done[0].TokenCount != 2 {
+		t.Fatalf("Expected done event with answer content only, got %+v", done)
+	}
+}
@@ -25,6 +25,34 @@ type Provider interface {
 	SupportsMultimodal() bool
 }
 
+// TypedProvider 可报告服务商类型的服务商（可选接口）
+// 同一实现可能服务多种服务商类型（如 SiliconFlow 复用 OpenAI 兼容实现），此时 Name() 不等于数据库中的服务商类型
+type TypedProvider interface {
+	// ProviderType 返回数据库中配置的服务商类型（如 openai、siliconflow）
+	ProviderType() string
+}
+
+// ProviderTypeOf 返回服务商类型（未实现 TypedProvider 时使用 Name()）
+func ProviderTypeOf(provider Provider) string {
+	if typed, ok := provider.(TypedProvider); ok {
+		return typed.ProviderType()
+	}
+	return provider.Name()
+}
+
+// CompletionProvider 支持非流式调用的服务商（可选接口，用于不支持流式输出的模型）
+type CompletionProvider interface {
+	// Chat 非流式聊天，一次性返回完整结果
+	Chat(ctx context.Context, req *ChatRequest) (*ChatCompletion, error)
+}
+
+// ChatCompletion 非流式聊天结果
+type ChatCompletion struct {
+	Content      string `json:"content"`
+	FinishReason string `json:"finish_reason,omitempty"`
+	TokenCount   *int   `json:"token_count,omitempty"`
+}
+
 // ChatRequest 统一的聊天请求格式
 type ChatRequest struct {
 	// 消息内容
@@ -43,6 +71,9 @@ type ChatRequest struct {
 	// 工具调用（可选）
 	Tools []Tool `json:"tools,omitempty"`
 
+	// 结构化输出（可选，由编排器校验服务商支持情况）
+	ResponseFormat *types.ResponseFormat `json:"response_format,omitempty"`
+
 	// 服务商特定选项
 	ProviderOptions map[string]interface{} `json:"provider_options,omitempty"`
 }
@@ -108,11 +139,14 @@ type StreamEvent struct {
 type EventType string
 
 const (
-	EventStart  EventType = "start"
-	EventToken  EventType = "token"
-	EventDone   EventType = "done"
-	EventError  EventType = "error"
-	EventThink  EventType = "think" // 思考过程（Claude）
+	EventStart EventType = "start"
+	EventToken EventType = "token"
+	EventDone  EventType = "done"
+	EventError EventType = "error"
+	EventThink EventType = "think" // Deprecated: 使用 EventReasoning
+
+	// EventReasoning 推理过程（DeepSeek-R1 的 reasoning_content、Claude Extended Thinking 等），与回答 token 分开下发
+	EventReasoning EventType = "reasoning"
 )
 
 // ProviderConfig 服务商配置
@@ -150,14 +184,14 @@ type MultiProviderOrchestrator interface {
 
 // ContextManager 上下文管理器
 type ContextManager interface {
-	// GetHistory 获取历史消息
-	GetHistory(topicID string, limit int) ([]Message, error)
+	// GetHistory 获取用户会话中最近的历史消息（按时间正序）
+	GetHistory(ctx context.Context, userID, topicID string, limit int) ([]Message, error)
 
 	// SaveMessage 保存消息
-	SaveMessage(topicID string, role string, content []ContentBlock) error
+	SaveMessage(ctx context.Context, topicID string, role string, content []ContentBlock) error
 
 	// BuildContext 构建完整上下文
-	BuildContext(topicID string, newMessage string, contentBlocks []types.MessageContentBlock) ([]Message, error)
+	BuildContext(ctx context.Context, userID, topicID string, newMessage string, contentBlocks []types.MessageContentBlock) ([]Message, error)
 }
 
 // WebSearchProvider 联网搜索提供者
@@ -0,0 +1,210 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// 服务商选项校验策略
+const (
+	OptionPolicyReject = "reject" // 拒绝未知/非法选项（默认）
+	OptionPolicyWarn   = "warn"   // 记录警告后原样透传
+)
+
+// OptionType 选项值类型
+type OptionType string
+
+const (
+	OptionTypeString     OptionType = "string"
+	OptionTypeNumber     OptionType = "number"
+	OptionTypeInteger    OptionType = "integer"
+	OptionTypeBool       OptionType = "bool"
+	OptionTypeObject     OptionType = "object"
+	OptionTypeStringList OptionType = "string_list"
+)
+
+// OptionSchema 单个选项的校验规则
+type OptionSchema struct {
+	Type     OptionType
+	Enum     []string                      // 可选值（仅 string）
+	Min      *float64                      // 最小值（number/integer）
+	Max      *float64                      // 最大值（number/integer）
+	Validate func(value interface{}) error // 额外校验（可选）
+}
+
+// ProviderOptionError 选项校验错误
+type ProviderOptionError struct {
+	Key    string
+	Reason string
+}
+
+func (e *ProviderOptionError) Error() string {
+	return fmt.Sprintf("option %q: %s", e.Key, e.Reason)
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+// providerOptionSchemas 各服务商已知选项（按数据库中的服务商类型索引，见 ProviderTypeOf）
+// OpenAI 兼容服务商复用同一实现，但支持的选项不同，需按类型单独登记
+var providerOptionSchemas = map[string]map[string]OptionSchema{
+	"openai": {
+		"response_format":   {Type: OptionTypeObject, Validate: validateOpenAIResponseFormat},
+		"seed":              {Type: OptionTypeInteger},
+		"presence_penalty":  {Type: OptionTypeNumber, Min: float64Ptr(-2), Max: float64Ptr(2)},
+		"frequency_penalty": {Type: OptionTypeNumber, Min: float64Ptr(-2), Max: float64Ptr(2)},
+		"stop":              {Type: OptionTypeStringList},
+		"user":              {Type: OptionTypeString},
+		"reasoning_effort":  {Type: OptionTypeString, Enum: []string{"low", "medium", "high"}},
+		"logprobs":          {Type: OptionTypeBool},
+		"top_logprobs":      {Type: OptionTypeInteger, Min: float64Ptr(0), Max: float64Ptr(20)},
+	},
+	"siliconflow": {
+		"response_format":   {Type: OptionTypeObject, Validate: validateOpenAIResponseFormat},
+		"frequency_penalty": {Type: OptionTypeNumber, Min: float64Ptr(-2), Max: float64Ptr(2)},
+		"stop":              {Type: OptionTypeStringList},
+		"top_k":             {Type: OptionTypeInteger, Min: float64Ptr(0)},
+		"min_p":             {Type: OptionTypeNumber, Min: float64Ptr(0), Max: float64Ptr(1)},
+		"enable_thinking":   {Type: OptionTypeBool},
+		"thinking_budget":   {Type: OptionTypeInteger, Min: float64Ptr(128), Max: float64Ptr(32768)},
+	},
+	"anthropic": {
+		"thinking_budget": {Type: OptionTypeInteger, Min: float64Ptr(1024)},
+		"top_k":           {Type: OptionTypeInteger, Min: float64Ptr(0)},
+		"stop_sequences":  {Type: OptionTypeStringList},
+		"metadata":        {Type: OptionTypeObject},
+	},
+}
+
+// validateOpenAIResponseFormat 校验 OpenAI response_format
+func validateOpenAIResponseFormat(value interface{}) error {
+	format := value.(map[string]interface{})
+	formatType, _ := format["type"].(string)
+	switch formatType {
+	case "text", "json_object":
+		return nil
+	case "json_schema":
+		if _, ok := format["json_schema"].(map[string]interface{}); !ok {
+			return fmt.Errorf("json_schema is required when type is json_schema")
+		}
+		return nil
+	default:
+		return fmt.Errorf("type must be one of text, json_object, json_schema")
+	}
+}
+
+// ValidateProviderOptions 按服务商 schema 校验选项，返回所有问题（未注册 schema 的服务商不校验）
+func ValidateProviderOptions(providerType string, options map[string]interface{}) []*ProviderOptionError {
+	schemas, ok := providerOptionSchemas[providerType]
+	if !ok || len(options) == 0 {
+		return nil
+	}
+
+	// 按 key 排序保证错误信息稳定
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []*ProviderOptionError
+	for _, key := range keys {
+		schema, known := schemas[key]
+		if !known {
+			problems = append(problems, &ProviderOptionError{Key: key, Reason: "unknown option for provider " + providerType})
+			continue
+		}
+		if err := schema.check(options[key]); err != nil {
+			problems = append(problems, &ProviderOptionError{Key: key, Reason: err.Error()})
+		}
+	}
+
+	return problems
+}
+
+// check 校验单个选项值
+func (s OptionSchema) check(value interface{}) error {
+	switch s.Type {
+	case OptionTypeString:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string")
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			return fmt.Errorf("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+
+	case OptionTypeNumber, OptionTypeInteger:
+		num, ok := toFloat64(value)
+		if !ok {
+			return fmt.Errorf("expected %s", s.Type)
+		}
+		if s.Type == OptionTypeInteger && num != math.Trunc(num) {
+			return fmt.Errorf("expected integer")
+		}
+		if s.Min != nil && num < *s.Min {
+			return fmt.Errorf("must be >= %v", *s.Min)
+		}
+		if s.Max != nil && num > *s.Max {
+			return fmt.Errorf("must be <= %v", *s.Max)
+		}
+
+	case OptionTypeBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected bool")
+		}
+
+	case OptionTypeObject:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("expected object")
+		}
+
+	case OptionTypeStringList:
+		switch v := value.(type) {
+		case string, []string:
+		case []interface{}:
+			for _, item := range v {
+				if _, ok := item.(string); !ok {
+					return fmt.Errorf("expected list of strings")
+				}
+			}
+		default:
+			return fmt.Errorf("expected string or list of strings")
+		}
+	}
+
+	if s.Validate != nil {
+		return s.Validate(value)
+	}
+	return nil
+}
+
+// toFloat64 将 JSON 数字（float64）及 Go 整数类型统一转换为 float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
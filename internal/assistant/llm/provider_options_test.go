@@ -0,0 +1,187 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+func TestValidateProviderOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		options  map[string]interface{}
+		wantKeys []string
+	}{
+		{
+			name:     "valid openai options",
+			provider: "openai",
+			options: map[string]interface{}{
+				"response_format": map[string]interface{}{"type": "json_object"},
+				"seed":            float64(42),
+				"stop":            []interface{}{"END"},
+			},
+		},
+		{
+			name:     "invalid response_format type",
+			provider: "openai",
+			options: map[string]interface{}{
+				"response_format": map[string]interface{}{"type": "yaml"},
+			},
+			wantKeys: []string{"response_format"},
+		},
+		{
+			name:     "unknown option and non-integer seed",
+			provider: "openai",
+			options: map[string]interface{}{
+				"seed":    1.5,
+				"unknown": true,
+			},
+			wantKeys: []string{"seed", "unknown"},
+		},
+		{
+			name:     "anthropic thinking budget too small",
+			provider: "anthropic",
+			options: map[string]interface{}{
+				"thinking_budget": float64(100),
+			},
+			wantKeys: []string{"thinking_budget"},
+		},
+		{
+			name:     "siliconflow options",
+			provider: "siliconflow",
+			options: map[string]interface{}{
+				"enable_thinking": true,
+				"thinking_budget": float64(4096),
+				"top_k":           float64(50),
+			},
+		},
+		{
+			name:     "openai-only option rejected for siliconflow",
+			provider: "siliconflow",
+			options: map[string]interface{}{
+				"logprobs": true,
+			},
+			wantKeys: []string{"logprobs"},
+		},
+		{
+			name:     "siliconflow option rejected for openai",
+			provider: "openai",
+			options: map[string]interface{}{
+				"enable_thinking": true,
+			},
+			wantKeys: []string{"enable_thinking"},
+		},
+		{
+			name:     "provider without schema is not validated",
+			provider: "gemini",
+			options: map[string]interface{}{
+				"anything": "goes",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := ValidateProviderOptions(tt.provider, tt.options)
+			if len(problems) != len(tt.wantKeys) {
+				t.Fatalf("Expected %d problems, got %d: %v", len(tt.wantKeys), len(problems), problems)
+			}
+			for i, key := range tt.wantKeys {
+				if problems[i].Key != key {
+					t.Errorf("Expected problem for %q, got %q", key, problems[i].Key)
+				}
+			}
+		})
+	}
+}
+
+func TestChatStreamMulti_ProviderOptionPolicy(t *testing.T) {
+	validOptions := map[string]interface{}{
+		"response_format": map[string]interface{}{"type": "json_object"},
+	}
+	invalidOptions := map[string]interface{}{
+		"response_format": "json",
+	}
+
+	tests := []struct {
+		name        string
+		policy      string
+		options     map[string]interface{}
+		wantError   bool
+		wantForward bool
+	}{
+		{name: "valid option forwarded", policy: OptionPolicyReject, options: validOptions, wantForward: true},
+		{name: "invalid option rejected", policy: OptionPolicyReject, options: invalidOptions, wantError: true},
+		{name: "invalid option warned and forwarded", policy: OptionPolicyWarn, options: invalidOptions, wantForward: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{name: "openai", tokens: []string{"ok"}}
+			o := newTestOrchestrator(&OrchestratorConfig{ProviderOptionPolicy: tt.policy},
+				map[string]Provider{"p1": provider}, nil, nil)
+
+			ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+				Message: "hello",
+				Providers: []types.ProviderConfig{
+					{Provider: "p1", Model: "gpt-4o", Options: tt.options},
+				},
+			})
+			if err != nil {
+				t.Fatalf("ChatStreamMulti failed: %v", err)
+			}
+			responses := collectResponses(ch)
+
+			errors := responsesOfType(responses, "error")
+			if tt.wantError {
+				if len(errors) != 1 || !strings.Contains(errors[0].Error, "response_format") {
+					t.Fatalf("Expected one error mentioning response_format, got %v", errors)
+				}
+				if provider.lastRequest() != nil {
+					t.Error("Expected provider not to be called when options are rejected")
+				}
+				return
+			}
+
+			if len(errors) != 0 {
+				t.Fatalf("Unexpected error responses: %v", errors[0].Error)
+			}
+			req := provider.lastRequest()
+			if req == nil {
+				t.Fatal("Expected provider to be called")
+			}
+			if tt.wantForward && req.ProviderOptions["response_format"] == nil {
+				t.Errorf("Expected response_format to be forwarded, got %v", req.ProviderOptions)
+			}
+		})
+	}
+}
+
+func TestChatStreamMulti_ProviderOptionsUseProviderType(t *testing.T) {
+	// SiliconFlow 复用 OpenAI 实现（Name() 为 openai），选项按数据库中的服务商类型校验
+	provider := &fakeTypedProvider{fakeProvider: fakeProvider{name: "openai", tokens: []string{"ok"}}, providerType: "siliconflow"}
+	o := newTestOrchestrator(&OrchestratorConfig{ProviderOptionPolicy: OptionPolicyReject},
+		map[string]Provider{"p1": provider}, nil, nil)
+
+	ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+		Message: "hello",
+		Providers: []types.ProviderConfig{
+			{Provider: "p1", Model: "Qwen/Qwen3-8B", Options: map[string]interface{}{"enable_thinking": false}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ChatStreamMulti failed: %v", err)
+	}
+	responses := collectResponses(ch)
+
+	if errors := responsesOfType(responses, "error"); len(errors) != 0 {
+		t.Fatalf("Expected siliconflow option to be accepted, got %q", errors[0].Error)
+	}
+	req := provider.lastRequest()
+	if req == nil || req.ProviderOptions["enable_thinking"] != false {
+		t.Errorf("Expected enable_thinking to be forwarded, got %+v", req)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	aitypes "github.com/lk2023060901/ai-writer-backend/internal/ai/provider/types"
 	"github.com/lk2023060901/ai-writer-backend/internal/assistant/llm"
 )
 
@@ -109,7 +110,7 @@ func (p *AnthropicProvider) ChatStream(ctx context.Context, req *llm.ChatRequest
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		fmt.Printf("[Anthropic] API error response: %s\n", string(body))
-		return nil, fmt.Errorf("anthropic api error: %s - %s", resp.Status, string(body))
+		return nil, aitypes.NewStatusError("anthropic", resp.StatusCode, string(body))
 	}
 
 	// 5. 创建事件 channel
@@ -149,6 +150,22 @@ func (p *AnthropicProvider) convertRequest(req *llm.ChatRequest) map[string]inte
 		anthropicReq["system"] = req.SystemPrompt
 	}
 
+	// 服务商特定选项（已由编排器校验），不允许覆盖核心字段
+	for key, value := range req.ProviderOptions {
+		switch key {
+		case "model", "messages", "stream", "system":
+			continue
+		case "thinking_budget":
+			// Extended Thinking
+			anthropicReq["thinking"] = map[string]interface{}{
+				"type":          "enabled",
+				"budget_tokens": value,
+			}
+		default:
+			anthropicReq[key] = value
+		}
+	}
+
 	return anthropicReq
 }
 
@@ -262,10 +279,9 @@ func (p *AnthropicProvider) readStream(body io.ReadCloser, eventChan chan<- llm.
 				}
 				tokenIndex++
 			} else if event.Delta.Type == "thinking_delta" {
-				// 处理思考过程（Extended Thinking）
-				// 可以选择发送 EventThink 或者 EventToken
+				// 处理思考过程（Extended Thinking），作为推理事件下发
 				eventChan <- llm.StreamEvent{
-					Type:    llm.EventThink,
+					Type:    llm.EventReasoning,
 					Content: event.Delta.Thinking,
 					Index:   tokenIndex,
 				}
@@ -3,16 +3,36 @@ package providers
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/lk2023060901/ai-writer-backend/internal/assistant/llm"
 	knowledgebiz "github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
 	"go.uber.org/zap"
 )
 
+// 服务商缓存配置
+const (
+	providerRecordTTL  = 30 * time.Second // 服务商记录缓存时长，过期后重新查询数据库（配置变更最多延迟该时长生效）
+	maxCachedProviders = 64               // 缓存的服务商数量上限，超出时淘汰最早查询的记录
+)
+
 // DatabaseProviderFactory 基于数据库的服务商工厂
 type DatabaseProviderFactory struct {
 	aiProviderUseCase *knowledgebiz.AIProviderUseCase
 	logger            *zap.Logger
+
+	mu        sync.Mutex
+	cache     map[string]*cachedProvider // 服务商 ID -> 服务商记录及基于该记录创建的实例
+	recordTTL time.Duration
+	now       func() time.Time
+}
+
+// cachedProvider 缓存的服务商记录与实例
+type cachedProvider struct {
+	record    *knowledgebiz.AIProvider
+	fetchedAt time.Time    // 从数据库查询记录的时间，超过 recordTTL 后重新查询
+	provider  llm.Provider // 基于 record 创建的实例，记录的 updated_at 变化后重建
 }
 
 // NewDatabaseProviderFactory 创建服务商工厂
@@ -20,30 +40,20 @@ func NewDatabaseProviderFactory(aiProviderUseCase *knowledgebiz.AIProviderUseCas
 	return &DatabaseProviderFactory{
 		aiProviderUseCase: aiProviderUseCase,
 		logger:            logger,
+		cache:             make(map[string]*cachedProvider),
+		recordTTL:         providerRecordTTL,
+		now:               time.Now,
 	}
 }
 
 // CreateProvider 创建服务商实例（实现 ProviderFactory 接口）
+// 服务商记录缓存 recordTTL，期间不再查询数据库；调用方覆盖 API Key/BaseURL 时创建独立实例且不缓存
 func (f *DatabaseProviderFactory) CreateProvider(config llm.ProviderConfig) (llm.Provider, error) {
-	ctx := context.Background()
-
-	f.logger.Info("CreateProvider called",
-		zap.String("provider_id", config.Provider))
-
-	// 从数据库获取服务商配置（通过 UUID）
-	providerConfig, err := f.aiProviderUseCase.GetAIProviderByID(ctx, config.Provider)
+	entry, err := f.getProvider(context.Background(), config.Provider)
 	if err != nil {
-		f.logger.Error("Failed to get provider from database",
-			zap.String("provider_id", config.Provider),
-			zap.Error(err))
-		return nil, fmt.Errorf("get provider config: %w", err)
+		return nil, err
 	}
-
-	f.logger.Info("Provider config retrieved from database",
-		zap.String("provider_id", config.Provider),
-		zap.String("provider_type", providerConfig.ProviderType),
-		zap.String("provider_name", providerConfig.ProviderName),
-		zap.Bool("is_enabled", providerConfig.IsEnabled))
+	providerConfig := entry.record
 
 	if !providerConfig.IsEnabled {
 		f.logger.Warn("Provider is disabled",
@@ -68,8 +78,72 @@ func (f *DatabaseProviderFactory) CreateProvider(config llm.ProviderConfig) (llm
 		baseURL = providerConfig.APIBaseURL
 	}
 
-	// 根据类型创建对应的 Provider（使用数据库中的 ProviderType）
-	switch providerConfig.ProviderType {
+	if config.APIKey == "" && config.BaseURL == "" {
+		return entry.provider, nil
+	}
+	return newProvider(providerConfig.ProviderType, apiKey, baseURL)
+}
+
+// getProvider 返回服务商记录与实例：缓存未过期时直接使用，否则重新查询数据库
+// 记录未更新（updated_at 不变）时复用已创建的实例，避免重复构建 HTTP 客户端
+func (f *DatabaseProviderFactory) getProvider(ctx context.Context, providerID string) (*cachedProvider, error) {
+	f.mu.Lock()
+	cached, ok := f.cache[providerID]
+	f.mu.Unlock()
+	if ok && f.now().Sub(cached.fetchedAt) < f.recordTTL {
+		return cached, nil
+	}
+
+	// 从数据库获取服务商配置（通过 UUID）
+	record, err := f.aiProviderUseCase.GetAIProviderByID(ctx, providerID)
+	if err != nil {
+		f.logger.Error("Failed to get provider from database",
+			zap.String("provider_id", providerID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get provider config: %w", err)
+	}
+
+	f.logger.Info("Provider config retrieved from database",
+		zap.String("provider_id", providerID),
+		zap.String("provider_type", record.ProviderType),
+		zap.String("provider_name", record.ProviderName),
+		zap.Bool("is_enabled", record.IsEnabled))
+
+	entry := &cachedProvider{record: record, fetchedAt: f.now()}
+	if ok && cached.provider != nil && cached.record.UpdatedAt.Equal(record.UpdatedAt) {
+		entry.provider = cached.provider
+	} else if record.IsEnabled && record.APIKey != "" {
+		provider, err := newProvider(record.ProviderType, record.APIKey, record.APIBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		entry.provider = provider
+	}
+
+	f.storeProvider(providerID, entry)
+	return entry, nil
+}
+
+// storeProvider 缓存服务商记录与实例（超过上限时淘汰最早查询的记录）
+func (f *DatabaseProviderFactory) storeProvider(providerID string, entry *cachedProvider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.cache[providerID]; !exists && len(f.cache) >= maxCachedProviders {
+		var oldestID string
+		for id, cached := range f.cache {
+			if oldestID == "" || cached.fetchedAt.Before(f.cache[oldestID].fetchedAt) {
+				oldestID = id
+			}
+		}
+		delete(f.cache, oldestID)
+	}
+	f.cache[providerID] = entry
+}
+
+// newProvider 根据类型创建对应的 Provider（使用数据库中的 ProviderType）
+func newProvider(providerType, apiKey, baseURL string) (llm.Provider, error) {
+	switch providerType {
 	case "openai":
 		return NewOpenAIProvider(apiKey, baseURL), nil
 
@@ -81,13 +155,13 @@ func (f *DatabaseProviderFactory) CreateProvider(config llm.ProviderConfig) (llm
 
 	case "siliconflow":
 		// SiliconFlow 兼容 OpenAI API
-		return NewOpenAIProvider(apiKey, baseURL), nil
+		return NewOpenAICompatibleProvider(providerType, apiKey, baseURL), nil
 
 	case "grok":
 		return NewGrokProvider(apiKey, baseURL), nil
 
 	default:
-		return nil, fmt.Errorf("unsupported provider type: %s", providerConfig.ProviderType)
+		return nil, fmt.Errorf("unsupported provider type: %s", providerType)
 	}
 }
 
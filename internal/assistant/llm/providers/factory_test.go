@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/llm"
+	knowledgebiz "github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"go.uber.org/zap"
+)
+
+type fakeAIProviderRepo struct {
+	mu        sync.Mutex
+	providers map[string]*knowledgebiz.AIProvider
+	gets      int
+}
+
+func (r *fakeAIProviderRepo) ListAll(ctx context.Context) ([]*knowledgebiz.AIProvider, error) {
+	return nil, nil
+}
+
+func (r *fakeAIProviderRepo) GetByID(ctx context.Context, id string) (*knowledgebiz.AIProvider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gets++
+	p, ok := r.providers[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (r *fakeAIProviderRepo) GetByType(ctx context.Context, providerType string) (*knowledgebiz.AIProvider, error) {
+	return nil, errors.New("not found")
+}
+
+func (r *fakeAIProviderRepo) UpdateStatus(ctx context.Context, id string, isEnabled bool) error {
+	return nil
+}
+
+func (r *fakeAIProviderRepo) UpdateConfig(ctx context.Context, id string, apiKey, apiBaseURL *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.providers[id]
+	if apiKey != nil {
+		p.APIKey = *apiKey
+	}
+	if apiBaseURL != nil {
+		p.APIBaseURL = *apiBaseURL
+	}
+	p.UpdatedAt = p.UpdatedAt.Add(time.Second)
+	return nil
+}
+
+func (r *fakeAIProviderRepo) getCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gets
+}
+
+func TestCreateProvider_CachesRecordUntilTTL(t *testing.T) {
+	repo := &fakeAIProviderRepo{providers: map[string]*knowledgebiz.AIProvider{
+		"p-1": {ID: "p-1", ProviderType: "openai", APIKey: "key-1", APIBaseURL: "https://api.example.com", IsEnabled: true, UpdatedAt: time.Now()},
+	}}
+	useCase := knowledgebiz.NewAIProviderUseCase(repo)
+	factory := NewDatabaseProviderFactory(useCase, zap.NewNop())
+	now := time.Now()
+	factory.now = func() time.Time { return now }
+
+	first, err := factory.CreateProvider(llm.ProviderConfig{Provider: "p-1"})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+
+	// 并发创建同一服务商：缓存有效期内不再查询数据库
+	const callers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := factory.CreateProvider(llm.ProviderConfig{Provider: "p-1"})
+			if err != nil {
+				t.Errorf("CreateProvider failed: %v", err)
+			}
+			if p != first {
+				t.Error("Expected the cached instance")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := repo.getCount(); got != 1 {
+		t.Errorf("Expected one database lookup within the TTL, got %d", got)
+	}
+
+	// 调用方覆盖 API Key 时使用独立实例，且不进入缓存
+	override, err := factory.CreateProvider(llm.ProviderConfig{Provider: "p-1", APIKey: "override"})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+	if override == first || override.(*OpenAIProvider).apiKey != "override" {
+		t.Error("Expected overridden API key to use a separate instance")
+	}
+
+	// 记录过期后重新查询；记录未变化时复用实例
+	now = now.Add(providerRecordTTL)
+	again, err := factory.CreateProvider(llm.ProviderConfig{Provider: "p-1"})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+	if again != first || repo.getCount() != 2 {
+		t.Errorf("Expected a refreshed lookup to reuse the unchanged instance, lookups=%d", repo.getCount())
+	}
+
+	// 记录更新后，过期前仍使用旧实例，过期后重建
+	newKey := "key-2"
+	if err := useCase.UpdateProviderConfig(context.Background(), "p-1", &newKey, nil); err != nil {
+		t.Fatalf("UpdateProviderConfig failed: %v", err)
+	}
+	if p, _ := factory.CreateProvider(llm.ProviderConfig{Provider: "p-1"}); p != first {
+		t.Error("Expected the cached instance until the TTL expires")
+	}
+	now = now.Add(providerRecordTTL)
+	updated, err := factory.CreateProvider(llm.ProviderConfig{Provider: "p-1"})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+	if updated == first {
+		t.Fatal("Expected a new instance after the provider was updated")
+	}
+	if got := updated.(*OpenAIProvider).apiKey; got != newKey {
+		t.Errorf("Expected updated API key %q, got %q", newKey, got)
+	}
+
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	if len(factory.cache) != 1 {
+		t.Errorf("Expected one cache entry per provider, got %d", len(factory.cache))
+	}
+}
+
+func TestCreateProvider_BoundsCache(t *testing.T) {
+	repo := &fakeAIProviderRepo{providers: map[string]*knowledgebiz.AIProvider{}}
+	for i := 0; i <= maxCachedProviders; i++ {
+		id := fmt.Sprintf("p-%d", i)
+		repo.providers[id] = &knowledgebiz.AIProvider{ID: id, ProviderType: "openai", APIKey: "key", IsEnabled: true}
+	}
+	factory := NewDatabaseProviderFactory(knowledgebiz.NewAIProviderUseCase(repo), zap.NewNop())
+	now := time.Now()
+	factory.now = func() time.Time { return now }
+
+	for i := 0; i <= maxCachedProviders; i++ {
+		now = now.Add(time.Millisecond)
+		if _, err := factory.CreateProvider(llm.ProviderConfig{Provider: fmt.Sprintf("p-%d", i)}); err != nil {
+			t.Fatalf("CreateProvider failed: %v", err)
+		}
+	}
+
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	if len(factory.cache) != maxCachedProviders {
+		t.Errorf("Expected cache to be capped at %d, got %d", maxCachedProviders, len(factory.cache))
+	}
+	if _, ok := factory.cache["p-0"]; ok {
+		t.Error("Expected the oldest entry to be evicted")
+	}
+}
+
+func TestNewProvider_ReportsProviderType(t *testing.T) {
+	for _, providerType := range []string{"openai", "siliconflow", "anthropic", "gemini", "grok"} {
+		p, err := newProvider(providerType, "key", "")
+		if err != nil {
+			t.Fatalf("newProvider(%q) failed: %v", providerType, err)
+		}
+		if got := llm.ProviderTypeOf(p); got != providerType {
+			t.Errorf("Expected provider type %q, got %q", providerType, got)
+		}
+	}
+}
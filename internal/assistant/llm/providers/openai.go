@@ -10,26 +10,35 @@ import (
 	"net/http"
 	"strings"
 
+	aitypes "github.com/lk2023060901/ai-writer-backend/internal/ai/provider/types"
 	"github.com/lk2023060901/ai-writer-backend/internal/assistant/llm"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
 )
 
 // OpenAIProvider OpenAI 服务商适配器
 type OpenAIProvider struct {
-	apiKey  string
-	baseURL string
-	client  *http.Client
+	apiKey       string
+	baseURL      string
+	client       *http.Client
+	providerType string // 数据库中的服务商类型（OpenAI 兼容服务商复用本实现）
 }
 
 // NewOpenAIProvider 创建 OpenAI 提供者
 func NewOpenAIProvider(apiKey, baseURL string) *OpenAIProvider {
+	return NewOpenAICompatibleProvider("openai", apiKey, baseURL)
+}
+
+// NewOpenAICompatibleProvider 创建 OpenAI 兼容服务商（如 siliconflow）的提供者
+func NewOpenAICompatibleProvider(providerType, apiKey, baseURL string) *OpenAIProvider {
 	if baseURL == "" {
 		baseURL = "https://api.openai.com/v1"
 	}
 
 	return &OpenAIProvider{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		client:  &http.Client{},
+		apiKey:       apiKey,
+		baseURL:      baseURL,
+		client:       &http.Client{},
+		providerType: providerType,
 	}
 }
 
@@ -38,6 +47,11 @@ func (p *OpenAIProvider) Name() string {
 	return "openai"
 }
 
+// ProviderType 实现 llm.TypedProvider
+func (p *OpenAIProvider) ProviderType() string {
+	return p.providerType
+}
+
 // ValidateConfig 验证配置
 func (p *OpenAIProvider) ValidateConfig() error {
 	if p.apiKey == "" {
@@ -93,7 +107,7 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, req *llm.ChatRequest) (
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("openai api error: %s - %s", resp.Status, string(body))
+		return nil, aitypes.NewStatusError("openai", resp.StatusCode, string(body))
 	}
 
 	// 5. 创建事件 channel
@@ -105,6 +119,53 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, req *llm.ChatRequest) (
 	return eventChan, nil
 }
 
+// Chat 非流式聊天（用于不支持流式输出的模型）
+func (p *OpenAIProvider) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatCompletion, error) {
+	openaiReq := p.convertRequest(req)
+	openaiReq["stream"] = false
+
+	body, err := json.Marshal(openaiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, aitypes.NewStatusError("openai", resp.StatusCode, string(body))
+	}
+
+	var completion OpenAIChatCompletion
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("openai api returned no choices")
+	}
+
+	result := &llm.ChatCompletion{
+		Content:      completion.Choices[0].Message.Content,
+		FinishReason: completion.Choices[0].FinishReason,
+	}
+	if completion.Usage != nil {
+		result.TokenCount = &completion.Usage.CompletionTokens
+	}
+	return result, nil
+}
+
 // convertRequest 转换请求格式
 func (p *OpenAIProvider) convertRequest(req *llm.ChatRequest) map[string]interface{} {
 	openaiReq := map[string]interface{}{
@@ -135,9 +196,93 @@ func (p *OpenAIProvider) convertRequest(req *llm.ChatRequest) map[string]interfa
 		openaiReq["messages"] = append([]map[string]interface{}{systemMsg}, messages...)
 	}
 
+	// 服务商特定选项（已由编排器校验），不允许覆盖核心字段
+	for key, value := range req.ProviderOptions {
+		switch key {
+		case "model", "messages", "stream":
+			continue
+		}
+		openaiReq[key] = value
+	}
+
+	// 结构化输出（优先于 provider_options 中的 response_format）
+	if req.ResponseFormat != nil && req.ResponseFormat.Type != "" {
+		openaiReq["response_format"] = openAIResponseFormat(req.ResponseFormat)
+	}
+
 	return openaiReq
 }
 
+// openAIResponseFormat 转换为 OpenAI response_format 参数
+func openAIResponseFormat(format *types.ResponseFormat) map[string]interface{} {
+	if format.Type != llm.ResponseFormatJSONSchema {
+		return map[string]interface{}{"type": format.Type}
+	}
+
+	name := format.Name
+	if name == "" {
+		name = "response"
+	}
+	return map[string]interface{}{
+		"type": llm.ResponseFormatJSONSchema,
+		"json_schema": map[string]interface{}{
+			"name":   name,
+			"schema": format.Schema,
+			"strict": format.Strict,
+		},
+	}
+}
+
+// structuredOutputModels 支持某种结构化输出格式的模型（前缀匹配）
+type structuredOutputModels struct {
+	supported   []string // 支持的模型前缀
+	unsupported []string // 匹配 supported 但实际不支持的模型前缀（优先判断）
+}
+
+// structuredOutputSupport 各服务商类型的结构化输出支持：服务商类型 -> 格式类型 -> 模型
+// 未登记的服务商类型、格式或模型均视为不支持，避免把 response_format 发给会忽略或拒绝它的模型
+var structuredOutputSupport = map[string]map[string]structuredOutputModels{
+	"openai": {
+		llm.ResponseFormatJSONObject: {
+			supported:   []string{"gpt-3.5-turbo", "gpt-4-turbo", "gpt-4-1106", "gpt-4-0125", "gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4"},
+			unsupported: []string{"o1-mini", "o1-preview"},
+		},
+		llm.ResponseFormatJSONSchema: {
+			supported:   []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4"},
+			unsupported: []string{"gpt-4o-2024-05-13", "o1-mini", "o1-preview"},
+		},
+	},
+	"siliconflow": {
+		// SiliconFlow 只支持 json_object（JSON 模式）
+		llm.ResponseFormatJSONObject: {
+			supported: []string{"deepseek-ai/DeepSeek-V", "deepseek-ai/DeepSeek-R1", "Qwen/Qwen2.5-", "Qwen/Qwen3-", "THUDM/glm-4", "zai-org/GLM-4"},
+		},
+	},
+}
+
+// SupportsResponseFormat 实现 llm.StructuredOutputProvider（按服务商类型与模型判断，未登记的模型只支持 text）
+func (p *OpenAIProvider) SupportsResponseFormat(model, formatType string) bool {
+	if formatType == llm.ResponseFormatText {
+		return true
+	}
+
+	models, ok := structuredOutputSupport[p.providerType][formatType]
+	if !ok {
+		return false
+	}
+	for _, prefix := range models.unsupported {
+		if strings.HasPrefix(model, prefix) {
+			return false
+		}
+	}
+	for _, prefix := range models.supported {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // convertMessages 转换消息格式
 func (p *OpenAIProvider) convertMessages(messages []llm.Message) []map[string]interface{} {
 	var result []map[string]interface{}
@@ -190,6 +335,7 @@ func (p *OpenAIProvider) readStream(body io.ReadCloser, eventChan chan<- llm.Str
 
 	scanner := bufio.NewScanner(body)
 	tokenIndex := 0
+	reasoningIndex := 0
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -232,6 +378,16 @@ func (p *OpenAIProvider) readStream(body io.ReadCloser, eventChan chan<- llm.Str
 				return
 			}
 
+			// 推理模型（如 DeepSeek-R1）的思考内容单独下发，不混入回答 token
+			if choice.Delta.ReasoningContent != "" {
+				eventChan <- llm.StreamEvent{
+					Type:    llm.EventReasoning,
+					Content: choice.Delta.ReasoningContent,
+					Index:   reasoningIndex,
+				}
+				reasoningIndex++
+			}
+
 			// 发送 token
 			if choice.Delta.Content != "" {
 				eventChan <- llm.StreamEvent{
@@ -269,6 +425,26 @@ type OpenAIStreamChoice struct {
 }
 
 type OpenAIDelta struct {
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"` // 推理过程（DeepSeek-R1 等）
+}
+
+type OpenAIChatCompletion struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Choices []OpenAIChatChoice `json:"choices"`
+	Usage   *OpenAIUsage       `json:"usage,omitempty"`
+}
+
+type OpenAIChatChoice struct {
+	Index        int         `json:"index"`
+	Message      OpenAIDelta `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	aitypes "github.com/lk2023060901/ai-writer-backend/internal/ai/provider/types"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/llm"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+// deepSeekReasonerStream DeepSeek-R1 流式响应录制（思考内容在 reasoning_content，回答在 content）
+const deepSeekReasonerStream = `data: {"id":"1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"role":"assistant","content":null,"reasoning_content":""},"finish_reason":null}]}
+
+data: {"id":"1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":null,"reasoning_content":"用户问 1+1，"},"finish_reason":null}]}
+
+data: {"id":"1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":null,"reasoning_content":"答案是 2。"},"finish_reason":null}]}
+
+data: {"id":"1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":"1+1","reasoning_content":null},"finish_reason":null}]}
+
+data: {"id":"1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":" 等于 2。","reasoning_content":null},"finish_reason":null}]}
+
+data: {"id":"1","object":"chat.completion.chunk","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":"","reasoning_content":null},"finish_reason":"stop"}]}
+
+data: [DONE]
+`
+
+func TestOpenAIReadStream_SeparatesReasoning(t *testing.T) {
+	p := &OpenAIProvider{}
+	eventChan := make(chan llm.StreamEvent, 16)
+	go p.readStream(io.NopCloser(strings.NewReader(deepSeekReasonerStream)), eventChan)
+
+	var reasoning, answer strings.Builder
+	var reasoningIndexes, tokenIndexes []int
+	var done bool
+	for event := range eventChan {
+		switch event.Type {
+		case llm.EventReasoning:
+			reasoning.WriteString(event.Content)
+			reasoningIndexes = append(reasoningIndexes, event.Index)
+		case llm.EventToken:
+			answer.WriteString(event.Content)
+			tokenIndexes = append(tokenIndexes, event.Index)
+		case llm.EventDone:
+			done = true
+		case llm.EventError:
+			t.Fatalf("Unexpected error event: %v", event.Error)
+		}
+	}
+
+	if got := reasoning.String(); got != "用户问 1+1，答案是 2。" {
+		t.Errorf("Expected reasoning content, got %q", got)
+	}
+	if got := answer.String(); got != "1+1 等于 2。" {
+		t.Errorf("Expected answer content without reasoning, got %q", got)
+	}
+	if len(reasoningIndexes) != 2 || reasoningIndexes[0] != 0 || reasoningIndexes[1] != 1 {
+		t.Errorf("Expected reasoning indexes [0 1], got %v", reasoningIndexes)
+	}
+	if len(tokenIndexes) != 2 || tokenIndexes[0] != 0 || tokenIndexes[1] != 1 {
+		t.Errorf("Expected token indexes [0 1], got %v", tokenIndexes)
+	}
+	if !done {
+		t.Error("Expected a done event")
+	}
+}
+
+func TestOpenAIConvertRequest_ResponseFormat(t *testing.T) {
+	p := &OpenAIProvider{}
+	schema := map[string]interface{}{"type": "object"}
+	req := &llm.ChatRequest{
+		Model:           "gpt-4o",
+		ResponseFormat:  &types.ResponseFormat{Type: llm.ResponseFormatJSONSchema, Schema: schema, Strict: true},
+		ProviderOptions: map[string]interface{}{"response_format": map[string]interface{}{"type": "text"}},
+	}
+
+	format, ok := p.convertRequest(req)["response_format"].(map[string]interface{})
+	if !ok || format["type"] != llm.ResponseFormatJSONSchema {
+		t.Fatalf("Expected json_schema response_format to override provider options, got %v", format)
+	}
+	jsonSchema := format["json_schema"].(map[string]interface{})
+	if jsonSchema["name"] != "response" || jsonSchema["strict"] != true || jsonSchema["schema"] == nil {
+		t.Errorf("Unexpected json_schema payload %v", jsonSchema)
+	}
+}
+
+func TestOpenAISupportsResponseFormat(t *testing.T) {
+	openai := NewOpenAIProvider("sk-test", "")
+	siliconflow := NewOpenAICompatibleProvider("siliconflow", "sk-test", "")
+
+	tests := []struct {
+		name     string
+		provider *OpenAIProvider
+		model    string
+		format   string
+		want     bool
+	}{
+		{name: "gpt-4o-mini json_schema", provider: openai, model: "gpt-4o-mini", format: llm.ResponseFormatJSONSchema, want: true},
+		{name: "gpt-3.5-turbo json_object", provider: openai, model: "gpt-3.5-turbo", format: llm.ResponseFormatJSONObject, want: true},
+		{name: "gpt-3.5-turbo json_schema", provider: openai, model: "gpt-3.5-turbo", format: llm.ResponseFormatJSONSchema},
+		{name: "gpt-4 base model", provider: openai, model: "gpt-4", format: llm.ResponseFormatJSONObject},
+		{name: "o1-mini", provider: openai, model: "o1-mini", format: llm.ResponseFormatJSONObject},
+		{name: "unknown model on openai", provider: openai, model: "Qwen/Qwen2.5-7B-Instruct", format: llm.ResponseFormatJSONObject},
+		{name: "siliconflow qwen json_object", provider: siliconflow, model: "Qwen/Qwen2.5-7B-Instruct", format: llm.ResponseFormatJSONObject, want: true},
+		{name: "siliconflow json_schema", provider: siliconflow, model: "Qwen/Qwen2.5-7B-Instruct", format: llm.ResponseFormatJSONSchema},
+		{name: "openai model on siliconflow", provider: siliconflow, model: "gpt-4o", format: llm.ResponseFormatJSONObject},
+		{name: "unknown provider type", provider: NewOpenAICompatibleProvider("custom", "sk-test", ""), model: "gpt-4o", format: llm.ResponseFormatJSONObject},
+		{name: "text always supported", provider: siliconflow, model: "any-model", format: llm.ResponseFormatText, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.provider.SupportsResponseFormat(tt.model, tt.format); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOpenAIChatStream_ReturnsClassifiedStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached"}}`))
+	}))
+	defer server.Close()
+
+	p := NewOpenAIProvider("sk-test", server.URL)
+	_, err := p.ChatStream(context.Background(), &llm.ChatRequest{Model: "gpt-4o"})
+
+	var providerErr *aitypes.ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("Expected *ProviderError, got %T: %v", err, err)
+	}
+	if providerErr.Type != aitypes.ErrorTypeRateLimit || providerErr.StatusCode != http.StatusTooManyRequests || providerErr.Provider != "openai" {
+		t.Errorf("Unexpected provider error %+v", providerErr)
+	}
+}
@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"go.uber.org/zap"
+)
+
+// 结构化输出格式
+const (
+	ResponseFormatText       = "text"        // 普通文本（默认）
+	ResponseFormatJSONObject = "json_object" // 任意合法 JSON 对象
+	ResponseFormatJSONSchema = "json_schema" // 符合给定 JSON Schema 的 JSON
+)
+
+var (
+	// ErrInvalidResponseFormat 请求的 response_format 无效
+	ErrInvalidResponseFormat = errors.New("invalid response format")
+	// ErrResponseFormatUnsupported 服务商/模型不支持请求的结构化输出格式
+	ErrResponseFormatUnsupported = errors.New("response format not supported by provider")
+	// ErrInvalidStructuredOutput 修复重试后模型输出仍不是合法的结构化结果
+	ErrInvalidStructuredOutput = errors.New("model did not return valid structured output")
+)
+
+// StructuredOutputProvider 支持结构化输出的服务商（可选接口，未实现的服务商只支持 text）
+type StructuredOutputProvider interface {
+	// SupportsResponseFormat 是否支持指定的 response_format 类型（json_object/json_schema）
+	SupportsResponseFormat(model, formatType string) bool
+}
+
+// validateResponseFormat 校验请求中的 response_format
+func validateResponseFormat(format *types.ResponseFormat) error {
+	if format == nil {
+		return nil
+	}
+	switch format.Type {
+	case ResponseFormatText, ResponseFormatJSONObject:
+		return nil
+	case ResponseFormatJSONSchema:
+		if len(format.Schema) == 0 {
+			return fmt.Errorf("%w: json_schema requires a schema", ErrInvalidResponseFormat)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidResponseFormat, format.Type)
+	}
+}
+
+// requiresStructuredOutput 是否需要校验 JSON 输出
+func requiresStructuredOutput(format *types.ResponseFormat) bool {
+	return format != nil && format.Type != "" && format.Type != ResponseFormatText
+}
+
+// checkResponseFormatSupport 服务商不支持请求的格式时返回 ErrResponseFormatUnsupported
+func checkResponseFormatSupport(provider Provider, model string, format *types.ResponseFormat) error {
+	if !requiresStructuredOutput(format) {
+		return nil
+	}
+	structured, ok := provider.(StructuredOutputProvider)
+	if !ok || !structured.SupportsResponseFormat(model, format.Type) {
+		return fmt.Errorf("%w: %s does not support %s for model %s", ErrResponseFormatUnsupported, provider.Name(), format.Type, model)
+	}
+	return nil
+}
+
+// parseStructuredOutput 解析并校验模型输出，返回规范化的 JSON（去除 Markdown 代码块包裹）
+func parseStructuredOutput(content string, format *types.ResponseFormat) (string, error) {
+	trimmed := stripJSONCodeFence(content)
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(trimmed), &value); err != nil {
+		return "", fmt.Errorf("output is not valid JSON: %w", err)
+	}
+	if _, ok := value.(map[string]interface{}); !ok && format.Type == ResponseFormatJSONObject {
+		return "", fmt.Errorf("output is a JSON %s, expected an object", jsonTypeName(value))
+	}
+	if format.Type == ResponseFormatJSONSchema {
+		if err := validateJSONSchema(value, format.Schema, ""); err != nil {
+			return "", fmt.Errorf("output does not match schema: %w", err)
+		}
+	}
+	return trimmed, nil
+}
+
+// stripJSONCodeFence 去除 ```json ... ``` 包裹
+func stripJSONCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if newline := strings.IndexByte(trimmed, '\n'); newline >= 0 {
+		trimmed = trimmed[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(trimmed), "```"))
+}
+
+// chatStructured 非流式调用并校验结构化输出，不合法时附带错误信息要求模型修复后重试
+// 校验通过的 JSON 以与非流式回退相同的事件序列下发
+func (o *DefaultOrchestrator) chatStructured(ctx context.Context, provider Provider, req *ChatRequest) (<-chan StreamEvent, error) {
+	maxRepairs := o.config.StructuredOutputMaxRepairs
+	if maxRepairs < 0 {
+		maxRepairs = 0
+	}
+
+	attemptReq := *req
+	attemptReq.Stream = false
+	attemptReq.Messages = append([]Message(nil), req.Messages...)
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRepairs; attempt++ {
+		content, err := o.complete(ctx, provider, &attemptReq)
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := parseStructuredOutput(content, req.ResponseFormat)
+		if err == nil {
+			chunks := splitContent(output, o.config.NonStreamChunkSize)
+			eventChan := make(chan StreamEvent, len(chunks)+1)
+			eventChan <- StreamEvent{Type: EventStart}
+			for i, chunk := range chunks {
+				eventChan <- StreamEvent{Type: EventToken, Content: chunk, Index: i}
+			}
+			close(eventChan)
+			return eventChan, nil
+		}
+
+		lastErr = err
+		o.logger.Warn("Invalid structured output, requesting repair",
+			zap.String("provider", provider.Name()),
+			zap.String("model", req.Model),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err))
+
+		attemptReq.Messages = append(attemptReq.Messages,
+			Message{Role: "assistant", Content: []ContentBlock{{Type: "text", Text: content}}},
+			Message{Role: "user", Content: []ContentBlock{{Type: "text", Text: repairPrompt(req.ResponseFormat, err)}}},
+		)
+	}
+
+	return nil, fmt.Errorf("%w after %d attempts: %v", ErrInvalidStructuredOutput, maxRepairs+1, lastErr)
+}
+
+// complete 获取完整回复（优先非流式调用，否则汇总流式 token）
+func (o *DefaultOrchestrator) complete(ctx context.Context, provider Provider, req *ChatRequest) (string, error) {
+	if completer, ok := provider.(CompletionProvider); ok {
+		completion, err := completer.Chat(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return completion.Content, nil
+	}
+
+	streamChan, err := provider.ChatStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	var content strings.Builder
+	for event := range streamChan {
+		switch event.Type {
+		case EventToken:
+			content.WriteString(event.Content)
+		case EventError:
+			return "", event.Error
+		}
+	}
+	return content.String(), nil
+}
+
+// repairPrompt 构造要求模型修复输出的提示
+func repairPrompt(format *types.ResponseFormat, validationErr error) string {
+	prompt := fmt.Sprintf("你上一次的输出无法通过校验：%v。请只输出修正后的 JSON，不要包含任何解释或 Markdown 代码块。", validationErr)
+	if format.Type == ResponseFormatJSONSchema {
+		schema, _ := json.Marshal(format.Schema)
+		prompt += "\nJSON 必须符合以下 JSON Schema：\n" + string(schema)
+	}
+	return prompt
+}
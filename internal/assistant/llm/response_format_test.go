@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+)
+
+// sentimentFormat 分类任务的 json_schema 输出格式
+var sentimentFormat = &types.ResponseFormat{
+	Type: ResponseFormatJSONSchema,
+	Name: "sentiment",
+	Schema: map[string]interface{}{
+		"type":                 "object",
+		"required":             []interface{}{"label", "score"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"label": map[string]interface{}{"type": "string", "enum": []interface{}{"positive", "negative"}},
+			"score": map[string]interface{}{"type": "number"},
+		},
+	},
+}
+
+func runStructuredChat(t *testing.T, cfg *OrchestratorConfig, provider Provider, format *types.ResponseFormat) []*types.ChatResponse {
+	t.Helper()
+	o := newTestOrchestrator(cfg, map[string]Provider{"p1": provider}, nil, nil)
+	ch, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+		Message:        "这个产品太好用了",
+		Providers:      []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+		ResponseFormat: format,
+	})
+	if err != nil {
+		t.Fatalf("ChatStreamMulti failed: %v", err)
+	}
+	return collectResponses(ch)
+}
+
+func TestChatStreamMulti_StructuredOutputReturnsValidJSON(t *testing.T) {
+	provider := &fakeStructuredProvider{
+		fakeProvider: fakeProvider{name: "openai"},
+		replies:      []string{"```json\n{\"label\": \"positive\", \"score\": 0.9}\n```"},
+	}
+
+	responses := runStructuredChat(t, nil, provider, sentimentFormat)
+
+	if errs := responsesOfType(responses, "error"); len(errs) != 0 {
+		t.Fatalf("Unexpected error: %s", errs[0].Error)
+	}
+	done := responsesOfType(responses, "done")
+	if len(done) != 1 || done[0].Content != `{"label": "positive", "score": 0.9}` {
+		t.Fatalf("Expected fenced JSON to be unwrapped, got %+v", done)
+	}
+	req := provider.lastRequest()
+	if provider.calls() != 1 || req.ResponseFormat != sentimentFormat || req.Stream {
+		t.Errorf("Expected a single non-streaming call carrying the response format, got %d calls, %+v", provider.calls(), req)
+	}
+}
+
+func TestChatStreamMulti_StructuredOutputRepairsInvalidOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		first string
+	}{
+		{name: "malformed JSON", first: `{"label": "positive", "score": `},
+		{name: "schema violation", first: `{"label": "neutral", "score": 0.5}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeStructuredProvider{
+				fakeProvider: fakeProvider{name: "openai"},
+				replies:      []string{tt.first, `{"label": "positive", "score": 0.9}`},
+			}
+
+			responses := runStructuredChat(t, nil, provider, sentimentFormat)
+
+			done := responsesOfType(responses, "done")
+			if len(done) != 1 || done[0].Content != `{"label": "positive", "score": 0.9}` {
+				t.Fatalf("Expected repaired JSON, got %+v", responses)
+			}
+			if provider.calls() != 2 {
+				t.Fatalf("Expected one repair retry, got %d calls", provider.calls())
+			}
+
+			retry := provider.lastRequest()
+			n := len(retry.Messages)
+			if n < 2 || retry.Messages[n-2].Role != "assistant" || retry.Messages[n-2].Content[0].Text != tt.first {
+				t.Fatalf("Expected invalid output echoed as assistant message, got %+v", retry.Messages)
+			}
+			if last := retry.Messages[n-1]; last.Role != "user" || !strings.Contains(last.Content[0].Text, "JSON Schema") {
+				t.Errorf("Expected repair prompt with schema, got %+v", last)
+			}
+		})
+	}
+}
+
+func TestChatStreamMulti_StructuredOutputGivesUpAfterRepairs(t *testing.T) {
+	provider := &fakeStructuredProvider{
+		fakeProvider: fakeProvider{name: "openai"},
+		replies:      []string{"I think it is positive."},
+	}
+	cfg := DefaultOrchestratorConfig()
+	cfg.StructuredOutputMaxRepairs = 1
+
+	responses := runStructuredChat(t, cfg, provider, &types.ResponseFormat{Type: ResponseFormatJSONObject})
+
+	errs := responsesOfType(responses, "error")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error, ErrInvalidStructuredOutput.Error()) {
+		t.Fatalf("Expected invalid structured output error, got %+v", responses)
+	}
+	if provider.calls() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", provider.calls())
+	}
+}
+
+func TestChatStreamMulti_StructuredOutputUnsupportedProvider(t *testing.T) {
+	provider := &fakeProvider{name: "anthropic", tokens: []string{"{}"}}
+
+	responses := runStructuredChat(t, nil, provider, &types.ResponseFormat{Type: ResponseFormatJSONObject})
+
+	errs := responsesOfType(responses, "error")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error, ErrResponseFormatUnsupported.Error()) {
+		t.Fatalf("Expected unsupported response format error, got %+v", responses)
+	}
+	if provider.lastRequest() != nil {
+		t.Error("Expected provider not to be called")
+	}
+}
+
+func TestChatStreamMulti_InvalidResponseFormat(t *testing.T) {
+	o := newTestOrchestrator(nil, map[string]Provider{"p1": &fakeProvider{name: "openai"}}, nil, nil)
+
+	for _, format := range []*types.ResponseFormat{{Type: "xml"}, {Type: ResponseFormatJSONSchema}} {
+		_, err := o.ChatStreamMulti(context.Background(), &types.ChatRequest{
+			Message:        "hello",
+			Providers:      []types.ProviderConfig{{Provider: "p1", Model: "gpt-4o"}},
+			ResponseFormat: format,
+		})
+		if !errors.Is(err, ErrInvalidResponseFormat) {
+			t.Errorf("Expected ErrInvalidResponseFormat for %+v, got %v", format, err)
+		}
+	}
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"tags"},
+		"properties": map[string]interface{}{
+			"tags":  map[string]interface{}{"type": "array", "minItems": float64(1), "items": map[string]interface{}{"type": "string"}},
+			"count": map[string]interface{}{"type": "integer"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr string
+	}{
+		{name: "valid", value: map[string]interface{}{"tags": []interface{}{"a"}, "count": float64(2)}},
+		{name: "missing required", value: map[string]interface{}{}, wantErr: `missing required property "tags"`},
+		{name: "empty array", value: map[string]interface{}{"tags": []interface{}{}}, wantErr: "$.tags: expected at least 1 items"},
+		{name: "wrong item type", value: map[string]interface{}{"tags": []interface{}{float64(1)}}, wantErr: "$.tags[0]: expected type string"},
+		{name: "non-integer", value: map[string]interface{}{"tags": []interface{}{"a"}, "count": 1.5}, wantErr: "$.count: expected type integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJSONSchema(tt.value, schema, "")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
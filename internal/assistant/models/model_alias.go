@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ModelAlias is the GORM model for model_aliases table
+type ModelAlias struct {
+	Alias       string `gorm:"primaryKey;type:varchar(100)"`
+	Model       string `gorm:"type:varchar(200);not null;default:''"`
+	Description string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName specifies the table name
+func (ModelAlias) TableName() string {
+	return "model_aliases"
+}
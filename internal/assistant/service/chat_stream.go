@@ -2,6 +2,7 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -96,6 +97,12 @@ func (s *AssistantService) ChatStreamV2(c *gin.Context) {
 	// 调用多服务商并发流式响应
 	responseChan, err := orchestrator.ChatStreamMulti(ctx, &req)
 	if err != nil {
+		if errors.Is(err, llm.ErrFileProcessing) {
+			// 附件错误只返回分类后的提示，不暴露附件地址与下载细节
+			logger.Warn("处理聊天附件失败", zap.Error(err))
+			s.writeSSEError(c, llm.ClientErrorMessage(err))
+			return
+		}
 		s.writeSSEError(c, fmt.Sprintf("failed to start chat stream: %v", err))
 		return
 	}
@@ -308,6 +315,12 @@ func (s *AssistantService) ChatStreamLegacy(c *gin.Context) {
 	ctx := c.Request.Context()
 	responseChan, err := orchestrator.ChatStreamMulti(ctx, &chatReq)
 	if err != nil {
+		if errors.Is(err, llm.ErrFileProcessing) {
+			// 附件错误只返回分类后的提示，不暴露附件地址与下载细节
+			logger.Warn("处理聊天附件失败", zap.Error(err))
+			s.writeSSEError(c, llm.ClientErrorMessage(err))
+			return
+		}
 		s.writeSSEError(c, fmt.Sprintf("failed to start chat stream: %v", err))
 		return
 	}
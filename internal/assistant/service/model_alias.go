@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+)
+
+// ModelAliasService handles HTTP requests for model alias administration
+type ModelAliasService struct {
+	useCase *biz.ModelAliasUseCase
+}
+
+// NewModelAliasService creates a new model alias service
+func NewModelAliasService(useCase *biz.ModelAliasUseCase) *ModelAliasService {
+	return &ModelAliasService{
+		useCase: useCase,
+	}
+}
+
+// RegisterRoutes registers model alias routes (mount on an admin group)
+func (s *ModelAliasService) RegisterRoutes(r *gin.RouterGroup) {
+	aliases := r.Group("/model-aliases")
+	{
+		aliases.GET("", s.ListAliases)
+		aliases.PUT("/:alias", s.SetAlias)
+		aliases.DELETE("/:alias", s.DeleteAlias)
+	}
+}
+
+// SetModelAliasRequest represents the request to create or update an alias
+type SetModelAliasRequest struct {
+	Model       string `json:"model"` // empty leaves the alias unmapped
+	Description string `json:"description"`
+}
+
+// ListAliases lists all model aliases
+// @Summary List model aliases
+// @Tags model-aliases
+// @Produce json
+// @Success 200 {array} types.ModelAlias
+// @Router /api/v1/admin/model-aliases [get]
+func (s *ModelAliasService) ListAliases(c *gin.Context) {
+	aliases, err := s.useCase.ListAliases(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, aliases)
+}
+
+// SetAlias creates or updates a model alias
+// @Summary Create or update a model alias
+// @Tags model-aliases
+// @Accept json
+// @Produce json
+// @Param alias path string true "Alias"
+// @Param request body SetModelAliasRequest true "Target model"
+// @Success 200 {object} types.ModelAlias
+// @Router /api/v1/admin/model-aliases/{alias} [put]
+func (s *ModelAliasService) SetAlias(c *gin.Context) {
+	var req SetModelAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias, err := s.useCase.SetAlias(c.Request.Context(), c.Param("alias"), req.Model, req.Description)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, alias)
+}
+
+// DeleteAlias deletes a model alias
+// @Summary Delete a model alias
+// @Tags model-aliases
+// @Param alias path string true "Alias"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/admin/model-aliases/{alias} [delete]
+func (s *ModelAliasService) DeleteAlias(c *gin.Context) {
+	if err := s.useCase.DeleteAlias(c.Request.Context(), c.Param("alias")); err != nil {
+		if errors.Is(err, biz.ErrModelAliasNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "model alias deleted"})
+}
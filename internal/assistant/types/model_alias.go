@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// ModelAlias maps a stable alias (e.g. "claude-sonnet-latest") to a concrete model ID
+type ModelAlias struct {
+	Alias       string    `json:"alias"`
+	Model       string    `json:"model"` // 为空表示未映射
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -24,6 +24,15 @@ type ChatRequest struct {
 	Temperature     *float64         `json:"temperature,omitempty"`
 	MaxTokens       *int             `json:"max_tokens,omitempty"`
 	SystemPrompt    string           `json:"system_prompt,omitempty"`
+	ResponseFormat  *ResponseFormat  `json:"response_format,omitempty"` // 结构化输出（为空表示普通文本）
+}
+
+// ResponseFormat 结构化输出格式
+type ResponseFormat struct {
+	Type   string                 `json:"type"`             // text | json_object | json_schema
+	Name   string                 `json:"name,omitempty"`   // json_schema 名称（默认 response）
+	Schema map[string]interface{} `json:"schema,omitempty"` // JSON Schema（json_schema 时必填）
+	Strict bool                   `json:"strict,omitempty"` // 要求服务商严格遵循 schema（如 OpenAI strict 模式）
 }
 
 // MessageContentBlock 消息内容块（支持文本、图片、文件等）
@@ -73,7 +82,7 @@ type ChatResponse struct {
 	Model      string `json:"model"`       // 当前使用的模型
 
 	// 响应内容
-	EventType string                 `json:"event_type"` // start | token | done | error
+	EventType string                 `json:"event_type"` // start | token | reasoning | done | error | warning
 	Content   string                 `json:"content,omitempty"`
 	Index     int                    `json:"index,omitempty"`
 
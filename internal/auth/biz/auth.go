@@ -29,6 +29,7 @@ type User struct {
 	ID                  string // UUID v7
 	Name                string
 	Email               string
+	Role                string // user / admin
 	PasswordHash        string
 	EmailVerified       bool
 	TwoFactorEnabled    bool
@@ -108,6 +109,7 @@ func (uc *AuthUseCase) Register(ctx context.Context, name, email, password strin
 		ID:                         userID,
 		Name:                       name,
 		Email:                      email,
+		Role:                       auth.RoleUser,
 		PasswordHash:               string(passwordHash),
 		EmailVerified:              false,
 		EmailVerificationToken:     &verificationToken,
@@ -240,7 +242,7 @@ func (uc *AuthUseCase) RefreshAccessToken(ctx context.Context, refreshToken stri
 	}
 
 	// 生成新的 access token
-	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Email)
+	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -345,7 +347,7 @@ func (uc *AuthUseCase) Disable2FA(ctx context.Context, userID string, code strin
 // generateTokens 生成 token 对
 func (uc *AuthUseCase) generateTokens(ctx context.Context, user *User, ip string, rememberMe bool) (*LoginResult, error) {
 	// 生成 access token
-	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Email)
+	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +146,7 @@ func (r *AuthUserRepo) toUserPO(user *biz.User) *data.UserPO {
 		ID:                         user.ID,
 		Name:                       user.Name,
 		Email:                      user.Email,
+		Role:                       user.Role,
 		PasswordHash:               user.PasswordHash,
 		EmailVerified:              user.EmailVerified,
 		RefreshToken:               user.RefreshToken,
@@ -176,6 +177,7 @@ func (r *AuthUserRepo) toBizUser(po *data.UserPO) *biz.User {
 		ID:                         po.ID,
 		Name:                       po.Name,
 		Email:                      po.Email,
+		Role:                       po.Role,
 		PasswordHash:               po.PasswordHash,
 		EmailVerified:              po.EmailVerified,
 		TwoFactorEnabled:           po.TwoFactorEnabled,
@@ -15,10 +15,17 @@ const (
 	RefreshTokenDuration = 14 * 24 * time.Hour // Refresh Token 有效期（14天）
 )
 
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户
+	RoleAdmin = "admin" // 管理员
+)
+
 // JWTClaims JWT 声明
 type JWTClaims struct {
 	UserID string `json:"user_id"` // UUID
 	Email  string `json:"email"`
+	Role   string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -35,10 +42,11 @@ func NewJWTManager(secretKey string) *JWTManager {
 }
 
 // GenerateAccessToken 生成 Access Token
-func (m *JWTManager) GenerateAccessToken(userID string, email string) (string, error) {
+func (m *JWTManager) GenerateAccessToken(userID string, email string, role string) (string, error) {
 	claims := &JWTClaims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
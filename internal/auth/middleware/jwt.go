@@ -50,6 +50,7 @@ func JWTAuth(jwtSecret string, log *logger.Logger) gin.HandlerFunc {
 		// 将用户信息注入到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
 
 		c.Next()
 	}
@@ -80,6 +81,7 @@ func OptionalJWTAuth(jwtSecret string, log *logger.Logger) gin.HandlerFunc {
 
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
 		c.Next()
 	}
 }
@@ -87,16 +89,15 @@ func OptionalJWTAuth(jwtSecret string, log *logger.Logger) gin.HandlerFunc {
 // RequireRole 角色验证中间件（需要先经过 JWTAuth）
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从上下文获取用户角色（需要在 JWT claims 中添加 role 字段）
-		userRole, exists := c.Get("role")
-		if !exists {
+		// 从上下文获取用户角色（由 JWTAuth 从 access token 的 role 字段注入）
+		roleStr := c.GetString("role")
+		if roleStr == "" {
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 			c.Abort()
 			return
 		}
 
 		// 检查角色
-		roleStr := userRole.(string)
 		for _, role := range roles {
 			if roleStr == role {
 				c.Next()
@@ -18,12 +18,19 @@ type Config struct {
 	Auth      AuthConfig
 	Email     EmailConfig
 	OAuth2    OAuth2Config
+	Knowledge KnowledgeConfig
+	LLM       LLMConfig
+	Seed      SeedConfig
 }
 
 type ServerConfig struct {
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	GRPCPort int    `mapstructure:"grpc_port"`
+
+	// 内部指标监听（仅提供 /metrics，不挂在对外 API 路由上）
+	MetricsHost string `mapstructure:"metrics_host"` // 默认 127.0.0.1
+	MetricsPort int    `mapstructure:"metrics_port"` // 0 表示不暴露指标
 }
 
 type DatabaseConfig struct {
@@ -51,8 +58,35 @@ type MinIOConfig struct {
 }
 
 type MilvusConfig struct {
-	Host string
-	Port int
+	Host     string
+	Port     int
+	Address  string            `mapstructure:"address"`  // 完整地址（优先于 host/port，如 Zilliz Cloud 的 https://xxx.zillizcloud.com:443）
+	Username string            `mapstructure:"username"` // 用户名密码认证（可选）
+	Password string            `mapstructure:"password"`
+	APIKey   string            `mapstructure:"api_key"` // API Key 认证（可选，与用户名密码二选一）
+	Database string            `mapstructure:"database"`
+	TLS      MilvusTLSConfig   `mapstructure:"tls"`
+	Retry    MilvusRetryConfig `mapstructure:"retry"`
+
+	AutoFlush              bool   `mapstructure:"auto_flush"`               // 插入向量后不显式 Flush，依赖 Milvus 自动刷新（吞吐更高，新向量短时间内可能搜索不到）
+	SearchConsistencyLevel string `mapstructure:"search_consistency_level"` // 搜索一致性级别: Strong, Bounded, Session, Eventually（为空使用 collection 默认级别）
+}
+
+// MilvusTLSConfig Milvus TLS 配置（默认不启用）
+type MilvusTLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CACertFile         string `mapstructure:"ca_cert_file"`         // 校验服务端证书的 CA（为空使用系统根证书）
+	CertFile           string `mapstructure:"cert_file"`            // 双向 TLS 客户端证书
+	KeyFile            string `mapstructure:"key_file"`             // 双向 TLS 客户端私钥
+	ServerName         string `mapstructure:"server_name"`          // 证书校验使用的服务器名称
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // 跳过证书校验（仅用于测试）
+}
+
+// MilvusRetryConfig 向量操作瞬时错误（服务不可用、限流等）的重试配置，未配置时使用默认值
+type MilvusRetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`    // 最大尝试次数（含首次）
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 首次重试等待时间，之后每次翻倍
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // 单次等待时间上限
 }
 
 type LogConfig struct {
@@ -111,6 +145,60 @@ type OAuth2Config struct {
 	TokenURL     string   `mapstructure:"token_url"`
 }
 
+// KnowledgeConfig 知识库文档处理配置
+type KnowledgeConfig struct {
+	EmptyContentPolicy       string        `mapstructure:"empty_content_policy"`         // fail, mark-empty, retry-with-ocr
+	VectorSearchTimeout      time.Duration `mapstructure:"vector_search_timeout"`        // 向量搜索超时（0 表示不限制）
+	DuplicateDocumentPolicy  string        `mapstructure:"duplicate_document_policy"`    // allow, reject, return-existing
+	CompactAfterDeletes      int           `mapstructure:"compact_after_deletes"`        // 每个 collection 删除多少文档后自动压缩（0 表示不自动压缩）
+	CompactionTimeout        time.Duration `mapstructure:"compaction_timeout"`           // 后台压缩超时
+	SystemProviderIDs        []string      `mapstructure:"system_provider_ids"`          // 系统知识库允许使用的服务商 ID（为空表示不限制）
+	SystemEmbeddingModelID   string        `mapstructure:"system_embedding_model_id"`    // 系统知识库默认 Embedding 模型 ID
+	VectorContentMaxBytes    int           `mapstructure:"vector_content_max_bytes"`     // 写入 Milvus 的分块内容最大字节数（不超过 65535）
+	VectorContentPolicy      string        `mapstructure:"vector_content_policy"`        // truncate, reject
+	EmbeddingCostPer1KTokens float64       `mapstructure:"embedding_cost_per_1k_tokens"` // 每千 token 的 Embedding 单价（用于估算文档处理成本）
+	StrictChunkStrategy      bool          `mapstructure:"strict_chunk_strategy"`        // 不支持的分块策略直接失败（默认回退为 fixed）
+	BackupEmbeddingModelID   string        `mapstructure:"backup_embedding_model_id"`    // 主 Embedding 模型不可用时的备用模型 ID（需为同一模型的其他部署）
+	MaxSearchTopK            int           `mapstructure:"max_search_top_k"`             // 单次搜索的 topK 上限（默认 100）
+	MinResultContentLength   int           `mapstructure:"min_result_content_length"`    // 搜索结果内容的最小字符数（0 表示不过滤）
+	ModelSyncTimeout         time.Duration `mapstructure:"model_sync_timeout"`           // 单个服务商模型同步的截止时间（默认 2m）
+	ModelProbeConcurrency    int           `mapstructure:"model_probe_concurrency"`      // 模型同步时 embedding 维度探测并发数（默认 4）
+	MissingObjectPolicy      string        `mapstructure:"missing_object_policy"`        // reupload, skip-check
+	SkipUnchangedReprocess   bool          `mapstructure:"skip_unchanged_reprocess"`     // 文件与分块配置未变化时跳过重新处理
+	ModelListMaxPageSize     int           `mapstructure:"model_list_max_page_size"`     // 服务商模型列表单页数量上限（默认 200）
+
+	// 服务商类型 -> Embedding 请求格式（openai, cohere, gemini），未配置的类型使用默认映射
+	EmbeddingPayloadFormats map[string]string `mapstructure:"embedding_payload_formats"`
+}
+
+// SeedConfig 官方智能体与助手种子数据配置
+type SeedConfig struct {
+	Disabled bool   `mapstructure:"disabled"` // 关闭启动时写入
+	File     string `mapstructure:"file"`     // 种子定义 YAML 文件（为空使用内置默认值）
+}
+
+// LLMConfig 对话编排配置
+type LLMConfig struct {
+	ProviderOptionPolicy       string `mapstructure:"provider_option_policy"`        // reject, warn
+	KnowledgeSearchErrorPolicy string `mapstructure:"knowledge_search_error_policy"` // proceed, fail, warn
+	KnowledgeTemplate          string `mapstructure:"knowledge_template"`            // 知识库注入模板（text/template）
+	KnowledgeContextRole       string `mapstructure:"knowledge_context_role"`        // user, system
+	NonStreamChunkSize         int    `mapstructure:"non_stream_chunk_size"`         // 非流式模型回退时每个 token 事件的字符数（0 为整段）
+	MaxProvidersPerRequest     int    `mapstructure:"max_providers_per_request"`     // 单次请求最大服务商数（默认 5）
+	StructuredOutputMaxRepairs int    `mapstructure:"structured_output_max_repairs"` // 结构化输出不合法时的修复重试次数（默认 2，负数表示不重试）
+	HistoryMaxMessages         int    `mapstructure:"history_max_messages"`          // 携带的会话历史消息条数（默认 20，负数表示不携带）
+	HistoryTokenBudget         int    `mapstructure:"history_token_budget"`          // 会话历史的 token 预算（默认 8000，负数表示不限制）
+
+	// 聊天附件
+	FileMaxSize         int64    `mapstructure:"file_max_size"`          // 附件大小上限（字节，默认 10MB，负数表示不限制）
+	FileContentMaxChars int      `mapstructure:"file_content_max_chars"` // 注入的附件文本字符数上限（默认 20000，负数表示不截断）
+	FileAllowedHosts    []string `mapstructure:"file_allowed_hosts"`     // 允许下载附件的主机（为空时使用 MinIO 地址）
+
+	// 服务商调用失败重试
+	ProviderMaxRetries   int           `mapstructure:"provider_max_retries"`   // 可重试错误的重试次数（默认 1，负数表示不重试）
+	ProviderRetryBackoff time.Duration `mapstructure:"provider_retry_backoff"` // 重试退避间隔（默认 500ms）
+}
+
 func LoadConfig(path string) (*Config, error) {
 	viper.SetConfigFile(path)
 	viper.AutomaticEnv()
@@ -124,9 +212,28 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := config.Knowledge.validate(); err != nil {
+		return nil, fmt.Errorf("invalid knowledge config: %w", err)
+	}
+
 	return &config, nil
 }
 
+// validate 校验知识库策略配置（为空表示使用默认值），避免拼写错误的策略在运行时被静默忽略
+func (c *KnowledgeConfig) validate() error {
+	switch c.EmptyContentPolicy {
+	case "", "fail", "mark-empty", "retry-with-ocr":
+	default:
+		return fmt.Errorf("empty_content_policy must be one of fail, mark-empty, retry-with-ocr, got %q", c.EmptyContentPolicy)
+	}
+	switch c.MissingObjectPolicy {
+	case "", "reupload", "skip-check":
+	default:
+		return fmt.Errorf("missing_object_policy must be one of reupload, skip-check, got %q", c.MissingObjectPolicy)
+	}
+	return nil
+}
+
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
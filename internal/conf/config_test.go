@@ -0,0 +1,42 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_ValidatesKnowledgePolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "defaults", yaml: "knowledge: {}\n"},
+		{name: "valid empty content policy", yaml: "knowledge:\n  empty_content_policy: retry-with-ocr\n"},
+		{name: "invalid empty content policy", yaml: "knowledge:\n  empty_content_policy: retry-ocr\n", wantErr: "empty_content_policy"},
+		{name: "valid missing object policy", yaml: "knowledge:\n  missing_object_policy: skip-check\n"},
+		{name: "invalid missing object policy", yaml: "knowledge:\n  missing_object_policy: skip\n", wantErr: "missing_object_policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+
+			_, err := LoadConfig(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadConfig failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error mentioning %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
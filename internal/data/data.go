@@ -178,11 +178,31 @@ func initMinIO(config *conf.Config, log *zap.Logger) (*pkgminio.Client, error) {
 }
 
 func initMilvus(config *conf.Config, log *pkglogger.Logger) (*pkgmilvus.Client, error) {
-	addr := fmt.Sprintf("%s:%d", config.Milvus.Host, config.Milvus.Port)
-	milvusCfg := &pkgmilvus.Config{
-		Address:  addr,
-		Database: "default",
-	}
+	return pkgmilvus.New(context.Background(), newMilvusConfig(&config.Milvus), log)
+}
 
-	return pkgmilvus.New(context.Background(), milvusCfg, log)
+// newMilvusConfig 将应用配置转换为 Milvus 客户端配置（地址、认证、TLS）
+func newMilvusConfig(cfg *conf.MilvusConfig) *pkgmilvus.Config {
+	addr := cfg.Address
+	if addr == "" {
+		addr = fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	}
+	database := cfg.Database
+	if database == "" {
+		database = "default"
+	}
+
+	return &pkgmilvus.Config{
+		Address:            addr,
+		Username:           cfg.Username,
+		Password:           cfg.Password,
+		APIKey:             cfg.APIKey,
+		Database:           database,
+		EnableTLS:          cfg.TLS.Enabled,
+		CACertFile:         cfg.TLS.CACertFile,
+		CertFile:           cfg.TLS.CertFile,
+		KeyFile:            cfg.TLS.KeyFile,
+		ServerName:         cfg.TLS.ServerName,
+		InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+	}
 }
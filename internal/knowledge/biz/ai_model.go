@@ -29,10 +29,20 @@ type AIModel struct {
 	UpdatedAt time.Time
 }
 
+// ListAIModelsRequest 模型分页查询请求
+type ListAIModelsRequest struct {
+	ProviderID     string // 服务商 ID
+	CapabilityType string // 能力类型过滤（可选）
+	IsEnabled      *bool  // 启用状态过滤（可选）
+	Page           int    // 页码
+	PageSize       int    // 每页数量（0 表示不分页）
+}
+
 // AIModelRepo AI模型仓储接口
 type AIModelRepo interface {
 	GetByID(ctx context.Context, id string) (*AIModel, error)
 	ListByProviderID(ctx context.Context, providerID string) ([]*AIModel, error)
+	List(ctx context.Context, req *ListAIModelsRequest) ([]*AIModel, int64, error)       // 分页查询（按 model_name 排序），返回过滤后的总数
 	ListByCapabilityType(ctx context.Context, capabilityType string) ([]*AIModel, error) // 根据能力类型查询（在 JSONB 数组中查找）
 	ListAll(ctx context.Context) ([]*AIModel, error)
 	Create(ctx context.Context, model *AIModel) error
@@ -40,14 +50,27 @@ type AIModelRepo interface {
 	Delete(ctx context.Context, id string) error
 }
 
+// defaultMaxModelPageSize 模型列表单页数量上限默认值
+const defaultMaxModelPageSize = 200
+
+// AIModelConfig AI模型配置
+type AIModelConfig struct {
+	MaxPageSize int // 模型列表单页数量上限（0 使用默认值）；只指定 page 时按上限返回
+}
+
 // AIModelUseCase AI模型用例
 type AIModelUseCase struct {
-	repo AIModelRepo
+	repo        AIModelRepo
+	maxPageSize int
 }
 
 // NewAIModelUseCase 创建AI模型用例
-func NewAIModelUseCase(repo AIModelRepo) *AIModelUseCase {
-	return &AIModelUseCase{repo: repo}
+func NewAIModelUseCase(repo AIModelRepo, cfg *AIModelConfig) *AIModelUseCase {
+	maxPageSize := defaultMaxModelPageSize
+	if cfg != nil && cfg.MaxPageSize > 0 {
+		maxPageSize = cfg.MaxPageSize
+	}
+	return &AIModelUseCase{repo: repo, maxPageSize: maxPageSize}
 }
 
 // GetAIModelByID 根据ID获取AI模型
@@ -60,6 +83,22 @@ func (uc *AIModelUseCase) ListAIModelsByProviderID(ctx context.Context, provider
 	return uc.repo.ListByProviderID(ctx, providerID)
 }
 
+// ListAIModelsByProvider 分页获取服务商的模型列表（page_size 超过上限时截断），返回过滤后的总数
+// 未指定 page 与 page_size 时不分页，返回全部模型（与分页前的行为一致）
+func (uc *AIModelUseCase) ListAIModelsByProvider(ctx context.Context, req *ListAIModelsRequest) ([]*AIModel, int64, error) {
+	if req.Page <= 0 && req.PageSize <= 0 {
+		req.Page, req.PageSize = 0, 0
+		return uc.repo.List(ctx, req)
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > uc.maxPageSize {
+		req.PageSize = uc.maxPageSize
+	}
+	return uc.repo.List(ctx, req)
+}
+
 // ListAIModelsByCapabilityType 根据能力类型获取模型列表
 func (uc *AIModelUseCase) ListAIModelsByCapabilityType(ctx context.Context, capabilityType string) ([]*AIModel, error) {
 	return uc.repo.ListByCapabilityType(ctx, capabilityType)
@@ -0,0 +1,89 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func newModelListFixture() *AIModelUseCase {
+	repo := &fakeAIModelRepo{models: map[string]*AIModel{}}
+	for i := 1; i <= 5; i++ {
+		id := fmt.Sprintf("m-%d", i)
+		repo.models[id] = &AIModel{
+			ID:           id,
+			ProviderID:   "p-1",
+			ModelName:    fmt.Sprintf("model-%d", i),
+			IsEnabled:    i != 3, // model-3 已禁用
+			Capabilities: []string{CapabilityTypeChat},
+		}
+	}
+	repo.models["m-embed"] = &AIModel{ID: "m-embed", ProviderID: "p-1", ModelName: "model-embed", IsEnabled: true, Capabilities: []string{CapabilityTypeEmbedding}}
+	repo.models["m-other"] = &AIModel{ID: "m-other", ProviderID: "p-2", ModelName: "model-other", IsEnabled: true}
+	return NewAIModelUseCase(repo, &AIModelConfig{MaxPageSize: 4})
+}
+
+func modelNames(models []*AIModel) []string {
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m.ModelName
+	}
+	return names
+}
+
+func TestListAIModelsByProvider_Pagination(t *testing.T) {
+	uc := newModelListFixture()
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		page         int
+		pageSize     int
+		wantNames    []string
+		wantPageSize int
+	}{
+		{name: "first page", page: 1, pageSize: 2, wantNames: []string{"model-1", "model-2"}, wantPageSize: 2},
+		{name: "last partial page", page: 3, pageSize: 2, wantNames: []string{"model-5"}, wantPageSize: 2},
+		{name: "past the end", page: 4, pageSize: 2, wantNames: []string{}, wantPageSize: 2},
+		{name: "page size capped", page: 1, pageSize: 50, wantNames: []string{"model-1", "model-2", "model-3", "model-4"}, wantPageSize: 4},
+		{name: "page without size uses cap", page: 1, pageSize: 0, wantNames: []string{"model-1", "model-2", "model-3", "model-4"}, wantPageSize: 4},
+		{name: "no pagination returns all", page: 0, pageSize: 0, wantNames: []string{"model-1", "model-2", "model-3", "model-4", "model-5"}, wantPageSize: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &ListAIModelsRequest{ProviderID: "p-1", CapabilityType: CapabilityTypeChat, Page: tt.page, PageSize: tt.pageSize}
+			models, total, err := uc.ListAIModelsByProvider(ctx, req)
+			if err != nil {
+				t.Fatalf("ListAIModelsByProvider failed: %v", err)
+			}
+			if total != 5 {
+				t.Errorf("Expected total 5, got %d", total)
+			}
+			if req.PageSize != tt.wantPageSize {
+				t.Errorf("Expected page size %d, got %d", tt.wantPageSize, req.PageSize)
+			}
+			if got := modelNames(models); fmt.Sprint(got) != fmt.Sprint(tt.wantNames) {
+				t.Errorf("Expected %v, got %v", tt.wantNames, got)
+			}
+		})
+	}
+}
+
+func TestListAIModelsByProvider_EnabledFilter(t *testing.T) {
+	uc := newModelListFixture()
+	enabled := true
+
+	models, total, err := uc.ListAIModelsByProvider(context.Background(), &ListAIModelsRequest{ProviderID: "p-1", IsEnabled: &enabled})
+	if err != nil {
+		t.Fatalf("ListAIModelsByProvider failed: %v", err)
+	}
+	if total != 5 {
+		t.Errorf("Expected total 5 enabled models, got %d", total)
+	}
+	for _, m := range models {
+		if m.ModelName == "model-3" {
+			t.Errorf("Expected disabled model to be excluded, got %v", modelNames(models))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// compactionScheduler 按 collection 统计向量删除次数，达到阈值后触发后台压缩
+type compactionScheduler struct {
+	mu      sync.Mutex
+	deletes map[string]int  // collection -> 自上次压缩以来删除的文档数
+	running map[string]bool // collection -> 是否正在压缩
+	wg      sync.WaitGroup
+}
+
+func newCompactionScheduler() *compactionScheduler {
+	return &compactionScheduler{
+		deletes: make(map[string]int),
+		running: make(map[string]bool),
+	}
+}
+
+// record 记录删除次数，返回是否需要开始压缩（同一 collection 同时只有一个压缩任务）
+func (s *compactionScheduler) record(collection string, count, threshold int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletes[collection] += count
+	if s.deletes[collection] < threshold || s.running[collection] {
+		return false
+	}
+	s.deletes[collection] = 0
+	s.running[collection] = true
+	s.wg.Add(1)
+	return true
+}
+
+// reset 手动压缩后清零删除计数
+func (s *compactionScheduler) reset(collection string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletes[collection] = 0
+}
+
+// done 标记后台压缩结束
+func (s *compactionScheduler) done(collection string) {
+	s.mu.Lock()
+	delete(s.running, collection)
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+// recordVectorDeletes 记录 collection 的向量删除，达到 CompactAfterDeletes 后在后台压缩
+func (uc *DocumentUseCase) recordVectorDeletes(collection string, count int) {
+	threshold := uc.config.CompactAfterDeletes
+	if threshold <= 0 || count <= 0 {
+		return
+	}
+	if !uc.compaction.record(collection, count, threshold) {
+		return
+	}
+
+	go func() {
+		defer uc.compaction.done(collection)
+
+		// 压缩与触发删除的请求无关，使用独立的上下文
+		ctx := context.Background()
+		if uc.config.CompactionTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, uc.config.CompactionTimeout)
+			defer cancel()
+		}
+
+		if err := uc.vectorDB.CompactCollection(ctx, collection); err != nil {
+			uc.logger.Error("自动压缩 collection 失败",
+				zap.String("collection", collection),
+				zap.Error(err))
+			return
+		}
+		uc.logger.Info("自动压缩 collection 完成",
+			zap.String("collection", collection),
+			zap.Int("threshold", threshold))
+	}()
+}
+
+// WaitForCompactions 等待后台压缩结束（服务关闭时调用），ctx 到期时返回 ctx.Err()
+func (uc *DocumentUseCase) WaitForCompactions(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		uc.compaction.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CompactKnowledgeBase 手动压缩知识库的所有 collection（管理操作），返回已压缩的 collection
+func (uc *DocumentUseCase) CompactKnowledgeBase(ctx context.Context, kbID string) ([]string, error) {
+	kb, err := uc.kbRepo.GetByID(ctx, kbID, "")
+	if err != nil {
+		return nil, fmt.Errorf("knowledge base not found: %w", err)
+	}
+
+	targets := kb.EmbeddingTargets()
+	compacted := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if err := uc.vectorDB.CompactCollection(ctx, target.MilvusCollection); err != nil {
+			return compacted, fmt.Errorf("failed to compact collection %s: %w", target.MilvusCollection, err)
+		}
+		uc.compaction.reset(target.MilvusCollection)
+		compacted = append(compacted, target.MilvusCollection)
+	}
+
+	return compacted, nil
+}
@@ -0,0 +1,71 @@
+package biz
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDeleteDocument_CompactsAfterConfiguredDeletes(t *testing.T) {
+	f := newTestFixture()
+	f.config.CompactAfterDeletes = 2
+	ctx := context.Background()
+
+	for _, id := range []string{"doc-1", "doc-2", "doc-3"} {
+		f.addDocument(id, []byte("content of "+id))
+	}
+
+	if err := f.useCase.DeleteDocument(ctx, "doc-1", testUserID); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	if err := f.useCase.WaitForCompactions(ctx); err != nil {
+		t.Fatalf("WaitForCompactions failed: %v", err)
+	}
+	if got := f.vectorDB.compactedCollections(); len(got) != 0 {
+		t.Fatalf("Expected no compaction below threshold, got %v", got)
+	}
+
+	result := f.useCase.BatchDeleteDocuments(ctx, []string{"doc-2", "doc-3"}, testUserID)
+	if result.SuccessCount != 2 {
+		t.Fatalf("Expected 2 deleted documents, got %d", result.SuccessCount)
+	}
+	if err := f.useCase.WaitForCompactions(ctx); err != nil {
+		t.Fatalf("WaitForCompactions failed: %v", err)
+	}
+	if got := f.vectorDB.compactedCollections(); len(got) != 1 || got[0] != f.kb.MilvusCollection {
+		t.Errorf("Expected one compaction of %s, got %v", f.kb.MilvusCollection, got)
+	}
+}
+
+func TestDeleteDocument_AutoCompactionDisabledByDefault(t *testing.T) {
+	f := newTestFixture()
+	f.addDocument("doc-1", []byte("content"))
+
+	if err := f.useCase.DeleteDocument(context.Background(), "doc-1", testUserID); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	if err := f.useCase.WaitForCompactions(context.Background()); err != nil {
+		t.Fatalf("WaitForCompactions failed: %v", err)
+	}
+	if got := f.vectorDB.compactedCollections(); len(got) != 0 {
+		t.Errorf("Expected no compaction when CompactAfterDeletes is 0, got %v", got)
+	}
+}
+
+func TestCompactKnowledgeBase_CompactsAllCollections(t *testing.T) {
+	f := newTestFixture()
+	f.withCodeEmbeddingOverride()
+
+	collections, err := f.useCase.CompactKnowledgeBase(context.Background(), f.kb.ID)
+	if err != nil {
+		t.Fatalf("CompactKnowledgeBase failed: %v", err)
+	}
+
+	want := f.kb.MilvusCollection + ",kb_test_code"
+	if got := strings.Join(collections, ","); got != want {
+		t.Errorf("Expected compacted collections %s, got %s", want, got)
+	}
+	if got := strings.Join(f.vectorDB.compactedCollections(), ","); got != want {
+		t.Errorf("Expected vector DB compactions %s, got %s", want, got)
+	}
+}
@@ -2,16 +2,20 @@ package biz
 
 // DocumentResponse 文档响应结构体，用于 API 响应和 SSE 事件
 type DocumentResponse struct {
-	ID              string  `json:"id"`
-	KnowledgeBaseID string  `json:"knowledge_base_id"`
-	FileName        string  `json:"file_name"`
-	FileType        string  `json:"file_type"`
-	FileSize        int64   `json:"file_size"`
-	ProcessStatus   string  `json:"process_status"`
-	ProcessError    *string `json:"process_error,omitempty"`
-	ChunkCount      int64   `json:"chunk_count"`
-	CreatedAt       string  `json:"created_at"`
-	UpdatedAt       string  `json:"updated_at"`
+	ID              string        `json:"id"`
+	KnowledgeBaseID string        `json:"knowledge_base_id"`
+	FileName        string        `json:"file_name"`
+	FileType        string        `json:"file_type"`
+	FileSize        int64         `json:"file_size"`
+	ProcessStatus   ProcessStatus `json:"process_status"`
+	ProcessStage    ProcessStage  `json:"process_stage,omitempty"` // 处理子阶段（processing/failed 时返回）
+	ProcessError    *string       `json:"process_error,omitempty"`
+	ChunkCount      int64         `json:"chunk_count"`
+	CreatedAt       string        `json:"created_at"`
+	UpdatedAt       string        `json:"updated_at"`
+
+	Telemetry   *ProcessingTelemetryResponse `json:"telemetry,omitempty"`    // 处理统计（仅文档详情返回）
+	ContentType string                       `json:"content_type,omitempty"` // 原始文件 Content-Type（仅文档详情在 include_content_type=true 时返回）
 }
 
 // ToDocumentResponse 将 Document 转换为 DocumentResponse
@@ -27,6 +31,7 @@ func ToDocumentResponse(doc *Document) *DocumentResponse {
 		FileType:        doc.FileType,
 		FileSize:        doc.FileSize,
 		ProcessStatus:   doc.ProcessStatus,
+		ProcessStage:    doc.ProcessStage,
 		ChunkCount:      doc.ChunkCount,
 		CreatedAt:       doc.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:       doc.UpdatedAt.Format("2006-01-02 15:04:05"),
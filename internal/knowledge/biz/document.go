@@ -3,6 +3,7 @@ package biz
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -24,7 +25,8 @@ type Document struct {
 	FileHash        string          // 文件SHA256哈希（去重用）
 	MinioBucket     string          // MinIO bucket名称
 	MinioObjectKey  string          // MinIO对象键（基于hash的物理路径: files/{hash[:2]}/{hash}）
-	ProcessStatus   string          // pending, processing, completed, failed
+	ProcessStatus   ProcessStatus   // pending, processing, retrying, completed, failed, empty
+	ProcessStage    ProcessStage    // 处理子阶段（processing 时为当前阶段，failed 时为失败所在阶段）
 	ProcessError    string
 	ChunkCount      int64
 	TokenCount      int
@@ -35,6 +37,12 @@ type Document struct {
 	SourceURL     string // URL来源（当source_type=url时）
 	SourceContent string // 文本内容（当source_type=text时）
 
+	BatchID   string // 批量上传会话 ID（客户端提供，用于中断后续传）
+	DedupHash string // 知识库内去重哈希（reject/return-existing 策略下等于 FileHash，唯一约束防止并发上传重复创建）
+
+	Telemetry          *ProcessingTelemetry // 最近一次成功处理的耗时与成本统计（未处理完成时为 nil）
+	ProcessFingerprint string               // 最近一次成功处理时的输入指纹（文件哈希 + 分块配置），用于跳过无变化的重新处理
+
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
@@ -64,29 +72,39 @@ type DocumentUseCase struct {
 	vectorDB        VectorDBService
 	embedder        EmbeddingService
 	processor       DocumentProcessor
+	config          *DocumentConfig
 	logger          *logger.Logger
+
+	embeddingUsageRepo EmbeddingUsageRepo   // 记录每次处理的 Embedding token 用量（为 nil 时不记录）
+	compaction         *compactionScheduler // 按 collection 统计删除次数，触发自动压缩
 }
 
 // DocumentRepo 文档仓储接口
 type DocumentRepo interface {
-	Create(ctx context.Context, doc *Document) error
+	Create(ctx context.Context, doc *Document) error // 创建文档，同一事务中增加知识库文档计数
 	GetByID(ctx context.Context, id string) (*Document, error)
 	GetByIDs(ctx context.Context, ids []string) ([]*Document, error)  // 批量查询
+	GetByFileHash(ctx context.Context, kbID, fileHash string) (*Document, error) // 查询知识库内相同哈希的文档（不存在返回 nil）
+	GetByBatchFile(ctx context.Context, kbID, batchID, fileName string) (*Document, error) // 查询批量上传会话中已创建的同名文档（不存在返回 nil）
 	List(ctx context.Context, kbID string, req *ListDocumentsRequest) ([]*Document, int64, error)
 	Update(ctx context.Context, doc *Document) error
-	Delete(ctx context.Context, id string) error
-	BatchDelete(ctx context.Context, ids []string) error  // 批量删除
-	UpdateStatus(ctx context.Context, id, status, errorMsg string) error
+	Delete(ctx context.Context, id string) error // 删除文档，同一事务中减少知识库文档计数
+	BatchDelete(ctx context.Context, ids []string) error  // 批量删除，同一事务中按知识库减少文档计数
+	UpdateStatus(ctx context.Context, id string, status ProcessStatus, errorMsg string) error // 更新状态（无效状态返回 ErrInvalidProcessStatus；failed 以外的状态清空子阶段）
+	UpdateStage(ctx context.Context, id string, stage ProcessStage) error                     // 更新处理子阶段
+	UpdateMetadata(ctx context.Context, id string, metadata map[string]interface{}) error // 仅更新元数据
 }
 
 // ChunkRepo 分块仓储接口
 type ChunkRepo interface {
 	BatchCreate(ctx context.Context, chunks []*Chunk) error
 	GetByDocumentID(ctx context.Context, docID string) ([]*Chunk, error)
+	GetByDocumentIDs(ctx context.Context, docIDs []string, limitPerDoc int) (map[string][]*Chunk, error) // 单次查询多个文档的分块（按文档分组，limitPerDoc <= 0 表示不限制）
 	DeleteByDocumentID(ctx context.Context, docID string) error
 	BatchDeleteByDocumentIDs(ctx context.Context, docIDs []string) error  // 批量删除
 	DeleteByKnowledgeBaseID(ctx context.Context, kbID string) error
 	KeywordSearch(ctx context.Context, kbID, query string, topK int) ([]*Chunk, error) // 关键词搜索
+	ReindexKeywordSearchBatch(ctx context.Context, kbID, afterID string, batchSize int) (lastID string, count int, err error) // 重建一批分块的全文搜索向量
 }
 
 // FileStorageRepo 文件存储仓储接口
@@ -116,16 +134,19 @@ type StorageService interface {
 	UploadFile(ctx context.Context, bucket, objectName string, data []byte, contentType string) (string, error)
 	GetFile(ctx context.Context, bucket, objectName string) ([]byte, error)
 	DeleteFile(ctx context.Context, bucket, objectName string) error
+	ObjectExists(ctx context.Context, bucket, objectName string) (bool, error)
 }
 
 // VectorDBService 向量数据库服务接口（Milvus）
 type VectorDBService interface {
 	CreateCollection(ctx context.Context, collectionName string, dimension int) error
+	HasCollection(ctx context.Context, collectionName string) (bool, error)
 	InsertVectors(ctx context.Context, collectionName string, chunks []*Chunk) error
 	Search(ctx context.Context, collectionName string, vector []float32, topK int) ([]*SearchResult, error)
 	SearchWithThreshold(ctx context.Context, collectionName string, vector []float32, topK int, minScore float32) ([]*SearchResult, error)
 	DeleteByDocumentID(ctx context.Context, collectionName, documentID string) error
 	DropCollection(ctx context.Context, collectionName string) error
+	CompactCollection(ctx context.Context, collectionName string) error // 压缩 collection，清理已删除实体
 }
 
 // EmbeddingService Embedding 生成服务接口
@@ -136,7 +157,7 @@ type EmbeddingService interface {
 // DocumentProcessor 文档处理器接口
 type DocumentProcessor interface {
 	ExtractText(ctx context.Context, fileData []byte, fileType string) (string, error)
-	ChunkText(text string, chunkSize, chunkOverlap int, strategy string) ([]string, error)
+	ChunkText(text string, chunkSize, chunkOverlap int, overlapUnit, strategy string) ([]string, error)
 }
 
 // SearchResult 搜索结果
@@ -162,12 +183,18 @@ func NewDocumentUseCase(
 	aiModelRepo AIModelRepo,
 	aiProviderRepo AIProviderRepo,
 	fileStorageRepo FileStorageRepo,
+	embeddingUsageRepo EmbeddingUsageRepo,
 	storage StorageService,
 	vectorDB VectorDBService,
 	embedder EmbeddingService,
 	processor DocumentProcessor,
+	cfg *DocumentConfig,
 	log *logger.Logger,
 ) *DocumentUseCase {
+	if cfg == nil {
+		cfg = DefaultDocumentConfig()
+	}
+
 	return &DocumentUseCase{
 		DocumentRepo:    documentRepo,
 		chunkRepo:       chunkRepo,
@@ -179,19 +206,84 @@ func NewDocumentUseCase(
 		vectorDB:        vectorDB,
 		embedder:        embedder,
 		processor:       processor,
+		config:          cfg,
 		logger:          log,
+
+		embeddingUsageRepo: embeddingUsageRepo,
+		compaction:         newCompactionScheduler(),
 	}
 }
+// UploadOutcome 上传结果
+type UploadOutcome struct {
+	Document *Document
+	Existing bool // 按 return-existing 策略返回的已有文档（无需重新处理）
+	Resumed  bool // 批量上传续传时该会话中已创建的文档（跳过重新上传）
+}
+
+// NeedsProcessing 文档是否需要加入处理队列
+// 新建文档需要；续传的文档仍为 pending/failed（上次中断时未入队或处理失败）时需要；按策略返回的已有文档不需要
+func (o *UploadOutcome) NeedsProcessing() bool {
+	if o.Resumed {
+		return o.Document.ProcessStatus == ProcessStatusPending || o.Document.ProcessStatus == ProcessStatusFailed
+	}
+	return !o.Existing
+}
+
+// MaxBatchIDLength 批量上传会话 ID 的最大长度
+const MaxBatchIDLength = 64
+
 // UploadDocument 上传文档（支持内容去重）
 func (uc *DocumentUseCase) UploadDocument(ctx context.Context, kbID, userID string, fileName string, fileData []byte, fileType string) (*Document, error) {
-	// 验证知识库权限
+	outcome, err := uc.UploadDocumentWithOutcome(ctx, kbID, userID, fileName, fileData, fileType)
+	if err != nil {
+		return nil, err
+	}
+	return outcome.Document, nil
+}
+
+// UploadDocumentWithOutcome 上传文档，并按重复文档策略处理知识库内的相同文件
+func (uc *DocumentUseCase) UploadDocumentWithOutcome(ctx context.Context, kbID, userID string, fileName string, fileData []byte, fileType string) (*UploadOutcome, error) {
+	return uc.UploadBatchDocument(ctx, kbID, userID, "", &UploadFile{FileName: fileName, FileType: fileType, FileData: fileData})
+}
+
+// UploadBatchDocument 在批量上传会话中上传单个文档
+// batchID 非空时，会话中已创建的同名文档直接返回（Resumed），客户端中断后可用同一 batchID 重新提交整批文件
+// 返回的错误为 *UploadStageError，标明失败阶段
+func (uc *DocumentUseCase) UploadBatchDocument(ctx context.Context, kbID, userID, batchID string, file *UploadFile) (*UploadOutcome, error) {
+	if err := uc.checkBatchUpload(ctx, kbID, userID, batchID); err != nil {
+		return nil, err
+	}
+	return uc.uploadFile(ctx, kbID, batchID, file)
+}
+
+// checkBatchUpload 校验批次 ID 与知识库权限
+func (uc *DocumentUseCase) checkBatchUpload(ctx context.Context, kbID, userID, batchID string) error {
+	if len(batchID) > MaxBatchIDLength {
+		return newUploadStageError(UploadStageValidation, ErrInvalidBatchID)
+	}
+
 	kb, err := uc.kbRepo.GetByID(ctx, kbID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("knowledge base not found: %w", err)
+		return newUploadStageError(UploadStageValidation, fmt.Errorf("knowledge base not found: %w", err))
 	}
 
 	if kb.OwnerID != userID && kb.OwnerID != SystemOwnerID {
-		return nil, fmt.Errorf("permission denied")
+		return newUploadStageError(UploadStageValidation, fmt.Errorf("permission denied: %w", ErrUnauthorized))
+	}
+	return nil
+}
+
+// uploadFile 上传单个文件并创建文档（调用方已完成权限校验）
+func (uc *DocumentUseCase) uploadFile(ctx context.Context, kbID, batchID string, file *UploadFile) (*UploadOutcome, error) {
+	fileName, fileData, fileType := file.FileName, file.FileData, file.FileType
+
+	// 续传：跳过本会话中已创建的文档
+	resumedDoc, err := uc.findBatchDocument(ctx, kbID, batchID, fileName)
+	if err != nil {
+		return nil, newUploadStageError(UploadStageHashCheck, err)
+	}
+	if resumedDoc != nil {
+		return &UploadOutcome{Document: resumedDoc, Resumed: true}, nil
 	}
 
 	// 计算文件hash
@@ -199,18 +291,32 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, kbID, userID stri
 	bucket := "knowledge-bases"
 	contentType := getContentType(fileType)
 
+	// 检查知识库内是否已有相同文件
+	existingDoc, err := uc.findDuplicateDocument(ctx, kbID, fileHash)
+	if err != nil {
+		return nil, newUploadStageError(UploadStageHashCheck, err)
+	}
+	if existingDoc != nil {
+		return &UploadOutcome{Document: existingDoc, Existing: true}, nil
+	}
+
 	// 检查文件是否已存在（去重）
 	existingFile, err := uc.fileStorageRepo.GetByHash(ctx, fileHash)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check file existence: %w", err)
+		return nil, newUploadStageError(UploadStageHashCheck, fmt.Errorf("failed to check file existence: %w", err))
 	}
 
 	var physicalPath string
 	if existingFile != nil {
+		// 确认对象仍在 MinIO 中（缺失时重新上传）
+		if err := uc.ensureStoredObject(ctx, existingFile, fileData, contentType); err != nil {
+			return nil, newUploadStageError(UploadStageStorageUpload, err)
+		}
+
 		// 文件已存在，增加引用计数
 		err = uc.fileStorageRepo.IncrementReference(ctx, fileHash)
 		if err != nil {
-			return nil, fmt.Errorf("failed to increment reference: %w", err)
+			return nil, newUploadStageError(UploadStageFileStorage, fmt.Errorf("failed to increment reference: %w", err))
 		}
 		physicalPath = existingFile.ObjectKey
 	} else {
@@ -220,7 +326,7 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, kbID, userID stri
 		// 上传到MinIO
 		_, err = uc.storage.UploadFile(ctx, bucket, physicalPath, fileData, contentType)
 		if err != nil {
-			return nil, fmt.Errorf("failed to upload file: %w", err)
+			return nil, newUploadStageError(UploadStageStorageUpload, fmt.Errorf("failed to upload file: %w", err))
 		}
 
 		// 创建文件存储记录
@@ -240,7 +346,7 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, kbID, userID stri
 		if err != nil {
 			// 清理MinIO文件
 			_ = uc.storage.DeleteFile(ctx, bucket, physicalPath)
-			return nil, fmt.Errorf("failed to create file storage: %w", err)
+			return nil, newUploadStageError(UploadStageFileStorage, fmt.Errorf("failed to create file storage: %w", err))
 		}
 	}
 
@@ -255,10 +361,12 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, kbID, userID stri
 		FileHash:        fileHash,
 		MinioBucket:     bucket,
 		MinioObjectKey:  physicalPath, // 基于hash的物理路径: files/{hash[:2]}/{hash}
-		ProcessStatus:   "pending",
+		ProcessStatus:   ProcessStatusPending,
 		TokenCount:      0,
 		ChunkCount:      0,
 		SourceType:      "file", // 文件上传类型
+		BatchID:         batchID,
+		DedupHash:       uc.dedupHash(fileHash),
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
@@ -272,16 +380,75 @@ func (uc *DocumentUseCase) UploadDocument(ctx context.Context, kbID, userID stri
 			_, _ = uc.fileStorageRepo.DeleteIfNoReferences(ctx, fileHash)
 			_ = uc.storage.DeleteFile(ctx, bucket, physicalPath)
 		}
-		return nil, fmt.Errorf("failed to create document: %w", err)
+		// 并发上传相同文件时，去重检查之后由唯一约束拒绝，重新按策略处理
+		if errors.Is(err, ErrDocumentHashExists) {
+			existingDoc, dupErr := uc.findDuplicateDocument(ctx, kbID, fileHash)
+			if dupErr != nil {
+				return nil, newUploadStageError(UploadStageHashCheck, dupErr)
+			}
+			if existingDoc != nil {
+				return &UploadOutcome{Document: existingDoc, Existing: true}, nil
+			}
+		}
+		return nil, newUploadStageError(UploadStageDocumentCreate, fmt.Errorf("failed to create document: %w", err))
+	}
+
+	return &UploadOutcome{Document: doc}, nil
+}
+
+// findBatchDocument 查询批量上传会话中已创建的同名文档（未指定会话时返回 nil）
+func (uc *DocumentUseCase) findBatchDocument(ctx context.Context, kbID, batchID, fileName string) (*Document, error) {
+	if batchID == "" {
+		return nil, nil
 	}
 
+	doc, err := uc.DocumentRepo.GetByBatchFile(ctx, kbID, batchID, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check batch document: %w", err)
+	}
 	return doc, nil
 }
 
+// dedupHash 按重复文档策略返回写入唯一约束的去重哈希（allow 策略下为空，允许重复）
+func (uc *DocumentUseCase) dedupHash(fileHash string) string {
+	switch uc.config.DuplicateDocumentPolicy {
+	case DuplicateDocumentPolicyReject, DuplicateDocumentPolicyReturnExisting:
+		return fileHash
+	default:
+		return ""
+	}
+}
+
+// findDuplicateDocument 按重复文档策略检查知识库内相同哈希的文档
+// allow: 不检查；reject: 存在时返回 ErrDocumentHashExists；return-existing: 返回已有文档
+func (uc *DocumentUseCase) findDuplicateDocument(ctx context.Context, kbID, fileHash string) (*Document, error) {
+	policy := uc.config.DuplicateDocumentPolicy
+	if policy != DuplicateDocumentPolicyReject && policy != DuplicateDocumentPolicyReturnExisting {
+		return nil, nil
+	}
+
+	existing, err := uc.DocumentRepo.GetByFileHash(ctx, kbID, fileHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check duplicate document: %w", err)
+	}
+	if existing == nil {
+		return nil, nil
+	}
+
+	if policy == DuplicateDocumentPolicyReject {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentHashExists, existing.FileName)
+	}
+
+	uc.logger.Info("知识库中已存在相同文件，返回已有文档",
+		zap.String("kb_id", kbID),
+		zap.String("document_id", existing.ID))
+	return existing, nil
+}
+
 // ProcessDocument 处理文档（异步任务调用）
 func (uc *DocumentUseCase) ProcessDocument(ctx context.Context, documentID string) error {
 	// 更新状态为处理中
-	err := uc.DocumentRepo.UpdateStatus(ctx, documentID, "processing", "")
+	err := uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusProcessing, "")
 	if err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
@@ -289,66 +456,87 @@ func (uc *DocumentUseCase) ProcessDocument(ctx context.Context, documentID strin
 	// 获取文档信息
 	doc, err := uc.DocumentRepo.GetByID(ctx, documentID)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", "document not found")
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, "document not found")
 		return fmt.Errorf("document not found: %w", err)
 	}
 
 	// 获取知识库信息
 	kb, err := uc.kbRepo.GetByID(ctx, doc.KnowledgeBaseID, "")
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", "knowledge base not found")
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, "knowledge base not found")
 		return fmt.Errorf("knowledge base not found: %w", err)
 	}
 
+	// 按文件类型确定 Embedding 模型与 Collection（支持按类型覆盖）
+	target := kb.EmbeddingTargetFor(doc.FileType)
+
 	// 获取AI Model
-	aiModel, err := uc.aiModelRepo.GetByID(ctx, kb.EmbeddingModelID)
+	aiModel, err := uc.aiModelRepo.GetByID(ctx, target.EmbeddingModelID)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", "AI model not found")
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, "AI model not found")
 		return fmt.Errorf("AI model not found: %w", err)
 	}
 
 	// 获取AI Provider
 	aiProvider, err := uc.aiProviderRepo.GetByID(ctx, aiModel.ProviderID)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", "AI provider not found")
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, "AI provider not found")
 		return fmt.Errorf("AI provider not found: %w", err)
 	}
 
 	// 从MinIO获取文件
+	uc.enterStage(ctx, documentID, ProcessStageExtracting)
 	fileData, err := uc.storage.GetFile(ctx, doc.MinioBucket, doc.MinioObjectKey)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", fmt.Sprintf("failed to get file: %v", err))
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, fmt.Sprintf("failed to get file: %v", err))
 		return fmt.Errorf("failed to get file: %w", err)
 	}
 
 	// 提取文本
+	extractStart := time.Now()
 	text, err := uc.processor.ExtractText(ctx, fileData, doc.FileType)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", fmt.Sprintf("failed to extract text: %v", err))
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, fmt.Sprintf("failed to extract text: %v", err))
 		return fmt.Errorf("failed to extract text: %w", err)
 	}
+	extractionDuration := time.Since(extractStart)
 
 	// 分块
-	chunkTexts, err := uc.processor.ChunkText(text, kb.ChunkSize, kb.ChunkOverlap, kb.ChunkStrategy)
+	uc.enterStage(ctx, documentID, ProcessStageChunking)
+	chunkTexts, err := uc.chunkText(doc, kb, text)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", fmt.Sprintf("failed to chunk text: %v", err))
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, fmt.Sprintf("failed to chunk text: %v", err))
 		return fmt.Errorf("failed to chunk text: %w", err)
 	}
 
 	if len(chunkTexts) == 0 {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", "no content extracted")
-		return fmt.Errorf("no content extracted")
+		// 根据配置的策略处理空内容（如纯图片 PDF），OCR 重试计入提取耗时
+		ocrStart := time.Now()
+		chunkTexts, err = uc.handleEmptyContent(ctx, doc, kb, fileData)
+		extractionDuration += time.Since(ocrStart)
+		if err != nil {
+			return err
+		}
+		if len(chunkTexts) == 0 {
+			// 已标记为 empty 状态
+			return nil
+		}
 	}
 
 	// 生成 Embeddings
-	embeddings, err := uc.embedder.GenerateEmbeddings(ctx, chunkTexts, aiProvider, aiModel)
+	// 主模型被健康探测标记为不可用时路由到同一模型的备用部署（collection 维度仍以主模型为准）
+	uc.enterStage(ctx, documentID, ProcessStageEmbedding)
+	embedModel, embedProvider := uc.routeEmbedding(ctx, documentID, aiModel, aiProvider)
+	embeddingStart := time.Now()
+	embeddings, err := uc.embedder.GenerateEmbeddings(ctx, chunkTexts, embedProvider, embedModel)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", fmt.Sprintf("failed to generate embeddings: %v", err))
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, fmt.Sprintf("failed to generate embeddings: %v", err))
 		return fmt.Errorf("failed to generate embeddings: %w", err)
 	}
+	embeddingDuration := time.Since(embeddingStart)
 
 	// 确保 Milvus collection 存在
-	collectionName := kb.MilvusCollection
+	collectionName := target.MilvusCollection
 
 	// 检查模型是否支持 embedding
 	hasEmbedding := false
@@ -360,26 +548,38 @@ func (uc *DocumentUseCase) ProcessDocument(ctx context.Context, documentID strin
 	}
 
 	if !hasEmbedding {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", "model does not support embedding")
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, "model does not support embedding")
 		return fmt.Errorf("model does not support embedding: %s", aiModel.ModelName)
 	}
 
 	// 从模型直接获取 embedding dimensions
 	if aiModel.EmbeddingDimensions == nil || *aiModel.EmbeddingDimensions == 0 {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", "embedding dimensions not configured")
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, "embedding dimensions not configured")
 		return fmt.Errorf("embedding dimensions not configured for model: %s", aiModel.ModelName)
 	}
 
 	embeddingDimensions := *aiModel.EmbeddingDimensions
 
+	// 备用模型声明的维度与主模型相同，仍校验实际返回的向量，避免写入维度不兼容的向量
+	if embedModel.ID != aiModel.ID {
+		for _, embedding := range embeddings {
+			if len(embedding) != embeddingDimensions {
+				_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, "backup embedding dimension mismatch")
+				return fmt.Errorf("backup embedding model %s returned %d dimensions, expected %d", embedModel.ModelName, len(embedding), embeddingDimensions)
+			}
+		}
+	}
+
+	uc.enterStage(ctx, documentID, ProcessStageInserting)
 	err = uc.vectorDB.CreateCollection(ctx, collectionName, embeddingDimensions)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", fmt.Sprintf("failed to create collection: %v", err))
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, fmt.Sprintf("failed to create collection: %v", err))
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
 
 	// 创建 Chunks
 	chunks := make([]*Chunk, len(chunkTexts))
+	var embeddedTokens int64
 	for i, chunkText := range chunkTexts {
 		// 清理无效的 UTF-8 字符
 		cleanedText := sanitizeUTF8(chunkText)
@@ -394,39 +594,111 @@ func (uc *DocumentUseCase) ProcessDocument(ctx context.Context, documentID strin
 			Embedding:       embeddings[i],
 			CreatedAt:       time.Now(),
 		}
+		embeddedTokens += int64(chunks[i].TokenCount)
+	}
+
+	// Milvus content 字段有长度上限：按策略截断（数据库保留完整内容）或拒绝
+	vectorChunks, err := uc.prepareVectorChunks(documentID, chunks)
+	if err != nil {
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, err.Error())
+		return err
 	}
 
 	// 先插入向量到 Milvus（避免数据库失败导致 Milvus 插入被跳过）
-	err = uc.vectorDB.InsertVectors(ctx, collectionName, chunks)
+	err = uc.vectorDB.InsertVectors(ctx, collectionName, vectorChunks)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", fmt.Sprintf("failed to insert vectors: %v", err))
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, fmt.Sprintf("failed to insert vectors: %v", err))
 		return fmt.Errorf("failed to insert vectors: %w", err)
 	}
 
 	// 再保存到数据库
 	err = uc.chunkRepo.BatchCreate(ctx, chunks)
 	if err != nil {
-		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, "failed", fmt.Sprintf("failed to save chunks: %v", err))
+		_ = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusFailed, fmt.Sprintf("failed to save chunks: %v", err))
 		return fmt.Errorf("failed to save chunks: %w", err)
 	}
 
-	// 先增加知识库文档计数
-	err = uc.kbRepo.IncrementDocumentCount(ctx, doc.KnowledgeBaseID, 1)
-	if err != nil {
-		return fmt.Errorf("failed to increment document count: %w", err)
-	}
-
-	// 更新文档状态
-	doc.ProcessStatus = "completed"
+	// 更新文档状态（知识库文档计数由 DocumentRepo 在创建/删除文档的事务中维护）
+	doc.ProcessStatus = ProcessStatusCompleted
+	doc.ProcessStage = ProcessStageNone
+	doc.ProcessFingerprint = processFingerprint(doc, kb)
 	doc.ChunkCount = int64(len(chunks))
 	doc.UpdatedAt = time.Now()
+	doc.Telemetry = &ProcessingTelemetry{
+		ExtractionDuration:     extractionDuration,
+		ChunkCount:             len(chunks),
+		EmbeddingDuration:      embeddingDuration,
+		EmbeddedTokens:         embeddedTokens,
+		EstimatedEmbeddingCost: uc.estimateEmbeddingCost(embeddedTokens),
+		ProcessedAt:            doc.UpdatedAt,
+	}
 	err = uc.DocumentRepo.Update(ctx, doc)
 	if err != nil {
-		// 回滚文档计数
-		_ = uc.kbRepo.IncrementDocumentCount(ctx, doc.KnowledgeBaseID, -1)
 		return fmt.Errorf("failed to update document: %w", err)
 	}
 
+	// 记录本次处理的 Embedding 用量（按知识库/所有者汇总成本）
+	uc.recordEmbeddingUsage(ctx, kb, documentID, embedModel.ID, embeddedTokens)
+
+	return nil
+}
+
+// handleEmptyContent 处理文本提取/分块结果为空的文档
+// 返回非空 chunks 表示 OCR 重试成功；返回空 chunks 且无错误表示文档已被标记为 empty
+func (uc *DocumentUseCase) handleEmptyContent(ctx context.Context, doc *Document, kb *KnowledgeBase, fileData []byte) ([]string, error) {
+	const reason = "no content extracted"
+
+	switch uc.config.EmptyContentPolicy {
+	case EmptyContentPolicyMarkEmpty:
+		return nil, uc.markDocumentEmpty(ctx, doc.ID, reason)
+
+	case EmptyContentPolicyRetryWithOCR:
+		ocrProcessor, ok := uc.processor.(OCRDocumentProcessor)
+		if !ok {
+			return nil, uc.markDocumentEmpty(ctx, doc.ID, reason+" (OCR not available)")
+		}
+
+		uc.enterStage(ctx, doc.ID, ProcessStageExtracting)
+		uc.logger.Info("未提取到内容，使用 OCR 重试",
+			zap.String("document_id", doc.ID),
+			zap.String("file_type", doc.FileType))
+
+		text, err := ocrProcessor.ExtractTextWithOCR(ctx, fileData, doc.FileType)
+		if err != nil {
+			_ = uc.DocumentRepo.UpdateStatus(ctx, doc.ID, ProcessStatusFailed, fmt.Sprintf("failed to extract text with OCR: %v", err))
+			return nil, fmt.Errorf("failed to extract text with OCR: %w", err)
+		}
+
+		uc.enterStage(ctx, doc.ID, ProcessStageChunking)
+		chunkTexts, err := uc.chunkText(doc, kb, text)
+		if err != nil {
+			_ = uc.DocumentRepo.UpdateStatus(ctx, doc.ID, ProcessStatusFailed, fmt.Sprintf("failed to chunk text: %v", err))
+			return nil, fmt.Errorf("failed to chunk text: %w", err)
+		}
+
+		if len(chunkTexts) == 0 {
+			return nil, uc.markDocumentEmpty(ctx, doc.ID, reason+" (OCR retry returned no content)")
+		}
+
+		return chunkTexts, nil
+
+	default:
+		_ = uc.DocumentRepo.UpdateStatus(ctx, doc.ID, ProcessStatusFailed, reason)
+		return nil, fmt.Errorf(reason)
+	}
+}
+
+// markDocumentEmpty 将文档标记为 empty 状态（内容为空，但不视为处理失败）
+func (uc *DocumentUseCase) markDocumentEmpty(ctx context.Context, documentID, reason string) error {
+	uc.logger.Warn("文档没有可提取的内容",
+		zap.String("document_id", documentID),
+		zap.String("reason", reason))
+
+	err := uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusEmpty, reason)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
 	return nil
 }
 
@@ -449,12 +721,14 @@ func (uc *DocumentUseCase) DeleteDocument(ctx context.Context, documentID, userI
 	}
 
 	// 删除 Milvus 向量
-	_ = uc.vectorDB.DeleteByDocumentID(ctx, kb.MilvusCollection, documentID)
+	collection := kb.EmbeddingTargetFor(doc.FileType).MilvusCollection
+	_ = uc.vectorDB.DeleteByDocumentID(ctx, collection, documentID)
+	uc.recordVectorDeletes(collection, 1)
 
 	// 删除数据库中的 chunks
 	_ = uc.chunkRepo.DeleteByDocumentID(ctx, documentID)
 
-	// 删除文档记录
+	// 删除文档记录（同一事务中减少知识库文档计数）
 	err = uc.DocumentRepo.Delete(ctx, documentID)
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
@@ -477,9 +751,6 @@ func (uc *DocumentUseCase) DeleteDocument(ctx context.Context, documentID, userI
 		_ = uc.storage.DeleteFile(ctx, doc.MinioBucket, doc.MinioObjectKey)
 	}
 
-	// 减少知识库文档计数
-	_ = uc.kbRepo.IncrementDocumentCount(ctx, doc.KnowledgeBaseID, -1)
-
 	return nil
 }
 
@@ -525,7 +796,7 @@ func (uc *DocumentUseCase) BatchDeleteDocuments(ctx context.Context, documentIDs
 	// 第3步：按知识库分组文档ID（为批量删除 Milvus 做准备）
 	kbDocGroups := make(map[string][]string)
 	fileHashes := make([]string, 0, len(docs))
-	kbDeleteCounts := make(map[string]int)
+	successDocIDs := make([]string, 0, len(docs))
 
 	// 遍历所有要删除的文档ID
 	for _, docID := range documentIDs {
@@ -559,21 +830,14 @@ func (uc *DocumentUseCase) BatchDeleteDocuments(ctx context.Context, documentIDs
 			continue
 		}
 
-		// 收集待删除的文档ID（按知识库分组）
-		kbDocGroups[kb.MilvusCollection] = append(kbDocGroups[kb.MilvusCollection], docID)
+		// 收集待删除的文档ID（按 Collection 分组）
+		collection := kb.EmbeddingTargetFor(doc.FileType).MilvusCollection
+		kbDocGroups[collection] = append(kbDocGroups[collection], docID)
 		fileHashes = append(fileHashes, doc.FileHash)
-		kbDeleteCounts[doc.KnowledgeBaseID]++
+		successDocIDs = append(successDocIDs, docID)
 		result.SuccessCount++
 	}
 
-	// 构建成功删除的文档ID列表
-	successDocIDs := make([]string, 0, result.SuccessCount)
-	for _, docID := range documentIDs {
-		if _, exists := docMap[docID]; exists {
-			successDocIDs = append(successDocIDs, docID)
-		}
-	}
-
 	// 如果没有成功的文档，直接返回
 	if len(successDocIDs) == 0 {
 		return result
@@ -586,14 +850,28 @@ func (uc *DocumentUseCase) BatchDeleteDocuments(ctx context.Context, documentIDs
 			for _, docID := range docIDs {
 				_ = uc.vectorDB.DeleteByDocumentID(ctx, collection, docID)
 			}
+			uc.recordVectorDeletes(collection, len(docIDs))
 		}
 	}
 
 	// 第5步：批量删除 chunks（一次性删除所有）
 	_ = uc.chunkRepo.BatchDeleteByDocumentIDs(ctx, successDocIDs)
 
-	// 第6步：批量删除文档记录（新增批量删除方法）
-	_ = uc.DocumentRepo.BatchDelete(ctx, successDocIDs)
+	// 第6步：批量删除文档记录（同一事务中按知识库减少文档计数）
+	if err := uc.DocumentRepo.BatchDelete(ctx, successDocIDs); err != nil {
+		uc.logger.Error("批量删除文档记录失败",
+			zap.Strings("document_ids", successDocIDs),
+			zap.Error(err))
+		for _, docID := range successDocIDs {
+			result.FailedItems = append(result.FailedItems, FailedItem{
+				DocumentID: docID,
+				Error:      fmt.Sprintf("failed to delete document: %v", err),
+			})
+		}
+		result.FailedCount += len(successDocIDs)
+		result.SuccessCount = 0
+		return result
+	}
 
 	// 第7步：批量处理文件引用和物理删除
 	// 构建 fileHash 到文档的映射（用于获取正确的 bucket 和 key）
@@ -618,9 +896,6 @@ func (uc *DocumentUseCase) BatchDeleteDocuments(ctx context.Context, documentIDs
 		}
 	}
 
-	// 第8步：批量更新知识库文档计数（一次性更新所有知识库）
-	_ = uc.kbRepo.BatchUpdateDocumentCounts(ctx, kbDeleteCounts)
-
 	return result
 }
 
@@ -639,136 +914,38 @@ type FailedItem struct {
 }
 
 // BatchUploadDocuments 批量上传文档
-func (uc *DocumentUseCase) BatchUploadDocuments(ctx context.Context, kbID, userID string, files []*UploadFile) *BatchUploadResult {
+// batchID 为客户端提供的会话 ID（可为空）；使用同一 batchID 重试时只创建尚未创建的文档
+func (uc *DocumentUseCase) BatchUploadDocuments(ctx context.Context, kbID, userID, batchID string, files []*UploadFile) *BatchUploadResult {
 	result := &BatchUploadResult{
-		TotalCount:      len(files),
-		SuccessCount:    0,
-		FailedCount:     0,
-		SuccessItems:    make([]*Document, 0),
+		TotalCount:        len(files),
+		SuccessCount:      0,
+		FailedCount:       0,
+		SuccessItems:      make([]*Document, 0),
 		FailedUploadItems: make([]FailedUploadItem, 0),
 	}
 
-	// 验证知识库权限
-	kb, err := uc.kbRepo.GetByID(ctx, kbID, userID)
-	if err != nil {
-		// 全部失败
+	// 校验失败时全部文件失败
+	if err := uc.checkBatchUpload(ctx, kbID, userID, batchID); err != nil {
 		result.FailedCount = len(files)
 		for _, file := range files {
-			result.FailedUploadItems = append(result.FailedUploadItems, FailedUploadItem{
-				FileName: file.FileName,
-				Error:    "knowledge base not found",
-			})
-		}
-		return result
-	}
-
-	if kb.OwnerID != userID && kb.OwnerID != SystemOwnerID {
-		// 全部失败
-		result.FailedCount = len(files)
-		for _, file := range files {
-			result.FailedUploadItems = append(result.FailedUploadItems, FailedUploadItem{
-				FileName: file.FileName,
-				Error:    "permission denied",
-			})
+			result.FailedUploadItems = append(result.FailedUploadItems, newFailedUploadItem(file.FileName, err))
 		}
 		return result
 	}
 
 	// 逐个上传文件（支持去重）
 	for _, file := range files {
-		doc, err := func() (*Document, error) {
-			// 计算文件hash
-			fileHash := calculateSHA256(file.FileData)
-			bucket := "knowledge-bases"
-			contentType := getContentType(file.FileType)
-
-			// 检查文件是否已存在（去重）
-			existingFile, err := uc.fileStorageRepo.GetByHash(ctx, fileHash)
-			if err != nil {
-				return nil, fmt.Errorf("failed to check file existence: %w", err)
-			}
-
-			var physicalPath string
-			if existingFile != nil {
-				// 文件已存在，增加引用计数
-				err = uc.fileStorageRepo.IncrementReference(ctx, fileHash)
-				if err != nil {
-					return nil, fmt.Errorf("failed to increment reference: %w", err)
-				}
-				physicalPath = existingFile.ObjectKey
-			} else {
-				// 新文件，基于 hash 生成存储路径
-				physicalPath = fmt.Sprintf("files/%s/%s", fileHash[:2], fileHash)
-
-				// 上传到MinIO
-				_, err = uc.storage.UploadFile(ctx, bucket, physicalPath, file.FileData, contentType)
-				if err != nil {
-					return nil, fmt.Errorf("failed to upload file: %w", err)
-				}
-
-				// 创建文件存储记录
-				now := time.Now()
-				fileStorage := &FileStorage{
-					FileHash:         fileHash,
-					Bucket:           bucket,
-					ObjectKey:        physicalPath,
-					FileSize:         int64(len(file.FileData)),
-					ContentType:      contentType,
-					ReferenceCount:   1,
-					FirstUploadedAt:  now,
-					LastReferencedAt: now,
-				}
-
-				err = uc.fileStorageRepo.Create(ctx, fileStorage)
-				if err != nil {
-					// 清理MinIO文件
-					_ = uc.storage.DeleteFile(ctx, bucket, physicalPath)
-					return nil, fmt.Errorf("failed to create file storage: %w", err)
-				}
-			}
-
-			// 创建文档记录
-			docID := uuid.New().String()
-			doc := &Document{
-				ID:              docID,
-				KnowledgeBaseID: kbID,
-				FileName:        file.FileName,
-				FileType:        file.FileType,
-				FileSize:        int64(len(file.FileData)),
-				FileHash:        fileHash,
-				MinioBucket:     bucket,
-				MinioObjectKey:  physicalPath,
-				ProcessStatus:   "pending",
-				TokenCount:      0,
-				ChunkCount:      0,
-				CreatedAt:       time.Now(),
-				UpdatedAt:       time.Now(),
-			}
-
-			err = uc.DocumentRepo.Create(ctx, doc)
-			if err != nil {
-				// 回滚引用计数
-				if existingFile != nil {
-					_ = uc.fileStorageRepo.DecrementReference(ctx, fileHash)
-				} else {
-					_, _ = uc.fileStorageRepo.DeleteIfNoReferences(ctx, fileHash)
-					_ = uc.storage.DeleteFile(ctx, bucket, physicalPath)
-				}
-				return nil, fmt.Errorf("failed to create document: %w", err)
-			}
-
-			return doc, nil
-		}()
-
+		outcome, err := uc.uploadFile(ctx, kbID, batchID, file)
 		if err != nil {
 			result.FailedCount++
-			result.FailedUploadItems = append(result.FailedUploadItems, FailedUploadItem{
-				FileName: file.FileName,
-				Error:    err.Error(),
-			})
-		} else {
-			result.SuccessCount++
-			result.SuccessItems = append(result.SuccessItems, doc)
+			result.FailedUploadItems = append(result.FailedUploadItems, newFailedUploadItem(file.FileName, err))
+			continue
+		}
+
+		result.SuccessCount++
+		result.SuccessItems = append(result.SuccessItems, outcome.Document)
+		if outcome.Resumed {
+			result.ResumedCount++
 		}
 	}
 
@@ -787,6 +964,7 @@ type BatchUploadResult struct {
 	TotalCount        int                 `json:"total_count"`
 	SuccessCount      int                 `json:"success_count"`
 	FailedCount       int                 `json:"failed_count"`
+	ResumedCount      int                 `json:"resumed_count"` // 续传时跳过的已创建文档数（计入 SuccessCount）
 	SuccessItems      []*Document         `json:"success_items,omitempty"`
 	FailedUploadItems []FailedUploadItem  `json:"failed_items,omitempty"`
 }
@@ -794,11 +972,53 @@ type BatchUploadResult struct {
 // FailedUploadItem 上传失败项
 type FailedUploadItem struct {
 	FileName string `json:"file_name"`
+	Stage    string `json:"stage,omitempty"` // 失败阶段：validation、hash_check、storage_upload、file_storage、document_create
 	Error    string `json:"error"`
+	Cause    error  `json:"-"` // 底层错误（可用 errors.Is/As 判断类型）
+}
+
+// SearchOutcome 搜索结果（含降级信息）
+type SearchOutcome struct {
+	Results       []*SearchResult
+	Partial       bool   // 向量搜索超时，结果不完整
+	PartialReason string // 降级原因
+
+	Previews map[string][]*Chunk // 命中文档的分块预览（仅 PreviewChunksPerDocument > 0 时返回，key 为文档 ID）
+
+	KnowledgeTemplate *string // 知识库自定义注入模板（为 nil 时使用全局模板）
 }
 
 // SearchDocuments 向量搜索（支持混合检索）
 func (uc *DocumentUseCase) SearchDocuments(ctx context.Context, kbID, userID, query string, topK int) ([]*SearchResult, error) {
+	outcome, err := uc.SearchDocumentsWithOutcome(ctx, kbID, userID, query, topK)
+	if err != nil {
+		return nil, err
+	}
+	return outcome.Results, nil
+}
+
+// SearchOptions 搜索选项
+type SearchOptions struct {
+	TopK                     int  // 大于 0 时覆盖知识库配置的 TopK
+	IncludeDocumentMetadata  bool // 补充文档元数据（file_name），为 false 时不查询文档表
+	PreviewChunksPerDocument int  // 大于 0 时为每个命中文档附带前 N 个分块预览（单次批量查询）
+}
+
+// SearchDocumentsWithOutcome 向量搜索（支持混合检索），向量搜索超时时返回部分结果并标记
+func (uc *DocumentUseCase) SearchDocumentsWithOutcome(ctx context.Context, kbID, userID, query string, topK int) (*SearchOutcome, error) {
+	return uc.SearchDocumentsWithOptions(ctx, kbID, userID, query, &SearchOptions{
+		TopK:                    topK,
+		IncludeDocumentMetadata: true,
+	})
+}
+
+// SearchDocumentsWithOptions 按选项执行向量搜索（支持混合检索）
+func (uc *DocumentUseCase) SearchDocumentsWithOptions(ctx context.Context, kbID, userID, query string, opts *SearchOptions) (*SearchOutcome, error) {
+	if opts == nil {
+		opts = &SearchOptions{IncludeDocumentMetadata: true}
+	}
+	topK := opts.TopK
+
 	// 记录搜索请求
 	uc.logger.Info("知识库搜索请求",
 		zap.String("kb_id", kbID),
@@ -826,6 +1046,7 @@ func (uc *DocumentUseCase) SearchDocuments(ctx context.Context, kbID, userID, qu
 	if topK > 0 {
 		searchTopK = topK
 	}
+	searchTopK = uc.clampTopK(kbID, searchTopK)
 
 	uc.logger.Info("知识库搜索配置",
 		zap.String("kb_name", kb.Name),
@@ -833,55 +1054,43 @@ func (uc *DocumentUseCase) SearchDocuments(ctx context.Context, kbID, userID, qu
 		zap.Float32("threshold", kb.Threshold),
 		zap.Bool("enable_hybrid_search", kb.EnableHybridSearch))
 
-	// 获取AI Model
-	aiModel, err := uc.aiModelRepo.GetByID(ctx, kb.EmbeddingModelID)
-	if err != nil {
-		return nil, fmt.Errorf("AI model not found: %w", err)
-	}
-
-	// 获取AI Provider
-	aiProvider, err := uc.aiProviderRepo.GetByID(ctx, aiModel.ProviderID)
-	if err != nil {
-		return nil, fmt.Errorf("AI provider not found: %w", err)
-	}
-
-	// 生成查询的 embedding
-	embeddings, err := uc.embedder.GenerateEmbeddings(ctx, []string{query}, aiProvider, aiModel)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
-	}
-
 	var results []*SearchResult
+	outcome := &SearchOutcome{KnowledgeTemplate: kb.KnowledgeTemplate}
+
+	// 关键词检索使用过滤停用词后的查询（向量检索仍使用原始查询）
+	keywordQuery := removeStopwords(query, kb.KeywordStopwords)
 
 	// 判断是否启用混合检索
 	if kb.EnableHybridSearch {
 		// 混合检索：向量搜索 + 关键词搜索 + RRF 融合
-		results, err = uc.hybridSearch(ctx, kb.MilvusCollection, kbID, embeddings[0], query, searchTopK, kb.Threshold)
+		results, err = uc.hybridSearch(ctx, kb, keywordQuery, query, searchTopK, outcome)
 		if err != nil {
 			return nil, fmt.Errorf("hybrid search failed: %w", err)
 		}
 	} else {
 		// 纯向量搜索（在数据库层面应用阈值过滤）
-		results, err = uc.vectorDB.SearchWithThreshold(ctx, kb.MilvusCollection, embeddings[0], searchTopK, kb.Threshold)
+		results, err = uc.searchEmbeddingTargets(ctx, kb, query, searchTopK, outcome)
 		if err != nil {
 			return nil, fmt.Errorf("failed to search: %w", err)
 		}
-	}
 
-	// 补充文档元数据（文件名）
-	for _, result := range results {
-		if result.DocumentID != "" {
-			doc, err := uc.DocumentRepo.GetByID(ctx, result.DocumentID)
-			if err == nil {
-				// 将文档信息添加到 metadata
-				if result.Metadata == nil {
-					result.Metadata = make(map[string]interface{})
-				}
-				result.Metadata["file_name"] = doc.FileName
+		// 向量搜索超时：补充关键词结果
+		if outcome.Partial {
+			results, err = uc.appendKeywordResults(ctx, kbID, keywordQuery, searchTopK, results)
+			if err != nil {
+				return nil, err
 			}
 		}
 	}
 
+	// 丢弃内容过短的结果（融合之后执行）
+	results = uc.filterShortResults(kbID, results)
+
+	// 补充文档元数据（文件名）
+	if opts.IncludeDocumentMetadata {
+		uc.enrichDocumentMetadata(ctx, results)
+	}
+
 	// 计算分数统计
 	var minScore, maxScore float32
 	if len(results) > 0 {
@@ -907,21 +1116,216 @@ func (uc *DocumentUseCase) SearchDocuments(ctx context.Context, kbID, userID, qu
 		zap.Int("result_count", len(results)),
 		zap.Float32("min_score", minScore),
 		zap.Float32("max_score", maxScore),
-		zap.String("search_type", searchType))
+		zap.String("search_type", searchType),
+		zap.Bool("partial", outcome.Partial))
+
+	outcome.Results = results
+
+	// 附带命中文档的分块预览
+	if opts.PreviewChunksPerDocument > 0 {
+		uc.attachSearchPreviews(ctx, kbID, opts.PreviewChunksPerDocument, outcome)
+	}
+
+	return outcome, nil
+}
+
+// enrichDocumentMetadata 批量查询结果所属文档，将文件名写入 metadata
+func (uc *DocumentUseCase) enrichDocumentMetadata(ctx context.Context, results []*SearchResult) {
+	seen := make(map[string]bool)
+	var docIDs []string
+	for _, result := range results {
+		if result.DocumentID != "" && !seen[result.DocumentID] {
+			seen[result.DocumentID] = true
+			docIDs = append(docIDs, result.DocumentID)
+		}
+	}
+	if len(docIDs) == 0 {
+		return
+	}
+
+	docs, err := uc.DocumentRepo.GetByIDs(ctx, docIDs)
+	if err != nil {
+		uc.logger.Warn("查询搜索结果文档失败", zap.Error(err))
+		return
+	}
+
+	fileNames := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		fileNames[doc.ID] = doc.FileName
+	}
+
+	for _, result := range results {
+		fileName, ok := fileNames[result.DocumentID]
+		if !ok {
+			continue
+		}
+		// 将文档信息添加到 metadata
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]interface{})
+		}
+		result.Metadata["file_name"] = fileName
+	}
+}
+
+// searchEmbeddingTargets 在知识库所有向量化目标上检索（每个目标使用各自模型生成查询 embedding）
+// 仅有默认目标时直接返回其结果；存在覆盖时各路结果通过 RRF 融合排序（保留原始相似度分数）
+func (uc *DocumentUseCase) searchEmbeddingTargets(ctx context.Context, kb *KnowledgeBase, query string, topK int, outcome *SearchOutcome) ([]*SearchResult, error) {
+	targets := kb.EmbeddingTargets()
+	if len(targets) == 1 {
+		return uc.searchEmbeddingTarget(ctx, targets[0], query, topK, kb.Threshold, outcome)
+	}
+
+	resultSets := make([][]hybrid.SearchResult, 0, len(targets))
+	resultMap := make(map[string]*SearchResult)
+	for _, target := range targets {
+		// Collection 在首个对应类型的文档处理时才创建，尚未创建的目标没有可召回的向量
+		exists, err := uc.vectorDB.HasCollection(ctx, target.MilvusCollection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check collection %s: %w", target.MilvusCollection, err)
+		}
+		if !exists {
+			continue
+		}
+
+		results, err := uc.searchEmbeddingTarget(ctx, target, query, topK, kb.Threshold, outcome)
+		if err != nil {
+			return nil, err
+		}
+
+		resultSet := make([]hybrid.SearchResult, len(results))
+		for i, result := range results {
+			id := result.ChunkID
+			if id == "" {
+				id = result.DocumentID
+			}
+			if _, exists := resultMap[id]; !exists {
+				resultMap[id] = result
+			}
+			resultSet[i] = &hybrid.VectorSearchResult{ID: id, Score: result.Score}
+		}
+		resultSets = append(resultSets, resultSet)
+	}
+
+	// 不同模型的相似度分数不可直接比较，按各路排名融合
+	rrfResults := hybrid.ReciprocalRankFusion(resultSets, 60)
+	if len(rrfResults) > topK {
+		rrfResults = rrfResults[:topK]
+	}
+
+	fused := make([]*SearchResult, len(rrfResults))
+	for i, rrfResult := range rrfResults {
+		fused[i] = resultMap[rrfResult.ID]
+	}
+	return fused, nil
+}
+
+// searchEmbeddingTarget 使用目标模型生成查询 embedding 并在目标 Collection 中检索
+func (uc *DocumentUseCase) searchEmbeddingTarget(ctx context.Context, target EmbeddingTarget, query string, topK int, threshold float32, outcome *SearchOutcome) ([]*SearchResult, error) {
+	// 获取AI Model
+	aiModel, err := uc.aiModelRepo.GetByID(ctx, target.EmbeddingModelID)
+	if err != nil {
+		return nil, fmt.Errorf("AI model not found: %w", err)
+	}
+
+	// 获取AI Provider
+	aiProvider, err := uc.aiProviderRepo.GetByID(ctx, aiModel.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("AI provider not found: %w", err)
+	}
+
+	// 生成查询的 embedding
+	embeddings, err := uc.embedder.GenerateEmbeddings(ctx, []string{query}, aiProvider, aiModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	return uc.searchVectors(ctx, target.MilvusCollection, embeddings[0], topK, threshold, outcome)
+}
+
+// searchVectors 执行向量搜索（应用配置的超时）
+// 超时但调用方上下文仍有效时，该路向量检索视为无结果并在 outcome 中标记，不视为错误；
+// 其他 collection 已返回的结果与关键词结果仍会保留（VectorDBService 出错时不返回结果）
+func (uc *DocumentUseCase) searchVectors(ctx context.Context, collection string, embedding []float32, topK int, threshold float32, outcome *SearchOutcome) ([]*SearchResult, error) {
+	if uc.config.VectorSearchTimeout <= 0 {
+		return uc.vectorDB.SearchWithThreshold(ctx, collection, embedding, topK, threshold)
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, uc.config.VectorSearchTimeout)
+	defer cancel()
+
+	results, err := uc.vectorDB.SearchWithThreshold(searchCtx, collection, embedding, topK, threshold)
+	if err != nil {
+		if errors.Is(searchCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			uc.logger.Warn("向量搜索超时，返回部分结果",
+				zap.String("collection", collection),
+				zap.Duration("timeout", uc.config.VectorSearchTimeout),
+				zap.Error(err))
+			outcome.Partial = true
+			outcome.PartialReason = fmt.Sprintf("vector search timed out after %s", uc.config.VectorSearchTimeout)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// appendKeywordResults 将关键词搜索结果追加到向量结果之后（按文档去重）
+func (uc *DocumentUseCase) appendKeywordResults(ctx context.Context, kbID, query string, topK int, results []*SearchResult) ([]*SearchResult, error) {
+	keywordChunks, err := uc.keywordSearch(ctx, kbID, query, topK)
+	if err != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", err)
+	}
+
+	seen := make(map[string]bool, len(results))
+	for _, result := range results {
+		seen[result.DocumentID] = true
+	}
+
+	for _, chunk := range keywordChunks {
+		if len(results) >= topK {
+			break
+		}
+		if seen[chunk.DocumentID] {
+			continue
+		}
+		seen[chunk.DocumentID] = true
+
+		var score float32
+		if value, ok := chunk.Metadata["bm25_score"].(float32); ok {
+			score = value
+		}
+		results = append(results, &SearchResult{
+			ChunkID:    chunk.ID,
+			DocumentID: chunk.DocumentID,
+			Content:    chunk.Content,
+			Score:      score,
+		})
+	}
 
 	return results, nil
 }
 
+// keywordSearch 关键词搜索（查询在过滤停用词后为空时不检索）
+func (uc *DocumentUseCase) keywordSearch(ctx context.Context, kbID, query string, topK int) ([]*Chunk, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	return uc.chunkRepo.KeywordSearch(ctx, kbID, query, topK)
+}
+
 // hybridSearch 混合检索（向量 + 关键词 + RRF）
-func (uc *DocumentUseCase) hybridSearch(ctx context.Context, collection, kbID string, embedding []float32, query string, topK int, threshold float32) ([]*SearchResult, error) {
-	// 1. 向量搜索（应用阈值过滤）
-	vectorResults, err := uc.vectorDB.SearchWithThreshold(ctx, collection, embedding, topK*2, threshold) // 取2倍，融合后再截取
+// keywordQuery 为过滤停用词后的关键词查询，vectorQuery 为用于生成 embedding 的原始查询
+func (uc *DocumentUseCase) hybridSearch(ctx context.Context, kb *KnowledgeBase, keywordQuery, vectorQuery string, topK int, outcome *SearchOutcome) ([]*SearchResult, error) {
+	// 1. 向量搜索（应用阈值过滤，超时则使用部分结果）
+	candidateK := uc.capTopK(topK * 2) // 取2倍（不超过上限），融合后再截取
+	vectorResults, err := uc.searchEmbeddingTargets(ctx, kb, vectorQuery, candidateK, outcome)
 	if err != nil {
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
 
 	// 2. 关键词搜索
-	keywordChunks, err := uc.chunkRepo.KeywordSearch(ctx, kbID, query, topK*2)
+	keywordChunks, err := uc.keywordSearch(ctx, kb.ID, keywordQuery, candidateK)
 	if err != nil {
 		return nil, fmt.Errorf("keyword search failed: %w", err)
 	}
@@ -994,36 +1398,139 @@ func (uc *DocumentUseCase) hybridSearch(ctx context.Context, collection, kbID st
 	return finalResults, nil
 }
 
+// UpdateDocumentMetadata 更新文档元数据（merge=true 合并到现有元数据，否则整体替换）
+// 仅修改 documents.metadata，不影响已生成的 chunks 和向量
+func (uc *DocumentUseCase) UpdateDocumentMetadata(ctx context.Context, documentID, userID string, metadata map[string]interface{}, merge bool) (*Document, error) {
+	// 获取文档
+	doc, err := uc.DocumentRepo.GetByID(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("document not found: %w", err)
+	}
+
+	// 验证权限
+	kb, err := uc.kbRepo.GetByID(ctx, doc.KnowledgeBaseID, "")
+	if err != nil {
+		return nil, fmt.Errorf("knowledge base not found: %w", err)
+	}
+
+	if kb.OwnerID != userID && kb.OwnerID != SystemOwnerID {
+		return nil, fmt.Errorf("permission denied")
+	}
+
+	newMetadata := make(map[string]interface{}, len(metadata))
+	if merge {
+		for k, v := range doc.Metadata {
+			newMetadata[k] = v
+		}
+	}
+	for k, v := range metadata {
+		newMetadata[k] = v
+	}
+
+	err = uc.DocumentRepo.UpdateMetadata(ctx, documentID, newMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update document metadata: %w", err)
+	}
+
+	doc.Metadata = newMetadata
+	doc.UpdatedAt = time.Now()
+
+	return doc, nil
+}
+
+// ResolveContentType 按文件哈希查询 FileStorage 中记录的原始文件 Content-Type
+// 非文件来源（无哈希）或存储记录不存在时返回空字符串
+func (uc *DocumentUseCase) ResolveContentType(ctx context.Context, doc *Document) (string, error) {
+	if doc.FileHash == "" {
+		return "", nil
+	}
+
+	fs, err := uc.fileStorageRepo.GetByHash(ctx, doc.FileHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file storage: %w", err)
+	}
+	if fs == nil {
+		return "", nil
+	}
+
+	return fs.ContentType, nil
+}
+
 // ReprocessDocument 重新处理文档
-func (uc *DocumentUseCase) ReprocessDocument(ctx context.Context, documentID, userID string) error {
+// force 为 false 且开启 SkipUnchangedReprocess 时，文件与分块配置自上次成功处理后未变化则跳过（返回 skipped=true）
+func (uc *DocumentUseCase) ReprocessDocument(ctx context.Context, documentID, userID string, force bool) (bool, error) {
 	// 获取文档
 	doc, err := uc.DocumentRepo.GetByID(ctx, documentID)
 	if err != nil {
-		return fmt.Errorf("document not found: %w", err)
+		return false, fmt.Errorf("document not found: %w", err)
 	}
 
 	// 验证权限
 	kb, err := uc.kbRepo.GetByID(ctx, doc.KnowledgeBaseID, "")
 	if err != nil {
-		return fmt.Errorf("knowledge base not found: %w", err)
+		return false, fmt.Errorf("knowledge base not found: %w", err)
 	}
 
 	if kb.OwnerID != userID && kb.OwnerID != SystemOwnerID {
-		return fmt.Errorf("permission denied")
+		return false, fmt.Errorf("permission denied")
+	}
+
+	if !force && uc.unchangedSinceLastRun(doc, kb) {
+		uc.logger.Info("文档自上次处理后未变化，跳过重新处理",
+			zap.String("document_id", doc.ID),
+			zap.String("kb_id", kb.ID))
+		return true, nil
 	}
 
 	// 删除旧的向量和chunks
-	_ = uc.vectorDB.DeleteByDocumentID(ctx, kb.MilvusCollection, documentID)
+	collection := kb.EmbeddingTargetFor(doc.FileType).MilvusCollection
+	_ = uc.vectorDB.DeleteByDocumentID(ctx, collection, documentID)
+	uc.recordVectorDeletes(collection, 1)
 	_ = uc.chunkRepo.DeleteByDocumentID(ctx, documentID)
 
 	// 重置状态
-	err = uc.DocumentRepo.UpdateStatus(ctx, documentID, "pending", "")
+	err = uc.DocumentRepo.UpdateStatus(ctx, documentID, ProcessStatusPending, "")
 	if err != nil {
-		return fmt.Errorf("failed to reset status: %w", err)
+		return false, fmt.Errorf("failed to reset status: %w", err)
 	}
 
 	// 重新处理
-	return uc.ProcessDocument(ctx, documentID)
+	return false, uc.ProcessDocument(ctx, documentID)
+}
+
+// keywordReindexBatchSize 重建全文索引时每批处理的分块数
+const keywordReindexBatchSize = 500
+
+// ReindexKeywordSearch 按当前全文搜索配置重建知识库所有分块的 content_tsv（分批执行，管理操作）
+func (uc *DocumentUseCase) ReindexKeywordSearch(ctx context.Context, kbID string) (int, error) {
+	if _, err := uc.kbRepo.GetByID(ctx, kbID, ""); err != nil {
+		return 0, fmt.Errorf("knowledge base not found: %w", err)
+	}
+
+	total := 0
+	afterID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		lastID, count, err := uc.chunkRepo.ReindexKeywordSearchBatch(ctx, kbID, afterID, keywordReindexBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to reindex keyword search: %w", err)
+		}
+		total += count
+
+		if count < keywordReindexBatchSize {
+			break
+		}
+		afterID = lastID
+	}
+
+	uc.logger.Info("全文搜索索引重建完成",
+		zap.String("kb_id", kbID),
+		zap.Int("chunks", total))
+
+	return total, nil
 }
 
 // Helper functions
@@ -1042,6 +1549,14 @@ func getContentType(fileType string) string {
 		return "text/plain"
 	case "md":
 		return "text/markdown"
+	case "json":
+		return "application/json"
+	case "doc":
+		return "application/msword"
+	case "ppt":
+		return "application/vnd.ms-powerpoint"
+	case "pptx":
+		return "application/vnd.openxmlformats-officedocument.presentationml.presentation"
 	default:
 		return "application/octet-stream"
 	}
@@ -0,0 +1,205 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/sse"
+)
+
+func TestBatchUploadDocuments_ResumeSkipsCreatedDocuments(t *testing.T) {
+	f := newTestFixture()
+	ctx := context.Background()
+
+	files := make([]*UploadFile, 5)
+	for i := range files {
+		files[i] = &UploadFile{
+			FileName: fmt.Sprintf("file-%d.txt", i),
+			FileType: "txt",
+			FileData: []byte(fmt.Sprintf("content %d", i)),
+		}
+	}
+
+	// 第一次提交在第 3 个文件后中断
+	first := f.useCase.BatchUploadDocuments(ctx, f.kb.ID, testUserID, "batch-1", files[:3])
+	if first.SuccessCount != 3 || first.ResumedCount != 0 {
+		t.Fatalf("Expected 3 new documents, got success=%d resumed=%d", first.SuccessCount, first.ResumedCount)
+	}
+	created := make(map[string]string)
+	for _, doc := range first.SuccessItems {
+		created[doc.FileName] = doc.ID
+	}
+
+	// 使用同一 batch ID 重新提交整批文件
+	resumed := f.useCase.BatchUploadDocuments(ctx, f.kb.ID, testUserID, "batch-1", files)
+	if resumed.SuccessCount != 5 || resumed.FailedCount != 0 {
+		t.Fatalf("Expected 5 successful items, got success=%d failed=%d", resumed.SuccessCount, resumed.FailedCount)
+	}
+	if resumed.ResumedCount != 3 {
+		t.Errorf("Expected 3 resumed documents, got %d", resumed.ResumedCount)
+	}
+	for _, doc := range resumed.SuccessItems {
+		if id, ok := created[doc.FileName]; ok && doc.ID != id {
+			t.Errorf("Expected %s to reuse document %s, got %s", doc.FileName, id, doc.ID)
+		}
+		if doc.BatchID != "batch-1" {
+			t.Errorf("Expected batch ID batch-1 on %s, got %q", doc.FileName, doc.BatchID)
+		}
+	}
+	if got := len(f.docRepo.docs); got != 5 {
+		t.Errorf("Expected 5 documents after resume, got %d", got)
+	}
+
+	// 不同的 batch ID 是新的会话
+	other := f.useCase.BatchUploadDocuments(ctx, f.kb.ID, testUserID, "batch-2", files[:1])
+	if other.ResumedCount != 0 || len(f.docRepo.docs) != 6 {
+		t.Errorf("Expected a new document for another batch, got resumed=%d documents=%d", other.ResumedCount, len(f.docRepo.docs))
+	}
+}
+
+func TestUploadBatchDocument_Resume(t *testing.T) {
+	f := newTestFixture()
+	ctx := context.Background()
+	file := &UploadFile{FileName: "a.txt", FileType: "txt", FileData: []byte("hello")}
+
+	first, err := f.useCase.UploadBatchDocument(ctx, f.kb.ID, testUserID, "batch-1", file)
+	if err != nil {
+		t.Fatalf("UploadBatchDocument failed: %v", err)
+	}
+	if first.Resumed {
+		t.Error("Expected first upload not to be resumed")
+	}
+
+	second, err := f.useCase.UploadBatchDocument(ctx, f.kb.ID, testUserID, "batch-1", file)
+	if err != nil {
+		t.Fatalf("UploadBatchDocument failed: %v", err)
+	}
+	if !second.Resumed || second.Document.ID != first.Document.ID {
+		t.Errorf("Expected resumed document %s, got resumed=%v id=%s", first.Document.ID, second.Resumed, second.Document.ID)
+	}
+	if refs := f.fileRepo.files[first.Document.FileHash].ReferenceCount; refs != 1 {
+		t.Errorf("Expected file reference count 1 after resume, got %d", refs)
+	}
+
+	// 上次中断时仍为 pending 的文档需要重新入队，已处理完成的不需要
+	if !first.NeedsProcessing() || !second.NeedsProcessing() {
+		t.Errorf("Expected new and still-pending resumed documents to need processing")
+	}
+	for status, want := range map[ProcessStatus]bool{
+		ProcessStatusFailed:     true,
+		ProcessStatusProcessing: false,
+		ProcessStatusCompleted:  false,
+	} {
+		f.docRepo.docs[first.Document.ID].ProcessStatus = status
+		outcome, err := f.useCase.UploadBatchDocument(ctx, f.kb.ID, testUserID, "batch-1", file)
+		if err != nil {
+			t.Fatalf("UploadBatchDocument failed: %v", err)
+		}
+		if got := outcome.NeedsProcessing(); got != want {
+			t.Errorf("Resumed %s document: expected NeedsProcessing=%v, got %v", status, want, got)
+		}
+	}
+}
+
+func TestBatchUploadDocuments_ReportsFailureStage(t *testing.T) {
+	errOutage := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		setup     func(f *testFixture)
+		userID    string
+		wantStage string
+		wantErr   error
+	}{
+		{
+			name:      "permission denied",
+			userID:    "someone-else",
+			wantStage: UploadStageValidation,
+			wantErr:   ErrUnauthorized,
+		},
+		{
+			name: "duplicate rejected",
+			setup: func(f *testFixture) {
+				f.config.DuplicateDocumentPolicy = DuplicateDocumentPolicyReject
+				f.addDocument("doc-existing", []byte("content"))
+			},
+			wantStage: UploadStageHashCheck,
+			wantErr:   ErrDocumentHashExists,
+		},
+		{
+			name:      "file storage lookup",
+			setup:     func(f *testFixture) { f.fileRepo.getErr = errOutage },
+			wantStage: UploadStageHashCheck,
+			wantErr:   errOutage,
+		},
+		{
+			name:      "minio upload",
+			setup:     func(f *testFixture) { f.storage.uploadErr = errOutage },
+			wantStage: UploadStageStorageUpload,
+			wantErr:   errOutage,
+		},
+		{
+			name:      "file storage create",
+			setup:     func(f *testFixture) { f.fileRepo.createErr = errOutage },
+			wantStage: UploadStageFileStorage,
+			wantErr:   errOutage,
+		},
+		{
+			name: "file reference increment",
+			setup: func(f *testFixture) {
+				f.fileRepo.files[calculateSHA256([]byte("content"))] = &FileStorage{ObjectKey: "files/shared", ReferenceCount: 1}
+				f.fileRepo.incrementErr = errOutage
+			},
+			wantStage: UploadStageFileStorage,
+			wantErr:   errOutage,
+		},
+		{
+			name:      "document create",
+			setup:     func(f *testFixture) { f.docRepo.createErr = errOutage },
+			wantStage: UploadStageDocumentCreate,
+			wantErr:   errOutage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			userID := tt.userID
+			if userID == "" {
+				userID = testUserID
+			}
+
+			file := &UploadFile{FileName: "a.txt", FileType: "txt", FileData: []byte("content")}
+			result := f.useCase.BatchUploadDocuments(context.Background(), f.kb.ID, userID, "", []*UploadFile{file})
+			if result.FailedCount != 1 || len(result.FailedUploadItems) != 1 {
+				t.Fatalf("Expected 1 failed item, got %d", result.FailedCount)
+			}
+
+			item := result.FailedUploadItems[0]
+			if item.Stage != tt.wantStage {
+				t.Errorf("Expected stage %s, got %s (%s)", tt.wantStage, item.Stage, item.Error)
+			}
+			if !errors.Is(item.Cause, tt.wantErr) {
+				t.Errorf("Expected cause %v, got %v", tt.wantErr, item.Cause)
+			}
+			if item.Error == "" {
+				t.Error("Expected an error message")
+			}
+
+			// HTTP 批量上传逐个调用 UploadBatchDocument，SSE 失败事件通过 sse.StagedError 取得阶段
+			_, err := f.useCase.UploadBatchDocument(context.Background(), f.kb.ID, userID, "", file)
+			var stagedErr sse.StagedError
+			if !errors.As(err, &stagedErr) || stagedErr.FailedStage() != tt.wantStage {
+				t.Errorf("UploadBatchDocument: expected stage %s, got %v", tt.wantStage, err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UploadBatchDocument: expected cause %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
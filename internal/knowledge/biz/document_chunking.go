@@ -0,0 +1,28 @@
+package biz
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// chunkText 按知识库配置分块
+// 知识库的分块策略不受支持时（如版本回退后残留的新策略），默认回退为 fixed 并记录警告，StrictChunkStrategy 下直接返回错误
+func (uc *DocumentUseCase) chunkText(doc *Document, kb *KnowledgeBase, text string) ([]string, error) {
+	strategy := kb.ChunkStrategy
+	switch strategy {
+	case ChunkStrategyRecursive, ChunkStrategyFixed, "":
+	default:
+		if uc.config.StrictChunkStrategy {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedChunkStrategy, strategy)
+		}
+
+		uc.logger.Warn("不支持的分块策略，回退为固定长度分块",
+			zap.String("kb_id", kb.ID),
+			zap.String("document_id", doc.ID),
+			zap.String("chunk_strategy", strategy))
+		strategy = ChunkStrategyFixed
+	}
+
+	return uc.processor.ChunkText(text, kb.ChunkSize, kb.ChunkOverlap, kb.ChunkOverlapUnit, strategy)
+}
@@ -0,0 +1,105 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// strategyRecordingProcessor 记录分块时使用的策略
+type strategyRecordingProcessor struct {
+	fakeProcessor
+	strategies []string
+}
+
+func (p *strategyRecordingProcessor) ChunkText(text string, chunkSize, chunkOverlap int, overlapUnit, strategy string) ([]string, error) {
+	p.strategies = append(p.strategies, strategy)
+	return p.fakeProcessor.ChunkText(text, chunkSize, chunkOverlap, overlapUnit, strategy)
+}
+
+func TestProcessDocument_UnsupportedChunkStrategy(t *testing.T) {
+	tests := []struct {
+		name       string
+		strict     bool
+		wantErr    bool
+		wantStatus ProcessStatus
+	}{
+		{name: "lenient falls back to fixed", strict: false, wantStatus: "completed"},
+		{name: "strict fails", strict: true, wantErr: true, wantStatus: "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &strategyRecordingProcessor{}
+			f := newTestFixture().withProcessor(processor)
+			f.config.StrictChunkStrategy = tt.strict
+			f.kb.ChunkStrategy = "semantic"
+
+			doc := f.addDocument("doc-1", []byte("some content"))
+			err := f.useCase.ProcessDocument(context.Background(), doc.ID)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedChunkStrategy) {
+					t.Fatalf("Expected ErrUnsupportedChunkStrategy, got %v", err)
+				}
+				if len(processor.strategies) != 0 {
+					t.Errorf("Expected processor not to be called, got strategies %v", processor.strategies)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("ProcessDocument failed: %v", err)
+				}
+				if len(processor.strategies) != 1 || processor.strategies[0] != ChunkStrategyFixed {
+					t.Errorf("Expected fallback to %q, got %v", ChunkStrategyFixed, processor.strategies)
+				}
+			}
+
+			stored, _ := f.docRepo.GetByID(context.Background(), doc.ID)
+			if stored.ProcessStatus != tt.wantStatus {
+				t.Errorf("Expected status %q, got %q", tt.wantStatus, stored.ProcessStatus)
+			}
+		})
+	}
+}
+
+func TestProcessDocument_SupportedChunkStrategyUnchanged(t *testing.T) {
+	processor := &strategyRecordingProcessor{}
+	f := newTestFixture().withProcessor(processor)
+	f.config.StrictChunkStrategy = true
+	f.kb.ChunkStrategy = ChunkStrategyRecursive
+
+	doc := f.addDocument("doc-1", []byte("some content"))
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+	if len(processor.strategies) != 1 || processor.strategies[0] != ChunkStrategyRecursive {
+		t.Errorf("Expected %q to be passed through, got %v", ChunkStrategyRecursive, processor.strategies)
+	}
+}
+
+func TestIsValidChunkOverlap(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		overlap int
+		unit    string
+		want    bool
+	}{
+		{name: "tokens below chunk size", size: 512, overlap: 511, unit: ChunkOverlapUnitTokens, want: true},
+		{name: "tokens equal to chunk size", size: 512, overlap: 512, unit: ChunkOverlapUnitTokens, want: false},
+		{name: "negative overlap", size: 512, overlap: -1, unit: ChunkOverlapUnitTokens, want: false},
+		{name: "characters within half chunk", size: 512, overlap: 256, unit: ChunkOverlapUnitCharacters, want: true},
+		{name: "characters over half chunk", size: 512, overlap: 257, unit: ChunkOverlapUnitCharacters, want: false},
+		{name: "sentences within limit", size: 512, overlap: 5, unit: ChunkOverlapUnitSentences, want: true},
+		{name: "sentences over limit", size: 512, overlap: 6, unit: ChunkOverlapUnitSentences, want: false},
+		{name: "sentences on smallest chunk", size: 100, overlap: 2, unit: ChunkOverlapUnitSentences, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidChunkOverlap(tt.size, tt.overlap, tt.unit); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// 空内容处理策略（文本提取/分块结果为空时）
+const (
+	EmptyContentPolicyFail         = "fail"           // 标记为失败（默认）
+	EmptyContentPolicyMarkEmpty    = "mark-empty"     // 标记为 empty 状态，不视为错误
+	EmptyContentPolicyRetryWithOCR = "retry-with-ocr" // 使用 OCR 重新提取，仍为空则标记为 empty
+)
+
+// 同一知识库内重复文档（文件哈希相同）的处理策略
+const (
+	DuplicateDocumentPolicyAllow          = "allow"           // 允许重复添加（默认）
+	DuplicateDocumentPolicyReject         = "reject"          // 拒绝上传，返回 ErrDocumentHashExists
+	DuplicateDocumentPolicyReturnExisting = "return-existing" // 返回已有文档，不创建新记录
+)
+
+// 分块内容超过 Milvus content 字段长度时的处理策略
+const (
+	VectorContentPolicyTruncate = "truncate" // 截断写入 Milvus 的内容，数据库保留完整内容（默认）
+	VectorContentPolicyReject   = "reject"   // 拒绝处理，文档标记为失败
+)
+
+// 复用已有文件（哈希相同）时 MinIO 对象缺失的处理策略
+const (
+	MissingObjectPolicyReupload  = "reupload"   // 增加引用前检查对象是否存在，缺失时重新上传（默认）
+	MissingObjectPolicySkipCheck = "skip-check" // 不检查，直接增加引用计数
+)
+
+// MilvusContentMaxBytes Milvus collection 中 content 字段（VARCHAR）的最大长度（字节）
+const MilvusContentMaxBytes = 65535
+
+// DocumentConfig 文档处理配置
+type DocumentConfig struct {
+	EmptyContentPolicy       string        // fail, mark-empty, retry-with-ocr
+	VectorSearchTimeout      time.Duration // 向量搜索超时（0 表示不限制，超时后返回部分结果 + 关键词结果）
+	DuplicateDocumentPolicy  string        // allow, reject, return-existing
+	CompactAfterDeletes      int           // 每个 collection 累计删除多少个文档的向量后自动压缩（0 表示不自动压缩）
+	CompactionTimeout        time.Duration // 后台压缩超时
+	VectorContentMaxBytes    int           // 写入 Milvus 的分块内容最大字节数（不超过 MilvusContentMaxBytes）
+	VectorContentPolicy      string        // truncate, reject
+	EmbeddingCostPer1KTokens float64       // 每千 token 的 Embedding 单价，用于估算文档处理成本（0 表示不估算）
+	StrictChunkStrategy      bool          // 知识库配置了不支持的分块策略时直接失败（默认回退为 fixed 并记录警告）
+	BackupEmbeddingModelID   string        // 主 Embedding 模型被标记为不可用时使用的备用模型 ID（需为同一模型的其他部署，为空表示不路由）
+	MaxSearchTopK            int           // 单次搜索的 topK 上限，超出的请求被截断（默认 DefaultMaxSearchTopK）
+	MinResultContentLength   int           // 搜索结果内容的最小字符数，低于该值的结果在融合后被丢弃（0 表示不过滤）
+	MissingObjectPolicy      string        // reupload, skip-check
+	SkipUnchangedReprocess   bool          // 重新处理时文件与分块配置自上次成功处理后未变化则跳过（可用 force 强制重新处理）
+}
+
+// DefaultDocumentConfig 默认文档处理配置
+func DefaultDocumentConfig() *DocumentConfig {
+	return &DocumentConfig{
+		EmptyContentPolicy:      EmptyContentPolicyFail,
+		DuplicateDocumentPolicy: DuplicateDocumentPolicyAllow,
+		CompactionTimeout:       10 * time.Minute,
+		VectorContentMaxBytes:   MilvusContentMaxBytes,
+		VectorContentPolicy:     VectorContentPolicyTruncate,
+		MaxSearchTopK:           DefaultMaxSearchTopK,
+		MissingObjectPolicy:     MissingObjectPolicyReupload,
+	}
+}
+
+// OCRDocumentProcessor 支持强制 OCR 提取的文档处理器（可选能力）
+type OCRDocumentProcessor interface {
+	ExtractTextWithOCR(ctx context.Context, fileData []byte, fileType string) (string, error)
+}
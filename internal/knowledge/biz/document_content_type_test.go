@@ -0,0 +1,65 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResolveContentType(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns stored content type", func(t *testing.T) {
+		f := newTestFixture()
+		doc, err := f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, "report.pdf", []byte("pdf content"), "pdf")
+		if err != nil {
+			t.Fatalf("UploadDocument failed: %v", err)
+		}
+
+		// 修改存储记录，确认返回的是 FileStorage 中的值而非按扩展名推断
+		stored, _ := f.fileRepo.GetByHash(ctx, doc.FileHash)
+		if stored == nil || stored.ContentType != "application/pdf" {
+			t.Fatalf("Expected file storage with application/pdf, got %+v", stored)
+		}
+		stored.ContentType = "application/x-pdf"
+
+		contentType, err := f.useCase.ResolveContentType(ctx, doc)
+		if err != nil {
+			t.Fatalf("ResolveContentType failed: %v", err)
+		}
+		if contentType != "application/x-pdf" {
+			t.Errorf("Expected content type from file storage, got %q", contentType)
+		}
+
+		resp := ToDocumentResponse(doc)
+		resp.ContentType = contentType
+		if resp.ContentType != stored.ContentType {
+			t.Errorf("Expected response content type %q, got %q", stored.ContentType, resp.ContentType)
+		}
+	})
+
+	t.Run("empty for documents without stored file", func(t *testing.T) {
+		f := newTestFixture()
+		for _, doc := range []*Document{
+			{ID: "text-doc", SourceType: "text"},
+			{ID: "missing", FileHash: "unknown-hash"},
+		} {
+			contentType, err := f.useCase.ResolveContentType(ctx, doc)
+			if err != nil {
+				t.Fatalf("ResolveContentType(%s) failed: %v", doc.ID, err)
+			}
+			if contentType != "" {
+				t.Errorf("Expected empty content type for %s, got %q", doc.ID, contentType)
+			}
+		}
+	})
+
+	t.Run("propagates lookup errors", func(t *testing.T) {
+		f := newTestFixture()
+		f.fileRepo.getErr = errors.New("db down")
+
+		if _, err := f.useCase.ResolveContentType(ctx, &Document{ID: "doc-1", FileHash: "hash"}); err == nil {
+			t.Fatal("Expected error when file storage lookup fails")
+		}
+	})
+}
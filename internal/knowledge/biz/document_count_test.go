@@ -0,0 +1,93 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDocumentCount_ConcurrentUploadsAndDeletes(t *testing.T) {
+	f := newTestFixture()
+	ctx := context.Background()
+
+	const existing, uploads = 20, 20
+	for i := 0; i < existing; i++ {
+		f.addDocument(fmt.Sprintf("doc-%d", i), []byte(fmt.Sprintf("existing content %d", i)))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, existing+uploads+1)
+	for i := 0; i < uploads; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := []byte(fmt.Sprintf("uploaded content %d", i))
+			if _, err := f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, fmt.Sprintf("upload-%d.txt", i), data, "txt"); err != nil {
+				errs <- fmt.Errorf("upload %d: %w", i, err)
+			}
+		}(i)
+	}
+	// 前一半单个删除，后一半批量删除（与单个删除有重叠，重复删除不应重复扣减）
+	for i := 0; i < existing/2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := f.useCase.DeleteDocument(ctx, fmt.Sprintf("doc-%d", i), testUserID); err != nil {
+				errs <- fmt.Errorf("delete %d: %w", i, err)
+			}
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ids := make([]string, 0, existing/2+1)
+		for i := existing/2 - 1; i < existing; i++ {
+			ids = append(ids, fmt.Sprintf("doc-%d", i))
+		}
+		f.useCase.BatchDeleteDocuments(ctx, ids, testUserID)
+	}()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		// 与批量删除竞争的单个删除可能找不到文档
+		t.Logf("concurrent operation: %v", err)
+	}
+
+	f.docRepo.mu.Lock()
+	actual := int64(len(f.docRepo.docs))
+	f.docRepo.mu.Unlock()
+
+	kb, err := f.kbRepo.GetByID(ctx, f.kb.ID, testUserID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if actual != uploads {
+		t.Errorf("Expected %d remaining documents, got %d", uploads, actual)
+	}
+	if kb.DocumentCount != actual {
+		t.Errorf("Expected document_count %d to match actual documents, got %d", actual, kb.DocumentCount)
+	}
+}
+
+func TestProcessDocument_DoesNotChangeDocumentCount(t *testing.T) {
+	f := newTestFixture()
+	doc := f.addDocument("doc-1", []byte("some content"))
+	ctx := context.Background()
+
+	// 首次处理与重新处理都不应改变计数（计数只随文档创建/删除变化）
+	for i := 0; i < 2; i++ {
+		if err := f.useCase.ProcessDocument(ctx, doc.ID); err != nil {
+			t.Fatalf("ProcessDocument failed: %v", err)
+		}
+	}
+
+	kb, err := f.kbRepo.GetByID(ctx, f.kb.ID, testUserID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if kb.DocumentCount != 1 {
+		t.Errorf("Expected document_count 1, got %d", kb.DocumentCount)
+	}
+}
@@ -0,0 +1,143 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestUploadDocument_DuplicateDocumentPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        string
+		wantErr       error
+		wantExisting  bool
+		wantDocuments int
+	}{
+		{name: "allow", policy: DuplicateDocumentPolicyAllow, wantDocuments: 2},
+		{name: "reject", policy: DuplicateDocumentPolicyReject, wantErr: ErrDocumentHashExists, wantDocuments: 1},
+		{name: "return existing", policy: DuplicateDocumentPolicyReturnExisting, wantExisting: true, wantDocuments: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			f.config.DuplicateDocumentPolicy = tt.policy
+			ctx := context.Background()
+			data := []byte("same file content")
+
+			first, err := f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, "a.txt", data, "txt")
+			if err != nil {
+				t.Fatalf("First upload failed: %v", err)
+			}
+
+			outcome, err := f.useCase.UploadDocumentWithOutcome(ctx, f.kb.ID, testUserID, "a-copy.txt", data, "txt")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Second upload failed: %v", err)
+				}
+				if outcome.Existing != tt.wantExisting {
+					t.Errorf("Expected Existing=%v, got %v", tt.wantExisting, outcome.Existing)
+				}
+				if tt.wantExisting && outcome.Document.ID != first.ID {
+					t.Errorf("Expected existing document %s, got %s", first.ID, outcome.Document.ID)
+				}
+			}
+
+			docs, _, _ := f.docRepo.List(ctx, f.kb.ID, &ListDocumentsRequest{Page: 1, PageSize: 10})
+			if len(docs) != tt.wantDocuments {
+				t.Errorf("Expected %d documents in KB, got %d", tt.wantDocuments, len(docs))
+			}
+
+			// 仅新建文档时增加物理文件引用
+			fs, _ := f.fileRepo.GetByHash(ctx, first.FileHash)
+			if fs == nil || fs.ReferenceCount != tt.wantDocuments {
+				t.Errorf("Expected reference count %d, got %+v", tt.wantDocuments, fs)
+			}
+		})
+	}
+}
+
+func TestUploadDocument_DuplicateInOtherKnowledgeBaseAllowed(t *testing.T) {
+	f := newTestFixture()
+	f.config.DuplicateDocumentPolicy = DuplicateDocumentPolicyReject
+	other := *f.kb
+	other.ID = "kb-2"
+	f.kbRepo.kbs[other.ID] = &other
+	ctx := context.Background()
+	data := []byte("shared file")
+
+	if _, err := f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, "a.txt", data, "txt"); err != nil {
+		t.Fatalf("Upload to first KB failed: %v", err)
+	}
+	if _, err := f.useCase.UploadDocument(ctx, other.ID, testUserID, "a.txt", data, "txt"); err != nil {
+		t.Fatalf("Expected same file in another KB to be allowed, got %v", err)
+	}
+}
+
+func TestUploadDocument_ConcurrentDuplicates(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       string
+		wantExisting bool
+	}{
+		{name: "reject", policy: DuplicateDocumentPolicyReject},
+		{name: "return existing", policy: DuplicateDocumentPolicyReturnExisting, wantExisting: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			f.config.DuplicateDocumentPolicy = tt.policy
+			ctx := context.Background()
+			data := []byte("uploaded twice in one batch")
+
+			const uploads = 8
+			var wg sync.WaitGroup
+			outcomes := make([]*UploadOutcome, uploads)
+			errs := make([]error, uploads)
+			for i := 0; i < uploads; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					outcomes[i], errs[i] = f.useCase.UploadDocumentWithOutcome(ctx, f.kb.ID, testUserID, fmt.Sprintf("copy-%d.txt", i), data, "txt")
+				}(i)
+			}
+			wg.Wait()
+
+			created := 0
+			for i := 0; i < uploads; i++ {
+				switch {
+				case errs[i] != nil:
+					if tt.wantExisting || !errors.Is(errs[i], ErrDocumentHashExists) {
+						t.Errorf("Upload %d: unexpected error %v", i, errs[i])
+					}
+				case outcomes[i].Existing:
+					if !tt.wantExisting {
+						t.Errorf("Upload %d: unexpected existing outcome", i)
+					}
+				default:
+					created++
+				}
+			}
+			if created != 1 {
+				t.Errorf("Expected exactly one created document, got %d", created)
+			}
+
+			docs, _, _ := f.docRepo.List(ctx, f.kb.ID, &ListDocumentsRequest{Page: 1, PageSize: 20})
+			if len(docs) != 1 {
+				t.Fatalf("Expected 1 document in KB, got %d", len(docs))
+			}
+			fs, _ := f.fileRepo.GetByHash(ctx, docs[0].FileHash)
+			if fs == nil || fs.ReferenceCount != 1 {
+				t.Errorf("Expected reference count 1 after rollbacks, got %+v", fs)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package biz
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProcessDocument_EmptyContentPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		processor   DocumentProcessor
+		wantErr     bool
+		wantStatus  ProcessStatus
+		wantReason  string
+		wantChunks  int
+		wantOCRCall bool
+	}{
+		{
+			name:       "fail",
+			policy:     EmptyContentPolicyFail,
+			processor:  &fakeProcessor{},
+			wantErr:    true,
+			wantStatus: "failed",
+			wantReason: "no content extracted",
+		},
+		{
+			name:       "mark empty",
+			policy:     EmptyContentPolicyMarkEmpty,
+			processor:  &fakeProcessor{},
+			wantStatus: "empty",
+			wantReason: "no content extracted",
+		},
+		{
+			name:       "retry with OCR unavailable",
+			policy:     EmptyContentPolicyRetryWithOCR,
+			processor:  &fakeProcessor{},
+			wantStatus: "empty",
+			wantReason: "OCR not available",
+		},
+		{
+			name:        "retry with OCR still empty",
+			policy:      EmptyContentPolicyRetryWithOCR,
+			processor:   &fakeOCRProcessor{ocrText: "  "},
+			wantStatus:  "empty",
+			wantReason:  "OCR retry returned no content",
+			wantOCRCall: true,
+		},
+		{
+			name:        "retry with OCR succeeds",
+			policy:      EmptyContentPolicyRetryWithOCR,
+			processor:   &fakeOCRProcessor{ocrText: "scanned text"},
+			wantStatus:  "completed",
+			wantChunks:  1,
+			wantOCRCall: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture().withProcessor(tt.processor)
+			f.config.EmptyContentPolicy = tt.policy
+
+			// 纯空白内容：提取后无法分块
+			doc := f.addDocument("doc-empty", []byte(" \n\n \n"))
+
+			err := f.useCase.ProcessDocument(context.Background(), doc.ID)
+			if tt.wantErr && err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			stored := f.docRepo.docs[doc.ID]
+			if stored.ProcessStatus != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, stored.ProcessStatus)
+			}
+			if tt.wantReason != "" && !strings.Contains(stored.ProcessError, tt.wantReason) {
+				t.Errorf("Expected reason containing %q, got %q", tt.wantReason, stored.ProcessError)
+			}
+			if got := len(f.chunkRepo.chunks[doc.ID]); got != tt.wantChunks {
+				t.Errorf("Expected %d chunks, got %d", tt.wantChunks, got)
+			}
+			if ocr, ok := tt.processor.(*fakeOCRProcessor); ok && tt.wantOCRCall && ocr.ocrCalls != 1 {
+				t.Errorf("Expected 1 OCR call, got %d", ocr.ocrCalls)
+			}
+		})
+	}
+}
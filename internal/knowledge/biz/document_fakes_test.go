@@ -0,0 +1,968 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 测试用内存实现（仅供 biz 包内的单元测试使用）
+
+type fakeDocumentRepo struct {
+	mu   sync.Mutex
+	docs map[string]*Document
+
+	fetches int                       // GetByID/GetByIDs 调用次数
+	stages  map[string][]ProcessStage // 按文档记录 UpdateStage 的调用顺序
+
+	kbRepo *fakeKnowledgeBaseRepo // 非空时模拟真实仓储：创建/删除文档时同步维护知识库文档计数
+
+	createErr error // Create 返回的错误
+}
+
+func newFakeDocumentRepo(docs ...*Document) *fakeDocumentRepo {
+	r := &fakeDocumentRepo{docs: make(map[string]*Document), stages: make(map[string][]ProcessStage)}
+	for _, doc := range docs {
+		r.docs[doc.ID] = doc
+	}
+	return r
+}
+
+func (r *fakeDocumentRepo) Create(ctx context.Context, doc *Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.createErr != nil {
+		return r.createErr
+	}
+	// 模拟 (knowledge_base_id, dedup_hash) 唯一约束
+	if doc.DedupHash != "" {
+		for _, existing := range r.docs {
+			if existing.KnowledgeBaseID == doc.KnowledgeBaseID && existing.DedupHash == doc.DedupHash {
+				return fmt.Errorf("%w: %s", ErrDocumentHashExists, doc.FileName)
+			}
+		}
+	}
+	if r.kbRepo != nil {
+		if err := r.kbRepo.IncrementDocumentCount(ctx, doc.KnowledgeBaseID, 1); err != nil {
+			return err
+		}
+	}
+	r.docs[doc.ID] = doc
+	return nil
+}
+
+func (r *fakeDocumentRepo) GetByID(ctx context.Context, id string) (*Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches++
+	doc, ok := r.docs[id]
+	if !ok {
+		return nil, ErrDocumentNotFound
+	}
+	copied := *doc
+	return &copied, nil
+}
+
+func (r *fakeDocumentRepo) GetByIDs(ctx context.Context, ids []string) ([]*Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches++
+	docs := make([]*Document, 0, len(ids))
+	for _, id := range ids {
+		if doc, ok := r.docs[id]; ok {
+			copied := *doc
+			docs = append(docs, &copied)
+		}
+	}
+	return docs, nil
+}
+
+func (r *fakeDocumentRepo) GetByFileHash(ctx context.Context, kbID, fileHash string) (*Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, doc := range r.docs {
+		if doc.KnowledgeBaseID == kbID && doc.FileHash == fileHash {
+			copied := *doc
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeDocumentRepo) GetByBatchFile(ctx context.Context, kbID, batchID, fileName string) (*Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, doc := range r.docs {
+		if doc.KnowledgeBaseID == kbID && doc.BatchID == batchID && doc.FileName == fileName {
+			copied := *doc
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeDocumentRepo) List(ctx context.Context, kbID string, req *ListDocumentsRequest) ([]*Document, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	docs := make([]*Document, 0)
+	for _, doc := range r.docs {
+		if doc.KnowledgeBaseID == kbID {
+			copied := *doc
+			docs = append(docs, &copied)
+		}
+	}
+	return docs, int64(len(docs)), nil
+}
+
+func (r *fakeDocumentRepo) Update(ctx context.Context, doc *Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *doc
+	r.docs[doc.ID] = &copied
+	return nil
+}
+
+func (r *fakeDocumentRepo) Delete(ctx context.Context, id string) error {
+	return r.BatchDelete(ctx, []string{id})
+}
+
+func (r *fakeDocumentRepo) BatchDelete(ctx context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		doc, ok := r.docs[id]
+		if !ok {
+			continue
+		}
+		if r.kbRepo != nil {
+			if err := r.kbRepo.IncrementDocumentCount(ctx, doc.KnowledgeBaseID, -1); err != nil {
+				return err
+			}
+		}
+		delete(r.docs, id)
+	}
+	return nil
+}
+
+func (r *fakeDocumentRepo) UpdateStatus(ctx context.Context, id string, status ProcessStatus, errorMsg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !status.Valid() {
+		return ErrInvalidProcessStatus
+	}
+	doc, ok := r.docs[id]
+	if !ok {
+		return ErrDocumentNotFound
+	}
+	doc.ProcessStatus = status
+	doc.ProcessError = errorMsg
+	if status != ProcessStatusFailed {
+		doc.ProcessStage = ProcessStageNone
+	}
+	return nil
+}
+
+func (r *fakeDocumentRepo) UpdateStage(ctx context.Context, id string, stage ProcessStage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !stage.Valid() {
+		return ErrInvalidProcessStage
+	}
+	doc, ok := r.docs[id]
+	if !ok {
+		return ErrDocumentNotFound
+	}
+	doc.ProcessStage = stage
+	r.stages[id] = append(r.stages[id], stage)
+	return nil
+}
+
+func (r *fakeDocumentRepo) UpdateMetadata(ctx context.Context, id string, metadata map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc, ok := r.docs[id]
+	if !ok {
+		return ErrDocumentNotFound
+	}
+	doc.Metadata = metadata
+	return nil
+}
+
+type fakeChunkRepo struct {
+	mu        sync.Mutex
+	chunks    map[string][]*Chunk // documentID -> chunks
+	mutations int                 // 写操作次数
+
+	tsv            map[string]string // chunkID -> 全文索引内容（空表示未建立索引）
+	reindexBatches int
+	keywordTopKs   []int // 每次关键词检索请求的 topK
+	batchGets      int   // GetByDocumentIDs 调用次数
+}
+
+func newFakeChunkRepo() *fakeChunkRepo {
+	return &fakeChunkRepo{chunks: make(map[string][]*Chunk)}
+}
+
+func (r *fakeChunkRepo) BatchCreate(ctx context.Context, chunks []*Chunk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mutations++
+	for _, chunk := range chunks {
+		r.chunks[chunk.DocumentID] = append(r.chunks[chunk.DocumentID], chunk)
+	}
+	return nil
+}
+
+func (r *fakeChunkRepo) GetByDocumentID(ctx context.Context, docID string) ([]*Chunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.chunks[docID], nil
+}
+
+func (r *fakeChunkRepo) GetByDocumentIDs(ctx context.Context, docIDs []string, limitPerDoc int) (map[string][]*Chunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batchGets++
+	grouped := make(map[string][]*Chunk)
+	for _, id := range docIDs {
+		chunks := append([]*Chunk(nil), r.chunks[id]...)
+		sort.Slice(chunks, func(i, j int) bool { return chunks[i].Position < chunks[j].Position })
+		if limitPerDoc > 0 && len(chunks) > limitPerDoc {
+			chunks = chunks[:limitPerDoc]
+		}
+		if len(chunks) > 0 {
+			grouped[id] = chunks
+		}
+	}
+	return grouped, nil
+}
+
+func (r *fakeChunkRepo) DeleteByDocumentID(ctx context.Context, docID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mutations++
+	delete(r.chunks, docID)
+	return nil
+}
+
+func (r *fakeChunkRepo) BatchDeleteByDocumentIDs(ctx context.Context, docIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mutations++
+	for _, id := range docIDs {
+		delete(r.chunks, id)
+	}
+	return nil
+}
+
+func (r *fakeChunkRepo) DeleteByKnowledgeBaseID(ctx context.Context, kbID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mutations++
+	for docID, chunks := range r.chunks {
+		if len(chunks) > 0 && chunks[0].KnowledgeBaseID == kbID {
+			delete(r.chunks, docID)
+		}
+	}
+	return nil
+}
+
+// KeywordSearch 仅在已建立全文索引的分块中做子串匹配（模拟 content_tsv @@ query）
+func (r *fakeChunkRepo) KeywordSearch(ctx context.Context, kbID, query string, topK int) ([]*Chunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keywordTopKs = append(r.keywordTopKs, topK)
+	// 模拟 plainto_tsquery：所有词都需出现在已索引内容中
+	terms := strings.Fields(strings.ToLower(query))
+	var results []*Chunk
+	for _, chunk := range r.sortedChunks(kbID) {
+		if r.tsv[chunk.ID] != "" && len(terms) > 0 && containsAllTerms(r.tsv[chunk.ID], terms) {
+			results = append(results, chunk)
+		}
+	}
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func containsAllTerms(text string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *fakeChunkRepo) ReindexKeywordSearchBatch(ctx context.Context, kbID, afterID string, batchSize int) (string, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reindexBatches++
+	if r.tsv == nil {
+		r.tsv = make(map[string]string)
+	}
+	lastID, count := afterID, 0
+	for _, chunk := range r.sortedChunks(kbID) {
+		if chunk.ID <= afterID || count >= batchSize {
+			continue
+		}
+		r.tsv[chunk.ID] = strings.ToLower(chunk.Content)
+		lastID = chunk.ID
+		count++
+	}
+	return lastID, count, nil
+}
+
+// sortedChunks 按 ID 排序返回知识库下所有分块（调用方需持有锁）
+func (r *fakeChunkRepo) sortedChunks(kbID string) []*Chunk {
+	var all []*Chunk
+	for _, chunks := range r.chunks {
+		for _, chunk := range chunks {
+			if chunk.KnowledgeBaseID == kbID {
+				all = append(all, chunk)
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
+
+type fakeKnowledgeBaseRepo struct {
+	mu  sync.Mutex
+	kbs map[string]*KnowledgeBase
+}
+
+func newFakeKnowledgeBaseRepo(kbs ...*KnowledgeBase) *fakeKnowledgeBaseRepo {
+	r := &fakeKnowledgeBaseRepo{kbs: make(map[string]*KnowledgeBase)}
+	for _, kb := range kbs {
+		r.kbs[kb.ID] = kb
+	}
+	return r
+}
+
+func (r *fakeKnowledgeBaseRepo) Create(ctx context.Context, kb *KnowledgeBase) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.kbs[kb.ID] = kb
+	return nil
+}
+
+func (r *fakeKnowledgeBaseRepo) GetByID(ctx context.Context, id string, userID string) (*KnowledgeBase, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kb, ok := r.kbs[id]
+	if !ok {
+		return nil, ErrKnowledgeBaseNotFound
+	}
+	copied := *kb
+	return &copied, nil
+}
+
+func (r *fakeKnowledgeBaseRepo) List(ctx context.Context, req *ListKnowledgeBasesRequest) ([]*KnowledgeBase, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeKnowledgeBaseRepo) Update(ctx context.Context, kb *KnowledgeBase) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.kbs[kb.ID] = kb
+	return nil
+}
+
+func (r *fakeKnowledgeBaseRepo) Delete(ctx context.Context, id string, ownerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.kbs, id)
+	return nil
+}
+
+func (r *fakeKnowledgeBaseRepo) IncrementDocumentCount(ctx context.Context, id string, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kb, ok := r.kbs[id]
+	if !ok {
+		return ErrKnowledgeBaseNotFound
+	}
+	kb.DocumentCount += int64(delta)
+	return nil
+}
+
+func (r *fakeKnowledgeBaseRepo) BatchUpdateDocumentCounts(ctx context.Context, deltas map[string]int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, delta := range deltas {
+		if kb, ok := r.kbs[id]; ok {
+			kb.DocumentCount += int64(delta)
+		}
+	}
+	return nil
+}
+
+func (r *fakeKnowledgeBaseRepo) ListIDs(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.kbs))
+	for id := range r.kbs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+type fakeAIModelRepo struct {
+	mu     sync.Mutex
+	models map[string]*AIModel
+}
+
+func (r *fakeAIModelRepo) GetByID(ctx context.Context, id string) (*AIModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if model, ok := r.models[id]; ok {
+		return model, nil
+	}
+	return nil, fmt.Errorf("model not found: %s", id)
+}
+
+func (r *fakeAIModelRepo) ListByProviderID(ctx context.Context, providerID string) ([]*AIModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	models := make([]*AIModel, 0)
+	for _, model := range r.models {
+		if model.ProviderID == providerID {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+func (r *fakeAIModelRepo) List(ctx context.Context, req *ListAIModelsRequest) ([]*AIModel, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := make([]*AIModel, 0)
+	for _, model := range r.models {
+		if model.ProviderID != req.ProviderID {
+			continue
+		}
+		if req.IsEnabled != nil && model.IsEnabled != *req.IsEnabled {
+			continue
+		}
+		hasCapability := req.CapabilityType == ""
+		for _, c := range model.Capabilities {
+			if c == req.CapabilityType {
+				hasCapability = true
+			}
+		}
+		if !hasCapability {
+			continue
+		}
+		matched = append(matched, model)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ModelName < matched[j].ModelName })
+
+	total := int64(len(matched))
+	if req.PageSize == 0 {
+		return matched, total, nil
+	}
+	start := (req.Page - 1) * req.PageSize
+	if start >= len(matched) {
+		return []*AIModel{}, total, nil
+	}
+	end := start + req.PageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], total, nil
+}
+
+func (r *fakeAIModelRepo) ListByCapabilityType(ctx context.Context, capabilityType string) ([]*AIModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return nil, nil
+}
+
+func (r *fakeAIModelRepo) ListAll(ctx context.Context) ([]*AIModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	models := make([]*AIModel, 0, len(r.models))
+	for _, model := range r.models {
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+func (r *fakeAIModelRepo) Create(ctx context.Context, model *AIModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models[model.ID] = model
+	return nil
+}
+
+func (r *fakeAIModelRepo) Update(ctx context.Context, model *AIModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models[model.ID] = model
+	return nil
+}
+
+func (r *fakeAIModelRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.models, id)
+	return nil
+}
+
+type fakeAIProviderRepo struct {
+	providers map[string]*AIProvider
+}
+
+func (r *fakeAIProviderRepo) ListAll(ctx context.Context) ([]*AIProvider, error) {
+	providers := make([]*AIProvider, 0, len(r.providers))
+	for _, p := range r.providers {
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+func (r *fakeAIProviderRepo) GetByID(ctx context.Context, id string) (*AIProvider, error) {
+	if p, ok := r.providers[id]; ok {
+		return p, nil
+	}
+	return nil, ErrAIProviderNotFound
+}
+
+func (r *fakeAIProviderRepo) GetByType(ctx context.Context, providerType string) (*AIProvider, error) {
+	for _, p := range r.providers {
+		if p.ProviderType == providerType {
+			return p, nil
+		}
+	}
+	return nil, ErrAIProviderNotFound
+}
+
+func (r *fakeAIProviderRepo) UpdateStatus(ctx context.Context, id string, isEnabled bool) error {
+	return nil
+}
+
+func (r *fakeAIProviderRepo) UpdateConfig(ctx context.Context, id string, apiKey, apiBaseURL *string) error {
+	return nil
+}
+
+type fakeFileStorageRepo struct {
+	mu    sync.Mutex
+	files map[string]*FileStorage
+
+	getErr       error // GetByHash 返回的错误
+	createErr    error // Create 返回的错误
+	incrementErr error // IncrementReference 返回的错误
+}
+
+func newFakeFileStorageRepo() *fakeFileStorageRepo {
+	return &fakeFileStorageRepo{files: make(map[string]*FileStorage)}
+}
+
+func (r *fakeFileStorageRepo) Create(ctx context.Context, fs *FileStorage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.files[fs.FileHash] = fs
+	return nil
+}
+
+func (r *fakeFileStorageRepo) GetByHash(ctx context.Context, fileHash string) (*FileStorage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	return r.files[fileHash], nil
+}
+
+func (r *fakeFileStorageRepo) IncrementReference(ctx context.Context, fileHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.incrementErr != nil {
+		return r.incrementErr
+	}
+	if fs, ok := r.files[fileHash]; ok {
+		fs.ReferenceCount++
+	}
+	return nil
+}
+
+func (r *fakeFileStorageRepo) DecrementReference(ctx context.Context, fileHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fs, ok := r.files[fileHash]; ok {
+		fs.ReferenceCount--
+	}
+	return nil
+}
+
+func (r *fakeFileStorageRepo) BatchDecrementReferences(ctx context.Context, fileHashes []string) error {
+	for _, h := range fileHashes {
+		_ = r.DecrementReference(ctx, h)
+	}
+	return nil
+}
+
+func (r *fakeFileStorageRepo) DeleteIfNoReferences(ctx context.Context, fileHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fs, ok := r.files[fileHash]; ok && fs.ReferenceCount <= 0 {
+		delete(r.files, fileHash)
+		return true, nil
+	}
+	return false, nil
+}
+
+type fakeEmbeddingUsageRepo struct {
+	mu     sync.Mutex
+	usages []*EmbeddingUsage
+}
+
+func (r *fakeEmbeddingUsageRepo) Create(ctx context.Context, usage *EmbeddingUsage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.usages = append(r.usages, usage)
+	return nil
+}
+
+func (r *fakeEmbeddingUsageRepo) SumByKnowledgeBase(ctx context.Context, query *EmbeddingUsageQuery) ([]*KnowledgeBaseEmbeddingUsage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sums []*KnowledgeBaseEmbeddingUsage
+	byKB := make(map[string]*KnowledgeBaseEmbeddingUsage)
+	for _, usage := range r.usages {
+		if usage.CreatedAt.Before(query.From) || !usage.CreatedAt.Before(query.To) {
+			continue
+		}
+		if query.OwnerID != "" && usage.OwnerID != query.OwnerID {
+			continue
+		}
+		sum, ok := byKB[usage.KnowledgeBaseID]
+		if !ok {
+			sum = &KnowledgeBaseEmbeddingUsage{KnowledgeBaseID: usage.KnowledgeBaseID, OwnerID: usage.OwnerID}
+			byKB[usage.KnowledgeBaseID] = sum
+			sums = append(sums, sum)
+		}
+		sum.Tokens += usage.Tokens
+		sum.Runs++
+	}
+	return sums, nil
+}
+
+type fakeStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+
+	uploadErr error // UploadFile 返回的错误
+	statErr   error // ObjectExists 返回的错误
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{objects: make(map[string][]byte)}
+}
+
+func (s *fakeStorage) UploadFile(ctx context.Context, bucket, objectName string, data []byte, contentType string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uploadErr != nil {
+		return "", s.uploadErr
+	}
+	s.objects[bucket+"/"+objectName] = data
+	return objectName, nil
+}
+
+func (s *fakeStorage) GetFile(ctx context.Context, bucket, objectName string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[bucket+"/"+objectName]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s", objectName)
+	}
+	return data, nil
+}
+
+func (s *fakeStorage) DeleteFile(ctx context.Context, bucket, objectName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, bucket+"/"+objectName)
+	return nil
+}
+
+func (s *fakeStorage) ObjectExists(ctx context.Context, bucket, objectName string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.statErr != nil {
+		return false, s.statErr
+	}
+	_, ok := s.objects[bucket+"/"+objectName]
+	return ok, nil
+}
+
+type fakeVectorDB struct {
+	mu        sync.Mutex
+	vectors   map[string][]*Chunk // collection -> chunks
+	results   []*SearchResult     // Search/SearchWithThreshold 返回值
+	mutations int                 // 写操作次数
+
+	// 模拟慢查询的 collection：阻塞直到 ctx 结束，与真实实现一致出错时不返回结果
+	slowCollections map[string]bool
+
+	collectionResults map[string][]*SearchResult // 按 collection 返回的结果（优先于 results）
+	searched          []string                   // 已检索的 collection
+	compacted         []string                   // 已压缩的 collection
+	topKs             []int                      // 每次检索请求的 topK
+}
+
+func newFakeVectorDB() *fakeVectorDB {
+	return &fakeVectorDB{vectors: make(map[string][]*Chunk)}
+}
+
+func (v *fakeVectorDB) CreateCollection(ctx context.Context, collectionName string, dimension int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, exists := v.vectors[collectionName]; !exists {
+		v.vectors[collectionName] = nil
+	}
+	return nil
+}
+
+// HasCollection 创建过、写入过或预置了检索结果的 collection 视为存在
+func (v *fakeVectorDB) HasCollection(ctx context.Context, collectionName string) (bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, created := v.vectors[collectionName]
+	_, seeded := v.collectionResults[collectionName]
+	return created || seeded, nil
+}
+
+func (v *fakeVectorDB) InsertVectors(ctx context.Context, collectionName string, chunks []*Chunk) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mutations++
+	v.vectors[collectionName] = append(v.vectors[collectionName], chunks...)
+	return nil
+}
+
+func (v *fakeVectorDB) Search(ctx context.Context, collectionName string, vector []float32, topK int) ([]*SearchResult, error) {
+	return v.SearchWithThreshold(ctx, collectionName, vector, topK, 0)
+}
+
+func (v *fakeVectorDB) SearchWithThreshold(ctx context.Context, collectionName string, vector []float32, topK int, minScore float32) ([]*SearchResult, error) {
+	if v.slowCollections[collectionName] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.searched = append(v.searched, collectionName)
+	v.topKs = append(v.topKs, topK)
+	results := v.results
+	if collectionResults, ok := v.collectionResults[collectionName]; ok {
+		results = collectionResults
+	}
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func (v *fakeVectorDB) DeleteByDocumentID(ctx context.Context, collectionName, documentID string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mutations++
+	kept := v.vectors[collectionName][:0]
+	for _, chunk := range v.vectors[collectionName] {
+		if chunk.DocumentID != documentID {
+			kept = append(kept, chunk)
+		}
+	}
+	v.vectors[collectionName] = kept
+	return nil
+}
+
+func (v *fakeVectorDB) DropCollection(ctx context.Context, collectionName string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mutations++
+	delete(v.vectors, collectionName)
+	return nil
+}
+
+func (v *fakeVectorDB) CompactCollection(ctx context.Context, collectionName string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.compacted = append(v.compacted, collectionName)
+	return nil
+}
+
+func (v *fakeVectorDB) compactedCollections() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]string(nil), v.compacted...)
+}
+
+type fakeEmbedder struct {
+	dimension int
+
+	mu     sync.Mutex
+	models []string // 每次调用使用的模型 ID
+}
+
+func (e *fakeEmbedder) GenerateEmbeddings(ctx context.Context, texts []string, provider *AIProvider, model *AIModel) ([][]float32, error) {
+	e.mu.Lock()
+	e.models = append(e.models, model.ID)
+	e.mu.Unlock()
+
+	embeddings := make([][]float32, len(texts))
+	for i := range texts {
+		embeddings[i] = make([]float32, e.dimension)
+	}
+	return embeddings, nil
+}
+
+type fakeProcessor struct {
+	text   string
+	chunks []string
+}
+
+func (p *fakeProcessor) ExtractText(ctx context.Context, fileData []byte, fileType string) (string, error) {
+	if p.text != "" {
+		return p.text, nil
+	}
+	return string(fileData), nil
+}
+
+func (p *fakeProcessor) ChunkText(text string, chunkSize, chunkOverlap int, overlapUnit, strategy string) ([]string, error) {
+	if p.chunks != nil {
+		return p.chunks, nil
+	}
+	if strings.TrimSpace(text) == "" {
+		return []string{}, nil
+	}
+	return []string{text}, nil
+}
+
+// fakeOCRProcessor 支持 OCR 重新提取的处理器
+type fakeOCRProcessor struct {
+	fakeProcessor
+	ocrText  string
+	ocrCalls int
+}
+
+func (p *fakeOCRProcessor) ExtractTextWithOCR(ctx context.Context, fileData []byte, fileType string) (string, error) {
+	p.ocrCalls++
+	return p.ocrText, nil
+}
+
+// testFixture 组装 DocumentUseCase 及其依赖
+type testFixture struct {
+	docRepo    *fakeDocumentRepo
+	chunkRepo  *fakeChunkRepo
+	kbRepo     *fakeKnowledgeBaseRepo
+	modelRepo  *fakeAIModelRepo
+	fileRepo   *fakeFileStorageRepo
+	usageRepo  *fakeEmbeddingUsageRepo
+	storage    *fakeStorage
+	vectorDB   *fakeVectorDB
+	embedder   *fakeEmbedder
+	processor  DocumentProcessor
+	config     *DocumentConfig
+	useCase    *DocumentUseCase
+	kb         *KnowledgeBase
+	embedModel *AIModel
+	aiProvider *AIProvider
+}
+
+const testUserID = "user-1"
+
+func newTestFixture() *testFixture {
+	dims := 4
+	provider := &AIProvider{ID: "provider-1", ProviderType: "openai", ProviderName: "OpenAI", IsEnabled: true}
+	model := &AIModel{
+		ID:                  "model-1",
+		ProviderID:          provider.ID,
+		ModelName:           "text-embedding-3-small",
+		Capabilities:        []string{CapabilityTypeEmbedding},
+		EmbeddingDimensions: &dims,
+	}
+	kb := &KnowledgeBase{
+		ID:               "kb-1",
+		OwnerID:          testUserID,
+		Name:             "test kb",
+		EmbeddingModelID: model.ID,
+		ChunkSize:        512,
+		ChunkStrategy:    "recursive",
+		MilvusCollection: "kb_test",
+		TopK:             5,
+	}
+
+	f := &testFixture{
+		docRepo:    newFakeDocumentRepo(),
+		chunkRepo:  newFakeChunkRepo(),
+		kbRepo:     newFakeKnowledgeBaseRepo(kb),
+		modelRepo:  &fakeAIModelRepo{models: map[string]*AIModel{model.ID: model}},
+		fileRepo:   newFakeFileStorageRepo(),
+		usageRepo:  &fakeEmbeddingUsageRepo{},
+		storage:    newFakeStorage(),
+		vectorDB:   newFakeVectorDB(),
+		embedder:   &fakeEmbedder{dimension: dims},
+		processor:  &fakeProcessor{},
+		config:     DefaultDocumentConfig(),
+		kb:         kb,
+		embedModel: model,
+		aiProvider: provider,
+	}
+	f.docRepo.kbRepo = f.kbRepo
+	f.useCase = NewDocumentUseCase(
+		f.docRepo,
+		f.chunkRepo,
+		f.kbRepo,
+		f.modelRepo,
+		&fakeAIProviderRepo{providers: map[string]*AIProvider{provider.ID: provider}},
+		f.fileRepo,
+		f.usageRepo,
+		f.storage,
+		f.vectorDB,
+		f.embedder,
+		f.processor,
+		f.config,
+		&logger.Logger{Logger: zap.NewNop()},
+	)
+	return f
+}
+
+// withProcessor 替换文档处理器
+func (f *testFixture) withProcessor(processor DocumentProcessor) *testFixture {
+	f.processor = processor
+	f.useCase.processor = processor
+	return f
+}
+
+// addDocument 添加一个已上传的待处理文档
+func (f *testFixture) addDocument(id string, content []byte) *Document {
+	doc := &Document{
+		ID:              id,
+		KnowledgeBaseID: f.kb.ID,
+		FileName:        id + ".pdf",
+		FileType:        "pdf",
+		FileSize:        int64(len(content)),
+		FileHash:        calculateSHA256(content),
+		MinioBucket:     "knowledge-bases",
+		MinioObjectKey:  "files/" + id,
+		ProcessStatus:   "pending",
+	}
+	_ = f.docRepo.Create(context.Background(), doc)
+	f.storage.objects[doc.MinioBucket+"/"+doc.MinioObjectKey] = content
+	return doc
+}
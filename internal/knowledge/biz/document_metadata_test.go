@@ -0,0 +1,99 @@
+package biz
+
+import (
+	"context"
+	"testing"
+)
+
+func newMetadataTestFixture() (*testFixture, *Document) {
+	f := newTestFixture()
+	doc := &Document{
+		ID:              "doc-1",
+		KnowledgeBaseID: f.kb.ID,
+		FileName:        "report.pdf",
+		FileType:        "pdf",
+		ProcessStatus:   "completed",
+		ChunkCount:      1,
+		Metadata: map[string]interface{}{
+			"title":  "Old Title",
+			"author": "alice",
+		},
+	}
+	f.docRepo.docs[doc.ID] = doc
+	f.chunkRepo.chunks[doc.ID] = []*Chunk{{ID: "chunk-1", DocumentID: doc.ID, KnowledgeBaseID: f.kb.ID, Content: "hello"}}
+	return f, doc
+}
+
+func TestUpdateDocumentMetadata_Merge(t *testing.T) {
+	f, doc := newMetadataTestFixture()
+
+	updated, err := f.useCase.UpdateDocumentMetadata(context.Background(), doc.ID, testUserID, map[string]interface{}{
+		"title":    "New Title",
+		"category": "finance",
+	}, true)
+	if err != nil {
+		t.Fatalf("UpdateDocumentMetadata failed: %v", err)
+	}
+
+	stored := f.docRepo.docs[doc.ID].Metadata
+	expected := map[string]interface{}{
+		"title":    "New Title",
+		"author":   "alice",
+		"category": "finance",
+	}
+	if len(stored) != len(expected) {
+		t.Fatalf("Expected %d metadata keys, got %d: %v", len(expected), len(stored), stored)
+	}
+	for k, v := range expected {
+		if stored[k] != v {
+			t.Errorf("Expected metadata[%s]=%v, got %v", k, v, stored[k])
+		}
+	}
+	if updated.Metadata["author"] != "alice" {
+		t.Errorf("Expected returned document to keep existing keys, got %v", updated.Metadata)
+	}
+
+	if f.chunkRepo.mutations != 0 || f.vectorDB.mutations != 0 {
+		t.Errorf("Expected chunks and vectors untouched, got chunk mutations=%d vector mutations=%d",
+			f.chunkRepo.mutations, f.vectorDB.mutations)
+	}
+	if len(f.chunkRepo.chunks[doc.ID]) != 1 {
+		t.Errorf("Expected chunks to be preserved")
+	}
+}
+
+func TestUpdateDocumentMetadata_Replace(t *testing.T) {
+	f, doc := newMetadataTestFixture()
+
+	_, err := f.useCase.UpdateDocumentMetadata(context.Background(), doc.ID, testUserID, map[string]interface{}{
+		"category": "finance",
+	}, false)
+	if err != nil {
+		t.Fatalf("UpdateDocumentMetadata failed: %v", err)
+	}
+
+	stored := f.docRepo.docs[doc.ID].Metadata
+	if len(stored) != 1 || stored["category"] != "finance" {
+		t.Errorf("Expected metadata to be replaced, got %v", stored)
+	}
+	if f.docRepo.docs[doc.ID].ProcessStatus != "completed" {
+		t.Errorf("Expected process status unchanged, got %s", f.docRepo.docs[doc.ID].ProcessStatus)
+	}
+	if f.chunkRepo.mutations != 0 || f.vectorDB.mutations != 0 {
+		t.Errorf("Expected chunks and vectors untouched")
+	}
+}
+
+func TestUpdateDocumentMetadata_PermissionDenied(t *testing.T) {
+	f, doc := newMetadataTestFixture()
+
+	_, err := f.useCase.UpdateDocumentMetadata(context.Background(), doc.ID, "other-user", map[string]interface{}{
+		"category": "finance",
+	}, true)
+	if err == nil {
+		t.Fatal("Expected permission error for non-owner")
+	}
+	if f.docRepo.docs[doc.ID].Metadata["title"] != "Old Title" {
+		t.Errorf("Expected metadata unchanged on permission error")
+	}
+}
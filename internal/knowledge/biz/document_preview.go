@@ -0,0 +1,68 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// MaxPreviewChunksPerDocument 多文档预览时每个文档最多返回的分块数
+const MaxPreviewChunksPerDocument = 20
+
+// PreviewDocuments 批量获取知识库内多个文档的前 limitPerDoc 个分块（单次查询）
+// 不属于该知识库的文档会被忽略
+func (uc *DocumentUseCase) PreviewDocuments(ctx context.Context, kbID, userID string, docIDs []string, limitPerDoc int) (map[string][]*Chunk, error) {
+	kb, err := uc.kbRepo.GetByID(ctx, kbID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge base not found: %w", err)
+	}
+
+	if kb.OwnerID != userID && kb.OwnerID != SystemOwnerID {
+		return nil, fmt.Errorf("permission denied")
+	}
+
+	return uc.loadDocumentPreviews(ctx, kbID, docIDs, limitPerDoc)
+}
+
+// loadDocumentPreviews 查询文档的前 limitPerDoc 个分块，并过滤掉其他知识库的分块
+func (uc *DocumentUseCase) loadDocumentPreviews(ctx context.Context, kbID string, docIDs []string, limitPerDoc int) (map[string][]*Chunk, error) {
+	if limitPerDoc <= 0 || limitPerDoc > MaxPreviewChunksPerDocument {
+		limitPerDoc = MaxPreviewChunksPerDocument
+	}
+
+	grouped, err := uc.chunkRepo.GetByDocumentIDs(ctx, docIDs, limitPerDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document chunks: %w", err)
+	}
+
+	previews := make(map[string][]*Chunk, len(grouped))
+	for docID, chunks := range grouped {
+		if len(chunks) > 0 && chunks[0].KnowledgeBaseID == kbID {
+			previews[docID] = chunks
+		}
+	}
+	return previews, nil
+}
+
+// attachSearchPreviews 为搜索命中的文档附带分块预览（失败时仅记录警告，不影响搜索结果）
+func (uc *DocumentUseCase) attachSearchPreviews(ctx context.Context, kbID string, limitPerDoc int, outcome *SearchOutcome) {
+	seen := make(map[string]bool)
+	var docIDs []string
+	for _, result := range outcome.Results {
+		if result.DocumentID != "" && !seen[result.DocumentID] {
+			seen[result.DocumentID] = true
+			docIDs = append(docIDs, result.DocumentID)
+		}
+	}
+	if len(docIDs) == 0 {
+		return
+	}
+
+	previews, err := uc.loadDocumentPreviews(ctx, kbID, docIDs, limitPerDoc)
+	if err != nil {
+		uc.logger.Warn("查询搜索结果文档预览失败", zap.Error(err))
+		return
+	}
+	outcome.Previews = previews
+}
@@ -0,0 +1,96 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// addChunks 为文档添加 count 个分块（按位置倒序写入，验证预览按位置排序）
+func (f *testFixture) addChunks(kbID, docID string, count int) {
+	chunks := make([]*Chunk, count)
+	for i := 0; i < count; i++ {
+		pos := count - 1 - i
+		chunks[i] = &Chunk{
+			ID:              fmt.Sprintf("%s-chunk-%d", docID, pos),
+			DocumentID:      docID,
+			KnowledgeBaseID: kbID,
+			Content:         fmt.Sprintf("%s content %d", docID, pos),
+			Position:        pos,
+		}
+	}
+	_ = f.chunkRepo.BatchCreate(context.Background(), chunks)
+}
+
+func TestPreviewDocuments_GroupsChunksPerDocument(t *testing.T) {
+	f := newTestFixture()
+	f.addChunks(f.kb.ID, "doc-a", 5)
+	f.addChunks(f.kb.ID, "doc-b", 1)
+	f.addChunks("other-kb", "doc-other", 3)
+
+	previews, err := f.useCase.PreviewDocuments(context.Background(), f.kb.ID, testUserID, []string{"doc-a", "doc-b", "doc-other", "doc-missing"}, 2)
+	if err != nil {
+		t.Fatalf("PreviewDocuments failed: %v", err)
+	}
+
+	if f.chunkRepo.batchGets != 1 {
+		t.Errorf("Expected a single batch query, got %d", f.chunkRepo.batchGets)
+	}
+	if len(previews) != 2 {
+		t.Fatalf("Expected previews for doc-a and doc-b only, got %v", previews)
+	}
+	if got := previews["doc-a"]; len(got) != 2 || got[0].Position != 0 || got[1].Position != 1 {
+		t.Errorf("Expected first two chunks of doc-a, got %+v", got)
+	}
+	if got := previews["doc-b"]; len(got) != 1 || got[0].ID != "doc-b-chunk-0" {
+		t.Errorf("Expected the only chunk of doc-b, got %+v", got)
+	}
+}
+
+func TestPreviewDocuments_PermissionDenied(t *testing.T) {
+	f := newTestFixture()
+	f.addChunks(f.kb.ID, "doc-a", 1)
+
+	if _, err := f.useCase.PreviewDocuments(context.Background(), f.kb.ID, "someone-else", []string{"doc-a"}, 1); err == nil {
+		t.Fatal("Expected permission error for non-owner")
+	}
+	if f.chunkRepo.batchGets != 0 {
+		t.Errorf("Expected no chunk query when permission denied, got %d", f.chunkRepo.batchGets)
+	}
+}
+
+func TestSearchDocumentsWithOptions_AttachesPreviews(t *testing.T) {
+	f := newTestFixture()
+	f.addChunks(f.kb.ID, "doc-a", 4)
+	f.addChunks(f.kb.ID, "doc-b", 4)
+	f.vectorDB.results = []*SearchResult{
+		{ChunkID: "doc-a-chunk-2", DocumentID: "doc-a", Content: "a", Score: 0.9},
+		{ChunkID: "doc-b-chunk-3", DocumentID: "doc-b", Content: "b", Score: 0.8},
+		{ChunkID: "doc-a-chunk-3", DocumentID: "doc-a", Content: "a", Score: 0.7},
+	}
+
+	outcome, err := f.useCase.SearchDocumentsWithOptions(context.Background(), f.kb.ID, testUserID, "query", &SearchOptions{
+		PreviewChunksPerDocument: 3,
+	})
+	if err != nil {
+		t.Fatalf("SearchDocumentsWithOptions failed: %v", err)
+	}
+
+	if f.chunkRepo.batchGets != 1 {
+		t.Errorf("Expected previews loaded with a single batch query, got %d", f.chunkRepo.batchGets)
+	}
+	for _, docID := range []string{"doc-a", "doc-b"} {
+		if got := outcome.Previews[docID]; len(got) != 3 {
+			t.Errorf("Expected 3 preview chunks for %s, got %d", docID, len(got))
+		}
+	}
+
+	// 未请求预览时不查询分块
+	outcome, err = f.useCase.SearchDocumentsWithOptions(context.Background(), f.kb.ID, testUserID, "query", &SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchDocumentsWithOptions failed: %v", err)
+	}
+	if outcome.Previews != nil || f.chunkRepo.batchGets != 1 {
+		t.Errorf("Expected no previews without option, got %v (batch gets %d)", outcome.Previews, f.chunkRepo.batchGets)
+	}
+}
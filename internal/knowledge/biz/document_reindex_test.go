@@ -0,0 +1,61 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestReindexKeywordSearch(t *testing.T) {
+	f := newTestFixture()
+	ctx := context.Background()
+
+	// 分块在触发器建立前写入：content_tsv 为空
+	total := keywordReindexBatchSize*2 + 1
+	chunks := make([]*Chunk, total)
+	for i := range chunks {
+		content := fmt.Sprintf("filler chunk %d", i)
+		if i == total-1 {
+			content = "the quick brown fox"
+		}
+		chunks[i] = &Chunk{
+			ID:              fmt.Sprintf("chunk-%05d", i),
+			DocumentID:      "doc-1",
+			KnowledgeBaseID: f.kb.ID,
+			Content:         content,
+			Position:        i,
+		}
+	}
+	if err := f.chunkRepo.BatchCreate(ctx, chunks); err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	before, _ := f.chunkRepo.KeywordSearch(ctx, f.kb.ID, "fox", 10)
+	if len(before) != 0 {
+		t.Fatalf("Expected no keyword results before reindex, got %d", len(before))
+	}
+
+	count, err := f.useCase.ReindexKeywordSearch(ctx, f.kb.ID)
+	if err != nil {
+		t.Fatalf("ReindexKeywordSearch failed: %v", err)
+	}
+	if count != total {
+		t.Errorf("Expected %d chunks reindexed, got %d", total, count)
+	}
+	if f.chunkRepo.reindexBatches != 3 {
+		t.Errorf("Expected 3 batches, got %d", f.chunkRepo.reindexBatches)
+	}
+
+	after, _ := f.chunkRepo.KeywordSearch(ctx, f.kb.ID, "fox", 10)
+	if len(after) != 1 || after[0].ID != chunks[total-1].ID {
+		t.Fatalf("Expected chunk %s to be keyword-searchable, got %v", chunks[total-1].ID, after)
+	}
+}
+
+func TestReindexKeywordSearch_KnowledgeBaseNotFound(t *testing.T) {
+	f := newTestFixture()
+
+	if _, err := f.useCase.ReindexKeywordSearch(context.Background(), "missing-kb"); err == nil {
+		t.Fatal("Expected error for missing knowledge base")
+	}
+}
@@ -0,0 +1,52 @@
+package biz
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// processFingerprint 计算影响处理结果的输入指纹（文件哈希、分块参数、Embedding 模型与 collection）
+func processFingerprint(doc *Document, kb *KnowledgeBase) string {
+	target := kb.EmbeddingTargetFor(doc.FileType)
+	input := fmt.Sprintf("%s|%s|%d|%d|%s|%s|%s",
+		doc.FileHash,
+		kb.ChunkStrategy,
+		kb.ChunkSize,
+		kb.ChunkOverlap,
+		kb.ChunkOverlapUnit,
+		target.EmbeddingModelID,
+		target.MilvusCollection,
+	)
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsUnchangedSinceLastRun 文档自上次成功处理后文件与分块配置均未变化（未开启 SkipUnchangedReprocess 时始终返回 false）
+// 文档不存在时返回 ErrDocumentNotFound
+func (uc *DocumentUseCase) IsUnchangedSinceLastRun(ctx context.Context, documentID string) (bool, error) {
+	if !uc.config.SkipUnchangedReprocess {
+		return false, nil
+	}
+
+	doc, err := uc.DocumentRepo.GetByID(ctx, documentID)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDocumentNotFound, err)
+	}
+	kb, err := uc.kbRepo.GetByID(ctx, doc.KnowledgeBaseID, "")
+	if err != nil {
+		return false, fmt.Errorf("knowledge base not found: %w", err)
+	}
+
+	return uc.unchangedSinceLastRun(doc, kb), nil
+}
+
+// unchangedSinceLastRun 上次处理成功且输入指纹未变化
+func (uc *DocumentUseCase) unchangedSinceLastRun(doc *Document, kb *KnowledgeBase) bool {
+	if !uc.config.SkipUnchangedReprocess || doc.ProcessStatus != ProcessStatusCompleted || doc.ProcessFingerprint == "" {
+		return false
+	}
+
+	return doc.ProcessFingerprint == processFingerprint(doc, kb)
+}
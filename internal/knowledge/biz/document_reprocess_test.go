@@ -0,0 +1,91 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReprocessDocument_SkipsUnchangedContent(t *testing.T) {
+	f := newTestFixture()
+	f.config.SkipUnchangedReprocess = true
+	ctx := context.Background()
+
+	doc := f.addDocument("doc-1", []byte("some content"))
+	if err := f.useCase.ProcessDocument(ctx, doc.ID); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+	chunksBefore := f.storedChunkIDs(f.kb.ID)
+
+	skipped, err := f.useCase.ReprocessDocument(ctx, doc.ID, testUserID, false)
+	if err != nil {
+		t.Fatalf("ReprocessDocument failed: %v", err)
+	}
+	if !skipped {
+		t.Fatal("Expected unchanged reprocess to be skipped")
+	}
+	if len(f.embedder.models) != 1 {
+		t.Errorf("Expected no new embedding calls, got %d total", len(f.embedder.models))
+	}
+	if got := f.storedChunkIDs(f.kb.ID); len(got) != len(chunksBefore) || got[0] != chunksBefore[0] {
+		t.Errorf("Expected chunks untouched, had %v, got %v", chunksBefore, got)
+	}
+
+	// 分块配置变化后完整重新处理
+	f.kb.ChunkSize++
+	skipped, err = f.useCase.ReprocessDocument(ctx, doc.ID, testUserID, false)
+	if err != nil {
+		t.Fatalf("ReprocessDocument after chunk config change failed: %v", err)
+	}
+	if skipped {
+		t.Fatal("Expected changed chunk config to trigger reprocessing")
+	}
+	if len(f.embedder.models) != 2 {
+		t.Errorf("Expected a second embedding call, got %d total", len(f.embedder.models))
+	}
+	if got := f.storedChunkIDs(f.kb.ID); len(got) == 0 || got[0] == chunksBefore[0] {
+		t.Errorf("Expected regenerated chunks, got %v", got)
+	}
+}
+
+func TestReprocessDocument_ForceAndDisabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		force   bool
+	}{
+		{name: "force", enabled: true, force: true},
+		{name: "disabled", enabled: false, force: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			f.config.SkipUnchangedReprocess = tt.enabled
+			ctx := context.Background()
+
+			doc := f.addDocument("doc-1", []byte("some content"))
+			if err := f.useCase.ProcessDocument(ctx, doc.ID); err != nil {
+				t.Fatalf("ProcessDocument failed: %v", err)
+			}
+
+			skipped, err := f.useCase.ReprocessDocument(ctx, doc.ID, testUserID, tt.force)
+			if err != nil {
+				t.Fatalf("ReprocessDocument failed: %v", err)
+			}
+			if skipped || len(f.embedder.models) != 2 {
+				t.Errorf("Expected full reprocessing, skipped=%v embedding calls=%d", skipped, len(f.embedder.models))
+			}
+		})
+	}
+}
+
+func TestIsUnchangedSinceLastRun_MissingDocument(t *testing.T) {
+	f := newTestFixture()
+	f.config.SkipUnchangedReprocess = true
+
+	_, err := f.useCase.IsUnchangedSinceLastRun(context.Background(), "missing")
+	if !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("Expected ErrDocumentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSearchDocuments_VectorSearchTimeoutReturnsPartial(t *testing.T) {
+	tests := []struct {
+		name   string
+		hybrid bool
+	}{
+		{name: "vector only", hybrid: false},
+		{name: "hybrid", hybrid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			f.config.VectorSearchTimeout = 20 * time.Millisecond
+			f.kb.EnableHybridSearch = tt.hybrid
+			ctx := context.Background()
+
+			// 代码文件覆盖 Collection 慢查询超时，默认 Collection 正常返回
+			f.withCodeEmbeddingOverride()
+			f.vectorDB.slowCollections = map[string]bool{"kb_test_code": true}
+			f.vectorDB.collectionResults = map[string][]*SearchResult{
+				f.kb.MilvusCollection: {{ChunkID: "chunk-v", DocumentID: "doc-vector", Content: "vector hit", Score: 0.9}},
+				"kb_test_code":        {{ChunkID: "chunk-c", DocumentID: "doc-code", Content: "code hit", Score: 0.9}},
+			}
+
+			// 关键词结果
+			_ = f.chunkRepo.BatchCreate(ctx, []*Chunk{
+				{ID: "chunk-k", DocumentID: "doc-keyword", KnowledgeBaseID: f.kb.ID, Content: "keyword hit"},
+			})
+			_, _, _ = f.chunkRepo.ReindexKeywordSearchBatch(ctx, f.kb.ID, "", 100)
+
+			start := time.Now()
+			outcome, err := f.useCase.SearchDocumentsWithOutcome(ctx, f.kb.ID, testUserID, "keyword", 5)
+			if err != nil {
+				t.Fatalf("Expected degraded search to succeed, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected search to return shortly after timeout, took %s", elapsed)
+			}
+
+			if !outcome.Partial || outcome.PartialReason == "" {
+				t.Errorf("Expected partial flag with reason, got %+v", outcome)
+			}
+
+			docIDs := make(map[string]bool)
+			for _, result := range outcome.Results {
+				docIDs[result.DocumentID] = true
+			}
+			if !docIDs["doc-vector"] || !docIDs["doc-keyword"] || docIDs["doc-code"] {
+				t.Errorf("Expected completed vector and keyword results only, got %v", docIDs)
+			}
+		})
+	}
+}
+
+func TestSearchDocuments_SingleCollectionTimeoutFallsBackToKeywords(t *testing.T) {
+	f := newTestFixture()
+	f.config.VectorSearchTimeout = 20 * time.Millisecond
+	f.vectorDB.slowCollections = map[string]bool{f.kb.MilvusCollection: true}
+	ctx := context.Background()
+
+	_ = f.chunkRepo.BatchCreate(ctx, []*Chunk{
+		{ID: "chunk-k", DocumentID: "doc-keyword", KnowledgeBaseID: f.kb.ID, Content: "keyword hit"},
+	})
+	_, _, _ = f.chunkRepo.ReindexKeywordSearchBatch(ctx, f.kb.ID, "", 100)
+
+	outcome, err := f.useCase.SearchDocumentsWithOutcome(ctx, f.kb.ID, testUserID, "keyword", 5)
+	if err != nil {
+		t.Fatalf("Expected degraded search to succeed, got %v", err)
+	}
+	if !outcome.Partial || len(outcome.Results) != 1 || outcome.Results[0].DocumentID != "doc-keyword" {
+		t.Errorf("Expected partial keyword-only results, got %+v", outcome)
+	}
+}
+
+func TestSearchDocuments_NoTimeoutNotPartial(t *testing.T) {
+	f := newTestFixture()
+	f.config.VectorSearchTimeout = time.Second
+	f.vectorDB.results = []*SearchResult{
+		{ChunkID: "chunk-v", DocumentID: "doc-vector", Content: "vector hit", Score: 0.9},
+	}
+
+	outcome, err := f.useCase.SearchDocumentsWithOutcome(context.Background(), f.kb.ID, testUserID, "query", 5)
+	if err != nil {
+		t.Fatalf("SearchDocumentsWithOutcome failed: %v", err)
+	}
+	if outcome.Partial {
+		t.Error("Expected complete results")
+	}
+	if len(outcome.Results) != 1 {
+		t.Errorf("Expected 1 result, got %d", len(outcome.Results))
+	}
+}
+
+func TestSearchDocumentsWithOptions_DocumentMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		include     bool
+		wantFetches int
+	}{
+		{name: "metadata skipped", include: false, wantFetches: 0},
+		{name: "metadata batched", include: true, wantFetches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			ctx := context.Background()
+			_ = f.docRepo.Create(ctx, &Document{ID: "doc-1", KnowledgeBaseID: f.kb.ID, FileName: "a.md"})
+			_ = f.docRepo.Create(ctx, &Document{ID: "doc-2", KnowledgeBaseID: f.kb.ID, FileName: "b.md"})
+			f.vectorDB.results = []*SearchResult{
+				{ChunkID: "chunk-1", DocumentID: "doc-1", Content: "first", Score: 0.9},
+				{ChunkID: "chunk-2", DocumentID: "doc-1", Content: "second", Score: 0.8},
+				{ChunkID: "chunk-3", DocumentID: "doc-2", Content: "third", Score: 0.7},
+			}
+
+			outcome, err := f.useCase.SearchDocumentsWithOptions(ctx, f.kb.ID, testUserID, "query", &SearchOptions{
+				IncludeDocumentMetadata: tt.include,
+			})
+			if err != nil {
+				t.Fatalf("SearchDocumentsWithOptions failed: %v", err)
+			}
+			if len(outcome.Results) != 3 {
+				t.Fatalf("Expected 3 results, got %d", len(outcome.Results))
+			}
+			if f.docRepo.fetches != tt.wantFetches {
+				t.Errorf("Expected %d document fetches, got %d", tt.wantFetches, f.docRepo.fetches)
+			}
+
+			for _, result := range outcome.Results {
+				_, hasFileName := result.Metadata["file_name"]
+				if hasFileName != tt.include {
+					t.Errorf("Result %s: expected file_name present=%v, metadata %v", result.ChunkID, tt.include, result.Metadata)
+				}
+			}
+			if tt.include && outcome.Results[2].Metadata["file_name"] != "b.md" {
+				t.Errorf("Expected file_name b.md, got %v", outcome.Results[2].Metadata["file_name"])
+			}
+		})
+	}
+}
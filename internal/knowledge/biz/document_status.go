@@ -0,0 +1,79 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ProcessStatus 文档处理状态
+type ProcessStatus string
+
+const (
+	ProcessStatusPending    ProcessStatus = "pending"    // 等待处理
+	ProcessStatusProcessing ProcessStatus = "processing" // 处理中（子阶段见 ProcessStage）
+	ProcessStatusRetrying   ProcessStatus = "retrying"   // 处理失败，等待队列重试
+	ProcessStatusCompleted  ProcessStatus = "completed"  // 处理完成
+	ProcessStatusFailed     ProcessStatus = "failed"     // 处理失败（ProcessStage 保留失败时所在阶段）
+	ProcessStatusEmpty      ProcessStatus = "empty"      // 未提取到内容（按 mark-empty 策略，不视为失败）
+)
+
+// Valid 检查状态是否有效
+func (s ProcessStatus) Valid() bool {
+	switch s {
+	case ProcessStatusPending, ProcessStatusProcessing, ProcessStatusRetrying,
+		ProcessStatusCompleted, ProcessStatusFailed, ProcessStatusEmpty:
+		return true
+	}
+	return false
+}
+
+// String 返回字符串表示
+func (s ProcessStatus) String() string {
+	return string(s)
+}
+
+// ParseProcessStatus 解析处理状态（无效时返回 ErrInvalidProcessStatus）
+func ParseProcessStatus(s string) (ProcessStatus, error) {
+	status := ProcessStatus(s)
+	if !status.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidProcessStatus, s)
+	}
+	return status, nil
+}
+
+// ProcessStage 文档处理子阶段
+type ProcessStage string
+
+const (
+	ProcessStageNone       ProcessStage = ""           // 未在处理中
+	ProcessStageExtracting ProcessStage = "extracting" // 读取文件并提取文本（含 OCR 重试）
+	ProcessStageChunking   ProcessStage = "chunking"   // 文本分块
+	ProcessStageEmbedding  ProcessStage = "embedding"  // 生成 Embedding
+	ProcessStageInserting  ProcessStage = "inserting"  // 写入 Milvus 和数据库
+)
+
+// Valid 检查子阶段是否有效
+func (s ProcessStage) Valid() bool {
+	switch s {
+	case ProcessStageNone, ProcessStageExtracting, ProcessStageChunking, ProcessStageEmbedding, ProcessStageInserting:
+		return true
+	}
+	return false
+}
+
+// String 返回字符串表示
+func (s ProcessStage) String() string {
+	return string(s)
+}
+
+// enterStage 记录文档进入的处理子阶段（失败只记录日志，不中断处理）
+func (uc *DocumentUseCase) enterStage(ctx context.Context, documentID string, stage ProcessStage) {
+	if err := uc.DocumentRepo.UpdateStage(ctx, documentID, stage); err != nil {
+		uc.logger.Warn("更新文档处理阶段失败",
+			zap.String("document_id", documentID),
+			zap.String("stage", stage.String()),
+			zap.Error(err))
+	}
+}
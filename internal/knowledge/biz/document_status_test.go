@@ -0,0 +1,72 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseProcessStatus(t *testing.T) {
+	for _, s := range []string{"pending", "processing", "retrying", "completed", "failed", "empty"} {
+		if status, err := ParseProcessStatus(s); err != nil || status.String() != s {
+			t.Errorf("Expected %q to parse, got %q, %v", s, status, err)
+		}
+	}
+
+	for _, s := range []string{"", "partial", "Completed", "done"} {
+		if _, err := ParseProcessStatus(s); !errors.Is(err, ErrInvalidProcessStatus) {
+			t.Errorf("Expected ErrInvalidProcessStatus for %q, got %v", s, err)
+		}
+	}
+}
+
+func TestProcessStage_Valid(t *testing.T) {
+	for _, stage := range []ProcessStage{ProcessStageNone, ProcessStageExtracting, ProcessStageChunking, ProcessStageEmbedding, ProcessStageInserting} {
+		if !stage.Valid() {
+			t.Errorf("Expected stage %q to be valid", stage)
+		}
+	}
+	if ProcessStage("uploading").Valid() {
+		t.Error("Expected unknown stage to be invalid")
+	}
+}
+
+func TestProcessDocument_RecordsStageTransitions(t *testing.T) {
+	f := newTestFixture()
+	doc := f.addDocument("doc-1", []byte("some content"))
+
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+
+	want := []ProcessStage{ProcessStageExtracting, ProcessStageChunking, ProcessStageEmbedding, ProcessStageInserting}
+	got := f.docRepo.stages[doc.ID]
+	if len(got) != len(want) {
+		t.Fatalf("Expected stages %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected stages %v, got %v", want, got)
+		}
+	}
+
+	stored, _ := f.docRepo.GetByID(context.Background(), doc.ID)
+	if stored.ProcessStatus != ProcessStatusCompleted || stored.ProcessStage != ProcessStageNone {
+		t.Errorf("Expected completed with no stage, got %q/%q", stored.ProcessStatus, stored.ProcessStage)
+	}
+}
+
+func TestProcessDocument_FailureKeepsStage(t *testing.T) {
+	f := newTestFixture()
+	doc := f.addDocument("doc-1", []byte("some content"))
+	_ = f.storage.DeleteFile(context.Background(), doc.MinioBucket, doc.MinioObjectKey)
+
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err == nil {
+		t.Fatal("Expected ProcessDocument to fail when the file is missing")
+	}
+
+	stored, _ := f.docRepo.GetByID(context.Background(), doc.ID)
+	if stored.ProcessStatus != ProcessStatusFailed || stored.ProcessStage != ProcessStageExtracting {
+		t.Errorf("Expected failed at extracting, got %q/%q", stored.ProcessStatus, stored.ProcessStage)
+	}
+}
@@ -0,0 +1,47 @@
+package biz
+
+import "time"
+
+// ProcessingTelemetry 文档最近一次成功处理的耗时与成本统计，用于定位高成本文档和预算 Embedding 开销
+type ProcessingTelemetry struct {
+	ExtractionDuration     time.Duration // 文本提取耗时（含空内容时的 OCR 重试）
+	ChunkCount             int           // 分块数量
+	EmbeddingDuration      time.Duration // 生成 Embedding 耗时
+	EmbeddedTokens         int64         // 送入 Embedding 的 token 总数
+	EstimatedEmbeddingCost float64       // 按 EmbeddingCostPer1KTokens 估算的 Embedding 成本
+	ProcessedAt            time.Time     // 处理完成时间
+}
+
+// ProcessingTelemetryResponse 文档处理统计响应结构体
+type ProcessingTelemetryResponse struct {
+	ExtractionDurationMs   int64   `json:"extraction_duration_ms"`
+	ChunkCount             int     `json:"chunk_count"`
+	EmbeddingDurationMs    int64   `json:"embedding_duration_ms"`
+	EmbeddedTokens         int64   `json:"embedded_tokens"`
+	EstimatedEmbeddingCost float64 `json:"estimated_embedding_cost"`
+	ProcessedAt            string  `json:"processed_at"`
+}
+
+// ToProcessingTelemetryResponse 将 ProcessingTelemetry 转换为响应结构体
+func ToProcessingTelemetryResponse(t *ProcessingTelemetry) *ProcessingTelemetryResponse {
+	if t == nil {
+		return nil
+	}
+
+	return &ProcessingTelemetryResponse{
+		ExtractionDurationMs:   t.ExtractionDuration.Milliseconds(),
+		ChunkCount:             t.ChunkCount,
+		EmbeddingDurationMs:    t.EmbeddingDuration.Milliseconds(),
+		EmbeddedTokens:         t.EmbeddedTokens,
+		EstimatedEmbeddingCost: t.EstimatedEmbeddingCost,
+		ProcessedAt:            t.ProcessedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// estimateEmbeddingCost 按每千 token 单价估算 Embedding 成本（未配置单价时为 0）
+func (uc *DocumentUseCase) estimateEmbeddingCost(tokens int64) float64 {
+	if uc.config.EmbeddingCostPer1KTokens <= 0 {
+		return 0
+	}
+	return float64(tokens) / 1000 * uc.config.EmbeddingCostPer1KTokens
+}
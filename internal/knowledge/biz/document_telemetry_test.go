@@ -0,0 +1,100 @@
+package biz
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// slowProcessor 提取文本前等待 delay
+type slowProcessor struct {
+	fakeProcessor
+	delay time.Duration
+}
+
+func (p *slowProcessor) ExtractText(ctx context.Context, fileData []byte, fileType string) (string, error) {
+	time.Sleep(p.delay)
+	return p.fakeProcessor.ExtractText(ctx, fileData, fileType)
+}
+
+// slowEmbedder 生成 Embedding 前等待 delay
+type slowEmbedder struct {
+	*fakeEmbedder
+	delay time.Duration
+}
+
+func (e *slowEmbedder) GenerateEmbeddings(ctx context.Context, texts []string, provider *AIProvider, model *AIModel) ([][]float32, error) {
+	time.Sleep(e.delay)
+	return e.fakeEmbedder.GenerateEmbeddings(ctx, texts, provider, model)
+}
+
+func TestProcessDocument_RecordsTelemetry(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	f := newTestFixture().withProcessor(&slowProcessor{
+		fakeProcessor: fakeProcessor{chunks: []string{"first chunk content", "second chunk", "third"}},
+		delay:         delay,
+	})
+	f.useCase.embedder = &slowEmbedder{fakeEmbedder: f.embedder, delay: delay}
+	f.config.EmbeddingCostPer1KTokens = 0.02
+
+	doc := f.addDocument("doc-1", []byte("ignored"))
+	before := time.Now()
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+
+	stored, err := f.docRepo.GetByID(context.Background(), doc.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	telemetry := stored.Telemetry
+	if telemetry == nil {
+		t.Fatal("Expected telemetry to be recorded")
+	}
+
+	if telemetry.ExtractionDuration < delay {
+		t.Errorf("Expected extraction duration >= %v, got %v", delay, telemetry.ExtractionDuration)
+	}
+	if telemetry.EmbeddingDuration < delay {
+		t.Errorf("Expected embedding duration >= %v, got %v", delay, telemetry.EmbeddingDuration)
+	}
+	if telemetry.ChunkCount != 3 {
+		t.Errorf("Expected chunk count 3, got %d", telemetry.ChunkCount)
+	}
+
+	var wantTokens int64
+	for _, chunk := range f.chunkRepo.chunks[doc.ID] {
+		wantTokens += int64(chunk.TokenCount)
+	}
+	if wantTokens == 0 || telemetry.EmbeddedTokens != wantTokens {
+		t.Errorf("Expected %d embedded tokens, got %d", wantTokens, telemetry.EmbeddedTokens)
+	}
+	wantCost := float64(wantTokens) / 1000 * 0.02
+	if math.Abs(telemetry.EstimatedEmbeddingCost-wantCost) > 1e-12 {
+		t.Errorf("Expected estimated cost %v, got %v", wantCost, telemetry.EstimatedEmbeddingCost)
+	}
+	if telemetry.ProcessedAt.Before(before) {
+		t.Errorf("Expected processed_at after %v, got %v", before, telemetry.ProcessedAt)
+	}
+
+	resp := ToProcessingTelemetryResponse(telemetry)
+	if resp.ExtractionDurationMs < delay.Milliseconds() || resp.EmbeddedTokens != wantTokens {
+		t.Errorf("Unexpected telemetry response: %+v", resp)
+	}
+}
+
+func TestProcessDocument_NoTelemetryOnFailure(t *testing.T) {
+	f := newTestFixture()
+	delete(f.modelRepo.models, f.embedModel.ID)
+
+	doc := f.addDocument("doc-1", []byte("some content"))
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err == nil {
+		t.Fatal("Expected ProcessDocument to fail without an embedding model")
+	}
+
+	stored, _ := f.docRepo.GetByID(context.Background(), doc.ID)
+	if stored.Telemetry != nil {
+		t.Errorf("Expected no telemetry for a failed run, got %+v", stored.Telemetry)
+	}
+}
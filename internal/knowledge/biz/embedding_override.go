@@ -0,0 +1,119 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// EmbeddingOverride 按文件类型覆盖的 Embedding 模型配置
+// 每个覆盖使用独立的 Milvus Collection（不同模型的向量维度、语义空间不同，不能混存）
+type EmbeddingOverride struct {
+	FileTypes        []string `json:"file_types"`         // 文件扩展名（如 go、py），不区分大小写
+	EmbeddingModelID string   `json:"embedding_model_id"` // 覆盖使用的 Embedding 模型 ID
+	MilvusCollection string   `json:"milvus_collection"`  // 覆盖专用 Collection（创建知识库时生成）
+}
+
+// EmbeddingTarget 文档向量化的目标（模型 + Collection）
+type EmbeddingTarget struct {
+	EmbeddingModelID string
+	MilvusCollection string
+}
+
+// EmbeddingTargetFor 返回文件类型对应的向量化目标，未配置覆盖时使用知识库默认模型
+func (kb *KnowledgeBase) EmbeddingTargetFor(fileType string) EmbeddingTarget {
+	fileType = normalizeFileType(fileType)
+	for _, override := range kb.EmbeddingOverrides {
+		for _, ft := range override.FileTypes {
+			if ft == fileType {
+				return EmbeddingTarget{
+					EmbeddingModelID: override.EmbeddingModelID,
+					MilvusCollection: override.MilvusCollection,
+				}
+			}
+		}
+	}
+	return EmbeddingTarget{
+		EmbeddingModelID: kb.EmbeddingModelID,
+		MilvusCollection: kb.MilvusCollection,
+	}
+}
+
+// EmbeddingTargets 返回知识库所有向量化目标（默认目标在前，按 Collection 去重），用于检索时多路召回
+func (kb *KnowledgeBase) EmbeddingTargets() []EmbeddingTarget {
+	targets := []EmbeddingTarget{{
+		EmbeddingModelID: kb.EmbeddingModelID,
+		MilvusCollection: kb.MilvusCollection,
+	}}
+	seen := map[string]bool{kb.MilvusCollection: true}
+	for _, override := range kb.EmbeddingOverrides {
+		if seen[override.MilvusCollection] {
+			continue
+		}
+		seen[override.MilvusCollection] = true
+		targets = append(targets, EmbeddingTarget{
+			EmbeddingModelID: override.EmbeddingModelID,
+			MilvusCollection: override.MilvusCollection,
+		})
+	}
+	return targets
+}
+
+// normalizeFileType 统一文件类型格式（小写，去掉前导点）
+func normalizeFileType(fileType string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(fileType), "."))
+}
+
+// buildEmbeddingOverrides 校验覆盖配置并为每个覆盖生成独立的 Collection 名称
+// 同一文件类型只能出现在一个覆盖中；使用默认模型的覆盖没有意义，直接忽略
+func (uc *KnowledgeBaseUseCase) buildEmbeddingOverrides(
+	ctx context.Context,
+	userID string,
+	defaultModelID string,
+	overrides []EmbeddingOverride,
+) ([]EmbeddingOverride, error) {
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+
+	assigned := make(map[string]bool)
+	result := make([]EmbeddingOverride, 0, len(overrides))
+	for _, override := range overrides {
+		if override.EmbeddingModelID == "" {
+			return nil, fmt.Errorf("embedding override requires embedding_model_id")
+		}
+		if override.EmbeddingModelID == defaultModelID {
+			continue
+		}
+
+		fileTypes := make([]string, 0, len(override.FileTypes))
+		for _, ft := range override.FileTypes {
+			ft = normalizeFileType(ft)
+			if ft == "" {
+				continue
+			}
+			if assigned[ft] {
+				return nil, fmt.Errorf("file type %q is assigned to more than one embedding override", ft)
+			}
+			assigned[ft] = true
+			fileTypes = append(fileTypes, ft)
+		}
+		if len(fileTypes) == 0 {
+			return nil, fmt.Errorf("embedding override for model %s has no file types", override.EmbeddingModelID)
+		}
+
+		if _, err := uc.aiModelRepo.GetByID(ctx, override.EmbeddingModelID); err != nil {
+			return nil, fmt.Errorf("embedding override model not found: %w", err)
+		}
+
+		result = append(result, EmbeddingOverride{
+			FileTypes:        fileTypes,
+			EmbeddingModelID: override.EmbeddingModelID,
+			MilvusCollection: fmt.Sprintf("kb_%s_%s", userID[:8], uuid.New().String()[:8]),
+		})
+	}
+
+	return result, nil
+}
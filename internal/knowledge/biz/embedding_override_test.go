@@ -0,0 +1,205 @@
+package biz
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// withCodeEmbeddingOverride 为知识库添加代码文件的 Embedding 覆盖
+func (f *testFixture) withCodeEmbeddingOverride() *AIModel {
+	dims := 4
+	codeModel := &AIModel{
+		ID:                  "model-code",
+		ProviderID:          f.aiProvider.ID,
+		ModelName:           "code-embedding",
+		Capabilities:        []string{CapabilityTypeEmbedding},
+		EmbeddingDimensions: &dims,
+	}
+	f.modelRepo.models[codeModel.ID] = codeModel
+	f.kb.EmbeddingOverrides = []EmbeddingOverride{{
+		FileTypes:        []string{"go", "py"},
+		EmbeddingModelID: codeModel.ID,
+		MilvusCollection: "kb_test_code",
+	}}
+	return codeModel
+}
+
+func TestProcessDocument_EmbeddingOverrideRoutesByFileType(t *testing.T) {
+	f := newTestFixture()
+	codeModel := f.withCodeEmbeddingOverride()
+
+	pdfDoc := f.addDocument("doc-pdf", []byte("a prose document"))
+	codeDoc := f.addDocument("doc-code", []byte("package main"))
+	codeDoc.FileName = "main.go"
+	codeDoc.FileType = "go"
+
+	ctx := context.Background()
+	for _, doc := range []*Document{pdfDoc, codeDoc} {
+		if err := f.useCase.ProcessDocument(ctx, doc.ID); err != nil {
+			t.Fatalf("ProcessDocument(%s) failed: %v", doc.ID, err)
+		}
+	}
+
+	if got := f.embedder.models; len(got) != 2 || got[0] != f.embedModel.ID || got[1] != codeModel.ID {
+		t.Errorf("Expected embeddings from [%s %s], got %v", f.embedModel.ID, codeModel.ID, got)
+	}
+
+	assertCollectionDocs(t, f.vectorDB, f.kb.MilvusCollection, pdfDoc.ID)
+	assertCollectionDocs(t, f.vectorDB, "kb_test_code", codeDoc.ID)
+
+	// 删除时按文件类型定位 Collection
+	if err := f.useCase.DeleteDocument(ctx, codeDoc.ID, testUserID); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	assertCollectionDocs(t, f.vectorDB, "kb_test_code")
+	assertCollectionDocs(t, f.vectorDB, f.kb.MilvusCollection, pdfDoc.ID)
+}
+
+func TestSearchDocuments_FusesEmbeddingOverrideCollections(t *testing.T) {
+	f := newTestFixture()
+	codeModel := f.withCodeEmbeddingOverride()
+	f.vectorDB.collectionResults = map[string][]*SearchResult{
+		f.kb.MilvusCollection: {
+			{ChunkID: "chunk-prose", DocumentID: "doc-pdf", Content: "prose", Score: 0.6},
+		},
+		"kb_test_code": {
+			{ChunkID: "chunk-code-1", DocumentID: "doc-code", Content: "func main()", Score: 0.9},
+			{ChunkID: "chunk-code-2", DocumentID: "doc-code", Content: "package main", Score: 0.8},
+		},
+	}
+
+	results, err := f.useCase.SearchDocuments(context.Background(), f.kb.ID, testUserID, "main function", 5)
+	if err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+
+	if got := strings.Join(f.vectorDB.searched, ","); got != f.kb.MilvusCollection+",kb_test_code" {
+		t.Errorf("Expected both collections to be searched, got %s", got)
+	}
+	if got := f.embedder.models; len(got) != 2 || got[0] != f.embedModel.ID || got[1] != codeModel.ID {
+		t.Errorf("Expected query embeddings from [%s %s], got %v", f.embedModel.ID, codeModel.ID, got)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 fused results, got %d", len(results))
+	}
+
+	// 各路第一名并列领先，保留原始相似度分数
+	top := map[string]bool{results[0].ChunkID: true, results[1].ChunkID: true}
+	if !top["chunk-prose"] || !top["chunk-code-1"] || results[2].ChunkID != "chunk-code-2" {
+		t.Errorf("Unexpected fused order: %s, %s, %s", results[0].ChunkID, results[1].ChunkID, results[2].ChunkID)
+	}
+	if results[2].Score != 0.8 {
+		t.Errorf("Expected original score 0.8 to be kept, got %v", results[2].Score)
+	}
+}
+
+func TestSearchDocuments_SkipsUncreatedOverrideCollection(t *testing.T) {
+	f := newTestFixture()
+	f.withCodeEmbeddingOverride()
+	f.vectorDB.collectionResults = map[string][]*SearchResult{
+		f.kb.MilvusCollection: {{ChunkID: "chunk-prose", DocumentID: "doc-pdf", Content: "prose", Score: 0.6}},
+	}
+
+	// 尚未上传代码文件，覆盖 Collection 还不存在
+	results, err := f.useCase.SearchDocuments(context.Background(), f.kb.ID, testUserID, "main function", 5)
+	if err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+	if got := strings.Join(f.vectorDB.searched, ","); got != f.kb.MilvusCollection {
+		t.Errorf("Expected only the default collection to be searched, got %s", got)
+	}
+	if len(results) != 1 || results[0].ChunkID != "chunk-prose" {
+		t.Errorf("Expected default collection results, got %+v", results)
+	}
+}
+
+func TestDeleteKnowledgeBase_DropsOverrideCollections(t *testing.T) {
+	f := newTestFixture()
+	f.withCodeEmbeddingOverride()
+	codeDoc := f.addDocument("doc-code", []byte("package main"))
+	codeDoc.FileName = "main.go"
+	codeDoc.FileType = "go"
+	ctx := context.Background()
+	if err := f.useCase.ProcessDocument(ctx, codeDoc.ID); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+
+	uc := NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, nil, nil, f.vectorDB)
+	if err := uc.DeleteKnowledgeBase(ctx, f.kb.ID, testUserID); err != nil {
+		t.Fatalf("DeleteKnowledgeBase failed: %v", err)
+	}
+
+	if exists, _ := f.vectorDB.HasCollection(ctx, "kb_test_code"); exists {
+		t.Error("Expected override collection to be dropped")
+	}
+	if _, err := f.kbRepo.GetByID(ctx, f.kb.ID, testUserID); err == nil {
+		t.Error("Expected knowledge base to be deleted")
+	}
+}
+
+func TestCreateKnowledgeBase_EmbeddingOverrides(t *testing.T) {
+	f := newTestFixture()
+	codeModel := f.withCodeEmbeddingOverride()
+	uc := NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, nil, nil, f.vectorDB)
+	userID := "user-00000001"
+
+	kb, err := uc.CreateKnowledgeBase(context.Background(), userID, &CreateKnowledgeBaseRequest{
+		Name:             "mixed",
+		EmbeddingModelID: f.embedModel.ID,
+		EmbeddingOverrides: []EmbeddingOverride{
+			{FileTypes: []string{".GO", "py"}, EmbeddingModelID: codeModel.ID},
+			{FileTypes: []string{"md"}, EmbeddingModelID: f.embedModel.ID}, // 与默认模型相同，忽略
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase failed: %v", err)
+	}
+
+	if len(kb.EmbeddingOverrides) != 1 {
+		t.Fatalf("Expected 1 override, got %d", len(kb.EmbeddingOverrides))
+	}
+	override := kb.EmbeddingOverrides[0]
+	if strings.Join(override.FileTypes, ",") != "go,py" {
+		t.Errorf("Expected normalized file types go,py, got %v", override.FileTypes)
+	}
+	if override.MilvusCollection == "" || override.MilvusCollection == kb.MilvusCollection {
+		t.Errorf("Expected a dedicated collection, got %q (default %q)", override.MilvusCollection, kb.MilvusCollection)
+	}
+	if target := kb.EmbeddingTargetFor("md"); target.EmbeddingModelID != f.embedModel.ID {
+		t.Errorf("Expected md to use the default model, got %s", target.EmbeddingModelID)
+	}
+
+	_, err = uc.CreateKnowledgeBase(context.Background(), userID, &CreateKnowledgeBaseRequest{
+		Name:             "conflict",
+		EmbeddingModelID: "model-1",
+		EmbeddingOverrides: []EmbeddingOverride{
+			{FileTypes: []string{"go"}, EmbeddingModelID: codeModel.ID},
+			{FileTypes: []string{"go"}, EmbeddingModelID: "model-other"},
+		},
+	})
+	if err == nil {
+		t.Error("Expected error for a file type assigned to two overrides")
+	}
+}
+
+// assertCollectionDocs 断言 collection 中的向量恰好来自指定文档
+func assertCollectionDocs(t *testing.T, vectorDB *fakeVectorDB, collection string, docIDs ...string) {
+	t.Helper()
+	vectorDB.mu.Lock()
+	defer vectorDB.mu.Unlock()
+
+	got := make(map[string]bool)
+	for _, chunk := range vectorDB.vectors[collection] {
+		got[chunk.DocumentID] = true
+	}
+	if len(got) != len(docIDs) {
+		t.Errorf("Collection %s: expected documents %v, got %v", collection, docIDs, got)
+		return
+	}
+	for _, id := range docIDs {
+		if !got[id] {
+			t.Errorf("Collection %s: expected document %s, got %v", collection, id, got)
+		}
+	}
+}
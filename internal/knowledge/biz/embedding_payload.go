@@ -0,0 +1,229 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Embedding 请求/响应格式（{base} 未包含版本段时追加默认版本：OpenAI/Cohere 为 /v1，Gemini 为 /v1beta）
+const (
+	EmbeddingPayloadFormatOpenAI = "openai" // POST {base}/embeddings {model, input} -> data[].embedding
+	EmbeddingPayloadFormatCohere = "cohere" // POST {base}/embed {model, texts, input_type} -> embeddings[][]
+	EmbeddingPayloadFormatGemini = "gemini" // POST {base}/models/{model}:batchEmbedContents {requests[].content.parts[].text} -> embeddings[].values
+)
+
+// apiVersionSegment 匹配 Base URL 路径中的版本段（v1、v2、v1beta 等）
+var apiVersionSegment = regexp.MustCompile(`(^|/)v\d+[a-z0-9]*(/|$)`)
+
+// defaultEmbeddingPayloadFormats 服务商类型默认使用的 Embedding 格式（未列出的类型使用 OpenAI 格式）
+var defaultEmbeddingPayloadFormats = map[string]string{
+	"cohere": EmbeddingPayloadFormatCohere,
+	"gemini": EmbeddingPayloadFormatGemini,
+}
+
+// EmbeddingPayloadAdapter 按服务商接口格式构造 Embedding 请求并解析响应
+type EmbeddingPayloadAdapter interface {
+	NewRequest(ctx context.Context, baseURL, apiKey, model string, texts []string) (*http.Request, error)
+	ParseResponse(body []byte) ([][]float32, error) // 按输入顺序返回向量
+}
+
+// EmbeddingPayloadAdapterFor 根据服务商类型选择 Embedding 格式适配器（formats 为配置的 provider_type -> 格式，优先于默认映射）
+func EmbeddingPayloadAdapterFor(providerType string, formats map[string]string) (EmbeddingPayloadAdapter, error) {
+	format, ok := formats[providerType]
+	if !ok {
+		format = defaultEmbeddingPayloadFormats[providerType]
+	}
+
+	switch format {
+	case EmbeddingPayloadFormatOpenAI, "":
+		return openAIEmbeddingAdapter{}, nil
+	case EmbeddingPayloadFormatCohere:
+		return cohereEmbeddingAdapter{}, nil
+	case EmbeddingPayloadFormatGemini:
+		return geminiEmbeddingAdapter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported embedding payload format %q for provider type %s", format, providerType)
+	}
+}
+
+// RequestEmbeddings 通过适配器调用服务商 Embedding 接口，返回与输入顺序一致的向量
+func RequestEmbeddings(ctx context.Context, client *http.Client, adapter EmbeddingPayloadAdapter, baseURL, apiKey, model string, texts []string) ([][]float32, error) {
+	req, err := adapter.NewRequest(ctx, baseURL, apiKey, model, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build embedding request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	embeddings, err := adapter.ParseResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+	}
+	for i, embedding := range embeddings {
+		if len(embedding) == 0 {
+			return nil, fmt.Errorf("empty embedding returned for input %d", i)
+		}
+	}
+	return embeddings, nil
+}
+
+// withAPIVersion Base URL 路径不含版本段时追加默认版本（数据库预置的服务商地址只有域名，如 https://api.openai.com）
+func withAPIVersion(baseURL, version string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	path := baseURL
+	if u, err := url.Parse(baseURL); err == nil {
+		path = u.Path
+	}
+	if apiVersionSegment.MatchString(path) {
+		return baseURL
+	}
+	return baseURL + "/" + version
+}
+
+// newEmbeddingJSONRequest 构造 JSON POST 请求
+func newEmbeddingJSONRequest(ctx context.Context, endpoint string, body interface{}) (*http.Request, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// openAIEmbeddingAdapter OpenAI 兼容格式（OpenAI、硅基流动、智谱等）
+type openAIEmbeddingAdapter struct{}
+
+func (openAIEmbeddingAdapter) NewRequest(ctx context.Context, baseURL, apiKey, model string, texts []string) (*http.Request, error) {
+	req, err := newEmbeddingJSONRequest(ctx, withAPIVersion(baseURL, "v1")+"/embeddings", map[string]interface{}{
+		"model": model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return req, nil
+}
+
+func (openAIEmbeddingAdapter) ParseResponse(body []byte) ([][]float32, error) {
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+
+	// 按 index 排序，兼容不保证顺序的实现
+	sort.SliceStable(result.Data, func(i, j int) bool { return result.Data[i].Index < result.Data[j].Index })
+	embeddings := make([][]float32, len(result.Data))
+	for i, item := range result.Data {
+		embeddings[i] = item.Embedding
+	}
+	return embeddings, nil
+}
+
+// cohereEmbeddingAdapter Cohere 格式（texts 输入，embeddings 二维数组输出）
+type cohereEmbeddingAdapter struct{}
+
+func (cohereEmbeddingAdapter) NewRequest(ctx context.Context, baseURL, apiKey, model string, texts []string) (*http.Request, error) {
+	req, err := newEmbeddingJSONRequest(ctx, withAPIVersion(baseURL, "v1")+"/embed", map[string]interface{}{
+		"model":      model,
+		"texts":      texts,
+		"input_type": "search_document",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return req, nil
+}
+
+func (cohereEmbeddingAdapter) ParseResponse(body []byte) ([][]float32, error) {
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	return result.Embeddings, nil
+}
+
+// geminiEmbeddingAdapter Gemini batchEmbedContents 格式（嵌套的 content.parts 输入，embeddings[].values 输出）
+type geminiEmbeddingAdapter struct{}
+
+func (geminiEmbeddingAdapter) NewRequest(ctx context.Context, baseURL, apiKey, model string, texts []string) (*http.Request, error) {
+	modelPath := model
+	if !strings.HasPrefix(modelPath, "models/") {
+		modelPath = "models/" + modelPath
+	}
+
+	type part struct {
+		Text string `json:"text"`
+	}
+	type content struct {
+		Parts []part `json:"parts"`
+	}
+	type request struct {
+		Model   string  `json:"model"`
+		Content content `json:"content"`
+	}
+	requests := make([]request, len(texts))
+	for i, text := range texts {
+		requests[i] = request{Model: modelPath, Content: content{Parts: []part{{Text: text}}}}
+	}
+
+	endpoint := withAPIVersion(baseURL, "v1beta") + "/" + modelPath + ":batchEmbedContents"
+	req, err := newEmbeddingJSONRequest(ctx, endpoint, map[string]interface{}{"requests": requests})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-goog-api-key", apiKey)
+	return req, nil
+}
+
+func (geminiEmbeddingAdapter) ParseResponse(body []byte) ([][]float32, error) {
+	var result struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+
+	embeddings := make([][]float32, len(result.Embeddings))
+	for i, item := range result.Embeddings {
+		embeddings[i] = item.Values
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,167 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// embeddingStub 记录请求并返回固定响应的 Embedding 服务
+type embeddingStub struct {
+	path   string
+	header http.Header
+	body   map[string]interface{}
+}
+
+func (s *embeddingStub) serve(t *testing.T, response string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.path = r.URL.Path
+		s.header = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&s.body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func requestStubEmbeddings(t *testing.T, providerType string, formats map[string]string, baseURL string) [][]float32 {
+	t.Helper()
+	adapter, err := EmbeddingPayloadAdapterFor(providerType, formats)
+	if err != nil {
+		t.Fatalf("EmbeddingPayloadAdapterFor failed: %v", err)
+	}
+	embeddings, err := RequestEmbeddings(context.Background(), http.DefaultClient, adapter, baseURL, "sk-test", "embed-model", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("RequestEmbeddings failed: %v", err)
+	}
+	return embeddings
+}
+
+func assertEmbeddings(t *testing.T, got [][]float32, want [][]float32) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d embeddings, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) || got[i][0] != want[i][0] {
+			t.Errorf("Embedding %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestRequestEmbeddings_OpenAIFormat(t *testing.T) {
+	stub := &embeddingStub{}
+	// 响应乱序，按 index 还原输入顺序
+	server := stub.serve(t, `{"data":[{"index":1,"embedding":[2,2,2]},{"index":0,"embedding":[1,1,1]}]}`)
+
+	got := requestStubEmbeddings(t, "siliconflow", nil, server.URL+"/v1")
+
+	if stub.path != "/v1/embeddings" {
+		t.Errorf("Expected path /v1/embeddings, got %s", stub.path)
+	}
+	if auth := stub.header.Get("Authorization"); auth != "Bearer sk-test" {
+		t.Errorf("Expected bearer auth, got %q", auth)
+	}
+	if stub.body["model"] != "embed-model" || stub.body["input"] == nil {
+		t.Errorf("Expected {model, input} body, got %v", stub.body)
+	}
+	assertEmbeddings(t, got, [][]float32{{1, 1, 1}, {2, 2, 2}})
+}
+
+func TestRequestEmbeddings_CohereFormat(t *testing.T) {
+	stub := &embeddingStub{}
+	server := stub.serve(t, `{"id":"x","embeddings":[[1,1],[2,2]]}`)
+
+	got := requestStubEmbeddings(t, "cohere", nil, server.URL)
+
+	if stub.path != "/v1/embed" {
+		t.Errorf("Expected path /v1/embed, got %s", stub.path)
+	}
+	texts, ok := stub.body["texts"].([]interface{})
+	if !ok || len(texts) != 2 || stub.body["input"] != nil {
+		t.Errorf("Expected texts field instead of input, got %v", stub.body)
+	}
+	assertEmbeddings(t, got, [][]float32{{1, 1}, {2, 2}})
+}
+
+func TestRequestEmbeddings_GeminiFormat(t *testing.T) {
+	stub := &embeddingStub{}
+	server := stub.serve(t, `{"embeddings":[{"values":[1,1,1,1]},{"values":[2,2,2,2]}]}`)
+
+	got := requestStubEmbeddings(t, "gemini", nil, server.URL+"/v1beta")
+
+	if stub.path != "/v1beta/models/embed-model:batchEmbedContents" {
+		t.Errorf("Unexpected path %s", stub.path)
+	}
+	if key := stub.header.Get("x-goog-api-key"); key != "sk-test" {
+		t.Errorf("Expected x-goog-api-key header, got %q", key)
+	}
+	requests, ok := stub.body["requests"].([]interface{})
+	if !ok || len(requests) != 2 {
+		t.Fatalf("Expected 2 nested requests, got %v", stub.body)
+	}
+	first := requests[0].(map[string]interface{})
+	parts := first["content"].(map[string]interface{})["parts"].([]interface{})
+	if first["model"] != "models/embed-model" || parts[0].(map[string]interface{})["text"] != "a" {
+		t.Errorf("Unexpected nested request %v", first)
+	}
+	assertEmbeddings(t, got, [][]float32{{1, 1, 1, 1}, {2, 2, 2, 2}})
+}
+
+func TestRequestEmbeddings_SeedBaseURLWithoutVersion(t *testing.T) {
+	// 数据库预置的服务商地址不带版本路径（如 https://api.siliconflow.cn）
+	tests := []struct {
+		providerType string
+		response     string
+		wantPath     string
+	}{
+		{providerType: "siliconflow", response: `{"data":[{"index":0,"embedding":[1]},{"index":1,"embedding":[2]}]}`, wantPath: "/v1/embeddings"},
+		{providerType: "openai", response: `{"data":[{"index":0,"embedding":[1]},{"index":1,"embedding":[2]}]}`, wantPath: "/v1/embeddings"},
+		{providerType: "gemini", response: `{"embeddings":[{"values":[1]},{"values":[2]}]}`, wantPath: "/v1beta/models/embed-model:batchEmbedContents"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.providerType, func(t *testing.T) {
+			stub := &embeddingStub{}
+			server := stub.serve(t, tt.response)
+
+			got := requestStubEmbeddings(t, tt.providerType, nil, server.URL+"/")
+			if stub.path != tt.wantPath {
+				t.Errorf("Expected path %s, got %s", tt.wantPath, stub.path)
+			}
+			assertEmbeddings(t, got, [][]float32{{1}, {2}})
+		})
+	}
+}
+
+func TestEmbeddingPayloadAdapterFor_ConfigOverride(t *testing.T) {
+	// 自定义服务商类型配置为 Cohere 格式
+	stub := &embeddingStub{}
+	server := stub.serve(t, `{"embeddings":[[1],[2]]}`)
+
+	got := requestStubEmbeddings(t, "my-gateway", map[string]string{"my-gateway": EmbeddingPayloadFormatCohere}, server.URL+"/api/v2")
+	if stub.path != "/api/v2/embed" {
+		t.Errorf("Expected configured cohere format, got path %s", stub.path)
+	}
+	assertEmbeddings(t, got, [][]float32{{1}, {2}})
+
+	if _, err := EmbeddingPayloadAdapterFor("my-gateway", map[string]string{"my-gateway": "soap"}); err == nil {
+		t.Error("Expected error for unknown payload format")
+	}
+}
+
+func TestRequestEmbeddings_CountMismatch(t *testing.T) {
+	stub := &embeddingStub{}
+	server := stub.serve(t, `{"embeddings":[[1]]}`)
+
+	adapter, _ := EmbeddingPayloadAdapterFor("cohere", nil)
+	if _, err := RequestEmbeddings(context.Background(), http.DefaultClient, adapter, server.URL, "sk-test", "embed-model", []string{"a", "b"}); err == nil {
+		t.Error("Expected error when response has fewer embeddings than inputs")
+	}
+}
@@ -0,0 +1,79 @@
+package biz
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// embeddingModelUnhealthy 模型验证（健康探测）将模型标记为不可用
+func embeddingModelUnhealthy(model *AIModel) bool {
+	return model.VerificationStatus == "error" || model.VerificationStatus == "deprecated"
+}
+
+// supportsEmbedding 模型是否具备 embedding 能力
+func supportsEmbedding(model *AIModel) bool {
+	for _, c := range model.Capabilities {
+		if c == CapabilityTypeEmbedding {
+			return true
+		}
+	}
+	return false
+}
+
+// sameEmbeddingModel 两个模型是否为同一 Embedding 模型（如不同服务商部署的 text-embedding-3-small）
+// 只有同一模型生成的向量处于同一向量空间，才能与 collection 中已有向量、查询向量比较
+func sameEmbeddingModel(a, b *AIModel) bool {
+	return strings.EqualFold(path.Base(a.ModelName), path.Base(b.ModelName))
+}
+
+// routeEmbedding 选择生成 Embedding 使用的模型与服务商
+// 主模型被标记为不可用且配置了备用模型时，路由到同一模型的其他部署（维度相同、自身可用），保证向量与知识库已有向量可比；
+// 备用模型不满足条件时记录警告，仍使用主模型
+func (uc *DocumentUseCase) routeEmbedding(ctx context.Context, documentID string, primary *AIModel, primaryProvider *AIProvider) (*AIModel, *AIProvider) {
+	backupID := uc.config.BackupEmbeddingModelID
+	if backupID == "" || backupID == primary.ID || !embeddingModelUnhealthy(primary) {
+		return primary, primaryProvider
+	}
+
+	logger := uc.logger.With(
+		zap.String("document_id", documentID),
+		zap.String("primary_model_id", primary.ID),
+		zap.String("backup_model_id", backupID))
+
+	backup, err := uc.aiModelRepo.GetByID(ctx, backupID)
+	if err != nil {
+		logger.Warn("备用 Embedding 模型不存在，使用主模型", zap.Error(err))
+		return primary, primaryProvider
+	}
+	if !sameEmbeddingModel(primary, backup) {
+		// 不同模型的向量即使维度相同也不在同一向量空间，混入 collection 会破坏检索结果
+		logger.Warn("备用 Embedding 模型与主模型不是同一模型，使用主模型",
+			zap.String("primary_model", primary.ModelName),
+			zap.String("backup_model", backup.ModelName))
+		return primary, primaryProvider
+	}
+	if !supportsEmbedding(backup) || embeddingModelUnhealthy(backup) {
+		logger.Warn("备用 Embedding 模型不可用，使用主模型",
+			zap.String("verification_status", backup.VerificationStatus))
+		return primary, primaryProvider
+	}
+	if primary.EmbeddingDimensions == nil || backup.EmbeddingDimensions == nil ||
+		*primary.EmbeddingDimensions != *backup.EmbeddingDimensions {
+		logger.Warn("备用 Embedding 模型维度与主模型不一致，使用主模型")
+		return primary, primaryProvider
+	}
+
+	backupProvider, err := uc.aiProviderRepo.GetByID(ctx, backup.ProviderID)
+	if err != nil || !backupProvider.IsEnabled {
+		logger.Warn("备用 Embedding 服务商不可用，使用主模型", zap.Error(err))
+		return primary, primaryProvider
+	}
+
+	logger.Warn("主 Embedding 模型不健康，切换到备用模型",
+		zap.String("verification_status", primary.VerificationStatus),
+		zap.String("backup_provider", backupProvider.ProviderName))
+	return backup, backupProvider
+}
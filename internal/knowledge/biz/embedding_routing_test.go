@@ -0,0 +1,94 @@
+package biz
+
+import (
+	"context"
+	"testing"
+)
+
+// withBackupEmbeddingModel 注册备用 Embedding 模型及其服务商，并配置为备用模型
+func (f *testFixture) withBackupEmbeddingModel(dims int) *AIModel {
+	provider := &AIProvider{ID: "provider-backup", ProviderType: "siliconflow", ProviderName: "Backup", IsEnabled: true}
+	model := &AIModel{
+		ID:                  "model-backup",
+		ProviderID:          provider.ID,
+		ModelName:           f.embedModel.ModelName, // 同一模型的另一个部署
+		Capabilities:        []string{CapabilityTypeEmbedding},
+		EmbeddingDimensions: &dims,
+		VerificationStatus:  "available",
+	}
+	f.modelRepo.models[model.ID] = model
+	f.useCase.aiProviderRepo.(*fakeAIProviderRepo).providers[provider.ID] = provider
+	f.config.BackupEmbeddingModelID = model.ID
+	return model
+}
+
+func TestProcessDocument_RoutesToBackupWhenPrimaryUnhealthy(t *testing.T) {
+	f := newTestFixture()
+	backup := f.withBackupEmbeddingModel(*f.embedModel.EmbeddingDimensions)
+	f.embedModel.VerificationStatus = "error"
+
+	doc := f.addDocument("doc-1", []byte("some content"))
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+
+	if len(f.embedder.models) != 1 || f.embedder.models[0] != backup.ID {
+		t.Fatalf("Expected embeddings from %s, got %v", backup.ID, f.embedder.models)
+	}
+	if got := len(f.vectorDB.vectors[f.kb.MilvusCollection]); got == 0 {
+		t.Errorf("Expected vectors to be written to the KB collection")
+	}
+	stored, _ := f.docRepo.GetByID(context.Background(), doc.ID)
+	if stored.ProcessStatus != "completed" {
+		t.Errorf("Expected status completed, got %q", stored.ProcessStatus)
+	}
+}
+
+func TestProcessDocument_EmbeddingRouting(t *testing.T) {
+	tests := []struct {
+		name          string
+		primaryStatus string
+		backupDims    int
+		backupModel   string
+		wantModel     string
+	}{
+		{name: "healthy primary", primaryStatus: "available", backupDims: 4, wantModel: "model-1"},
+		{name: "deprecated primary", primaryStatus: "deprecated", backupDims: 4, wantModel: "model-backup"},
+		{name: "backup dimension mismatch keeps primary", primaryStatus: "error", backupDims: 8, wantModel: "model-1"},
+		{name: "different backup model keeps primary", primaryStatus: "error", backupDims: 4, backupModel: "bge-m3", wantModel: "model-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			backup := f.withBackupEmbeddingModel(tt.backupDims)
+			if tt.backupModel != "" {
+				backup.ModelName = tt.backupModel
+			}
+			f.embedModel.VerificationStatus = tt.primaryStatus
+
+			doc := f.addDocument("doc-1", []byte("some content"))
+			if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err != nil {
+				t.Fatalf("ProcessDocument failed: %v", err)
+			}
+			if len(f.embedder.models) != 1 || f.embedder.models[0] != tt.wantModel {
+				t.Errorf("Expected embeddings from %s, got %v", tt.wantModel, f.embedder.models)
+			}
+		})
+	}
+}
+
+func TestProcessDocument_BackupReturningWrongDimensionsFails(t *testing.T) {
+	f := newTestFixture()
+	f.withBackupEmbeddingModel(*f.embedModel.EmbeddingDimensions)
+	f.embedModel.VerificationStatus = "error"
+	f.embedder.dimension = 8 // 备用模型实际返回的向量维度与声明不符
+
+	doc := f.addDocument("doc-1", []byte("some content"))
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err == nil {
+		t.Fatal("Expected ProcessDocument to fail on dimension mismatch")
+	}
+	if got := len(f.vectorDB.vectors[f.kb.MilvusCollection]); got != 0 {
+		t.Errorf("Expected no vectors to be written, got %d", got)
+	}
+}
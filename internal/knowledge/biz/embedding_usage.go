@@ -0,0 +1,133 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidUsageRange 统计时间范围无效
+var ErrInvalidUsageRange = errors.New("invalid usage date range")
+
+// EmbeddingUsage 单次文档处理的 Embedding token 用量记录（每次成功处理追加一条，重新处理会再次计入）
+type EmbeddingUsage struct {
+	ID              string
+	KnowledgeBaseID string
+	OwnerID         string // 知识库所有者（按所有者汇总成本）
+	DocumentID      string
+	ModelID         string // 实际生成 Embedding 的模型（可能是备用模型）
+	Tokens          int64
+	CreatedAt       time.Time
+}
+
+// EmbeddingUsageQuery Embedding 用量统计查询
+type EmbeddingUsageQuery struct {
+	From    time.Time // 起始时间（含）
+	To      time.Time // 结束时间（不含）
+	OwnerID string    // 非空时只统计该所有者的知识库
+}
+
+// KnowledgeBaseEmbeddingUsage 单个知识库的 Embedding 用量汇总
+type KnowledgeBaseEmbeddingUsage struct {
+	KnowledgeBaseID string
+	OwnerID         string
+	Tokens          int64
+	Runs            int64 // 处理次数
+}
+
+// OwnerEmbeddingUsage 单个所有者的 Embedding 用量汇总
+type OwnerEmbeddingUsage struct {
+	OwnerID string
+	Tokens  int64
+	Runs    int64
+}
+
+// EmbeddingUsageReport Embedding 用量统计结果
+type EmbeddingUsageReport struct {
+	From           time.Time
+	To             time.Time
+	KnowledgeBases []*KnowledgeBaseEmbeddingUsage // 按 token 数降序
+	Owners         []*OwnerEmbeddingUsage         // 按 token 数降序
+	TotalTokens    int64
+}
+
+// EmbeddingUsageRepo Embedding 用量仓储接口
+type EmbeddingUsageRepo interface {
+	Create(ctx context.Context, usage *EmbeddingUsage) error
+	SumByKnowledgeBase(ctx context.Context, query *EmbeddingUsageQuery) ([]*KnowledgeBaseEmbeddingUsage, error) // 按知识库汇总时间范围内的用量
+}
+
+// recordEmbeddingUsage 记录一次处理的 Embedding 用量（失败只记录日志，不影响文档处理结果）
+func (uc *DocumentUseCase) recordEmbeddingUsage(ctx context.Context, kb *KnowledgeBase, documentID, modelID string, tokens int64) {
+	if uc.embeddingUsageRepo == nil || tokens <= 0 {
+		return
+	}
+
+	usage := &EmbeddingUsage{
+		ID:              uuid.New().String(),
+		KnowledgeBaseID: kb.ID,
+		OwnerID:         kb.OwnerID,
+		DocumentID:      documentID,
+		ModelID:         modelID,
+		Tokens:          tokens,
+		CreatedAt:       time.Now(),
+	}
+	if err := uc.embeddingUsageRepo.Create(ctx, usage); err != nil {
+		uc.logger.Warn("记录 Embedding 用量失败",
+			zap.String("kb_id", kb.ID),
+			zap.String("document_id", documentID),
+			zap.Int64("tokens", tokens),
+			zap.Error(err))
+	}
+}
+
+// GetEmbeddingUsageReport 统计时间范围内各知识库及各所有者的 Embedding token 用量（管理操作）
+func (uc *DocumentUseCase) GetEmbeddingUsageReport(ctx context.Context, query *EmbeddingUsageQuery) (*EmbeddingUsageReport, error) {
+	if query == nil || query.From.IsZero() || query.To.IsZero() || !query.From.Before(query.To) {
+		return nil, ErrInvalidUsageRange
+	}
+
+	report := &EmbeddingUsageReport{
+		From:           query.From,
+		To:             query.To,
+		KnowledgeBases: []*KnowledgeBaseEmbeddingUsage{},
+		Owners:         []*OwnerEmbeddingUsage{},
+	}
+	if uc.embeddingUsageRepo == nil {
+		return report, nil
+	}
+
+	kbUsages, err := uc.embeddingUsageRepo.SumByKnowledgeBase(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate embedding usage: %w", err)
+	}
+
+	owners := make(map[string]*OwnerEmbeddingUsage)
+	for _, usage := range kbUsages {
+		report.KnowledgeBases = append(report.KnowledgeBases, usage)
+		report.TotalTokens += usage.Tokens
+
+		owner, ok := owners[usage.OwnerID]
+		if !ok {
+			owner = &OwnerEmbeddingUsage{OwnerID: usage.OwnerID}
+			owners[usage.OwnerID] = owner
+			report.Owners = append(report.Owners, owner)
+		}
+		owner.Tokens += usage.Tokens
+		owner.Runs += usage.Runs
+	}
+
+	sort.SliceStable(report.KnowledgeBases, func(i, j int) bool {
+		return report.KnowledgeBases[i].Tokens > report.KnowledgeBases[j].Tokens
+	})
+	sort.SliceStable(report.Owners, func(i, j int) bool {
+		return report.Owners[i].Tokens > report.Owners[j].Tokens
+	})
+
+	return report, nil
+}
@@ -0,0 +1,105 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbeddingUsage_AccumulatesAcrossDocuments(t *testing.T) {
+	f := newTestFixture()
+	ctx := context.Background()
+
+	otherKB := *f.kb
+	otherKB.ID = "kb-2"
+	otherKB.OwnerID = "user-2"
+	otherKB.MilvusCollection = "kb_test_2"
+	_ = f.kbRepo.Create(ctx, &otherKB)
+
+	// fakeProcessor 将整段内容作为一个分块，token 数按 len/4 估算
+	f.addDocument("doc-1", []byte(strings.Repeat("a", 40)))
+	f.addDocument("doc-2", []byte(strings.Repeat("b", 80)))
+	doc3 := f.addDocument("doc-3", []byte(strings.Repeat("c", 120)))
+	doc3.KnowledgeBaseID = otherKB.ID
+	_ = f.docRepo.Update(ctx, doc3)
+
+	for _, id := range []string{"doc-1", "doc-2", "doc-3"} {
+		if err := f.useCase.ProcessDocument(ctx, id); err != nil {
+			t.Fatalf("ProcessDocument(%s) failed: %v", id, err)
+		}
+	}
+	// 重新处理同样计入用量
+	if _, err := f.useCase.ReprocessDocument(ctx, "doc-1", testUserID, false); err != nil {
+		t.Fatalf("ReprocessDocument failed: %v", err)
+	}
+
+	report, err := f.useCase.GetEmbeddingUsageReport(ctx, &EmbeddingUsageQuery{
+		From: time.Now().Add(-time.Hour),
+		To:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("GetEmbeddingUsageReport failed: %v", err)
+	}
+
+	if report.TotalTokens != 70 {
+		t.Errorf("Expected 70 total tokens, got %d", report.TotalTokens)
+	}
+	if len(report.KnowledgeBases) != 2 {
+		t.Fatalf("Expected 2 knowledge bases, got %d", len(report.KnowledgeBases))
+	}
+	if kb := report.KnowledgeBases[0]; kb.KnowledgeBaseID != f.kb.ID || kb.Tokens != 40 || kb.Runs != 3 {
+		t.Errorf("Expected kb-1 with 40 tokens over 3 runs, got %+v", kb)
+	}
+	if kb := report.KnowledgeBases[1]; kb.KnowledgeBaseID != otherKB.ID || kb.Tokens != 30 || kb.Runs != 1 {
+		t.Errorf("Expected kb-2 with 30 tokens over 1 run, got %+v", kb)
+	}
+	if len(report.Owners) != 2 || report.Owners[0].OwnerID != testUserID || report.Owners[0].Tokens != 40 || report.Owners[1].Tokens != 30 {
+		t.Errorf("Unexpected owner totals: %+v %+v", report.Owners[0], report.Owners[1])
+	}
+
+	// 按所有者过滤
+	owned, err := f.useCase.GetEmbeddingUsageReport(ctx, &EmbeddingUsageQuery{
+		From:    time.Now().Add(-time.Hour),
+		To:      time.Now().Add(time.Hour),
+		OwnerID: "user-2",
+	})
+	if err != nil {
+		t.Fatalf("GetEmbeddingUsageReport failed: %v", err)
+	}
+	if owned.TotalTokens != 30 || len(owned.KnowledgeBases) != 1 {
+		t.Errorf("Expected only kb-2 usage for user-2, got %+v", owned)
+	}
+}
+
+func TestEmbeddingUsage_OutsideRangeExcluded(t *testing.T) {
+	f := newTestFixture()
+	ctx := context.Background()
+
+	f.addDocument("doc-1", []byte(strings.Repeat("a", 40)))
+	if err := f.useCase.ProcessDocument(ctx, "doc-1"); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+
+	report, err := f.useCase.GetEmbeddingUsageReport(ctx, &EmbeddingUsageQuery{
+		From: time.Now().Add(time.Hour),
+		To:   time.Now().Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("GetEmbeddingUsageReport failed: %v", err)
+	}
+	if report.TotalTokens != 0 || len(report.KnowledgeBases) != 0 {
+		t.Errorf("Expected no usage outside the range, got %+v", report)
+	}
+}
+
+func TestEmbeddingUsage_InvalidRange(t *testing.T) {
+	f := newTestFixture()
+	now := time.Now()
+
+	_, err := f.useCase.GetEmbeddingUsageReport(context.Background(), &EmbeddingUsageQuery{From: now, To: now.Add(-time.Hour)})
+	if !errors.Is(err, ErrInvalidUsageRange) {
+		t.Fatalf("Expected ErrInvalidUsageRange, got %v", err)
+	}
+}
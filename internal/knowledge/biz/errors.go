@@ -21,16 +21,23 @@ var (
 	ErrKnowledgeBaseNameRequired     = errors.New("knowledge base name is required")
 	ErrKnowledgeBaseInvalidChunkSize = errors.New("invalid chunk size")
 	ErrKnowledgeBaseInvalidOverlap   = errors.New("invalid chunk overlap")
+	ErrKnowledgeBaseInvalidTemplate  = errors.New("invalid knowledge template")
+	ErrSystemProviderRequired        = errors.New("system knowledge base must use a system provider")
 )
 
 // Document 相关错误
 var (
-	ErrDocumentNotFound       = errors.New("document not found")
-	ErrDocumentInvalidType    = errors.New("invalid document type")
-	ErrDocumentTooLarge       = errors.New("document too large")
-	ErrDocumentHashExists     = errors.New("document with same hash already exists")
-	ErrDocumentProcessing     = errors.New("document is being processed")
-	ErrDocumentAlreadyFailed  = errors.New("document processing already failed")
+	ErrDocumentNotFound         = errors.New("document not found")
+	ErrDocumentInvalidType      = errors.New("invalid document type")
+	ErrDocumentTooLarge         = errors.New("document too large")
+	ErrDocumentHashExists       = errors.New("document with same hash already exists")
+	ErrDocumentProcessing       = errors.New("document is being processed")
+	ErrDocumentAlreadyFailed    = errors.New("document processing already failed")
+	ErrInvalidBatchID           = errors.New("invalid batch id")
+	ErrChunkContentTooLong      = errors.New("chunk content exceeds vector store limit")
+	ErrUnsupportedChunkStrategy = errors.New("unsupported chunk strategy")
+	ErrInvalidProcessStatus     = errors.New("invalid document process status")
+	ErrInvalidProcessStage      = errors.New("invalid document process stage")
 )
 
 // 权限相关错误
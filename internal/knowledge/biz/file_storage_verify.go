@@ -0,0 +1,34 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ensureStoredObject 复用已有文件前确认 MinIO 对象仍存在
+// 文件存储记录残留但对象已被删除（如 DeleteIfNoReferences 与上传并发）时，用本次上传的内容恢复对象，避免后续处理读取失败
+func (uc *DocumentUseCase) ensureStoredObject(ctx context.Context, file *FileStorage, fileData []byte, contentType string) error {
+	if uc.config.MissingObjectPolicy == MissingObjectPolicySkipCheck {
+		return nil
+	}
+
+	exists, err := uc.storage.ObjectExists(ctx, file.Bucket, file.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("failed to check stored file: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	uc.logger.Warn("文件记录存在但存储中的文件缺失，重新上传",
+		zap.String("file_hash", file.FileHash),
+		zap.String("bucket", file.Bucket),
+		zap.String("object_key", file.ObjectKey))
+
+	if _, err := uc.storage.UploadFile(ctx, file.Bucket, file.ObjectKey, fileData, contentType); err != nil {
+		return fmt.Errorf("failed to restore missing file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// uploadWithMissingObject 上传文件后删除其 MinIO 对象（保留文件存储记录），再次上传相同内容
+func uploadWithMissingObject(t *testing.T, f *testFixture, data []byte) (*FileStorage, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	first, err := f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, "a.txt", data, "txt")
+	if err != nil {
+		t.Fatalf("First upload failed: %v", err)
+	}
+	fs, _ := f.fileRepo.GetByHash(ctx, first.FileHash)
+	if fs == nil {
+		t.Fatal("Expected file storage record after first upload")
+	}
+	_ = f.storage.DeleteFile(ctx, fs.Bucket, fs.ObjectKey)
+
+	_, err = f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, "a-copy.txt", data, "txt")
+	return fs, err
+}
+
+func TestUploadDocument_RestoresMissingObject(t *testing.T) {
+	f := newTestFixture()
+	data := []byte("content whose object was deleted")
+
+	fs, err := uploadWithMissingObject(t, f, data)
+	if err != nil {
+		t.Fatalf("Second upload failed: %v", err)
+	}
+
+	stored, err := f.storage.GetFile(context.Background(), fs.Bucket, fs.ObjectKey)
+	if err != nil {
+		t.Fatalf("Expected object to be restored: %v", err)
+	}
+	if !bytes.Equal(stored, data) {
+		t.Errorf("Expected restored bytes %q, got %q", data, stored)
+	}
+	if fs.ReferenceCount != 2 {
+		t.Errorf("Expected reference count 2, got %d", fs.ReferenceCount)
+	}
+}
+
+func TestUploadDocument_SkipCheckLeavesMissingObject(t *testing.T) {
+	f := newTestFixture()
+	f.config.MissingObjectPolicy = MissingObjectPolicySkipCheck
+
+	fs, err := uploadWithMissingObject(t, f, []byte("content"))
+	if err != nil {
+		t.Fatalf("Second upload failed: %v", err)
+	}
+	if _, err := f.storage.GetFile(context.Background(), fs.Bucket, fs.ObjectKey); err == nil {
+		t.Error("Expected object to stay missing with skip-check policy")
+	}
+}
+
+func TestUploadDocument_ObjectCheckErrorKeepsReference(t *testing.T) {
+	f := newTestFixture()
+	ctx := context.Background()
+	data := []byte("content")
+
+	first, err := f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, "a.txt", data, "txt")
+	if err != nil {
+		t.Fatalf("First upload failed: %v", err)
+	}
+
+	statErr := errors.New("minio unavailable")
+	f.storage.statErr = statErr
+	if _, err := f.useCase.UploadDocument(ctx, f.kb.ID, testUserID, "a-copy.txt", data, "txt"); !errors.Is(err, statErr) {
+		t.Fatalf("Expected stat error, got %v", err)
+	}
+
+	fs, _ := f.fileRepo.GetByHash(ctx, first.FileHash)
+	if fs.ReferenceCount != 1 {
+		t.Errorf("Expected reference count to stay 1, got %d", fs.ReferenceCount)
+	}
+}
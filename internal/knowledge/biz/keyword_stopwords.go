@@ -0,0 +1,47 @@
+package biz
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeStopwords 规范化停用词列表（去空白、转小写、去重）
+func normalizeStopwords(stopwords []string) []string {
+	if len(stopwords) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(stopwords))
+	normalized := make([]string, 0, len(stopwords))
+	for _, word := range stopwords {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		normalized = append(normalized, word)
+	}
+	return normalized
+}
+
+// removeStopwords 构建 tsquery 前过滤查询中的停用词（不区分大小写，忽略词两端标点）
+func removeStopwords(query string, stopwords []string) string {
+	if len(stopwords) == 0 {
+		return query
+	}
+
+	stop := make(map[string]bool, len(stopwords))
+	for _, word := range stopwords {
+		stop[strings.ToLower(word)] = true
+	}
+
+	terms := strings.Fields(query)
+	kept := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if stop[strings.ToLower(strings.TrimFunc(term, unicode.IsPunct))] {
+			continue
+		}
+		kept = append(kept, term)
+	}
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,58 @@
+package biz
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchDocuments_KeywordStopwords(t *testing.T) {
+	tests := []struct {
+		name      string
+		stopwords []string
+		query     string
+		wantDocs  []string
+	}{
+		{name: "no stopwords", query: "acme", wantDocs: []string{"doc-acme"}},
+		{name: "stopword excluded from matching", stopwords: []string{"ACME", "the"}, query: "acme", wantDocs: nil},
+		{name: "remaining terms still match", stopwords: []string{"acme", "the"}, query: "the Acme widget", wantDocs: []string{"doc-widget"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			f.kb.EnableHybridSearch = true
+			f.kb.KeywordStopwords = normalizeStopwords(tt.stopwords)
+			ctx := context.Background()
+
+			_ = f.chunkRepo.BatchCreate(ctx, []*Chunk{
+				{ID: "chunk-1", DocumentID: "doc-acme", KnowledgeBaseID: f.kb.ID, Content: "Acme corporate overview"},
+				{ID: "chunk-2", DocumentID: "doc-widget", KnowledgeBaseID: f.kb.ID, Content: "the widget guide"},
+			})
+			_, _, _ = f.chunkRepo.ReindexKeywordSearchBatch(ctx, f.kb.ID, "", 100)
+
+			results, err := f.useCase.SearchDocuments(ctx, f.kb.ID, testUserID, tt.query, 5)
+			if err != nil {
+				t.Fatalf("SearchDocuments failed: %v", err)
+			}
+
+			if len(results) != len(tt.wantDocs) {
+				t.Fatalf("Expected %d results, got %d: %+v", len(tt.wantDocs), len(results), results)
+			}
+			for i, docID := range tt.wantDocs {
+				if results[i].DocumentID != docID {
+					t.Errorf("Result %d: expected %s, got %s", i, docID, results[i].DocumentID)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeStopwords(t *testing.T) {
+	got := normalizeStopwords([]string{" The ", "the", "", "Acme"})
+	if len(got) != 2 || got[0] != "the" || got[1] != "acme" {
+		t.Errorf("Unexpected normalized stopwords: %v", got)
+	}
+	if normalizeStopwords(nil) != nil {
+		t.Error("Expected nil for empty stopwords")
+	}
+}
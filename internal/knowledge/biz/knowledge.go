@@ -3,6 +3,8 @@ package biz
 import (
 	"context"
 	"fmt"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/google/uuid"
@@ -48,6 +50,7 @@ type KnowledgeBase struct {
 	RerankModelID    *string // 使用的 Rerank 模型 ID（可选）
 	ChunkSize        int
 	ChunkOverlap     int
+	ChunkOverlapUnit string // 重叠单位：tokens（默认）、characters、sentences
 	ChunkStrategy    string
 	MilvusCollection string
 	DocumentCount    int64
@@ -56,11 +59,65 @@ type KnowledgeBase struct {
 	Threshold           float32 // 相似度阈值（0.0-1.0），用于过滤低相关性结果，默认 0.0（不过滤）
 	TopK                int     // 返回文档数量，默认 5
 	EnableHybridSearch  bool    // 是否启用混合检索，默认 false
+	KeywordStopwords    []string // 关键词检索停用词（构建 tsquery 前过滤），默认为空
+
+	// 按文件类型覆盖的 Embedding 模型（各自独立 Collection，检索时融合），默认为空
+	EmbeddingOverrides []EmbeddingOverride
+
+	// 检索结果注入模板（Go text/template），为空时使用全局配置的模板
+	KnowledgeTemplate *string
 
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
 
+// 分块重叠单位（chunk_size 始终按 token 计算）
+const (
+	ChunkOverlapUnitTokens     = "tokens"     // 按 token 重叠（默认，与历史行为一致）
+	ChunkOverlapUnitCharacters = "characters" // 按字符重叠
+	ChunkOverlapUnitSentences  = "sentences"  // 按完整句子重叠
+)
+
+// normalizeKnowledgeTemplate 校验知识库注入模板（为空时返回 nil，表示使用全局模板）
+func normalizeKnowledgeTemplate(text *string) (*string, error) {
+	if text == nil || strings.TrimSpace(*text) == "" {
+		return nil, nil
+	}
+	if _, err := template.New("knowledge").Parse(*text); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKnowledgeBaseInvalidTemplate, err)
+	}
+	return text, nil
+}
+
+// isValidChunkOverlapUnit 校验重叠单位
+func isValidChunkOverlapUnit(unit string) bool {
+	switch unit {
+	case ChunkOverlapUnitTokens, ChunkOverlapUnitCharacters, ChunkOverlapUnitSentences:
+		return true
+	default:
+		return false
+	}
+}
+
+// maxOverlapSentencesPerChunkTokens 每多少个分块 token 允许 1 句重叠（按每句至少约 50 token 估算，重叠不超过半个分块）
+const maxOverlapSentencesPerChunkTokens = 100
+
+// isValidChunkOverlap 按重叠单位校验重叠大小，保证重叠明显小于一个分块，分块才能持续推进
+func isValidChunkOverlap(chunkSize, chunkOverlap int, unit string) bool {
+	if chunkOverlap < 0 {
+		return false
+	}
+	switch unit {
+	case ChunkOverlapUnitCharacters:
+		// 一个 token 至少对应一个字符，不超过 chunk_size 的一半即不超过半个分块
+		return chunkOverlap <= chunkSize/2
+	case ChunkOverlapUnitSentences:
+		return chunkOverlap <= chunkSize/maxOverlapSentencesPerChunkTokens
+	default:
+		return chunkOverlap < chunkSize
+	}
+}
+
 // IsOfficial 是否为官方知识库
 func (kb *KnowledgeBase) IsOfficial() bool {
 	return kb.OwnerID == SystemOwnerID
@@ -75,19 +132,24 @@ type KnowledgeBaseRepo interface {
 	Delete(ctx context.Context, id string, ownerID string) error
 	IncrementDocumentCount(ctx context.Context, id string, delta int) error
 	BatchUpdateDocumentCounts(ctx context.Context, deltas map[string]int) error  // 批量更新文档计数
+	ListIDs(ctx context.Context) ([]string, error)                               // 列出所有知识库 ID（运维任务使用）
 }
 
 // CreateKnowledgeBaseRequest 创建知识库请求
 type CreateKnowledgeBaseRequest struct {
 	Name             string
-	EmbeddingModelID string   // 必填，Embedding 模型 ID
+	EmbeddingModelID string   // 必填（用户设置了默认模型时可不传），Embedding 模型 ID
 	RerankModelID    *string  // 可选，Rerank 模型 ID
-	ChunkSize        *int     // 可选，不传则根据嵌入模型 max_context 自动设置
+	ChunkSize        *int     // 可选，不传则使用用户默认值，或根据嵌入模型 max_context 自动设置
 	ChunkOverlap     *int     // 可选，不传则为 0（不重叠）
+	ChunkOverlapUnit *string  // 可选，重叠单位（tokens/characters/sentences），默认 tokens
 	ChunkStrategy    *string  // 可选，不传则为 "recursive"
 	Threshold        *float32 // 可选，相似度阈值（0.0-1.0），默认 0.0（不过滤）
 	TopK             *int     // 可选，返回文档数量（1-20），默认 5
 	EnableHybridSearch *bool  // 可选，是否启用混合检索，默认 false
+	KeywordStopwords []string // 可选，关键词检索停用词，默认为空
+	EmbeddingOverrides []EmbeddingOverride // 可选，按文件类型覆盖 Embedding 模型（仅创建时可设置）
+	KnowledgeTemplate *string // 可选，检索结果注入模板，默认使用全局模板
 }
 
 // UpdateKnowledgeBaseRequest 更新知识库请求
@@ -96,6 +158,8 @@ type UpdateKnowledgeBaseRequest struct {
 	Threshold          *float32 // 可选，相似度阈值
 	TopK               *int     // 可选，返回文档数量
 	EnableHybridSearch *bool    // 可选，是否启用混合检索
+	KeywordStopwords   []string // 可选，关键词检索停用词（传空数组清空）
+	KnowledgeTemplate  *string  // 可选，检索结果注入模板（传空字符串恢复全局模板）
 }
 
 // ListKnowledgeBasesRequest 知识库列表请求
@@ -108,18 +172,27 @@ type ListKnowledgeBasesRequest struct {
 
 // KnowledgeBaseUseCase 知识库用例
 type KnowledgeBaseUseCase struct {
-	kbRepo      KnowledgeBaseRepo
-	aiModelRepo AIModelRepo
+	kbRepo       KnowledgeBaseRepo
+	aiModelRepo  AIModelRepo
+	defaultsRepo KnowledgeBaseDefaultsRepo  // 用户知识库默认设置（可为 nil）
+	systemConfig *SystemKnowledgeBaseConfig // 系统知识库服务商绑定（可为 nil）
+	vectorDB     VectorDBService            // 删除知识库时清理覆盖 Collection（可为 nil）
 }
 
 // NewKnowledgeBaseUseCase 创建知识库用例
 func NewKnowledgeBaseUseCase(
 	kbRepo KnowledgeBaseRepo,
 	aiModelRepo AIModelRepo,
+	defaultsRepo KnowledgeBaseDefaultsRepo,
+	systemConfig *SystemKnowledgeBaseConfig,
+	vectorDB VectorDBService,
 ) *KnowledgeBaseUseCase {
 	return &KnowledgeBaseUseCase{
-		kbRepo:      kbRepo,
-		aiModelRepo: aiModelRepo,
+		kbRepo:       kbRepo,
+		aiModelRepo:  aiModelRepo,
+		defaultsRepo: defaultsRepo,
+		systemConfig: systemConfig,
+		vectorDB:     vectorDB,
 	}
 }
 
@@ -133,6 +206,24 @@ func (uc *KnowledgeBaseUseCase) CreateKnowledgeBase(
 	if req.Name == "" {
 		return nil, ErrKnowledgeBaseNameRequired
 	}
+
+	// 未传的字段使用用户的默认设置
+	req, err := uc.applyUserDefaults(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 系统知识库未指定 Embedding 模型时使用系统服务商的默认模型
+	isSystem := userID == SystemOwnerID
+	if isSystem && req.EmbeddingModelID == "" {
+		modelID, err := uc.defaultSystemEmbeddingModel(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resolved := *req
+		resolved.EmbeddingModelID = modelID
+		req = &resolved
+	}
 	if req.EmbeddingModelID == "" {
 		return nil, ErrAIProviderNotFound
 	}
@@ -161,11 +252,16 @@ func (uc *KnowledgeBaseUseCase) CreateKnowledgeBase(
 		chunkOverlap = 0
 	}
 
+	chunkOverlapUnit := ChunkOverlapUnitTokens
+	if req.ChunkOverlapUnit != nil && *req.ChunkOverlapUnit != "" {
+		chunkOverlapUnit = *req.ChunkOverlapUnit
+	}
+
 	var chunkStrategy string
 	if req.ChunkStrategy != nil {
 		chunkStrategy = *req.ChunkStrategy
 	} else {
-		chunkStrategy = "recursive"
+		chunkStrategy = ChunkStrategyRecursive
 	}
 
 	// 设置检索配置默认值
@@ -188,7 +284,10 @@ func (uc *KnowledgeBaseUseCase) CreateKnowledgeBase(
 	if chunkSize < 100 || chunkSize > 10000 {
 		return nil, ErrKnowledgeBaseInvalidChunkSize
 	}
-	if chunkOverlap < 0 || chunkOverlap >= chunkSize {
+	if !isValidChunkOverlapUnit(chunkOverlapUnit) {
+		return nil, fmt.Errorf("chunk_overlap_unit must be one of tokens, characters, sentences")
+	}
+	if !isValidChunkOverlap(chunkSize, chunkOverlap, chunkOverlapUnit) {
 		return nil, ErrKnowledgeBaseInvalidOverlap
 	}
 	if threshold < 0.0 || threshold > 1.0 {
@@ -197,11 +296,35 @@ func (uc *KnowledgeBaseUseCase) CreateKnowledgeBase(
 	if topK < 1 || topK > 20 {
 		return nil, fmt.Errorf("top_k must be between 1 and 20")
 	}
+	knowledgeTemplate, err := normalizeKnowledgeTemplate(req.KnowledgeTemplate)
+	if err != nil {
+		return nil, err
+	}
 
 	// 3. 生成 Milvus Collection 名称
 	collectionName := fmt.Sprintf("kb_%s_%s",
 		userID[:8], uuid.New().String()[:8])
 
+	// 按文件类型覆盖的 Embedding 模型（每个覆盖生成独立 Collection）
+	embeddingOverrides, err := uc.buildEmbeddingOverrides(ctx, userID, req.EmbeddingModelID, req.EmbeddingOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	// 系统知识库的所有模型必须来自系统服务商，不依赖个人用户的服务商密钥
+	if isSystem {
+		modelIDs := []string{req.EmbeddingModelID}
+		if req.RerankModelID != nil && *req.RerankModelID != "" {
+			modelIDs = append(modelIDs, *req.RerankModelID)
+		}
+		for _, override := range embeddingOverrides {
+			modelIDs = append(modelIDs, override.EmbeddingModelID)
+		}
+		if err := uc.ensureSystemProviderModels(ctx, modelIDs...); err != nil {
+			return nil, err
+		}
+	}
+
 	// 4. 【阶段 3】在 Milvus 创建 Collection
 	// 当前阶段跳过，仅生成名称
 
@@ -215,12 +338,16 @@ func (uc *KnowledgeBaseUseCase) CreateKnowledgeBase(
 		RerankModelID:    req.RerankModelID,
 		ChunkSize:        chunkSize,
 		ChunkOverlap:     chunkOverlap,
+		ChunkOverlapUnit: chunkOverlapUnit,
 		ChunkStrategy:    chunkStrategy,
 		MilvusCollection: collectionName,
 		DocumentCount:    0,
 		Threshold:        threshold,
 		TopK:             topK,
 		EnableHybridSearch: enableHybridSearch,
+		KeywordStopwords: normalizeStopwords(req.KeywordStopwords),
+		EmbeddingOverrides: embeddingOverrides,
+		KnowledgeTemplate: knowledgeTemplate,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
@@ -298,6 +425,18 @@ func (uc *KnowledgeBaseUseCase) UpdateKnowledgeBase(
 		kb.EnableHybridSearch = *req.EnableHybridSearch
 	}
 
+	if req.KeywordStopwords != nil {
+		kb.KeywordStopwords = normalizeStopwords(req.KeywordStopwords)
+	}
+
+	if req.KnowledgeTemplate != nil {
+		knowledgeTemplate, err := normalizeKnowledgeTemplate(req.KnowledgeTemplate)
+		if err != nil {
+			return nil, err
+		}
+		kb.KnowledgeTemplate = knowledgeTemplate
+	}
+
 	kb.UpdatedAt = time.Now()
 
 	if err := uc.kbRepo.Update(ctx, kb); err != nil {
@@ -329,8 +468,32 @@ func (uc *KnowledgeBaseUseCase) DeleteKnowledgeBase(
 		return ErrUnauthorized
 	}
 
-	// 【阶段 3】删除 Milvus Collection
-	// 当前阶段跳过
+	// 删除按文件类型覆盖的 Collection（覆盖 Collection 只属于该知识库）
+	// 【阶段 3】删除默认 Milvus Collection，当前阶段跳过
+	if err := uc.dropOverrideCollections(ctx, kb); err != nil {
+		return err
+	}
 
 	return uc.kbRepo.Delete(ctx, id, userID)
 }
+
+// dropOverrideCollections 删除知识库的覆盖 Collection（尚未创建的跳过）
+func (uc *KnowledgeBaseUseCase) dropOverrideCollections(ctx context.Context, kb *KnowledgeBase) error {
+	if uc.vectorDB == nil {
+		return nil
+	}
+
+	for _, override := range kb.EmbeddingOverrides {
+		exists, err := uc.vectorDB.HasCollection(ctx, override.MilvusCollection)
+		if err != nil {
+			return fmt.Errorf("failed to check collection %s: %w", override.MilvusCollection, err)
+		}
+		if !exists {
+			continue
+		}
+		if err := uc.vectorDB.DropCollection(ctx, override.MilvusCollection); err != nil {
+			return fmt.Errorf("failed to drop collection %s: %w", override.MilvusCollection, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidKnowledgeBaseDefaults 用户知识库默认设置无效
+var ErrInvalidKnowledgeBaseDefaults = errors.New("invalid knowledge base defaults")
+
+// 分块策略（与 processor.ChunkText 支持的策略一致）
+const (
+	ChunkStrategyRecursive = "recursive"
+	ChunkStrategyFixed     = "fixed"
+)
+
+// KnowledgeBaseDefaults 用户级知识库默认设置
+// 创建知识库时，请求中未传的字段使用这里的值；这里也未设置的字段使用系统默认值
+type KnowledgeBaseDefaults struct {
+	UserID           string
+	EmbeddingModelID *string
+	ChunkSize        *int
+	ChunkOverlap     *int
+	ChunkStrategy    *string
+	TopK             *int
+	Threshold        *float32
+	UpdatedAt        time.Time
+}
+
+// KnowledgeBaseDefaultsRepo 用户知识库默认设置仓储接口
+type KnowledgeBaseDefaultsRepo interface {
+	Get(ctx context.Context, userID string) (*KnowledgeBaseDefaults, error) // 未设置时返回 nil, nil
+	Upsert(ctx context.Context, defaults *KnowledgeBaseDefaults) error
+}
+
+// GetUserDefaults 获取用户的知识库默认设置（未设置时返回空设置）
+func (uc *KnowledgeBaseUseCase) GetUserDefaults(ctx context.Context, userID string) (*KnowledgeBaseDefaults, error) {
+	if uc.defaultsRepo == nil {
+		return &KnowledgeBaseDefaults{UserID: userID}, nil
+	}
+	defaults, err := uc.defaultsRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if defaults == nil {
+		return &KnowledgeBaseDefaults{UserID: userID}, nil
+	}
+	return defaults, nil
+}
+
+// UpdateUserDefaults 校验并保存用户的知识库默认设置（整体替换）
+func (uc *KnowledgeBaseUseCase) UpdateUserDefaults(ctx context.Context, userID string, defaults *KnowledgeBaseDefaults) (*KnowledgeBaseDefaults, error) {
+	if uc.defaultsRepo == nil {
+		return nil, fmt.Errorf("knowledge base defaults are not supported")
+	}
+
+	saved := *defaults
+	saved.UserID = userID
+	if saved.EmbeddingModelID != nil && *saved.EmbeddingModelID == "" {
+		saved.EmbeddingModelID = nil
+	}
+	if err := uc.validateDefaults(ctx, &saved); err != nil {
+		return nil, err
+	}
+
+	saved.UpdatedAt = time.Now()
+	if err := uc.defaultsRepo.Upsert(ctx, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// validateDefaults 按创建知识库的规则校验默认设置（仅校验已设置的字段）
+func (uc *KnowledgeBaseUseCase) validateDefaults(ctx context.Context, d *KnowledgeBaseDefaults) error {
+	if d.ChunkSize != nil && (*d.ChunkSize < 100 || *d.ChunkSize > 10000) {
+		return fmt.Errorf("%w: chunk_size must be between 100 and 10000", ErrInvalidKnowledgeBaseDefaults)
+	}
+	if d.ChunkOverlap != nil {
+		if *d.ChunkOverlap < 0 {
+			return fmt.Errorf("%w: chunk_overlap must not be negative", ErrInvalidKnowledgeBaseDefaults)
+		}
+		if d.ChunkSize != nil && *d.ChunkOverlap >= *d.ChunkSize {
+			return fmt.Errorf("%w: chunk_overlap must be less than chunk_size", ErrInvalidKnowledgeBaseDefaults)
+		}
+	}
+	if d.ChunkStrategy != nil && *d.ChunkStrategy != ChunkStrategyRecursive && *d.ChunkStrategy != ChunkStrategyFixed {
+		return fmt.Errorf("%w: chunk_strategy must be one of recursive, fixed", ErrInvalidKnowledgeBaseDefaults)
+	}
+	if d.TopK != nil && (*d.TopK < 1 || *d.TopK > 20) {
+		return fmt.Errorf("%w: top_k must be between 1 and 20", ErrInvalidKnowledgeBaseDefaults)
+	}
+	if d.Threshold != nil && (*d.Threshold < 0.0 || *d.Threshold > 1.0) {
+		return fmt.Errorf("%w: threshold must be between 0.0 and 1.0", ErrInvalidKnowledgeBaseDefaults)
+	}
+
+	if d.EmbeddingModelID != nil {
+		model, err := uc.aiModelRepo.GetByID(ctx, *d.EmbeddingModelID)
+		if err != nil {
+			return fmt.Errorf("%w: embedding model not found: %v", ErrInvalidKnowledgeBaseDefaults, err)
+		}
+		isEmbedding := false
+		for _, capability := range model.Capabilities {
+			if capability == CapabilityTypeEmbedding {
+				isEmbedding = true
+				break
+			}
+		}
+		if !isEmbedding {
+			return fmt.Errorf("%w: model %s is not an embedding model", ErrInvalidKnowledgeBaseDefaults, model.ModelName)
+		}
+	}
+	return nil
+}
+
+// applyUserDefaults 用用户默认设置填充请求中未传的字段，返回填充后的请求副本
+func (uc *KnowledgeBaseUseCase) applyUserDefaults(ctx context.Context, userID string, req *CreateKnowledgeBaseRequest) (*CreateKnowledgeBaseRequest, error) {
+	if uc.defaultsRepo == nil || userID == SystemOwnerID {
+		return req, nil
+	}
+	defaults, err := uc.defaultsRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load knowledge base defaults: %w", err)
+	}
+	if defaults == nil {
+		return req, nil
+	}
+
+	resolved := *req
+	if resolved.EmbeddingModelID == "" && defaults.EmbeddingModelID != nil {
+		resolved.EmbeddingModelID = *defaults.EmbeddingModelID
+	}
+	if resolved.ChunkSize == nil {
+		resolved.ChunkSize = defaults.ChunkSize
+	}
+	if resolved.ChunkOverlap == nil {
+		resolved.ChunkOverlap = defaults.ChunkOverlap
+	}
+	if resolved.ChunkStrategy == nil {
+		resolved.ChunkStrategy = defaults.ChunkStrategy
+	}
+	if resolved.TopK == nil {
+		resolved.TopK = defaults.TopK
+	}
+	if resolved.Threshold == nil {
+		resolved.Threshold = defaults.Threshold
+	}
+	return &resolved, nil
+}
@@ -0,0 +1,161 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type fakeKnowledgeBaseDefaultsRepo struct {
+	mu       sync.Mutex
+	defaults map[string]*KnowledgeBaseDefaults
+}
+
+func (r *fakeKnowledgeBaseDefaultsRepo) Get(ctx context.Context, userID string) (*KnowledgeBaseDefaults, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, ok := r.defaults[userID]; ok {
+		copied := *d
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeKnowledgeBaseDefaultsRepo) Upsert(ctx context.Context, defaults *KnowledgeBaseDefaults) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *defaults
+	r.defaults[defaults.UserID] = &copied
+	return nil
+}
+
+func TestCreateKnowledgeBase_InheritsUserDefaults(t *testing.T) {
+	f := newTestFixture()
+	uc := NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, &fakeKnowledgeBaseDefaultsRepo{defaults: map[string]*KnowledgeBaseDefaults{}}, nil, f.vectorDB)
+	ctx := context.Background()
+	userID := "user-00000001"
+
+	chunkSize, overlap, topK := 800, 80, 12
+	strategy := ChunkStrategyFixed
+	threshold := float32(0.4)
+	if _, err := uc.UpdateUserDefaults(ctx, userID, &KnowledgeBaseDefaults{
+		EmbeddingModelID: &f.embedModel.ID,
+		ChunkSize:        &chunkSize,
+		ChunkOverlap:     &overlap,
+		ChunkStrategy:    &strategy,
+		TopK:             &topK,
+		Threshold:        &threshold,
+	}); err != nil {
+		t.Fatalf("UpdateUserDefaults failed: %v", err)
+	}
+
+	kb, err := uc.CreateKnowledgeBase(ctx, userID, &CreateKnowledgeBaseRequest{Name: "defaults"})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase failed: %v", err)
+	}
+	if kb.EmbeddingModelID != f.embedModel.ID || kb.ChunkSize != chunkSize || kb.ChunkOverlap != overlap ||
+		kb.ChunkStrategy != strategy || kb.TopK != topK || kb.Threshold != threshold {
+		t.Errorf("Expected KB to inherit user defaults, got model=%s size=%d overlap=%d strategy=%s topK=%d threshold=%v",
+			kb.EmbeddingModelID, kb.ChunkSize, kb.ChunkOverlap, kb.ChunkStrategy, kb.TopK, kb.Threshold)
+	}
+
+	// 请求中显式传入的字段优先于默认值
+	explicitTopK := 3
+	kb, err = uc.CreateKnowledgeBase(ctx, userID, &CreateKnowledgeBaseRequest{Name: "explicit", TopK: &explicitTopK})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase failed: %v", err)
+	}
+	if kb.TopK != explicitTopK || kb.ChunkSize != chunkSize {
+		t.Errorf("Expected explicit top_k %d with default chunk_size %d, got %d and %d", explicitTopK, chunkSize, kb.TopK, kb.ChunkSize)
+	}
+
+	// 其他用户不受影响
+	kb, err = uc.CreateKnowledgeBase(ctx, "user-00000002", &CreateKnowledgeBaseRequest{Name: "other", EmbeddingModelID: f.embedModel.ID})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase failed: %v", err)
+	}
+	if kb.TopK != 5 || kb.ChunkStrategy != ChunkStrategyRecursive {
+		t.Errorf("Expected system defaults for another user, got topK=%d strategy=%s", kb.TopK, kb.ChunkStrategy)
+	}
+}
+
+func TestUpdateUserDefaults_Validation(t *testing.T) {
+	f := newTestFixture()
+	chatModel := &AIModel{ID: "chat-1", ProviderID: f.aiProvider.ID, ModelName: "gpt-4o", Capabilities: []string{CapabilityTypeChat}}
+	f.modelRepo.models[chatModel.ID] = chatModel
+	repo := &fakeKnowledgeBaseDefaultsRepo{defaults: map[string]*KnowledgeBaseDefaults{}}
+	uc := NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, repo, nil, f.vectorDB)
+
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+	threshold := float32(1.5)
+	missing := "missing-model"
+
+	tests := []struct {
+		name     string
+		defaults *KnowledgeBaseDefaults
+	}{
+		{name: "chunk size too small", defaults: &KnowledgeBaseDefaults{ChunkSize: intPtr(50)}},
+		{name: "overlap not less than chunk size", defaults: &KnowledgeBaseDefaults{ChunkSize: intPtr(200), ChunkOverlap: intPtr(200)}},
+		{name: "negative overlap", defaults: &KnowledgeBaseDefaults{ChunkOverlap: intPtr(-1)}},
+		{name: "unknown strategy", defaults: &KnowledgeBaseDefaults{ChunkStrategy: strPtr("semantic")}},
+		{name: "top_k out of range", defaults: &KnowledgeBaseDefaults{TopK: intPtr(50)}},
+		{name: "threshold out of range", defaults: &KnowledgeBaseDefaults{Threshold: &threshold}},
+		{name: "missing model", defaults: &KnowledgeBaseDefaults{EmbeddingModelID: &missing}},
+		{name: "non-embedding model", defaults: &KnowledgeBaseDefaults{EmbeddingModelID: &chatModel.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.UpdateUserDefaults(context.Background(), "user-00000001", tt.defaults)
+			if !errors.Is(err, ErrInvalidKnowledgeBaseDefaults) {
+				t.Errorf("Expected ErrInvalidKnowledgeBaseDefaults, got %v", err)
+			}
+		})
+	}
+
+	if len(repo.defaults) != 0 {
+		t.Errorf("Expected invalid defaults not to be stored, got %d", len(repo.defaults))
+	}
+}
+
+func TestKnowledgeBase_KnowledgeTemplate(t *testing.T) {
+	f := newTestFixture()
+	uc := NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, nil, nil, f.vectorDB)
+	ctx := context.Background()
+	userID := "user-00000001"
+
+	invalid := "{{range .Results}"
+	_, err := uc.CreateKnowledgeBase(ctx, userID, &CreateKnowledgeBaseRequest{
+		Name: "invalid", EmbeddingModelID: f.embedModel.ID, KnowledgeTemplate: &invalid,
+	})
+	if !errors.Is(err, ErrKnowledgeBaseInvalidTemplate) {
+		t.Fatalf("Expected ErrKnowledgeBaseInvalidTemplate, got %v", err)
+	}
+
+	custom := "{{range .Results}}{{.Content}}\n{{end}}"
+	kb, err := uc.CreateKnowledgeBase(ctx, userID, &CreateKnowledgeBaseRequest{
+		Name: "custom", EmbeddingModelID: f.embedModel.ID, KnowledgeTemplate: &custom,
+	})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase failed: %v", err)
+	}
+	if kb.KnowledgeTemplate == nil || *kb.KnowledgeTemplate != custom {
+		t.Fatalf("Expected custom template to be stored, got %v", kb.KnowledgeTemplate)
+	}
+
+	// 传空字符串恢复全局模板
+	empty := ""
+	kb, err = uc.UpdateKnowledgeBase(ctx, kb.ID, userID, &UpdateKnowledgeBaseRequest{KnowledgeTemplate: &empty})
+	if err != nil {
+		t.Fatalf("UpdateKnowledgeBase failed: %v", err)
+	}
+	if kb.KnowledgeTemplate != nil {
+		t.Errorf("Expected template to be cleared, got %q", *kb.KnowledgeTemplate)
+	}
+
+	if _, err := uc.UpdateKnowledgeBase(ctx, kb.ID, userID, &UpdateKnowledgeBaseRequest{KnowledgeTemplate: &invalid}); !errors.Is(err, ErrKnowledgeBaseInvalidTemplate) {
+		t.Errorf("Expected ErrKnowledgeBaseInvalidTemplate on update, got %v", err)
+	}
+}
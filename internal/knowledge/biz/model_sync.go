@@ -3,9 +3,11 @@ package biz
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 )
 
 // ModelSyncLog 模型同步日志
@@ -37,6 +39,7 @@ type ModelSyncRequest struct {
 	ProviderID string
 	SyncedBy   string // 同步操作者
 	SyncType   string // manual, scheduled
+	DryRun     bool   // 仅计算差异，不写入模型和同步日志
 }
 
 // ModelSyncResult 模型同步结果
@@ -44,14 +47,46 @@ type ModelSyncResult struct {
 	NewModels        []*AIModel
 	DeprecatedModels []*AIModel
 	UpdatedModels    []*AIModel
+	Changes          map[string][]*ModelFieldChange // model_name -> 字段变更（仅更新的模型）
 	Errors           []error
 }
 
+// ModelFieldChange 模型单个字段的变更
+type ModelFieldChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+const (
+	// defaultModelSyncTimeout 单个服务商获取最新模型列表（含 embedding 维度探测）的默认超时
+	defaultModelSyncTimeout = 2 * time.Minute
+	// defaultDimensionProbeConcurrency 同步时 embedding 维度探测的默认并发数
+	defaultDimensionProbeConcurrency = 4
+)
+
+// ModelSyncConfig 模型同步配置
+type ModelSyncConfig struct {
+	Timeout                   time.Duration     // 单个服务商同步的截止时间（0 使用默认值）；调用方 ctx 的截止时间更早时以 ctx 为准
+	DimensionProbeConcurrency int               // 同步时 embedding 维度探测的并发数（0 使用默认值）
+	EmbeddingPayloadFormats   map[string]string // provider_type -> Embedding 请求格式（覆盖默认映射）
+}
+
 // ModelSyncUseCase 模型同步用例
 type ModelSyncUseCase struct {
 	aiProviderRepo AIProviderRepo
 	aiModelRepo    AIModelRepo
 	syncLogRepo    ModelSyncLogRepo
+
+	httpClient  *http.Client  // 不设置固定超时，由请求 ctx 控制截止时间与取消
+	syncTimeout time.Duration // 单个服务商同步的截止时间
+
+	verifyConcurrency int           // 批量验证并发数
+	verifyInterval    time.Duration // 批量验证请求间隔（限速）
+
+	dimensionProbes           singleflight.Group // 合并并发的相同 embedding 维度探测请求
+	dimensionProbeConcurrency int                // 同步时 embedding 维度探测并发数（请求间隔与批量验证共用 verifyInterval）
+	embeddingPayloadFormats   map[string]string  // provider_type -> Embedding 请求格式
 }
 
 // NewModelSyncUseCase 创建模型同步用例
@@ -59,11 +94,34 @@ func NewModelSyncUseCase(
 	aiProviderRepo AIProviderRepo,
 	aiModelRepo AIModelRepo,
 	syncLogRepo ModelSyncLogRepo,
+	cfg *ModelSyncConfig,
 ) *ModelSyncUseCase {
+	syncTimeout := defaultModelSyncTimeout
+	if cfg != nil && cfg.Timeout > 0 {
+		syncTimeout = cfg.Timeout
+	}
+	probeConcurrency := defaultDimensionProbeConcurrency
+	if cfg != nil && cfg.DimensionProbeConcurrency > 0 {
+		probeConcurrency = cfg.DimensionProbeConcurrency
+	}
+	var payloadFormats map[string]string
+	if cfg != nil {
+		payloadFormats = cfg.EmbeddingPayloadFormats
+	}
+
 	return &ModelSyncUseCase{
 		aiProviderRepo: aiProviderRepo,
 		aiModelRepo:    aiModelRepo,
 		syncLogRepo:    syncLogRepo,
+
+		httpClient:  &http.Client{},
+		syncTimeout: syncTimeout,
+
+		verifyConcurrency: defaultVerifyConcurrency,
+		verifyInterval:    defaultVerifyInterval,
+
+		dimensionProbeConcurrency: probeConcurrency,
+		embeddingPayloadFormats:   payloadFormats,
 	}
 }
 
@@ -81,14 +139,19 @@ func (uc *ModelSyncUseCase) SyncProviderModels(ctx context.Context, req *ModelSy
 		return nil, fmt.Errorf("failed to list current models: %w", err)
 	}
 
-	// 获取最新的模型列表（根据不同 Provider 调用不同的实现）
-	latestModels, err := uc.fetchLatestModels(ctx, provider)
+	// 获取最新的模型列表（根据不同 Provider 调用不同的实现），受同步截止时间约束
+	fetchCtx, cancel := context.WithTimeout(ctx, uc.syncTimeout)
+	defer cancel()
+	latestModels, err := uc.fetchLatestModels(fetchCtx, provider)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch latest models: %w", err)
 	}
 
 	// 对比模型列表，找出新增、更新、弃用的模型
 	result := uc.compareModels(currentModels, latestModels)
+	if req.DryRun {
+		return result, nil
+	}
 
 	// 应用变更到数据库
 	if err := uc.applyChanges(ctx, result); err != nil {
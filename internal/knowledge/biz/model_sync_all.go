@@ -0,0 +1,118 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// 跨服务商同步的失败处理模式
+const (
+	SyncFailureModeContinue = "continue" // 单个服务商失败后继续同步其余服务商，收集各自的错误（默认）
+	SyncFailureModeStop     = "stop"     // 遇到首个失败的服务商即停止，其余服务商标记为 skipped
+)
+
+// 单个服务商的同步状态
+const (
+	ProviderSyncStatusSuccess = "success"
+	ProviderSyncStatusFailed  = "failed"
+	ProviderSyncStatusSkipped = "skipped"
+)
+
+// SyncAllProvidersRequest 同步所有服务商模型请求
+type SyncAllProvidersRequest struct {
+	SyncedBy    string // 同步操作者
+	SyncType    string // manual, scheduled
+	FailureMode string // continue（默认）、stop
+}
+
+// ProviderSyncOutcome 单个服务商的同步结果
+type ProviderSyncOutcome struct {
+	ProviderID   string
+	ProviderName string
+	Status       string           // success, failed, skipped
+	Result       *ModelSyncResult // 仅 success 时有值
+	Error        error            // failed 时为同步错误；skipped 时说明跳过原因
+}
+
+// SyncAllProvidersResult 同步所有服务商模型的汇总结果
+type SyncAllProvidersResult struct {
+	Outcomes     []*ProviderSyncOutcome
+	SuccessCount int
+	FailedCount  int
+	SkippedCount int
+	Stopped      bool // 是否因 stop 模式提前停止
+}
+
+// HasFailures 是否存在同步失败的服务商
+func (r *SyncAllProvidersResult) HasFailures() bool {
+	return r.FailedCount > 0
+}
+
+// SyncAllProviders 依次同步所有已启用服务商的模型
+// 未启用的服务商标记为 skipped；单个服务商失败时按 FailureMode 决定继续或停止，两种模式都返回每个服务商的结果
+func (uc *ModelSyncUseCase) SyncAllProviders(ctx context.Context, req *SyncAllProvidersRequest) (*SyncAllProvidersResult, error) {
+	failureMode := req.FailureMode
+	if failureMode == "" {
+		failureMode = SyncFailureModeContinue
+	}
+	if failureMode != SyncFailureModeContinue && failureMode != SyncFailureModeStop {
+		return nil, fmt.Errorf("invalid failure mode: %s", failureMode)
+	}
+
+	providers, err := uc.aiProviderRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list providers: %w", err)
+	}
+
+	// 按名称排序保证同步顺序稳定
+	sort.Slice(providers, func(i, j int) bool {
+		if providers[i].ProviderName != providers[j].ProviderName {
+			return providers[i].ProviderName < providers[j].ProviderName
+		}
+		return providers[i].ID < providers[j].ID
+	})
+
+	result := &SyncAllProvidersResult{Outcomes: make([]*ProviderSyncOutcome, 0, len(providers))}
+	for _, provider := range providers {
+		outcome := &ProviderSyncOutcome{
+			ProviderID:   provider.ID,
+			ProviderName: provider.ProviderName,
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+
+		switch {
+		case result.Stopped:
+			outcome.Status = ProviderSyncStatusSkipped
+			outcome.Error = fmt.Errorf("sync stopped after an earlier provider failed")
+		case !provider.IsEnabled:
+			outcome.Status = ProviderSyncStatusSkipped
+			outcome.Error = fmt.Errorf("provider %s is disabled", provider.ProviderName)
+		default:
+			syncResult, err := uc.SyncProviderModels(ctx, &ModelSyncRequest{
+				ProviderID: provider.ID,
+				SyncedBy:   req.SyncedBy,
+				SyncType:   req.SyncType,
+			})
+			if err != nil {
+				outcome.Status = ProviderSyncStatusFailed
+				outcome.Error = err
+				result.Stopped = failureMode == SyncFailureModeStop
+			} else {
+				outcome.Status = ProviderSyncStatusSuccess
+				outcome.Result = syncResult
+			}
+		}
+
+		switch outcome.Status {
+		case ProviderSyncStatusSuccess:
+			result.SuccessCount++
+		case ProviderSyncStatusFailed:
+			result.FailedCount++
+		case ProviderSyncStatusSkipped:
+			result.SkippedCount++
+		}
+	}
+
+	return result, nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 )
 
 // fetchLatestModels 从 AI 服务商 API 获取最新模型列表
@@ -103,13 +104,8 @@ func (uc *ModelSyncUseCase) fetchSiliconFlowModels(ctx context.Context, provider
 					UpdatedAt:               now,
 				}
 
-				// 根据能力类型设置特定字段
-				if st.capabilityType == CapabilityTypeEmbedding {
-					// 获取 embedding 维度
-					if dim, err := uc.getEmbeddingDimensions(ctx, provider, m.ID); err == nil {
-						model.EmbeddingDimensions = &dim
-					}
-				} else if st.capabilityType == CapabilityTypeChat {
+				// 根据能力类型设置特定字段（embedding 维度在聚合完成后统一并发探测）
+				if st.capabilityType == CapabilityTypeChat {
 					// Chat 模型默认支持流式
 					model.SupportsStream = true
 					// 推断其他能力
@@ -125,10 +121,17 @@ func (uc *ModelSyncUseCase) fetchSiliconFlowModels(ctx context.Context, provider
 
 	// 转换为数组
 	models := make([]*AIModel, 0, len(modelMap))
+	var embeddingModels []*AIModel
 	for _, model := range modelMap {
 		models = append(models, model)
+		if supportsEmbedding(model) {
+			embeddingModels = append(embeddingModels, model)
+		}
 	}
 
+	// 获取 embedding 维度
+	uc.populateEmbeddingDimensions(ctx, provider, embeddingModels)
+
 	return models, nil
 }
 
@@ -144,8 +147,7 @@ func (uc *ModelSyncUseCase) fetchSiliconFlowModelsBySubType(ctx context.Context,
 	req.Header.Set("Authorization", "Bearer "+provider.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := uc.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
@@ -175,8 +177,7 @@ func (uc *ModelSyncUseCase) fetchAnthropicModels(ctx context.Context, provider *
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("anthropic-version", "2023-06-01")
 
-		client := &http.Client{Timeout: 30 * time.Second}
-		resp, err := client.Do(req)
+		resp, err := uc.httpClient.Do(req)
 		if err == nil && resp.StatusCode == http.StatusOK {
 			defer resp.Body.Close()
 
@@ -231,6 +232,11 @@ func (uc *ModelSyncUseCase) fetchAnthropicModels(ctx context.Context, provider *
 		}
 	}
 
+	// 调用方取消或超时时直接返回，不回退到预定义列表
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// API 调用失败，使用官方预定义列表
 	// https://docs.anthropic.com/en/docs/about-claude/models
 	now := time.Now()
@@ -316,8 +322,7 @@ func (uc *ModelSyncUseCase) fetchZhipuModels(ctx context.Context, provider *AIPr
 	req.Header.Set("Authorization", "Bearer "+provider.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := uc.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
@@ -366,54 +371,68 @@ func (uc *ModelSyncUseCase) fetchZhipuModels(ctx context.Context, provider *AIPr
 	return models, nil
 }
 
-// getEmbeddingDimensions 通过测试调用获取 embedding 维度
-func (uc *ModelSyncUseCase) getEmbeddingDimensions(ctx context.Context, provider *AIProvider, modelName string) (int, error) {
-	url := provider.APIBaseURL + "/embeddings"
+// populateEmbeddingDimensions 有界并发地探测 embedding 模型的维度（按 verifyInterval 限速），探测失败的模型不设置维度
+func (uc *ModelSyncUseCase) populateEmbeddingDimensions(ctx context.Context, provider *AIProvider, models []*AIModel) {
+	concurrency := uc.dimensionProbeConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultDimensionProbeConcurrency
+	}
 
-	requestBody := map[string]interface{}{
-		"model": modelName,
-		"input": "hi",
+	// 限速：每个 tick 放行一次请求
+	var ticker *time.Ticker
+	if uc.verifyInterval > 0 {
+		ticker = time.NewTicker(uc.verifyInterval)
+		defer ticker.Stop()
 	}
 
-	bodyBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		return 0, err
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for i, model := range models {
+		if ticker != nil && i > 0 {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		g.Go(func() error {
+			if dim, err := uc.getEmbeddingDimensions(ctx, provider, model.ModelName); err == nil {
+				model.EmbeddingDimensions = &dim
+			}
+			return nil
+		})
 	}
+	_ = g.Wait()
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+// getEmbeddingDimensions 获取 embedding 维度（相同 provider + model 的并发调用共享一次探测结果）
+func (uc *ModelSyncUseCase) getEmbeddingDimensions(ctx context.Context, provider *AIProvider, modelName string) (int, error) {
+	key := provider.ID + "/" + modelName
+	dim, err, _ := uc.dimensionProbes.Do(key, func() (interface{}, error) {
+		return uc.probeEmbeddingDimensions(ctx, provider, modelName)
+	})
 	if err != nil {
 		return 0, err
 	}
+	return dim.(int), nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+provider.APIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+// probeEmbeddingDimensions 通过测试调用获取 embedding 维度（按服务商类型选择请求格式）
+func (uc *ModelSyncUseCase) probeEmbeddingDimensions(ctx context.Context, provider *AIProvider, modelName string) (int, error) {
+	adapter, err := EmbeddingPayloadAdapterFor(provider.ProviderType, uc.embeddingPayloadFormats)
 	if err != nil {
 		return 0, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	var result struct {
-		Data []struct {
-			Embedding []float64 `json:"embedding"`
-		} `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	embeddings, err := RequestEmbeddings(ctx, uc.httpClient, adapter, provider.APIBaseURL, provider.APIKey, modelName, []string{"hi"})
+	if err != nil {
 		return 0, err
 	}
 
-	if len(result.Data) == 0 || len(result.Data[0].Embedding) == 0 {
-		return 0, fmt.Errorf("no embedding data returned")
-	}
-
-	return len(result.Data[0].Embedding), nil
+	return len(embeddings[0]), nil
 }
 
 // contains 字符串包含（不区分大小写）
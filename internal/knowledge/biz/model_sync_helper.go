@@ -2,6 +2,9 @@ package biz
 
 import (
 	"context"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // compareModels 对比当前模型和最新模型列表
@@ -10,6 +13,7 @@ func (uc *ModelSyncUseCase) compareModels(current, latest []*AIModel) *ModelSync
 		NewModels:        []*AIModel{},
 		DeprecatedModels: []*AIModel{},
 		UpdatedModels:    []*AIModel{},
+		Changes:          map[string][]*ModelFieldChange{},
 		Errors:           []error{},
 	}
 
@@ -42,45 +46,11 @@ func (uc *ModelSyncUseCase) compareModels(current, latest []*AIModel) *ModelSync
 	// 找出需要更新的模型（比较 max_tokens 等字段）
 	for _, latest := range latestMap {
 		if current, exists := currentMap[latest.ModelName]; exists {
-			needUpdate := false
-
-			// 比较 max_tokens
-			if latest.MaxTokens != nil && current.MaxTokens != nil {
-				if *latest.MaxTokens != *current.MaxTokens {
-					needUpdate = true
-				}
-			} else if (latest.MaxTokens == nil) != (current.MaxTokens == nil) {
-				needUpdate = true
-			}
-
-			// 比较 display_name
-			if latest.DisplayName != current.DisplayName {
-				needUpdate = true
-			}
-
-			// 比较 capabilities
-			if !capabilitiesEqual(current.Capabilities, latest.Capabilities) {
-				needUpdate = true
-			}
-
-			// 比较能力标志
-			if current.SupportsStream != latest.SupportsStream ||
-				current.SupportsVision != latest.SupportsVision ||
-				current.SupportsFunctionCalling != latest.SupportsFunctionCalling ||
-				current.SupportsReasoning != latest.SupportsReasoning ||
-				current.SupportsWebSearch != latest.SupportsWebSearch {
-				needUpdate = true
-			}
-
-			// 比较 embedding_dimensions
-			if !embeddingDimensionsEqual(current.EmbeddingDimensions, latest.EmbeddingDimensions) {
-				needUpdate = true
-			}
-
-			if needUpdate {
+			if changes := diffModelFields(current, latest); len(changes) > 0 {
 				// 保留原 ID，更新字段
 				latest.ID = current.ID
 				result.UpdatedModels = append(result.UpdatedModels, latest)
+				result.Changes[latest.ModelName] = changes
 			}
 		}
 	}
@@ -88,6 +58,49 @@ func (uc *ModelSyncUseCase) compareModels(current, latest []*AIModel) *ModelSync
 	return result
 }
 
+// diffModelFields 比较模型字段（max_tokens、display_name、能力等），返回发生变化的字段
+func diffModelFields(current, latest *AIModel) []*ModelFieldChange {
+	var changes []*ModelFieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &ModelFieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+
+	add("max_tokens", formatIntPtr(current.MaxTokens), formatIntPtr(latest.MaxTokens))
+	add("display_name", current.DisplayName, latest.DisplayName)
+	if !capabilitiesEqual(current.Capabilities, latest.Capabilities) {
+		changes = append(changes, &ModelFieldChange{
+			Field:    "capabilities",
+			OldValue: formatCapabilities(current.Capabilities),
+			NewValue: formatCapabilities(latest.Capabilities),
+		})
+	}
+	add("supports_stream", strconv.FormatBool(current.SupportsStream), strconv.FormatBool(latest.SupportsStream))
+	add("supports_vision", strconv.FormatBool(current.SupportsVision), strconv.FormatBool(latest.SupportsVision))
+	add("supports_function_calling", strconv.FormatBool(current.SupportsFunctionCalling), strconv.FormatBool(latest.SupportsFunctionCalling))
+	add("supports_reasoning", strconv.FormatBool(current.SupportsReasoning), strconv.FormatBool(latest.SupportsReasoning))
+	add("supports_web_search", strconv.FormatBool(current.SupportsWebSearch), strconv.FormatBool(latest.SupportsWebSearch))
+	add("embedding_dimensions", formatIntPtr(current.EmbeddingDimensions), formatIntPtr(latest.EmbeddingDimensions))
+
+	return changes
+}
+
+// formatIntPtr 格式化可空整数（nil 为空字符串）
+func formatIntPtr(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// formatCapabilities 按字母序拼接能力类型（与顺序无关）
+func formatCapabilities(capabilities []string) string {
+	sorted := append([]string(nil), capabilities...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
 // applyChanges 应用变更到数据库
 func (uc *ModelSyncUseCase) applyChanges(ctx context.Context, result *ModelSyncResult) error {
 	// 插入新模型（能力已包含在模型中，不需要单独插入）
@@ -141,14 +154,3 @@ func capabilitiesEqual(a, b []string) bool {
 
 	return true
 }
-
-// embeddingDimensionsEqual 比较两个 embedding dimensions 是否相等
-func embeddingDimensionsEqual(a, b *int) bool {
-	if a == nil && b == nil {
-		return true
-	}
-	if a == nil || b == nil {
-		return false
-	}
-	return *a == *b
-}
@@ -0,0 +1,321 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetEmbeddingDimensions_DeduplicatesConcurrentProbes(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"embedding":[0.1,0.2,0.3]}]}`))
+	}))
+	defer server.Close()
+
+	provider := &AIProvider{ID: "provider-1", ProviderType: "siliconflow", APIKey: "key", APIBaseURL: server.URL}
+	uc := NewModelSyncUseCase(nil, nil, nil, nil)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	dims := make([]int, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dims[i], errs[i] = uc.getEmbeddingDimensions(context.Background(), provider, "bge-m3")
+		}(i)
+	}
+
+	// 等待首个探测请求到达后再放行，保证其余调用方加入同一次探测
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&calls) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 underlying HTTP call, got %d", got)
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Caller %d failed: %v", i, errs[i])
+		}
+		if dims[i] != 3 {
+			t.Errorf("Caller %d: expected dimension 3, got %d", i, dims[i])
+		}
+	}
+}
+
+type fakeModelSyncLogRepo struct {
+	mu   sync.Mutex
+	logs []*ModelSyncLog
+}
+
+func (r *fakeModelSyncLogRepo) Create(ctx context.Context, log *ModelSyncLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *fakeModelSyncLogRepo) ListByProviderID(ctx context.Context, providerID string, limit int) ([]*ModelSyncLog, error) {
+	return nil, nil
+}
+
+func (r *fakeModelSyncLogRepo) GetLatest(ctx context.Context, providerID string) (*ModelSyncLog, error) {
+	return nil, nil
+}
+
+func TestSyncAllProviders_FailureMode(t *testing.T) {
+	var mu sync.Mutex
+	var calledKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calledKeys = append(calledKeys, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer server.Close()
+
+	newProviders := func() *fakeAIProviderRepo {
+		return &fakeAIProviderRepo{providers: map[string]*AIProvider{
+			"p-a": {ID: "p-a", ProviderName: "a", ProviderType: "siliconflow", APIKey: "key-a", APIBaseURL: server.URL, IsEnabled: true},
+			"p-b": {ID: "p-b", ProviderName: "b", ProviderType: "unsupported", APIKey: "key-b", APIBaseURL: server.URL, IsEnabled: true},
+			"p-c": {ID: "p-c", ProviderName: "c", ProviderType: "siliconflow", APIKey: "key-c", APIBaseURL: server.URL, IsEnabled: true},
+			"p-d": {ID: "p-d", ProviderName: "d", ProviderType: "siliconflow", APIKey: "key-d", APIBaseURL: server.URL, IsEnabled: false},
+		}}
+	}
+
+	tests := []struct {
+		name         string
+		mode         string
+		wantStatuses []string
+		wantKeys     map[string]bool
+	}{
+		{
+			name: "stop on first error",
+			mode: SyncFailureModeStop,
+			wantStatuses: []string{
+				ProviderSyncStatusSuccess, ProviderSyncStatusFailed, ProviderSyncStatusSkipped, ProviderSyncStatusSkipped,
+			},
+			wantKeys: map[string]bool{"Bearer key-a": true},
+		},
+		{
+			name: "continue on error",
+			mode: SyncFailureModeContinue,
+			wantStatuses: []string{
+				ProviderSyncStatusSuccess, ProviderSyncStatusFailed, ProviderSyncStatusSuccess, ProviderSyncStatusSkipped,
+			},
+			wantKeys: map[string]bool{"Bearer key-a": true, "Bearer key-c": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			calledKeys = nil
+			mu.Unlock()
+
+			uc := NewModelSyncUseCase(newProviders(), &fakeAIModelRepo{models: map[string]*AIModel{}}, &fakeModelSyncLogRepo{}, nil)
+			result, err := uc.SyncAllProviders(context.Background(), &SyncAllProvidersRequest{SyncType: "manual", FailureMode: tt.mode})
+			if err != nil {
+				t.Fatalf("SyncAllProviders failed: %v", err)
+			}
+
+			if len(result.Outcomes) != len(tt.wantStatuses) {
+				t.Fatalf("Expected %d outcomes, got %d", len(tt.wantStatuses), len(result.Outcomes))
+			}
+			for i, want := range tt.wantStatuses {
+				if got := result.Outcomes[i]; got.Status != want {
+					t.Errorf("Provider %s: expected status %s, got %s (%v)", got.ProviderName, want, got.Status, got.Error)
+				}
+			}
+			if !result.HasFailures() || result.Outcomes[1].Error == nil {
+				t.Error("Expected the failing provider's error to be reported")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			called := make(map[string]bool)
+			for _, key := range calledKeys {
+				if !tt.wantKeys[key] {
+					t.Errorf("Unexpected API call with %s", key)
+				}
+				called[key] = true
+			}
+			if len(called) != len(tt.wantKeys) {
+				t.Errorf("Expected API calls for %v, got %v", tt.wantKeys, called)
+			}
+		})
+	}
+}
+
+// newHangingServer 返回一个在客户端断开前不响应的服务器，started 在首个请求到达时关闭
+func newHangingServer(t *testing.T) (*httptest.Server, <-chan struct{}) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	// 先放行挂起的请求再关闭服务器（Cleanup 按注册的逆序执行）
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	return server, started
+}
+
+func TestFetchModels_CancelledContextReturnsPromptly(t *testing.T) {
+	uc := NewModelSyncUseCase(nil, nil, nil, nil)
+
+	fetchers := map[string]func(ctx context.Context, provider *AIProvider) error{
+		"siliconflow": func(ctx context.Context, provider *AIProvider) error {
+			_, err := uc.fetchSiliconFlowModelsBySubType(ctx, provider, "chat")
+			return err
+		},
+		"anthropic": func(ctx context.Context, provider *AIProvider) error {
+			_, err := uc.fetchAnthropicModels(ctx, provider)
+			return err
+		},
+		"zhipu": func(ctx context.Context, provider *AIProvider) error {
+			_, err := uc.fetchZhipuModels(ctx, provider)
+			return err
+		},
+		"embedding dimensions": func(ctx context.Context, provider *AIProvider) error {
+			_, err := uc.probeEmbeddingDimensions(ctx, provider, "bge-m3")
+			return err
+		},
+	}
+
+	for name, fetch := range fetchers {
+		t.Run(name, func(t *testing.T) {
+			// 请求到达服务器后再取消，验证进行中的请求被中断
+			server, started := newHangingServer(t)
+			provider := &AIProvider{ID: "provider-1", ProviderType: "siliconflow", APIKey: "key", APIBaseURL: server.URL}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				select {
+				case <-started:
+				case <-time.After(time.Second):
+				}
+				cancel()
+			}()
+
+			done := make(chan error, 1)
+			go func() { done <- fetch(ctx, provider) }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("Expected context.Canceled, got %v", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Fetch did not return after the context was cancelled")
+			}
+		})
+	}
+}
+
+func TestSyncProviderModels_RespectsSyncTimeout(t *testing.T) {
+	server, _ := newHangingServer(t)
+	provider := &AIProvider{ID: "provider-1", ProviderName: "sf", ProviderType: "siliconflow", APIKey: "key", APIBaseURL: server.URL, IsEnabled: true}
+	uc := NewModelSyncUseCase(
+		&fakeAIProviderRepo{providers: map[string]*AIProvider{provider.ID: provider}},
+		&fakeAIModelRepo{models: map[string]*AIModel{}},
+		&fakeModelSyncLogRepo{},
+		&ModelSyncConfig{Timeout: 50 * time.Millisecond},
+	)
+
+	start := time.Now()
+	_, err := uc.SyncProviderModels(context.Background(), &ModelSyncRequest{ProviderID: provider.ID, SyncType: "manual"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected sync to stop at the configured deadline, took %v", elapsed)
+	}
+}
+
+func TestFetchSiliconFlowModels_ProbesDimensionsConcurrently(t *testing.T) {
+	const modelCount, limit = 6, 3
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/models" {
+			resp := SiliconFlowModelsResponse{Object: "list"}
+			if r.URL.Query().Get("sub_type") == "embedding" {
+				for i := 0; i < modelCount; i++ {
+					resp.Data = append(resp.Data, SiliconFlowModelData{ID: fmt.Sprintf("embed-%d", i)})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		// 保持请求进行中，使并发探测重叠
+		time.Sleep(50 * time.Millisecond)
+
+		var req struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var index int
+		_, _ = fmt.Sscanf(req.Model, "embed-%d", &index)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"embedding": make([]float64, index+1)}},
+		})
+	}))
+	defer server.Close()
+
+	provider := &AIProvider{ID: "provider-1", ProviderType: "siliconflow", APIKey: "key", APIBaseURL: server.URL}
+	uc := NewModelSyncUseCase(nil, nil, nil, &ModelSyncConfig{DimensionProbeConcurrency: limit})
+	uc.verifyInterval = 0
+
+	models, err := uc.fetchSiliconFlowModels(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("fetchSiliconFlowModels failed: %v", err)
+	}
+	if len(models) != modelCount {
+		t.Fatalf("Expected %d models, got %d", modelCount, len(models))
+	}
+	for _, model := range models {
+		var index int
+		_, _ = fmt.Sscanf(model.ModelName, "embed-%d", &index)
+		if model.EmbeddingDimensions == nil || *model.EmbeddingDimensions != index+1 {
+			t.Errorf("Model %s: expected %d dimensions, got %v", model.ModelName, index+1, model.EmbeddingDimensions)
+		}
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max != limit {
+		t.Errorf("Expected probes to run %d at a time, got max %d", limit, max)
+	}
+}
@@ -0,0 +1,199 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultVerifyConcurrency 批量验证模型的默认并发数
+	defaultVerifyConcurrency = 4
+	// defaultVerifyInterval 批量验证模型时两次请求之间的最小间隔（限速）
+	defaultVerifyInterval = 200 * time.Millisecond
+)
+
+// ModelVerifyResult 单个模型验证结果
+type ModelVerifyResult struct {
+	ModelID   string `json:"model_id"`
+	ModelName string `json:"model_name"`
+	Status    string `json:"status"` // available, deprecated, error
+	Error     string `json:"error,omitempty"`
+}
+
+// ProviderVerifySummary 服务商模型批量验证汇总
+type ProviderVerifySummary struct {
+	ProviderID       string               `json:"provider_id"`
+	TotalCount       int                  `json:"total_count"`
+	AvailableCount   int                  `json:"available_count"`
+	UnavailableCount int                  `json:"unavailable_count"`
+	Results          []*ModelVerifyResult `json:"results"`
+}
+
+// modelNotFoundError 服务商返回 404（模型已下线或不存在）
+type modelNotFoundError struct {
+	modelName string
+}
+
+func (e *modelNotFoundError) Error() string {
+	return fmt.Sprintf("model %s not found on provider", e.modelName)
+}
+
+// VerifyModel 验证单个模型是否可用，并更新 VerificationStatus/LastVerifiedAt
+func (uc *ModelSyncUseCase) VerifyModel(ctx context.Context, modelID string) (*ModelVerifyResult, error) {
+	model, err := uc.aiModelRepo.GetByID(ctx, modelID)
+	if err != nil {
+		return nil, fmt.Errorf("model not found: %w", err)
+	}
+
+	provider, err := uc.aiProviderRepo.GetByID(ctx, model.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("provider not found: %w", err)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, uc.syncTimeout)
+	defer cancel()
+	return uc.verifyAndUpdate(ctx, checkCtx, provider, model), nil
+}
+
+// VerifyProviderModels 并发（有界、限速）验证服务商下所有模型，返回可用/不可用汇总
+func (uc *ModelSyncUseCase) VerifyProviderModels(ctx context.Context, providerID string) (*ProviderVerifySummary, error) {
+	provider, err := uc.aiProviderRepo.GetByID(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("provider not found: %w", err)
+	}
+	if provider.APIKey == "" {
+		return nil, fmt.Errorf("provider %s has no API key configured", provider.ProviderName)
+	}
+
+	models, err := uc.aiModelRepo.ListByProviderID(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	concurrency := uc.verifyConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultVerifyConcurrency
+	}
+
+	// 限速：每个 tick 放行一次请求
+	var ticker *time.Ticker
+	if uc.verifyInterval > 0 {
+		ticker = time.NewTicker(uc.verifyInterval)
+		defer ticker.Stop()
+	}
+
+	// 整批验证请求受同步截止时间约束，写回验证状态仍使用调用方的 ctx
+	checkCtx, cancel := context.WithTimeout(ctx, uc.syncTimeout)
+	defer cancel()
+
+	results := make([]*ModelVerifyResult, len(models))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, model := range models {
+		if ticker != nil && i > 0 {
+			select {
+			case <-ticker.C:
+			case <-checkCtx.Done():
+			}
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-checkCtx.Done():
+			results[i] = &ModelVerifyResult{ModelID: model.ID, ModelName: model.ModelName, Status: "error", Error: checkCtx.Err().Error()}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, model *AIModel) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = uc.verifyAndUpdate(ctx, checkCtx, provider, model)
+		}(i, model)
+	}
+	wg.Wait()
+
+	summary := &ProviderVerifySummary{
+		ProviderID: providerID,
+		TotalCount: len(models),
+		Results:    results,
+	}
+	for _, result := range results {
+		if result.Status == "available" {
+			summary.AvailableCount++
+		} else {
+			summary.UnavailableCount++
+		}
+	}
+
+	return summary, nil
+}
+
+// verifyAndUpdate 调用服务商接口验证模型（checkCtx 控制请求截止时间）并写回验证状态
+func (uc *ModelSyncUseCase) verifyAndUpdate(ctx, checkCtx context.Context, provider *AIProvider, model *AIModel) *ModelVerifyResult {
+	result := &ModelVerifyResult{
+		ModelID:   model.ID,
+		ModelName: model.ModelName,
+		Status:    "available",
+	}
+
+	if err := uc.checkModelAvailable(checkCtx, provider, model.ModelName); err != nil {
+		result.Error = err.Error()
+		if _, ok := err.(*modelNotFoundError); ok {
+			result.Status = "deprecated"
+		} else {
+			result.Status = "error"
+		}
+	}
+
+	now := time.Now()
+	model.VerificationStatus = result.Status
+	model.LastVerifiedAt = &now
+	if err := uc.aiModelRepo.Update(ctx, model); err != nil {
+		result.Error = fmt.Sprintf("failed to update verification status: %v", err)
+	}
+
+	return result
+}
+
+// checkModelAvailable 通过 GET /models/{model} 检查模型是否可用
+func (uc *ModelSyncUseCase) checkModelAvailable(ctx context.Context, provider *AIProvider, modelName string) error {
+	endpoint := provider.APIBaseURL + "/models/" + url.PathEscape(modelName)
+	if provider.ProviderType == "anthropic" {
+		endpoint = provider.APIBaseURL + "/v1/models/" + url.PathEscape(modelName)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+provider.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	if provider.ProviderType == "anthropic" {
+		req.Header.Set("x-api-key", provider.APIKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}
+
+	resp, err := uc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return &modelNotFoundError{modelName: modelName}
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+}
@@ -0,0 +1,123 @@
+package biz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVerifyProviderModels(t *testing.T) {
+	available := map[string]bool{"model-ok-1": true, "model-ok-2": true}
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, "/models/")
+		switch {
+		case available[name]:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id":"` + name + `"}`))
+		case name == "model-broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := &AIProvider{ID: "provider-1", ProviderType: "siliconflow", ProviderName: "SiliconFlow", APIKey: "key", APIBaseURL: server.URL}
+	modelRepo := &fakeAIModelRepo{models: map[string]*AIModel{}}
+	for _, name := range []string{"model-ok-1", "model-ok-2", "model-gone-1", "model-gone-2", "model-broken"} {
+		modelRepo.models[name] = &AIModel{ID: name, ProviderID: provider.ID, ModelName: name, VerificationStatus: "unknown"}
+	}
+	modelRepo.models["other"] = &AIModel{ID: "other", ProviderID: "provider-2", ModelName: "other", VerificationStatus: "unknown"}
+
+	uc := NewModelSyncUseCase(&fakeAIProviderRepo{providers: map[string]*AIProvider{provider.ID: provider}}, modelRepo, nil, nil)
+	uc.verifyConcurrency = 2
+	uc.verifyInterval = 0
+
+	summary, err := uc.VerifyProviderModels(context.Background(), provider.ID)
+	if err != nil {
+		t.Fatalf("VerifyProviderModels failed: %v", err)
+	}
+
+	if summary.TotalCount != 5 || summary.AvailableCount != 2 || summary.UnavailableCount != 3 {
+		t.Errorf("Unexpected summary counts: %+v", summary)
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", max)
+	}
+
+	wantStatus := map[string]string{
+		"model-ok-1":   "available",
+		"model-ok-2":   "available",
+		"model-gone-1": "deprecated",
+		"model-gone-2": "deprecated",
+		"model-broken": "error",
+	}
+	for _, result := range summary.Results {
+		if result.Status != wantStatus[result.ModelName] {
+			t.Errorf("Model %s: expected status %s, got %s", result.ModelName, wantStatus[result.ModelName], result.Status)
+		}
+		if result.Status != "available" && result.Error == "" {
+			t.Errorf("Model %s: expected per-model error", result.ModelName)
+		}
+
+		stored := modelRepo.models[result.ModelID]
+		if stored.VerificationStatus != wantStatus[result.ModelName] {
+			t.Errorf("Model %s: expected stored status %s, got %s", result.ModelName, wantStatus[result.ModelName], stored.VerificationStatus)
+		}
+		if stored.LastVerifiedAt == nil {
+			t.Errorf("Model %s: expected LastVerifiedAt to be set", result.ModelName)
+		}
+	}
+
+	if modelRepo.models["other"].LastVerifiedAt != nil {
+		t.Error("Expected models of other providers to be untouched")
+	}
+}
+
+func TestVerifyProviderModels_RespectsSyncTimeout(t *testing.T) {
+	server, _ := newHangingServer(t)
+	provider := &AIProvider{ID: "provider-1", ProviderType: "siliconflow", ProviderName: "SiliconFlow", APIKey: "key", APIBaseURL: server.URL}
+	modelRepo := &fakeAIModelRepo{models: map[string]*AIModel{
+		"model-1": {ID: "model-1", ProviderID: provider.ID, ModelName: "model-1"},
+		"model-2": {ID: "model-2", ProviderID: provider.ID, ModelName: "model-2"},
+	}}
+	uc := NewModelSyncUseCase(&fakeAIProviderRepo{providers: map[string]*AIProvider{provider.ID: provider}}, modelRepo, nil,
+		&ModelSyncConfig{Timeout: 50 * time.Millisecond})
+	uc.verifyInterval = 0
+
+	start := time.Now()
+	summary, err := uc.VerifyProviderModels(context.Background(), provider.ID)
+	if err != nil {
+		t.Fatalf("VerifyProviderModels failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected verification to stop at the configured deadline, took %v", elapsed)
+	}
+	if summary.UnavailableCount != 2 {
+		t.Errorf("Expected both models to time out, got %+v", summary)
+	}
+	for _, result := range summary.Results {
+		if result.Status != "error" || !strings.Contains(result.Error, context.DeadlineExceeded.Error()) {
+			t.Errorf("Model %s: expected deadline error, got %s %q", result.ModelName, result.Status, result.Error)
+		}
+		// 验证超时后仍应写回状态
+		if modelRepo.models[result.ModelID].VerificationStatus != "error" {
+			t.Errorf("Model %s: expected stored status error", result.ModelName)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package biz
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// filterShortResults 丢弃内容字符数低于 MinResultContentLength 的搜索结果（未配置时原样返回）
+// 在融合之后、返回之前执行：过短的分块（如单独成块的标题）可能因关键词命中排名靠前，但对 RAG 没有实际内容
+func (uc *DocumentUseCase) filterShortResults(kbID string, results []*SearchResult) []*SearchResult {
+	minLength := uc.config.MinResultContentLength
+	if minLength <= 0 || len(results) == 0 {
+		return results
+	}
+
+	kept := make([]*SearchResult, 0, len(results))
+	for _, result := range results {
+		if utf8.RuneCountInString(strings.TrimSpace(result.Content)) >= minLength {
+			kept = append(kept, result)
+		}
+	}
+
+	if dropped := len(results) - len(kept); dropped > 0 {
+		uc.logger.Info("搜索结果内容过短，已过滤",
+			zap.String("kb_id", kbID),
+			zap.Int("min_content_length", minLength),
+			zap.Int("dropped_count", dropped))
+	}
+	return kept
+}
@@ -0,0 +1,50 @@
+package biz
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchDocuments_FiltersShortContent(t *testing.T) {
+	f := newTestFixture()
+	f.config.MinResultContentLength = 10
+	f.vectorDB.results = []*SearchResult{
+		{ChunkID: "heading", DocumentID: "doc-1", Content: "Overview", Score: 0.95},
+		{ChunkID: "body", DocumentID: "doc-1", Content: "The overview explains how the system works.", Score: 0.9},
+		{ChunkID: "padded", DocumentID: "doc-1", Content: "   短标题   ", Score: 0.85},
+		{ChunkID: "chinese", DocumentID: "doc-2", Content: "系统架构包含三个核心模块与存储层", Score: 0.8},
+	}
+
+	results, err := f.useCase.SearchDocuments(context.Background(), f.kb.ID, testUserID, "overview", 10)
+	if err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+
+	if len(results) != 2 || results[0].ChunkID != "body" || results[1].ChunkID != "chinese" {
+		t.Fatalf("Expected only long results [body chinese], got %v", resultChunkIDs(results))
+	}
+}
+
+func TestSearchDocuments_ContentFilterOffByDefault(t *testing.T) {
+	f := newTestFixture()
+	f.vectorDB.results = []*SearchResult{
+		{ChunkID: "heading", DocumentID: "doc-1", Content: "Overview", Score: 0.95},
+		{ChunkID: "body", DocumentID: "doc-1", Content: "The overview explains how the system works.", Score: 0.9},
+	}
+
+	results, err := f.useCase.SearchDocuments(context.Background(), f.kb.ID, testUserID, "overview", 10)
+	if err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected no filtering by default, got %v", resultChunkIDs(results))
+	}
+}
+
+func resultChunkIDs(results []*SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ChunkID
+	}
+	return ids
+}
@@ -0,0 +1,34 @@
+package biz
+
+import "go.uber.org/zap"
+
+// DefaultMaxSearchTopK 默认单次搜索最多返回的结果数
+const DefaultMaxSearchTopK = 100
+
+// maxSearchTopK 单次搜索的 topK 上限（未配置时使用默认值）
+func (uc *DocumentUseCase) maxSearchTopK() int {
+	if uc.config.MaxSearchTopK > 0 {
+		return uc.config.MaxSearchTopK
+	}
+	return DefaultMaxSearchTopK
+}
+
+// capTopK 将 topK 限制在上限以内（用于内部放大的候选数量，如混合检索的 2 倍召回）
+func (uc *DocumentUseCase) capTopK(topK int) int {
+	if limit := uc.maxSearchTopK(); topK > limit {
+		return limit
+	}
+	return topK
+}
+
+// clampTopK 将请求的 topK 限制在上限以内，超出时记录日志
+func (uc *DocumentUseCase) clampTopK(kbID string, topK int) int {
+	capped := uc.capTopK(topK)
+	if capped != topK {
+		uc.logger.Warn("搜索 top_k 超出上限，已截断",
+			zap.String("kb_id", kbID),
+			zap.Int("requested_top_k", topK),
+			zap.Int("max_top_k", capped))
+	}
+	return capped
+}
@@ -0,0 +1,55 @@
+package biz
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchDocuments_ClampsTopK(t *testing.T) {
+	tests := []struct {
+		name          string
+		hybrid        bool
+		requested     int
+		wantVectorK   int
+		wantKeywordK  int
+		wantKeywordOn bool
+	}{
+		{name: "vector oversized", requested: 100000, wantVectorK: 10},
+		{name: "vector within cap", requested: 7, wantVectorK: 7},
+		{name: "hybrid oversized", hybrid: true, requested: 100000, wantVectorK: 10, wantKeywordK: 10, wantKeywordOn: true},
+		{name: "hybrid multiplier capped", hybrid: true, requested: 8, wantVectorK: 10, wantKeywordK: 10, wantKeywordOn: true},
+		{name: "hybrid multiplier within cap", hybrid: true, requested: 4, wantVectorK: 8, wantKeywordK: 8, wantKeywordOn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixture()
+			f.config.MaxSearchTopK = 10
+			f.kb.EnableHybridSearch = tt.hybrid
+
+			if _, err := f.useCase.SearchDocuments(context.Background(), f.kb.ID, testUserID, "query", tt.requested); err != nil {
+				t.Fatalf("SearchDocuments failed: %v", err)
+			}
+
+			if len(f.vectorDB.topKs) != 1 || f.vectorDB.topKs[0] != tt.wantVectorK {
+				t.Errorf("Expected vector search topK %d, got %v", tt.wantVectorK, f.vectorDB.topKs)
+			}
+			if tt.wantKeywordOn && (len(f.chunkRepo.keywordTopKs) != 1 || f.chunkRepo.keywordTopKs[0] != tt.wantKeywordK) {
+				t.Errorf("Expected keyword search topK %d, got %v", tt.wantKeywordK, f.chunkRepo.keywordTopKs)
+			}
+		})
+	}
+}
+
+func TestSearchDocuments_ClampsKnowledgeBaseTopK(t *testing.T) {
+	f := newTestFixture()
+	f.config.MaxSearchTopK = 3
+	f.kb.TopK = 5
+
+	if _, err := f.useCase.SearchDocuments(context.Background(), f.kb.ID, testUserID, "query", 0); err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+	if len(f.vectorDB.topKs) != 1 || f.vectorDB.topKs[0] != 3 {
+		t.Errorf("Expected vector search topK 3, got %v", f.vectorDB.topKs)
+	}
+}
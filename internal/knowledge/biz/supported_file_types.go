@@ -0,0 +1,41 @@
+package biz
+
+import (
+	"context"
+	"sort"
+)
+
+// SupportedFileType 可上传的文件类型及其处理方式
+type SupportedFileType struct {
+	Extension   string `json:"extension"`    // 文件扩展名（小写，不含点）
+	ContentType string `json:"content_type"` // 上传时记录的 MIME 类型
+	Processor   string `json:"processor"`    // 文本提取方式：local、mineru
+}
+
+// FileTypeAwareProcessor 可声明支持的文件类型的文档处理器（可选能力）
+type FileTypeAwareProcessor interface {
+	SupportedFileTypes() map[string]string // 扩展名 -> 处理方式
+}
+
+// ListSupportedFileTypes 列出当前处理器配置下可上传的文件类型（按扩展名排序）
+// 处理器未声明支持的类型时返回空列表
+func (uc *DocumentUseCase) ListSupportedFileTypes(ctx context.Context) []*SupportedFileType {
+	aware, ok := uc.processor.(FileTypeAwareProcessor)
+	if !ok {
+		return []*SupportedFileType{}
+	}
+
+	types := aware.SupportedFileTypes()
+	result := make([]*SupportedFileType, 0, len(types))
+	for ext, processor := range types {
+		result = append(result, &SupportedFileType{
+			Extension:   ext,
+			ContentType: getContentType(ext),
+			Processor:   processor,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Extension < result[j].Extension
+	})
+	return result
+}
@@ -0,0 +1,46 @@
+package biz
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeFileTypeProcessor 声明支持文件类型的处理器
+type fakeFileTypeProcessor struct {
+	fakeProcessor
+	types map[string]string
+}
+
+func (p *fakeFileTypeProcessor) SupportedFileTypes() map[string]string {
+	return p.types
+}
+
+func TestListSupportedFileTypes_ReflectsProcessor(t *testing.T) {
+	f := newTestFixture()
+	f.withProcessor(&fakeFileTypeProcessor{types: map[string]string{
+		"txt":  "local",
+		"pdf":  "mineru",
+		"pptx": "mineru",
+	}})
+
+	types := f.useCase.ListSupportedFileTypes(context.Background())
+	want := []SupportedFileType{
+		{Extension: "pdf", ContentType: "application/pdf", Processor: "mineru"},
+		{Extension: "pptx", ContentType: "application/vnd.openxmlformats-officedocument.presentationml.presentation", Processor: "mineru"},
+		{Extension: "txt", ContentType: "text/plain", Processor: "local"},
+	}
+	if len(types) != len(want) {
+		t.Fatalf("Expected %d file types, got %d", len(want), len(types))
+	}
+	for i, w := range want {
+		if *types[i] != w {
+			t.Errorf("Index %d: expected %+v, got %+v", i, w, *types[i])
+		}
+	}
+
+	// 未声明支持类型的处理器返回空列表
+	f.withProcessor(&fakeProcessor{})
+	if types := f.useCase.ListSupportedFileTypes(context.Background()); len(types) != 0 {
+		t.Errorf("Expected no file types, got %d", len(types))
+	}
+}
@@ -0,0 +1,74 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+)
+
+// SystemKnowledgeBaseConfig 系统（官方共享）知识库的服务商绑定配置
+// 系统知识库对所有用户可见，Embedding/Rerank 模型应来自可靠的内部服务商，而不是某个用户配置的服务商密钥
+type SystemKnowledgeBaseConfig struct {
+	ProviderIDs      []string // 系统知识库允许使用的服务商 ID（为空表示不限制）
+	EmbeddingModelID string   // 创建系统知识库未指定 Embedding 模型时使用的默认模型（为空时取首个服务商的第一个 Embedding 模型）
+}
+
+// allowsProvider 服务商是否可用于系统知识库
+func (c *SystemKnowledgeBaseConfig) allowsProvider(providerID string) bool {
+	if c == nil || len(c.ProviderIDs) == 0 {
+		return true
+	}
+	for _, id := range c.ProviderIDs {
+		if id == providerID {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultSystemEmbeddingModel 返回系统知识库的默认 Embedding 模型 ID
+func (uc *KnowledgeBaseUseCase) defaultSystemEmbeddingModel(ctx context.Context) (string, error) {
+	cfg := uc.systemConfig
+	if cfg == nil {
+		return "", ErrAIProviderNotFound
+	}
+	if cfg.EmbeddingModelID != "" {
+		return cfg.EmbeddingModelID, nil
+	}
+	if len(cfg.ProviderIDs) == 0 {
+		return "", ErrAIProviderNotFound
+	}
+
+	models, err := uc.aiModelRepo.ListByProviderID(ctx, cfg.ProviderIDs[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to list system provider models: %w", err)
+	}
+	for _, model := range models {
+		if !model.IsEnabled {
+			continue
+		}
+		for _, capability := range model.Capabilities {
+			if capability == CapabilityTypeEmbedding {
+				return model.ID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("system provider %s has no enabled embedding model: %w", cfg.ProviderIDs[0], ErrAIProviderNotFound)
+}
+
+// ensureSystemProviderModels 校验系统知识库使用的模型都来自系统服务商
+func (uc *KnowledgeBaseUseCase) ensureSystemProviderModels(ctx context.Context, modelIDs ...string) error {
+	if uc.systemConfig == nil || len(uc.systemConfig.ProviderIDs) == 0 {
+		return nil
+	}
+
+	for _, modelID := range modelIDs {
+		model, err := uc.aiModelRepo.GetByID(ctx, modelID)
+		if err != nil {
+			return err
+		}
+		if !uc.systemConfig.allowsProvider(model.ProviderID) {
+			return fmt.Errorf("%w: model %s belongs to provider %s", ErrSystemProviderRequired, model.ModelName, model.ProviderID)
+		}
+	}
+	return nil
+}
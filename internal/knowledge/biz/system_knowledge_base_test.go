@@ -0,0 +1,86 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// withSystemProvider 添加系统服务商及其 Embedding 模型
+func (f *testFixture) withSystemProvider() *AIModel {
+	dims := 8
+	model := &AIModel{
+		ID:                  "model-system",
+		ProviderID:          "provider-system",
+		ModelName:           "internal-embedding",
+		IsEnabled:           true,
+		Capabilities:        []string{CapabilityTypeEmbedding},
+		EmbeddingDimensions: &dims,
+	}
+	f.modelRepo.models[model.ID] = model
+	return model
+}
+
+func TestCreateKnowledgeBase_SystemUsesConfiguredProvider(t *testing.T) {
+	f := newTestFixture()
+	systemModel := f.withSystemProvider()
+	uc := NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, nil, &SystemKnowledgeBaseConfig{
+		ProviderIDs: []string{systemModel.ProviderID},
+	}, f.vectorDB)
+	ctx := context.Background()
+
+	kb, err := uc.CreateKnowledgeBase(ctx, SystemOwnerID, &CreateKnowledgeBaseRequest{Name: "official docs"})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase failed: %v", err)
+	}
+	if kb.EmbeddingModelID != systemModel.ID {
+		t.Errorf("Expected system embedding model %s, got %s", systemModel.ID, kb.EmbeddingModelID)
+	}
+	if !kb.IsOfficial() {
+		t.Error("Expected an official knowledge base")
+	}
+
+	// 系统知识库不能使用用户服务商的模型
+	_, err = uc.CreateKnowledgeBase(ctx, SystemOwnerID, &CreateKnowledgeBaseRequest{
+		Name:             "user provider",
+		EmbeddingModelID: f.embedModel.ID,
+	})
+	if !errors.Is(err, ErrSystemProviderRequired) {
+		t.Errorf("Expected ErrSystemProviderRequired, got %v", err)
+	}
+
+	// 普通用户知识库不受限制
+	userKB, err := uc.CreateKnowledgeBase(ctx, "user-00000001", &CreateKnowledgeBaseRequest{
+		Name:             "personal",
+		EmbeddingModelID: f.embedModel.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase for user failed: %v", err)
+	}
+	if userKB.EmbeddingModelID != f.embedModel.ID {
+		t.Errorf("Expected user model %s, got %s", f.embedModel.ID, userKB.EmbeddingModelID)
+	}
+}
+
+func TestCreateKnowledgeBase_SystemDefaultEmbeddingModel(t *testing.T) {
+	f := newTestFixture()
+	systemModel := f.withSystemProvider()
+	uc := NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, nil, &SystemKnowledgeBaseConfig{
+		ProviderIDs:      []string{systemModel.ProviderID},
+		EmbeddingModelID: systemModel.ID,
+	}, f.vectorDB)
+
+	kb, err := uc.CreateKnowledgeBase(context.Background(), SystemOwnerID, &CreateKnowledgeBaseRequest{Name: "official"})
+	if err != nil {
+		t.Fatalf("CreateKnowledgeBase failed: %v", err)
+	}
+	if kb.EmbeddingModelID != systemModel.ID {
+		t.Errorf("Expected configured model %s, got %s", systemModel.ID, kb.EmbeddingModelID)
+	}
+
+	// 未配置系统服务商时，系统知识库仍需显式指定模型
+	uc = NewKnowledgeBaseUseCase(f.kbRepo, f.modelRepo, nil, nil, f.vectorDB)
+	if _, err := uc.CreateKnowledgeBase(context.Background(), SystemOwnerID, &CreateKnowledgeBaseRequest{Name: "official"}); !errors.Is(err, ErrAIProviderNotFound) {
+		t.Errorf("Expected ErrAIProviderNotFound, got %v", err)
+	}
+}
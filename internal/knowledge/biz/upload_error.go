@@ -0,0 +1,56 @@
+package biz
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 批量上传失败阶段
+const (
+	UploadStageValidation     = "validation"      // 知识库、权限、批次 ID 校验
+	UploadStageHashCheck      = "hash_check"      // 续传/重复文档/物理文件去重检查
+	UploadStageStorageUpload  = "storage_upload"  // 上传文件到 MinIO
+	UploadStageFileStorage    = "file_storage"    // 创建文件存储记录或增加引用计数
+	UploadStageDocumentCreate = "document_create" // 创建文档记录
+)
+
+// UploadStageError 带失败阶段的上传错误
+// 客户端可据此区分存储故障（storage_upload）与校验错误（validation），errors.Is/As 可继续匹配底层错误
+type UploadStageError struct {
+	Stage string
+	Err   error
+}
+
+func newUploadStageError(stage string, err error) *UploadStageError {
+	return &UploadStageError{Stage: stage, Err: err}
+}
+
+func (e *UploadStageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *UploadStageError) Unwrap() error {
+	return e.Err
+}
+
+// FailedStage 实现 sse.StagedError，批量上传的 SSE 失败事件据此附带 stage 字段
+func (e *UploadStageError) FailedStage() string {
+	return e.Stage
+}
+
+// newFailedUploadItem 根据上传错误构建失败项（未标注阶段的错误不填 Stage）
+func newFailedUploadItem(fileName string, err error) FailedUploadItem {
+	item := FailedUploadItem{
+		FileName: fileName,
+		Error:    err.Error(),
+		Cause:    err,
+	}
+
+	var stageErr *UploadStageError
+	if errors.As(err, &stageErr) {
+		item.Stage = stageErr.Stage
+		item.Error = stageErr.Err.Error()
+		item.Cause = stageErr.Err
+	}
+	return item
+}
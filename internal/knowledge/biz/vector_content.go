@@ -0,0 +1,66 @@
+package biz
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// vectorContentMaxBytes 写入 Milvus 的分块内容最大字节数
+func (uc *DocumentUseCase) vectorContentMaxBytes() int {
+	limit := uc.config.VectorContentMaxBytes
+	if limit <= 0 || limit > MilvusContentMaxBytes {
+		return MilvusContentMaxBytes
+	}
+	return limit
+}
+
+// prepareVectorChunks 返回写入 Milvus 的分块
+// 超长内容按 VectorContentPolicy 截断（返回副本，原分块保留完整内容写入数据库）或拒绝
+func (uc *DocumentUseCase) prepareVectorChunks(documentID string, chunks []*Chunk) ([]*Chunk, error) {
+	limit := uc.vectorContentMaxBytes()
+
+	var vectorChunks []*Chunk
+	for i, chunk := range chunks {
+		if len(chunk.Content) <= limit {
+			continue
+		}
+
+		if uc.config.VectorContentPolicy == VectorContentPolicyReject {
+			return nil, fmt.Errorf("%w: chunk %d is %d bytes (limit %d)", ErrChunkContentTooLong, chunk.Position, len(chunk.Content), limit)
+		}
+
+		if vectorChunks == nil {
+			vectorChunks = make([]*Chunk, len(chunks))
+			copy(vectorChunks, chunks)
+		}
+		truncated := *chunk
+		truncated.Content = truncateUTF8Bytes(chunk.Content, limit)
+		vectorChunks[i] = &truncated
+
+		uc.logger.Warn("分块内容超出向量库字段长度，已截断",
+			zap.String("document_id", documentID),
+			zap.String("chunk_id", chunk.ID),
+			zap.Int("position", chunk.Position),
+			zap.Int("original_bytes", len(chunk.Content)),
+			zap.Int("limit_bytes", limit))
+	}
+
+	if vectorChunks == nil {
+		return chunks, nil
+	}
+	return vectorChunks, nil
+}
+
+// truncateUTF8Bytes 将字符串截断到不超过 maxBytes 字节，且不切断多字节字符
+func truncateUTF8Bytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
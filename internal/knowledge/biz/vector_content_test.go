@@ -0,0 +1,66 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestProcessDocument_TruncatesOversizedVectorContent(t *testing.T) {
+	f := newTestFixture()
+	f.config.VectorContentMaxBytes = 100
+
+	// 多字节字符跨越截断位置
+	oversized := strings.Repeat("a", 99) + strings.Repeat("知", 50)
+	f.withProcessor(&fakeProcessor{text: "text", chunks: []string{"short chunk", oversized}})
+	doc := f.addDocument("doc-1", []byte("content"))
+
+	if err := f.useCase.ProcessDocument(context.Background(), doc.ID); err != nil {
+		t.Fatalf("ProcessDocument failed: %v", err)
+	}
+
+	f.vectorDB.mu.Lock()
+	vectors := f.vectorDB.vectors[f.kb.MilvusCollection]
+	f.vectorDB.mu.Unlock()
+	if len(vectors) != 2 {
+		t.Fatalf("Expected 2 vectors, got %d", len(vectors))
+	}
+	if vectors[0].Content != "short chunk" {
+		t.Errorf("Expected short chunk to be unchanged, got %q", vectors[0].Content)
+	}
+	if got := vectors[1].Content; got != strings.Repeat("a", 99) || !utf8.ValidString(got) {
+		t.Errorf("Expected content truncated to 99 bytes at a rune boundary, got %d bytes", len(got))
+	}
+
+	chunks := f.chunkRepo.chunks[doc.ID]
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks in DB, got %d", len(chunks))
+	}
+	if chunks[1].Content != oversized {
+		t.Errorf("Expected DB to keep full content (%d bytes), got %d bytes", len(oversized), len(chunks[1].Content))
+	}
+	if chunks[1].ID != vectors[1].ID {
+		t.Errorf("Expected vector and DB chunk to share ID, got %s and %s", vectors[1].ID, chunks[1].ID)
+	}
+}
+
+func TestProcessDocument_RejectsOversizedVectorContent(t *testing.T) {
+	f := newTestFixture()
+	f.config.VectorContentMaxBytes = 10
+	f.config.VectorContentPolicy = VectorContentPolicyReject
+	f.withProcessor(&fakeProcessor{text: "text", chunks: []string{"this chunk is too long"}})
+	doc := f.addDocument("doc-1", []byte("content"))
+
+	err := f.useCase.ProcessDocument(context.Background(), doc.ID)
+	if !errors.Is(err, ErrChunkContentTooLong) {
+		t.Fatalf("Expected ErrChunkContentTooLong, got %v", err)
+	}
+	if got := f.docRepo.docs[doc.ID].ProcessStatus; got != "failed" {
+		t.Errorf("Expected failed status, got %s", got)
+	}
+	if len(f.vectorDB.vectors[f.kb.MilvusCollection]) != 0 {
+		t.Error("Expected no vectors to be inserted")
+	}
+}
@@ -0,0 +1,212 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// rebuildDocumentsPageSize 重建向量时分页读取文档的页大小
+const rebuildDocumentsPageSize = 200
+
+// RebuildVectorsOptions 重建向量索引选项
+type RebuildVectorsOptions struct {
+	ResumeAfterDocumentID string                       // 非空时从该文档之后继续（文档按 ID 排序），不重建 collection
+	Progress              func(RebuildVectorsProgress) // 每完成一个文档回调一次
+}
+
+// RebuildVectorsProgress 重建进度
+type RebuildVectorsProgress struct {
+	KnowledgeBaseID    string
+	DocumentID         string // 刚完成的文档 ID（可作为断点续传位置）
+	ProcessedDocuments int
+	TotalDocuments     int
+	InsertedVectors    int
+}
+
+// RebuildVectorsResult 重建结果
+type RebuildVectorsResult struct {
+	KnowledgeBaseID    string
+	ProcessedDocuments int
+	SkippedDocuments   int // 断点之前已完成的文档数
+	InsertedVectors    int
+}
+
+// ListKnowledgeBaseIDs 列出所有知识库 ID（运维任务使用，不做权限过滤）
+func (uc *DocumentUseCase) ListKnowledgeBaseIDs(ctx context.Context) ([]string, error) {
+	return uc.kbRepo.ListIDs(ctx)
+}
+
+// RebuildVectors 使用数据库中保存的分块重建知识库的 Milvus 向量（Milvus 数据丢失时的恢复操作，无需重新提取文件）
+// 按知识库模型重新生成 Embedding；全新重建时先删除并重建 collection，断点续传时只补写剩余文档
+func (uc *DocumentUseCase) RebuildVectors(ctx context.Context, kbID string, opts *RebuildVectorsOptions) (*RebuildVectorsResult, error) {
+	if opts == nil {
+		opts = &RebuildVectorsOptions{}
+	}
+
+	kb, err := uc.kbRepo.GetByID(ctx, kbID, "")
+	if err != nil {
+		return nil, fmt.Errorf("knowledge base not found: %w", err)
+	}
+
+	docs, err := uc.listAllDocuments(ctx, kbID)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make(map[string]*AIModel)
+	providers := make(map[string]*AIProvider)
+	resolve := func(modelID string) (*AIModel, *AIProvider, error) {
+		if model, ok := models[modelID]; ok {
+			return model, providers[modelID], nil
+		}
+		model, err := uc.aiModelRepo.GetByID(ctx, modelID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("AI model not found: %w", err)
+		}
+		if !supportsEmbedding(model) {
+			return nil, nil, fmt.Errorf("model does not support embedding: %s", model.ModelName)
+		}
+		if model.EmbeddingDimensions == nil || *model.EmbeddingDimensions == 0 {
+			return nil, nil, fmt.Errorf("embedding dimensions not configured for model: %s", model.ModelName)
+		}
+		provider, err := uc.aiProviderRepo.GetByID(ctx, model.ProviderID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("AI provider not found: %w", err)
+		}
+		models[modelID] = model
+		providers[modelID] = provider
+		return model, provider, nil
+	}
+
+	// 重建 collection：全新重建时先删除残留数据，断点续传时 CreateCollection 幂等
+	for _, target := range kb.EmbeddingTargets() {
+		model, _, err := resolve(target.EmbeddingModelID)
+		if err != nil {
+			return nil, err
+		}
+		if opts.ResumeAfterDocumentID == "" {
+			if err := uc.vectorDB.DropCollection(ctx, target.MilvusCollection); err != nil {
+				return nil, fmt.Errorf("failed to drop collection: %w", err)
+			}
+		}
+		if err := uc.vectorDB.CreateCollection(ctx, target.MilvusCollection, *model.EmbeddingDimensions); err != nil {
+			return nil, fmt.Errorf("failed to create collection: %w", err)
+		}
+	}
+
+	result := &RebuildVectorsResult{KnowledgeBaseID: kbID}
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if opts.ResumeAfterDocumentID != "" && doc.ID <= opts.ResumeAfterDocumentID {
+			result.SkippedDocuments++
+			continue
+		}
+
+		inserted, err := uc.rebuildDocumentVectors(ctx, kb, doc, resolve, opts.ResumeAfterDocumentID != "")
+		if err != nil {
+			return result, fmt.Errorf("failed to rebuild document %s: %w", doc.ID, err)
+		}
+		result.ProcessedDocuments++
+		result.InsertedVectors += inserted
+
+		if opts.Progress != nil {
+			opts.Progress(RebuildVectorsProgress{
+				KnowledgeBaseID:    kbID,
+				DocumentID:         doc.ID,
+				ProcessedDocuments: result.SkippedDocuments + result.ProcessedDocuments,
+				TotalDocuments:     len(docs),
+				InsertedVectors:    result.InsertedVectors,
+			})
+		}
+	}
+
+	uc.logger.Info("向量重建完成",
+		zap.String("kb_id", kbID),
+		zap.Int("documents", result.ProcessedDocuments),
+		zap.Int("skipped", result.SkippedDocuments),
+		zap.Int("vectors", result.InsertedVectors))
+
+	return result, nil
+}
+
+// rebuildDocumentVectors 重新生成单个文档所有分块的向量并写入 Milvus，返回写入的向量数
+// 始终使用知识库配置的模型（不路由到备用模型），保证重建后的向量与查询向量在同一向量空间；
+// 断点续传时中断的文档可能已写入部分向量，先按文档删除再写入
+func (uc *DocumentUseCase) rebuildDocumentVectors(
+	ctx context.Context,
+	kb *KnowledgeBase,
+	doc *Document,
+	resolve func(modelID string) (*AIModel, *AIProvider, error),
+	resuming bool,
+) (int, error) {
+	chunks, err := uc.chunkRepo.GetByDocumentID(ctx, doc.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	target := kb.EmbeddingTargetFor(doc.FileType)
+	model, provider, err := resolve(target.EmbeddingModelID)
+	if err != nil {
+		return 0, err
+	}
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Content
+	}
+
+	embeddings, err := uc.embedder.GenerateEmbeddings(ctx, texts, provider, model)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	if len(embeddings) != len(chunks) {
+		return 0, fmt.Errorf("expected %d embeddings, got %d", len(chunks), len(embeddings))
+	}
+	for i, embedding := range embeddings {
+		if len(embedding) != *model.EmbeddingDimensions {
+			return 0, fmt.Errorf("embedding model %s returned %d dimensions, expected %d", model.ModelName, len(embedding), *model.EmbeddingDimensions)
+		}
+		chunks[i].Embedding = embedding
+	}
+
+	vectorChunks, err := uc.prepareVectorChunks(doc.ID, chunks)
+	if err != nil {
+		return 0, err
+	}
+	if resuming {
+		if err := uc.vectorDB.DeleteByDocumentID(ctx, target.MilvusCollection, doc.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete existing vectors: %w", err)
+		}
+	}
+	if err := uc.vectorDB.InsertVectors(ctx, target.MilvusCollection, vectorChunks); err != nil {
+		return 0, fmt.Errorf("failed to insert vectors: %w", err)
+	}
+
+	return len(vectorChunks), nil
+}
+
+// listAllDocuments 分页读取知识库所有文档，按 ID 排序保证断点续传顺序稳定
+func (uc *DocumentUseCase) listAllDocuments(ctx context.Context, kbID string) ([]*Document, error) {
+	var docs []*Document
+	for page := 1; ; page++ {
+		batch, total, err := uc.DocumentRepo.List(ctx, kbID, &ListDocumentsRequest{Page: page, PageSize: rebuildDocumentsPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list documents: %w", err)
+		}
+		docs = append(docs, batch...)
+		if len(batch) == 0 || int64(len(docs)) >= total {
+			break
+		}
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs, nil
+}
@@ -0,0 +1,146 @@
+package biz
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+// collectionChunkIDs 返回 collection 中的分块 ID（排序后）
+func (f *testFixture) collectionChunkIDs(collection string) []string {
+	var ids []string
+	for _, chunk := range f.vectorDB.vectors[collection] {
+		ids = append(ids, chunk.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// storedChunkIDs 返回数据库中知识库的分块 ID（排序后）
+func (f *testFixture) storedChunkIDs(kbID string) []string {
+	var ids []string
+	for _, chunk := range f.chunkRepo.sortedChunks(kbID) {
+		ids = append(ids, chunk.ID)
+	}
+	return ids
+}
+
+func TestRebuildVectors_MatchesChunkSet(t *testing.T) {
+	f := newTestFixture()
+	f.addDocument("doc-a", []byte("a"))
+	f.addDocument("doc-b", []byte("b"))
+	f.addChunks(f.kb.ID, "doc-a", 3)
+	f.addChunks(f.kb.ID, "doc-b", 2)
+	// Milvus 中残留的旧数据应在重建时清除
+	f.vectorDB.vectors[f.kb.MilvusCollection] = []*Chunk{{ID: "stale", DocumentID: "doc-gone"}}
+
+	var progress []RebuildVectorsProgress
+	result, err := f.useCase.RebuildVectors(context.Background(), f.kb.ID, &RebuildVectorsOptions{
+		Progress: func(p RebuildVectorsProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("RebuildVectors failed: %v", err)
+	}
+
+	want := f.storedChunkIDs(f.kb.ID)
+	got := f.collectionChunkIDs(f.kb.MilvusCollection)
+	if len(got) != len(want) {
+		t.Fatalf("Expected rebuilt collection %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected rebuilt collection %v, got %v", want, got)
+		}
+	}
+	for _, chunk := range f.vectorDB.vectors[f.kb.MilvusCollection] {
+		if len(chunk.Embedding) != *f.embedModel.EmbeddingDimensions {
+			t.Errorf("Expected %d-dim embedding for %s, got %d", *f.embedModel.EmbeddingDimensions, chunk.ID, len(chunk.Embedding))
+		}
+	}
+
+	if result.ProcessedDocuments != 2 || result.InsertedVectors != 5 {
+		t.Errorf("Expected 2 documents / 5 vectors, got %+v", result)
+	}
+	if len(progress) != 2 || progress[1].ProcessedDocuments != 2 || progress[1].TotalDocuments != 2 || progress[1].DocumentID != "doc-b" {
+		t.Errorf("Unexpected progress reports: %+v", progress)
+	}
+}
+
+func TestRebuildVectors_ResumesAfterDocument(t *testing.T) {
+	f := newTestFixture()
+	f.addDocument("doc-a", []byte("a"))
+	f.addDocument("doc-b", []byte("b"))
+	f.addChunks(f.kb.ID, "doc-a", 3)
+	f.addChunks(f.kb.ID, "doc-b", 2)
+
+	if _, err := f.useCase.RebuildVectors(context.Background(), f.kb.ID, nil); err != nil {
+		t.Fatalf("RebuildVectors failed: %v", err)
+	}
+	// 模拟 doc-b 写入前中断：只保留 doc-a 的向量
+	_ = f.vectorDB.DeleteByDocumentID(context.Background(), f.kb.MilvusCollection, "doc-b")
+
+	result, err := f.useCase.RebuildVectors(context.Background(), f.kb.ID, &RebuildVectorsOptions{ResumeAfterDocumentID: "doc-a"})
+	if err != nil {
+		t.Fatalf("Resumed RebuildVectors failed: %v", err)
+	}
+	if result.SkippedDocuments != 1 || result.ProcessedDocuments != 1 || result.InsertedVectors != 2 {
+		t.Errorf("Expected doc-a skipped and doc-b rebuilt, got %+v", result)
+	}
+
+	want := f.storedChunkIDs(f.kb.ID)
+	got := f.collectionChunkIDs(f.kb.MilvusCollection)
+	if len(got) != len(want) {
+		t.Fatalf("Expected resumed collection %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected resumed collection %v, got %v", want, got)
+		}
+	}
+}
+
+func TestRebuildVectors_ResumeReplacesPartiallyWrittenDocument(t *testing.T) {
+	f := newTestFixture()
+	f.addDocument("doc-a", []byte("a"))
+	f.addDocument("doc-b", []byte("b"))
+	f.addChunks(f.kb.ID, "doc-a", 3)
+	f.addChunks(f.kb.ID, "doc-b", 2)
+
+	// 模拟 doc-b 向量已写入、但进度尚未记录时中断
+	if _, err := f.useCase.RebuildVectors(context.Background(), f.kb.ID, nil); err != nil {
+		t.Fatalf("RebuildVectors failed: %v", err)
+	}
+	if _, err := f.useCase.RebuildVectors(context.Background(), f.kb.ID, &RebuildVectorsOptions{ResumeAfterDocumentID: "doc-a"}); err != nil {
+		t.Fatalf("Resumed RebuildVectors failed: %v", err)
+	}
+
+	want := f.storedChunkIDs(f.kb.ID)
+	if got := f.collectionChunkIDs(f.kb.MilvusCollection); len(got) != len(want) {
+		t.Errorf("Expected no duplicate vectors after resume, want %v, got %v", want, got)
+	}
+}
+
+func TestRebuildVectors_IgnoresBackupEmbeddingModel(t *testing.T) {
+	f := newTestFixture()
+	f.withBackupEmbeddingModel(*f.embedModel.EmbeddingDimensions)
+	f.embedModel.VerificationStatus = "error"
+	f.addDocument("doc-a", []byte("a"))
+	f.addChunks(f.kb.ID, "doc-a", 2)
+
+	if _, err := f.useCase.RebuildVectors(context.Background(), f.kb.ID, nil); err != nil {
+		t.Fatalf("RebuildVectors failed: %v", err)
+	}
+	for _, model := range f.embedder.models {
+		if model != f.embedModel.ID {
+			t.Errorf("Expected rebuild to use the knowledge base model %s, got %s", f.embedModel.ID, model)
+		}
+	}
+}
+
+func TestRebuildVectors_KnowledgeBaseNotFound(t *testing.T) {
+	f := newTestFixture()
+
+	if _, err := f.useCase.RebuildVectors(context.Background(), "missing-kb", nil); err == nil {
+		t.Fatal("Expected error for missing knowledge base")
+	}
+}
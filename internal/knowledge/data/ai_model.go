@@ -0,0 +1,225 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+)
+
+// AIModelPO AI模型数据库模型（能力字段以 JSONB 存储，见 04a_simplify_model_capabilities.sql）
+type AIModelPO struct {
+	ID                 string     `gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	ProviderID         string     `gorm:"type:uuid;not null;index:idx_ai_models_provider"`
+	ModelName          string     `gorm:"size:255;not null"`
+	DisplayName        string     `gorm:"size:255"`
+	MaxTokens          *int       `gorm:"column:max_tokens"`
+	IsEnabled          bool       `gorm:"column:is_enabled"` // 不设 gorm default，避免创建时 false 被数据库默认值覆盖
+	LastVerifiedAt     *time.Time `gorm:"column:last_verified_at"`
+	VerificationStatus string     `gorm:"size:20;default:'unknown'"`
+
+	Capabilities            string `gorm:"type:jsonb;not null;default:'[]'"` // 能力类型（JSON 数组）
+	SupportsStream          bool   `gorm:"default:false"`
+	SupportsVision          bool   `gorm:"default:false"`
+	SupportsFunctionCalling bool   `gorm:"default:false"`
+	SupportsReasoning       bool   `gorm:"default:false"`
+	SupportsWebSearch       bool   `gorm:"column:supports_web_search;default:false"`
+	EmbeddingDimensions     *int   `gorm:"column:embedding_dimensions"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (AIModelPO) TableName() string {
+	return "ai_models"
+}
+
+// AIModelRepo AI模型仓储实现
+type AIModelRepo struct {
+	db *database.DB
+}
+
+// NewAIModelRepo 创建AI模型仓储
+func NewAIModelRepo(db *database.DB) biz.AIModelRepo {
+	return &AIModelRepo{db: db}
+}
+
+// GetByID 根据ID获取AI模型
+func (r *AIModelRepo) GetByID(ctx context.Context, id string) (*biz.AIModel, error) {
+	var po AIModelPO
+	if err := r.db.WithContext(ctx).GetDB().Where("id = ?", id).First(&po).Error; err != nil {
+		if database.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("ai model %s not found", id)
+		}
+		return nil, err
+	}
+	return r.toModel(&po), nil
+}
+
+// ListByProviderID 获取服务商的所有模型（包括禁用的）
+func (r *AIModelRepo) ListByProviderID(ctx context.Context, providerID string) ([]*biz.AIModel, error) {
+	var pos []AIModelPO
+	err := r.db.WithContext(ctx).GetDB().
+		Where("provider_id = ?", providerID).
+		Order("model_name ASC").
+		Find(&pos).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toModels(pos), nil
+}
+
+// List 分页查询服务商的模型（按 model_name 排序），返回过滤后的总数；PageSize 为 0 时不分页
+func (r *AIModelRepo) List(ctx context.Context, req *biz.ListAIModelsRequest) ([]*biz.AIModel, int64, error) {
+	query := r.db.WithContext(ctx).GetDB().
+		Model(&AIModelPO{}).
+		Where("provider_id = ?", req.ProviderID)
+	if req.CapabilityType != "" {
+		query = query.Where("capabilities @> ?", capabilityFilter(req.CapabilityType))
+	}
+	if req.IsEnabled != nil {
+		query = query.Where("is_enabled = ?", *req.IsEnabled)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("model_name ASC")
+	if req.PageSize > 0 {
+		query = query.Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize)
+	}
+
+	var pos []AIModelPO
+	if err := query.Find(&pos).Error; err != nil {
+		return nil, 0, err
+	}
+	return r.toModels(pos), total, nil
+}
+
+// ListByCapabilityType 根据能力类型查询启用的模型（在 JSONB 数组中查找）
+func (r *AIModelRepo) ListByCapabilityType(ctx context.Context, capabilityType string) ([]*biz.AIModel, error) {
+	var pos []AIModelPO
+	err := r.db.WithContext(ctx).GetDB().
+		Where("is_enabled = true AND capabilities @> ?", capabilityFilter(capabilityType)).
+		Order("model_name ASC").
+		Find(&pos).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toModels(pos), nil
+}
+
+// ListAll 获取所有启用的AI模型
+func (r *AIModelRepo) ListAll(ctx context.Context) ([]*biz.AIModel, error) {
+	var pos []AIModelPO
+	err := r.db.WithContext(ctx).GetDB().
+		Where("is_enabled = true").
+		Order("provider_id ASC, model_name ASC").
+		Find(&pos).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toModels(pos), nil
+}
+
+// Create 创建模型（未指定 ID 时由数据库生成，并回填到 model）
+func (r *AIModelRepo) Create(ctx context.Context, model *biz.AIModel) error {
+	po := r.toPO(model)
+	if err := r.db.WithContext(ctx).GetDB().Create(po).Error; err != nil {
+		return err
+	}
+	model.ID = po.ID
+	return nil
+}
+
+// Update 更新模型
+func (r *AIModelRepo) Update(ctx context.Context, model *biz.AIModel) error {
+	po := r.toPO(model)
+	po.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).GetDB().
+		Model(&AIModelPO{}).
+		Where("id = ?", model.ID).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(po).Error
+}
+
+// Delete 删除模型
+func (r *AIModelRepo) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).GetDB().Where("id = ?", id).Delete(&AIModelPO{}).Error
+}
+
+// capabilityFilter 构造 JSONB 包含查询的参数（["chat"]）
+func capabilityFilter(capabilityType string) string {
+	bytes, _ := json.Marshal([]string{capabilityType})
+	return string(bytes)
+}
+
+func (r *AIModelRepo) toPO(model *biz.AIModel) *AIModelPO {
+	capabilities := "[]"
+	if len(model.Capabilities) > 0 {
+		if bytes, err := json.Marshal(model.Capabilities); err == nil {
+			capabilities = string(bytes)
+		}
+	}
+
+	return &AIModelPO{
+		ID:                      model.ID,
+		ProviderID:              model.ProviderID,
+		ModelName:               model.ModelName,
+		DisplayName:             model.DisplayName,
+		MaxTokens:               model.MaxTokens,
+		IsEnabled:               model.IsEnabled,
+		LastVerifiedAt:          model.LastVerifiedAt,
+		VerificationStatus:      model.VerificationStatus,
+		Capabilities:            capabilities,
+		SupportsStream:          model.SupportsStream,
+		SupportsVision:          model.SupportsVision,
+		SupportsFunctionCalling: model.SupportsFunctionCalling,
+		SupportsReasoning:       model.SupportsReasoning,
+		SupportsWebSearch:       model.SupportsWebSearch,
+		EmbeddingDimensions:     model.EmbeddingDimensions,
+		CreatedAt:               model.CreatedAt,
+		UpdatedAt:               model.UpdatedAt,
+	}
+}
+
+func (r *AIModelRepo) toModel(po *AIModelPO) *biz.AIModel {
+	var capabilities []string
+	if po.Capabilities != "" && po.Capabilities != "[]" {
+		_ = json.Unmarshal([]byte(po.Capabilities), &capabilities)
+	}
+
+	return &biz.AIModel{
+		ID:                      po.ID,
+		ProviderID:              po.ProviderID,
+		ModelName:               po.ModelName,
+		DisplayName:             po.DisplayName,
+		MaxTokens:               po.MaxTokens,
+		IsEnabled:               po.IsEnabled,
+		LastVerifiedAt:          po.LastVerifiedAt,
+		VerificationStatus:      po.VerificationStatus,
+		Capabilities:            capabilities,
+		SupportsStream:          po.SupportsStream,
+		SupportsVision:          po.SupportsVision,
+		SupportsFunctionCalling: po.SupportsFunctionCalling,
+		SupportsReasoning:       po.SupportsReasoning,
+		SupportsWebSearch:       po.SupportsWebSearch,
+		EmbeddingDimensions:     po.EmbeddingDimensions,
+		CreatedAt:               po.CreatedAt,
+		UpdatedAt:               po.UpdatedAt,
+	}
+}
+
+func (r *AIModelRepo) toModels(pos []AIModelPO) []*biz.AIModel {
+	models := make([]*biz.AIModel, len(pos))
+	for i := range pos {
+		models[i] = r.toModel(&pos[i])
+	}
+	return models
+}
@@ -0,0 +1,88 @@
+// +build integration
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+)
+
+// 集成测试说明:
+// 需要可用的 PostgreSQL，运行方式:
+//   go test -tags integration ./internal/knowledge/data/ -run TestAIModelRepo_List
+
+func TestAIModelRepo_List(t *testing.T) {
+	db, cleanup := setupSchemaDB(t)
+	defer cleanup()
+
+	if err := db.AutoMigrate(&AIModelPO{}); err != nil {
+		t.Fatalf("Failed to create ai_models table: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := NewAIModelRepo(db)
+	providerID, otherProviderID := uuid.New().String(), uuid.New().String()
+
+	for i := 1; i <= 5; i++ {
+		model := &biz.AIModel{
+			ProviderID:   providerID,
+			ModelName:    fmt.Sprintf("chat-%d", i),
+			IsEnabled:    i != 3, // chat-3 已禁用
+			Capabilities: []string{biz.CapabilityTypeChat},
+		}
+		if err := repo.Create(ctx, model); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if model.ID == "" {
+			t.Fatal("Expected Create to fill in the generated ID")
+		}
+	}
+	for _, model := range []*biz.AIModel{
+		{ProviderID: providerID, ModelName: "embed-1", IsEnabled: true, Capabilities: []string{biz.CapabilityTypeEmbedding}},
+		{ProviderID: otherProviderID, ModelName: "chat-other", IsEnabled: true, Capabilities: []string{biz.CapabilityTypeChat}},
+	} {
+		if err := repo.Create(ctx, model); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	names := func(models []*biz.AIModel) string {
+		result := make([]string, len(models))
+		for i, m := range models {
+			result[i] = m.ModelName
+		}
+		return fmt.Sprint(result)
+	}
+
+	// 不分页时返回服务商的全部模型
+	all, total, err := repo.List(ctx, &biz.ListAIModelsRequest{ProviderID: providerID})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 6 || len(all) != 6 {
+		t.Errorf("Expected all 6 models, got total=%d %s", total, names(all))
+	}
+
+	// 能力过滤 + 分页
+	page, total, err := repo.List(ctx, &biz.ListAIModelsRequest{ProviderID: providerID, CapabilityType: biz.CapabilityTypeChat, Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 5 || names(page) != "[chat-3 chat-4]" {
+		t.Errorf("Expected total=5 [chat-3 chat-4], got total=%d %s", total, names(page))
+	}
+
+	// 启用状态过滤（创建时 is_enabled=false 需要保留）
+	enabled := false
+	disabled, total, err := repo.List(ctx, &biz.ListAIModelsRequest{ProviderID: providerID, IsEnabled: &enabled})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 1 || names(disabled) != "[chat-3]" {
+		t.Errorf("Expected only chat-3 to be disabled, got total=%d %s", total, names(disabled))
+	}
+}
@@ -92,7 +92,10 @@ func (r *AIProviderRepo) UpdateStatus(ctx context.Context, id string, isEnabled
 	return r.db.WithContext(ctx).GetDB().
 		Model(&AIProviderPO{}).
 		Where("id = ?", id).
-		Update("is_enabled", isEnabled).
+		Updates(map[string]interface{}{
+			"is_enabled": isEnabled,
+			"updated_at": time.Now(),
+		}).
 		Error
 }
 
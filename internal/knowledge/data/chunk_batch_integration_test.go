@@ -0,0 +1,89 @@
+// +build integration
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"gorm.io/gorm"
+)
+
+// 集成测试说明:
+// 需要可用的 PostgreSQL，运行方式:
+//   go test -tags integration ./internal/knowledge/data/ -run TestChunkRepo_GetByDocumentIDs
+
+func TestChunkRepo_GetByDocumentIDs(t *testing.T) {
+	db, cleanup := setupSchemaDB(t)
+	defer cleanup()
+
+	if err := db.AutoMigrate(&ChunkPO{}); err != nil {
+		t.Fatalf("Failed to create chunks table: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := NewChunkRepo(db)
+	kbID := uuid.New().String()
+	docA, docB, docC := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	// docA 5 个分块、docB 2 个分块、docC 不查询
+	var chunks []*biz.Chunk
+	for docID, count := range map[string]int{docA: 5, docB: 2, docC: 3} {
+		for i := count - 1; i >= 0; i-- {
+			chunks = append(chunks, &biz.Chunk{
+				ID:              uuid.New().String(),
+				DocumentID:      docID,
+				KnowledgeBaseID: kbID,
+				Content:         fmt.Sprintf("%s-%d", docID, i),
+				Position:        i,
+				CreatedAt:       time.Now(),
+			})
+		}
+	}
+	if err := repo.BatchCreate(ctx, chunks); err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	// 统计查询次数
+	queries := 0
+	err := db.Callback().Query().After("gorm:query").Register("count_queries", func(*gorm.DB) { queries++ })
+	if err != nil {
+		t.Fatalf("Failed to register callback: %v", err)
+	}
+
+	grouped, err := repo.GetByDocumentIDs(ctx, []string{docA, docB}, 3)
+	if err != nil {
+		t.Fatalf("GetByDocumentIDs failed: %v", err)
+	}
+
+	if queries != 1 {
+		t.Errorf("Expected a single query, got %d", queries)
+	}
+	if _, ok := grouped[docC]; ok {
+		t.Errorf("Expected chunks of unrequested document to be excluded")
+	}
+	for docID, want := range map[string]int{docA: 3, docB: 2} {
+		got := grouped[docID]
+		if len(got) != want {
+			t.Fatalf("Expected %d chunks for %s, got %d", want, docID, len(got))
+		}
+		for i, chunk := range got {
+			if chunk.Position != i || chunk.Content != fmt.Sprintf("%s-%d", docID, i) {
+				t.Errorf("Expected chunk %d of %s in position order, got %+v", i, docID, chunk)
+			}
+		}
+	}
+
+	// limitPerDoc <= 0 返回全部分块
+	grouped, err = repo.GetByDocumentIDs(ctx, []string{docA}, 0)
+	if err != nil {
+		t.Fatalf("GetByDocumentIDs without limit failed: %v", err)
+	}
+	if len(grouped[docA]) != 5 {
+		t.Errorf("Expected all 5 chunks without limit, got %d", len(grouped[docA]))
+	}
+}
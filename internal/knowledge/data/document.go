@@ -4,17 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 // DocumentPO 文档数据库模型
 type DocumentPO struct {
 	ID              string    `gorm:"type:uuid;primarykey"`
-	KnowledgeBaseID string    `gorm:"column:knowledge_base_id;type:uuid;not null;index:idx_doc_kb_id"`
+	KnowledgeBaseID string    `gorm:"column:knowledge_base_id;type:uuid;not null;index:idx_doc_kb_id;uniqueIndex:idx_doc_kb_dedup_hash,priority:1"`
 	FileName        string    `gorm:"column:filename;size:255;not null"`
 	FileType        string    `gorm:"column:file_type;size:50;not null;index:idx_doc_file_type"`
 	FileSize        int64     `gorm:"column:file_size;not null"`
@@ -22,6 +25,7 @@ type DocumentPO struct {
 	MinioBucket     string    `gorm:"column:minio_bucket;size:100;not null"`
 	MinioObjectKey  string    `gorm:"column:minio_object_key;size:500;not null"`
 	ProcessStatus   string    `gorm:"column:status;size:50;not null;index:idx_doc_status;default:'pending'"`
+	ProcessStage    string    `gorm:"column:process_stage;size:20;not null;default:''"`
 	ProcessError    string    `gorm:"column:error_message;type:text"`
 	ChunkCount      int64     `gorm:"column:chunk_count;not null;default:0"`
 	TokenCount      int       `gorm:"column:token_count;not null;default:0"`
@@ -32,6 +36,17 @@ type DocumentPO struct {
 	SourceURL     string `gorm:"column:source_url;type:text"`
 	SourceContent string `gorm:"column:source_content;type:text"`
 
+	BatchID   string  `gorm:"column:batch_id;size:64;not null;default:''"`
+	DedupHash *string `gorm:"column:dedup_hash;size:64;uniqueIndex:idx_doc_kb_dedup_hash,priority:2"` // 为 NULL 时不参与唯一约束
+
+	// 处理统计（最近一次成功处理，未处理完成时为 NULL）
+	ExtractionDurationMs   *int64     `gorm:"column:extraction_duration_ms"`
+	EmbeddingDurationMs    *int64     `gorm:"column:embedding_duration_ms"`
+	EmbeddedTokens         *int64     `gorm:"column:embedded_tokens"`
+	EstimatedEmbeddingCost *float64   `gorm:"column:estimated_embedding_cost;type:numeric(14,6)"`
+	ProcessedAt            *time.Time `gorm:"column:processed_at"`
+	ProcessFingerprint     string     `gorm:"column:process_fingerprint;size:64;not null;default:''"`
+
 	CreatedAt       time.Time `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt       time.Time `gorm:"column:updated_at;not null;default:CURRENT_TIMESTAMP"`
 }
@@ -63,32 +78,65 @@ func (r *DocumentRepo) Create(ctx context.Context, doc *biz.Document) error {
 	}
 
 	po := &DocumentPO{
-		ID:              doc.ID,
-		KnowledgeBaseID: doc.KnowledgeBaseID,
-		FileName:        doc.FileName,
-		FileType:        doc.FileType,
-		FileSize:        doc.FileSize,
-		FileHash:        doc.FileHash,
-		MinioBucket:     doc.MinioBucket,
-		MinioObjectKey:  doc.MinioObjectKey,
-		ProcessStatus:   doc.ProcessStatus,
-		ProcessError:    doc.ProcessError,
-		ChunkCount:      doc.ChunkCount,
-		TokenCount:      doc.TokenCount,
-		Metadata:        metadataJSON,
-		SourceType:      doc.SourceType,
-		SourceURL:       doc.SourceURL,
-		SourceContent:   doc.SourceContent,
-		CreatedAt:       doc.CreatedAt,
-		UpdatedAt:       doc.UpdatedAt,
-	}
-
-	err := r.db.WithContext(ctx).GetDB().Create(po).Error
-	if err != nil {
-		return fmt.Errorf("failed to create document: %w", err)
+		ID:                 doc.ID,
+		KnowledgeBaseID:    doc.KnowledgeBaseID,
+		FileName:           doc.FileName,
+		FileType:           doc.FileType,
+		FileSize:           doc.FileSize,
+		FileHash:           doc.FileHash,
+		MinioBucket:        doc.MinioBucket,
+		MinioObjectKey:     doc.MinioObjectKey,
+		ProcessStatus:      doc.ProcessStatus.String(),
+		ProcessStage:       doc.ProcessStage.String(),
+		ProcessError:       doc.ProcessError,
+		ChunkCount:         doc.ChunkCount,
+		TokenCount:         doc.TokenCount,
+		Metadata:           metadataJSON,
+		SourceType:         doc.SourceType,
+		SourceURL:          doc.SourceURL,
+		SourceContent:      doc.SourceContent,
+		BatchID:            doc.BatchID,
+		DedupHash:          nullableString(doc.DedupHash),
+		ProcessFingerprint: doc.ProcessFingerprint,
+		CreatedAt:          doc.CreatedAt,
+		UpdatedAt:          doc.UpdatedAt,
+	}
+	po.setTelemetry(doc.Telemetry)
+
+	// 文档记录与知识库文档计数在同一事务中更新，避免并发上传/删除导致计数漂移
+	return r.db.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := tx.Create(po).Error; err != nil {
+			if isDedupConflict(err) {
+				return fmt.Errorf("%w: %s", biz.ErrDocumentHashExists, doc.FileName)
+			}
+			return fmt.Errorf("failed to create document: %w", err)
+		}
+		if err := incrementDocumentCount(tx, doc.KnowledgeBaseID, 1); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// nullableString 空字符串存为 NULL
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
 	}
+	return &s
+}
 
-	return nil
+// stringValue NULL 读取为空字符串
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// isDedupConflict 是否为知识库内去重唯一约束冲突
+func isDedupConflict(err error) bool {
+	return database.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "idx_doc_kb_dedup_hash")
 }
 
 // GetByID 根据 ID 获取文档
@@ -121,6 +169,40 @@ func (r *DocumentRepo) GetByIDs(ctx context.Context, ids []string) ([]*biz.Docum
 	return docs, nil
 }
 
+// GetByFileHash 查询知识库内相同哈希的文档（不存在返回 nil）
+func (r *DocumentRepo) GetByFileHash(ctx context.Context, kbID, fileHash string) (*biz.Document, error) {
+	var po DocumentPO
+	err := r.db.WithContext(ctx).GetDB().
+		Where("knowledge_base_id = ? AND file_hash = ?", kbID, fileHash).
+		Order("created_at ASC").
+		First(&po).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get document by hash: %w", err)
+	}
+
+	return r.toDomain(&po), nil
+}
+
+// GetByBatchFile 查询批量上传会话中已创建的同名文档（不存在返回 nil）
+func (r *DocumentRepo) GetByBatchFile(ctx context.Context, kbID, batchID, fileName string) (*biz.Document, error) {
+	var po DocumentPO
+	err := r.db.WithContext(ctx).GetDB().
+		Where("knowledge_base_id = ? AND batch_id = ? AND filename = ?", kbID, batchID, fileName).
+		Order("created_at ASC").
+		First(&po).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get document by batch: %w", err)
+	}
+
+	return r.toDomain(&po), nil
+}
+
 // List 列出文档
 func (r *DocumentRepo) List(ctx context.Context, kbID string, req *biz.ListDocumentsRequest) ([]*biz.Document, int64, error) {
 	var pos []DocumentPO
@@ -163,25 +245,30 @@ func (r *DocumentRepo) Update(ctx context.Context, doc *biz.Document) error {
 	}
 
 	po := &DocumentPO{
-		ID:              doc.ID,
-		KnowledgeBaseID: doc.KnowledgeBaseID,
-		FileName:        doc.FileName,
-		FileType:        doc.FileType,
-		FileSize:        doc.FileSize,
-		FileHash:        doc.FileHash,
-		MinioBucket:     doc.MinioBucket,
-		MinioObjectKey:  doc.MinioObjectKey,
-		ProcessStatus:   doc.ProcessStatus,
-		ProcessError:    doc.ProcessError,
-		ChunkCount:      doc.ChunkCount,
-		TokenCount:      doc.TokenCount,
-		Metadata:        metadataJSON,
-		SourceType:      doc.SourceType,
-		SourceURL:       doc.SourceURL,
-		SourceContent:   doc.SourceContent,
-		CreatedAt:       doc.CreatedAt, // 保持原始创建时间
-		UpdatedAt:       time.Now(),
-	}
+		ID:                 doc.ID,
+		KnowledgeBaseID:    doc.KnowledgeBaseID,
+		FileName:           doc.FileName,
+		FileType:           doc.FileType,
+		FileSize:           doc.FileSize,
+		FileHash:           doc.FileHash,
+		MinioBucket:        doc.MinioBucket,
+		MinioObjectKey:     doc.MinioObjectKey,
+		ProcessStatus:      doc.ProcessStatus.String(),
+		ProcessStage:       doc.ProcessStage.String(),
+		ProcessError:       doc.ProcessError,
+		ChunkCount:         doc.ChunkCount,
+		TokenCount:         doc.TokenCount,
+		Metadata:           metadataJSON,
+		SourceType:         doc.SourceType,
+		SourceURL:          doc.SourceURL,
+		SourceContent:      doc.SourceContent,
+		BatchID:            doc.BatchID,
+		DedupHash:          nullableString(doc.DedupHash),
+		ProcessFingerprint: doc.ProcessFingerprint,
+		CreatedAt:          doc.CreatedAt, // 保持原始创建时间
+		UpdatedAt:          time.Now(),
+	}
+	po.setTelemetry(doc.Telemetry)
 
 	err := r.db.WithContext(ctx).GetDB().Save(po).Error
 	if err != nil {
@@ -191,37 +278,99 @@ func (r *DocumentRepo) Update(ctx context.Context, doc *biz.Document) error {
 	return nil
 }
 
-// Delete 删除文档
+// Delete 删除文档，并在同一事务中减少知识库文档计数
 func (r *DocumentRepo) Delete(ctx context.Context, id string) error {
-	err := r.db.WithContext(ctx).GetDB().Where("id = ?", id).Delete(&DocumentPO{}).Error
-	if err != nil {
-		return fmt.Errorf("failed to delete document: %w", err)
-	}
+	return r.db.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		// DELETE ... RETURNING 保证只有真正删除了记录的事务才减少计数（并发删除同一文档时只减一次）
+		var deleted []DocumentPO
+		err := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "knowledge_base_id"}}}).
+			Where("id = ?", id).
+			Delete(&deleted).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete document: %w", err)
+		}
 
-	return nil
+		for _, kc := range countByKnowledgeBase(deleted) {
+			if err := incrementDocumentCount(tx, kc.id, -kc.count); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// BatchDelete 批量删除文档
+// BatchDelete 批量删除文档，并在同一事务中按知识库减少文档计数
 func (r *DocumentRepo) BatchDelete(ctx context.Context, ids []string) error {
 	if len(ids) == 0 {
 		return nil
 	}
 
-	err := r.db.WithContext(ctx).GetDB().Where("id IN ?", ids).Delete(&DocumentPO{}).Error
-	if err != nil {
-		return fmt.Errorf("failed to batch delete documents: %w", err)
+	return r.db.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		var deleted []DocumentPO
+		err := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "knowledge_base_id"}}}).
+			Where("id IN ?", ids).
+			Delete(&deleted).Error
+		if err != nil {
+			return fmt.Errorf("failed to batch delete documents: %w", err)
+		}
+
+		for _, kc := range countByKnowledgeBase(deleted) {
+			if err := incrementDocumentCount(tx, kc.id, -kc.count); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// kbDocumentCount 单个知识库被删除的文档数
+type kbDocumentCount struct {
+	id    string
+	count int
+}
+
+// countByKnowledgeBase 按知识库统计被删除的文档数（按知识库 ID 排序，保证多个事务加锁顺序一致）
+func countByKnowledgeBase(deleted []DocumentPO) []kbDocumentCount {
+	counts := make(map[string]int)
+	for _, po := range deleted {
+		counts[po.KnowledgeBaseID]++
 	}
 
+	result := make([]kbDocumentCount, 0, len(counts))
+	for id, count := range counts {
+		result = append(result, kbDocumentCount{id: id, count: count})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].id < result[j].id })
+	return result
+}
+
+// incrementDocumentCount 在事务内原子更新知识库文档计数
+func incrementDocumentCount(tx *gorm.DB, kbID string, delta int) error {
+	result := tx.Exec("UPDATE knowledge_bases SET document_count = document_count + ? WHERE id = ?", delta, kbID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update document count of knowledge base %s: %w", kbID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return biz.ErrKnowledgeBaseNotFound
+	}
 	return nil
 }
 
 // UpdateStatus 更新文档状态
-func (r *DocumentRepo) UpdateStatus(ctx context.Context, id, status, errorMsg string) error {
+func (r *DocumentRepo) UpdateStatus(ctx context.Context, id string, status biz.ProcessStatus, errorMsg string) error {
+	if !status.Valid() {
+		return fmt.Errorf("%w: %q", biz.ErrInvalidProcessStatus, status)
+	}
+
 	updates := map[string]interface{}{
-		"status":        status,   // 数据库字段名
-		"error_message": errorMsg, // 数据库字段名
+		"status":        status.String(), // 数据库字段名
+		"error_message": errorMsg,        // 数据库字段名
 		"updated_at":    time.Now(),
 	}
+	// 失败时保留所在子阶段便于排查，其余状态清空子阶段
+	if status != biz.ProcessStatusFailed {
+		updates["process_stage"] = biz.ProcessStageNone.String()
+	}
 
 	err := r.db.WithContext(ctx).GetDB().Model(&DocumentPO{}).
 		Where("id = ?", id).
@@ -234,6 +383,52 @@ func (r *DocumentRepo) UpdateStatus(ctx context.Context, id, status, errorMsg st
 	return nil
 }
 
+// UpdateStage 更新文档处理子阶段
+func (r *DocumentRepo) UpdateStage(ctx context.Context, id string, stage biz.ProcessStage) error {
+	if !stage.Valid() {
+		return fmt.Errorf("%w: %q", biz.ErrInvalidProcessStage, stage)
+	}
+
+	err := r.db.WithContext(ctx).GetDB().Model(&DocumentPO{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"process_stage": stage.String(),
+			"updated_at":    time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update document stage: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateMetadata 更新文档元数据（只更新 metadata 字段）
+func (r *DocumentRepo) UpdateMetadata(ctx context.Context, id string, metadata map[string]interface{}) error {
+	metadataJSON := "{}"
+	if len(metadata) > 0 {
+		bytes, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		metadataJSON = string(bytes)
+	}
+
+	result := r.db.WithContext(ctx).GetDB().Model(&DocumentPO{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"metadata":   metadataJSON,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update document metadata: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return biz.ErrDocumentNotFound
+	}
+
+	return nil
+}
+
 // toDomain 转换为领域模型
 func (r *DocumentRepo) toDomain(po *DocumentPO) *biz.Document {
 	// 反序列化Metadata
@@ -243,27 +438,76 @@ func (r *DocumentRepo) toDomain(po *DocumentPO) *biz.Document {
 	}
 
 	return &biz.Document{
-		ID:              po.ID,
-		KnowledgeBaseID: po.KnowledgeBaseID,
-		FileName:        po.FileName,
-		FileType:        po.FileType,
-		FileSize:        po.FileSize,
-		FileHash:        po.FileHash,
-		MinioBucket:     po.MinioBucket,
-		MinioObjectKey:  po.MinioObjectKey,
-		ProcessStatus:   po.ProcessStatus,
-		ProcessError:    po.ProcessError,
-		ChunkCount:      po.ChunkCount,
-		TokenCount:      po.TokenCount,
-		Metadata:        metadata,
-		SourceType:      po.SourceType,
-		SourceURL:       po.SourceURL,
-		SourceContent:   po.SourceContent,
-		CreatedAt:       po.CreatedAt,
-		UpdatedAt:       po.UpdatedAt,
+		ID:                 po.ID,
+		KnowledgeBaseID:    po.KnowledgeBaseID,
+		FileName:           po.FileName,
+		FileType:           po.FileType,
+		FileSize:           po.FileSize,
+		FileHash:           po.FileHash,
+		MinioBucket:        po.MinioBucket,
+		MinioObjectKey:     po.MinioObjectKey,
+		ProcessStatus:      biz.ProcessStatus(po.ProcessStatus),
+		ProcessStage:       biz.ProcessStage(po.ProcessStage),
+		ProcessError:       po.ProcessError,
+		ChunkCount:         po.ChunkCount,
+		TokenCount:         po.TokenCount,
+		Metadata:           metadata,
+		SourceType:         po.SourceType,
+		SourceURL:          po.SourceURL,
+		SourceContent:      po.SourceContent,
+		BatchID:            po.BatchID,
+		DedupHash:          stringValue(po.DedupHash),
+		Telemetry:          po.telemetry(),
+		ProcessFingerprint: po.ProcessFingerprint,
+		CreatedAt:          po.CreatedAt,
+		UpdatedAt:          po.UpdatedAt,
 	}
 }
 
+// setTelemetry 写入处理统计（nil 时清空）
+func (po *DocumentPO) setTelemetry(t *biz.ProcessingTelemetry) {
+	if t == nil {
+		po.ExtractionDurationMs, po.EmbeddingDurationMs, po.EmbeddedTokens, po.EstimatedEmbeddingCost, po.ProcessedAt = nil, nil, nil, nil, nil
+		return
+	}
+
+	extraction := t.ExtractionDuration.Milliseconds()
+	embedding := t.EmbeddingDuration.Milliseconds()
+	tokens := t.EmbeddedTokens
+	cost := t.EstimatedEmbeddingCost
+	processedAt := t.ProcessedAt
+	po.ExtractionDurationMs = &extraction
+	po.EmbeddingDurationMs = &embedding
+	po.EmbeddedTokens = &tokens
+	po.EstimatedEmbeddingCost = &cost
+	po.ProcessedAt = &processedAt
+}
+
+// telemetry 读取处理统计（未处理完成时返回 nil）
+func (po *DocumentPO) telemetry() *biz.ProcessingTelemetry {
+	if po.ProcessedAt == nil {
+		return nil
+	}
+
+	t := &biz.ProcessingTelemetry{
+		ChunkCount:  int(po.ChunkCount),
+		ProcessedAt: *po.ProcessedAt,
+	}
+	if po.ExtractionDurationMs != nil {
+		t.ExtractionDuration = time.Duration(*po.ExtractionDurationMs) * time.Millisecond
+	}
+	if po.EmbeddingDurationMs != nil {
+		t.EmbeddingDuration = time.Duration(*po.EmbeddingDurationMs) * time.Millisecond
+	}
+	if po.EmbeddedTokens != nil {
+		t.EmbeddedTokens = *po.EmbeddedTokens
+	}
+	if po.EstimatedEmbeddingCost != nil {
+		t.EstimatedEmbeddingCost = *po.EstimatedEmbeddingCost
+	}
+	return t
+}
+
 // ChunkPO 文档分块数据库模型
 type ChunkPO struct {
 	ID              string    `gorm:"type:uuid;primarykey"`
@@ -340,7 +584,7 @@ func (r *ChunkRepo) GetByDocumentID(ctx context.Context, docID string) ([]*biz.C
 	var pos []ChunkPO
 	err := r.db.WithContext(ctx).GetDB().
 		Where("document_id = ?", docID).
-		Order("position ASC").
+		Order("chunk_index ASC").
 		Find(&pos).Error
 
 	if err != nil {
@@ -348,28 +592,63 @@ func (r *ChunkRepo) GetByDocumentID(ctx context.Context, docID string) ([]*biz.C
 	}
 
 	chunks := make([]*biz.Chunk, len(pos))
-	for i, po := range pos {
-		// 反序列化Metadata
-		var metadata map[string]interface{}
-		if po.Metadata != "" && po.Metadata != "{}" {
-			_ = json.Unmarshal([]byte(po.Metadata), &metadata)
-		}
-
-		chunks[i] = &biz.Chunk{
-			ID:              po.ID,
-			DocumentID:      po.DocumentID,
-			KnowledgeBaseID: po.KnowledgeBaseID,
-			Content:         po.Content,
-			Position:        po.ChunkIndex,
-			TokenCount:      po.TokenCount,
-			Metadata:        metadata,
-			CreatedAt:       po.CreatedAt,
-		}
+	for i := range pos {
+		chunks[i] = r.toDomain(&pos[i])
 	}
 
 	return chunks, nil
 }
 
+// GetByDocumentIDs 批量获取多个文档的分块（单次查询，按文档 ID 分组）
+// limitPerDoc > 0 时使用 ROW_NUMBER() 窗口函数只返回每个文档的前 limitPerDoc 个分块
+func (r *ChunkRepo) GetByDocumentIDs(ctx context.Context, docIDs []string, limitPerDoc int) (map[string][]*biz.Chunk, error) {
+	grouped := make(map[string][]*biz.Chunk, len(docIDs))
+	if len(docIDs) == 0 {
+		return grouped, nil
+	}
+
+	db := r.db.WithContext(ctx).GetDB()
+	query := db.Model(&ChunkPO{}).Where("document_id IN ?", docIDs)
+	if limitPerDoc > 0 {
+		ranked := db.Model(&ChunkPO{}).
+			Select("chunks.*, ROW_NUMBER() OVER (PARTITION BY document_id ORDER BY chunk_index ASC) AS row_num").
+			Where("document_id IN ?", docIDs)
+		query = db.Table("(?) AS ranked", ranked).Where("row_num <= ?", limitPerDoc)
+	}
+
+	var pos []ChunkPO
+	err := query.Order("document_id ASC, chunk_index ASC").Find(&pos).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks by document ids: %w", err)
+	}
+
+	for i := range pos {
+		chunk := r.toDomain(&pos[i])
+		grouped[chunk.DocumentID] = append(grouped[chunk.DocumentID], chunk)
+	}
+
+	return grouped, nil
+}
+
+// toDomain 将 ChunkPO 转换为领域模型
+func (r *ChunkRepo) toDomain(po *ChunkPO) *biz.Chunk {
+	var metadata map[string]interface{}
+	if po.Metadata != "" && po.Metadata != "{}" {
+		_ = json.Unmarshal([]byte(po.Metadata), &metadata)
+	}
+
+	return &biz.Chunk{
+		ID:              po.ID,
+		DocumentID:      po.DocumentID,
+		KnowledgeBaseID: po.KnowledgeBaseID,
+		Content:         po.Content,
+		Position:        po.ChunkIndex,
+		TokenCount:      po.TokenCount,
+		Metadata:        metadata,
+		CreatedAt:       po.CreatedAt,
+	}
+}
+
 // DeleteByDocumentID 根据文档 ID 删除分块
 func (r *ChunkRepo) DeleteByDocumentID(ctx context.Context, docID string) error {
 	err := r.db.WithContext(ctx).GetDB().
@@ -413,6 +692,9 @@ func (r *ChunkRepo) DeleteByKnowledgeBaseID(ctx context.Context, kbID string) er
 	return nil
 }
 
+// ftsConfig 全文搜索配置（需与 chunks_content_tsv_trigger 保持一致）
+const ftsConfig = "simple"
+
 // KeywordSearchResult 关键词搜索结果（带相关度分数）
 type KeywordSearchResult struct {
 	Chunk *biz.Chunk
@@ -437,10 +719,10 @@ func (r *ChunkRepo) KeywordSearch(ctx context.Context, kbID, query string, topK
 		Model(&ChunkPO{}).
 		Select(`
 			chunks.*,
-			bm25_score(content_tsv, plainto_tsquery('simple', ?), 1.2, 0.75) as bm25_score
-		`, query).
+			bm25_score(content_tsv, plainto_tsquery(?::regconfig, ?), 1.2, 0.75) as bm25_score
+		`, ftsConfig, query).
 		Where("knowledge_base_id = ?", kbID).
-		Where("content_tsv @@ plainto_tsquery('simple', ?)", query).
+		Where("content_tsv @@ plainto_tsquery(?::regconfig, ?)", ftsConfig, query).
 		Order("bm25_score DESC").
 		Limit(topK).
 		Find(&results).Error
@@ -450,30 +732,43 @@ func (r *ChunkRepo) KeywordSearch(ctx context.Context, kbID, query string, topK
 	}
 
 	chunks := make([]*biz.Chunk, len(results))
-	for i, result := range results {
-		// 反序列化Metadata
-		var metadata map[string]interface{}
-		if result.Metadata != "" && result.Metadata != "{}" {
-			_ = json.Unmarshal([]byte(result.Metadata), &metadata)
-		}
-
-		chunks[i] = &biz.Chunk{
-			ID:              result.ID,
-			DocumentID:      result.DocumentID,
-			KnowledgeBaseID: result.KnowledgeBaseID,
-			Content:         result.Content,
-			Position:        result.ChunkIndex,
-			TokenCount:      result.TokenCount,
-			Metadata:        metadata,
-			CreatedAt:       result.CreatedAt,
-		}
+	for i := range results {
+		chunks[i] = r.toDomain(&results[i].ChunkPO)
 
 		// 将 BM25 分数存储到 metadata 中（供混合检索使用）
 		if chunks[i].Metadata == nil {
 			chunks[i].Metadata = make(map[string]interface{})
 		}
-		chunks[i].Metadata["bm25_score"] = result.BM25Score
+		chunks[i].Metadata["bm25_score"] = results[i].BM25Score
 	}
 
 	return chunks, nil
 }
+
+// ReindexKeywordSearchBatch 按 ID 顺序重建一批分块的 content_tsv，返回本批最后一个 ID 与处理数量
+func (r *ChunkRepo) ReindexKeywordSearchBatch(ctx context.Context, kbID, afterID string, batchSize int) (string, int, error) {
+	db := r.db.WithContext(ctx).GetDB()
+
+	query := db.Model(&ChunkPO{}).Where("knowledge_base_id = ?", kbID)
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+
+	var ids []string
+	err := query.Order("id ASC").Limit(batchSize).Pluck("id", &ids).Error
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to list chunks for reindex: %w", err)
+	}
+	if len(ids) == 0 {
+		return afterID, 0, nil
+	}
+
+	err = db.Model(&ChunkPO{}).
+		Where("id IN ?", ids).
+		Update("content_tsv", gorm.Expr("to_tsvector(?::regconfig, COALESCE(content, ''))", ftsConfig)).Error
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to update content_tsv: %w", err)
+	}
+
+	return ids[len(ids)-1], len(ids), nil
+}
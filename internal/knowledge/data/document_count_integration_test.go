@@ -0,0 +1,258 @@
+// +build integration
+
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+)
+
+// 集成测试说明:
+// 需要可用的 PostgreSQL，运行方式:
+//   go test -tags integration ./internal/knowledge/data/ -run TestDocumentCount
+
+// setupPooledSchemaDB 在独立 schema 中创建带连接池的测试数据库连接（并发测试需要多个连接）
+func setupPooledSchemaDB(t *testing.T) (*database.DB, func()) {
+	schemaDB, cleanupSchema := setupSchemaDB(t)
+
+	var schema string
+	if err := schemaDB.Raw("SELECT current_schema()").Scan(&schema).Error; err != nil {
+		cleanupSchema()
+		t.Fatalf("Failed to get schema: %v", err)
+	}
+
+	cfg := *schemaDB.Config()
+	cfg.MaxOpenConns = 10
+	cfg.MaxIdleConns = 10
+	// 池中每个连接都使用测试 schema
+	cfg.SearchPath = schema
+
+	log, err := logger.Development()
+	if err != nil {
+		cleanupSchema()
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	db, err := database.New(&cfg, log)
+	if err != nil {
+		cleanupSchema()
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	cleanup := func() {
+		db.Close()
+		cleanupSchema()
+	}
+	return db, cleanup
+}
+
+func TestDocumentCount_ConcurrentCreateAndDelete(t *testing.T) {
+	db, cleanup := setupPooledSchemaDB(t)
+	defer cleanup()
+
+	err := db.Exec(`
+		CREATE TABLE knowledge_bases (
+			id UUID PRIMARY KEY,
+			document_count BIGINT NOT NULL DEFAULT 0,
+			CONSTRAINT check_document_count_non_negative CHECK (document_count >= 0)
+		)`).Error
+	if err != nil {
+		t.Fatalf("Failed to create knowledge_bases table: %v", err)
+	}
+	if err := db.AutoMigrate(&DocumentPO{}); err != nil {
+		t.Fatalf("Failed to create documents table: %v", err)
+	}
+
+	kbID := uuid.New().String()
+	if err := db.Exec("INSERT INTO knowledge_bases (id) VALUES (?)", kbID).Error; err != nil {
+		t.Fatalf("Failed to insert knowledge base: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := NewDocumentRepo(db)
+	newDoc := func(i int) *biz.Document {
+		now := time.Now()
+		return &biz.Document{
+			ID:              uuid.New().String(),
+			KnowledgeBaseID: kbID,
+			FileName:        fmt.Sprintf("doc-%d.txt", i),
+			FileType:        "txt",
+			FileHash:        fmt.Sprintf("%064d", i),
+			MinioBucket:     "knowledge-bases",
+			MinioObjectKey:  fmt.Sprintf("files/%d", i),
+			ProcessStatus:   "pending",
+			SourceType:      "file",
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+	}
+
+	const existing, uploads = 40, 40
+	existingIDs := make([]string, existing)
+	for i := 0; i < existing; i++ {
+		doc := newDoc(i)
+		if err := repo.Create(ctx, doc); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		existingIDs[i] = doc.ID
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, existing+uploads)
+	for i := 0; i < uploads; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := repo.Create(ctx, newDoc(existing+i)); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	// 前一半单个删除（每个文档删除两次，第二次不应再扣减），后一半分两批批量删除
+	for i := 0; i < existing/2; i++ {
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				if err := repo.Delete(ctx, id); err != nil {
+					errs <- err
+				}
+			}(existingIDs[i])
+		}
+	}
+	for _, batch := range [][]string{existingIDs[existing/2 : existing*3/4], existingIDs[existing*3/4:]} {
+		wg.Add(1)
+		go func(ids []string) {
+			defer wg.Done()
+			if err := repo.BatchDelete(ctx, ids); err != nil {
+				errs <- err
+			}
+		}(batch)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent operation failed: %v", err)
+	}
+
+	var actual, count int64
+	if err := db.Raw("SELECT COUNT(*) FROM documents WHERE knowledge_base_id = ?", kbID).Scan(&actual).Error; err != nil {
+		t.Fatalf("Failed to count documents: %v", err)
+	}
+	if err := db.Raw("SELECT document_count FROM knowledge_bases WHERE id = ?", kbID).Scan(&count).Error; err != nil {
+		t.Fatalf("Failed to read document_count: %v", err)
+	}
+	if actual != uploads {
+		t.Errorf("Expected %d remaining documents, got %d", uploads, actual)
+	}
+	if count != actual {
+		t.Errorf("Expected document_count %d to match actual documents, got %d", actual, count)
+	}
+}
+
+func TestDocumentCount_CreateRollsBackWhenKnowledgeBaseMissing(t *testing.T) {
+	db, cleanup := setupPooledSchemaDB(t)
+	defer cleanup()
+
+	if err := db.Exec(`CREATE TABLE knowledge_bases (id UUID PRIMARY KEY, document_count BIGINT NOT NULL DEFAULT 0)`).Error; err != nil {
+		t.Fatalf("Failed to create knowledge_bases table: %v", err)
+	}
+	if err := db.AutoMigrate(&DocumentPO{}); err != nil {
+		t.Fatalf("Failed to create documents table: %v", err)
+	}
+
+	now := time.Now()
+	err := NewDocumentRepo(db).Create(context.Background(), &biz.Document{
+		ID:              uuid.New().String(),
+		KnowledgeBaseID: uuid.New().String(),
+		FileName:        "orphan.txt",
+		FileType:        "txt",
+		FileHash:        fmt.Sprintf("%064d", 0),
+		MinioBucket:     "knowledge-bases",
+		MinioObjectKey:  "files/orphan",
+		ProcessStatus:   "pending",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != biz.ErrKnowledgeBaseNotFound {
+		t.Fatalf("Expected ErrKnowledgeBaseNotFound, got %v", err)
+	}
+
+	var docs int64
+	if err := db.Raw("SELECT COUNT(*) FROM documents").Scan(&docs).Error; err != nil {
+		t.Fatalf("Failed to count documents: %v", err)
+	}
+	if docs != 0 {
+		t.Errorf("Expected document insert to be rolled back, got %d documents", docs)
+	}
+}
+
+func TestDocumentCreate_DedupHashConflict(t *testing.T) {
+	db, cleanup := setupPooledSchemaDB(t)
+	defer cleanup()
+
+	if err := db.Exec(`CREATE TABLE knowledge_bases (id UUID PRIMARY KEY, document_count BIGINT NOT NULL DEFAULT 0)`).Error; err != nil {
+		t.Fatalf("Failed to create knowledge_bases table: %v", err)
+	}
+	if err := db.AutoMigrate(&DocumentPO{}); err != nil {
+		t.Fatalf("Failed to create documents table: %v", err)
+	}
+
+	kbID := uuid.New().String()
+	if err := db.Exec("INSERT INTO knowledge_bases (id) VALUES (?)", kbID).Error; err != nil {
+		t.Fatalf("Failed to insert knowledge base: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := NewDocumentRepo(db)
+	hash := fmt.Sprintf("%064d", 7)
+	newDoc := func(name, dedupHash string) *biz.Document {
+		now := time.Now()
+		return &biz.Document{
+			ID:              uuid.New().String(),
+			KnowledgeBaseID: kbID,
+			FileName:        name,
+			FileType:        "txt",
+			FileHash:        hash,
+			MinioBucket:     "knowledge-bases",
+			MinioObjectKey:  "files/" + hash,
+			ProcessStatus:   "pending",
+			SourceType:      "file",
+			DedupHash:       dedupHash,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+	}
+
+	// allow 策略下不写入去重哈希，允许重复
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := repo.Create(ctx, newDoc(name, "")); err != nil {
+			t.Fatalf("Create without dedup hash failed: %v", err)
+		}
+	}
+
+	if err := repo.Create(ctx, newDoc("c.txt", hash)); err != nil {
+		t.Fatalf("First create with dedup hash failed: %v", err)
+	}
+	err := repo.Create(ctx, newDoc("d.txt", hash))
+	if !errors.Is(err, biz.ErrDocumentHashExists) {
+		t.Fatalf("Expected ErrDocumentHashExists, got %v", err)
+	}
+
+	var count int64
+	if err := db.Raw("SELECT document_count FROM knowledge_bases WHERE id = ?", kbID).Scan(&count).Error; err != nil {
+		t.Fatalf("Failed to read document_count: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected document_count 3 after rejected insert, got %d", count)
+	}
+}
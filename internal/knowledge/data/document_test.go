@@ -1,6 +1,8 @@
 package data
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -41,7 +43,7 @@ func TestDocumentPOMapping(t *testing.T) {
 		FileHash:        doc.FileHash,
 		MinioBucket:     doc.MinioBucket,
 		MinioObjectKey:  doc.MinioObjectKey,
-		ProcessStatus:   doc.ProcessStatus,
+		ProcessStatus:   doc.ProcessStatus.String(),
 		ProcessError:    doc.ProcessError,
 		ChunkCount:      doc.ChunkCount,
 		TokenCount:      doc.TokenCount,
@@ -73,3 +75,18 @@ func TestDocumentPOMapping(t *testing.T) {
 		t.Errorf("CreatedAt should not be zero time, got %s", formattedTime)
 	}
 }
+
+func TestDocumentRepo_RejectsInvalidStatusAndStage(t *testing.T) {
+	// 校验在访问数据库之前完成
+	repo := &DocumentRepo{}
+
+	err := repo.UpdateStatus(context.Background(), "test-id", biz.ProcessStatus("partial"), "")
+	if !errors.Is(err, biz.ErrInvalidProcessStatus) {
+		t.Errorf("Expected ErrInvalidProcessStatus, got %v", err)
+	}
+
+	err = repo.UpdateStage(context.Background(), "test-id", biz.ProcessStage("uploading"))
+	if !errors.Is(err, biz.ErrInvalidProcessStage) {
+		t.Errorf("Expected ErrInvalidProcessStage, got %v", err)
+	}
+}
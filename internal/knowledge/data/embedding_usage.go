@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+)
+
+// EmbeddingUsagePO Embedding 用量记录数据库模型
+type EmbeddingUsagePO struct {
+	ID              string    `gorm:"type:uuid;primarykey"`
+	KnowledgeBaseID string    `gorm:"column:knowledge_base_id;type:uuid;not null;index:idx_embedding_usage_kb_created"`
+	OwnerID         string    `gorm:"column:owner_id;type:uuid;not null;index:idx_embedding_usage_owner_created"`
+	DocumentID      string    `gorm:"column:document_id;type:uuid;not null"`
+	ModelID         string    `gorm:"column:model_id;type:uuid;not null"`
+	Tokens          int64     `gorm:"column:tokens;not null"`
+	CreatedAt       time.Time `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (EmbeddingUsagePO) TableName() string {
+	return "embedding_usage"
+}
+
+// EmbeddingUsageRepo Embedding 用量仓储实现
+type EmbeddingUsageRepo struct {
+	db *database.DB
+}
+
+// NewEmbeddingUsageRepo 创建 Embedding 用量仓储
+func NewEmbeddingUsageRepo(db *database.DB) biz.EmbeddingUsageRepo {
+	return &EmbeddingUsageRepo{db: db}
+}
+
+// Create 追加一条用量记录
+func (r *EmbeddingUsageRepo) Create(ctx context.Context, usage *biz.EmbeddingUsage) error {
+	po := &EmbeddingUsagePO{
+		ID:              usage.ID,
+		KnowledgeBaseID: usage.KnowledgeBaseID,
+		OwnerID:         usage.OwnerID,
+		DocumentID:      usage.DocumentID,
+		ModelID:         usage.ModelID,
+		Tokens:          usage.Tokens,
+		CreatedAt:       usage.CreatedAt,
+	}
+	if err := r.db.WithContext(ctx).GetDB().Create(po).Error; err != nil {
+		return fmt.Errorf("failed to create embedding usage: %w", err)
+	}
+	return nil
+}
+
+// SumByKnowledgeBase 按知识库汇总时间范围 [From, To) 内的用量
+func (r *EmbeddingUsageRepo) SumByKnowledgeBase(ctx context.Context, query *biz.EmbeddingUsageQuery) ([]*biz.KnowledgeBaseEmbeddingUsage, error) {
+	var rows []struct {
+		KnowledgeBaseID string
+		OwnerID         string
+		Tokens          int64
+		Runs            int64
+	}
+
+	db := r.db.WithContext(ctx).GetDB().
+		Model(&EmbeddingUsagePO{}).
+		Select("knowledge_base_id, owner_id, SUM(tokens) AS tokens, COUNT(*) AS runs").
+		Where("created_at >= ? AND created_at < ?", query.From, query.To)
+	if query.OwnerID != "" {
+		db = db.Where("owner_id = ?", query.OwnerID)
+	}
+
+	err := db.Group("knowledge_base_id, owner_id").
+		Order("tokens DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum embedding usage: %w", err)
+	}
+
+	usages := make([]*biz.KnowledgeBaseEmbeddingUsage, len(rows))
+	for i, row := range rows {
+		usages[i] = &biz.KnowledgeBaseEmbeddingUsage{
+			KnowledgeBaseID: row.KnowledgeBaseID,
+			OwnerID:         row.OwnerID,
+			Tokens:          row.Tokens,
+			Runs:            row.Runs,
+		}
+	}
+	return usages, nil
+}
@@ -0,0 +1,155 @@
+// +build integration
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+)
+
+// 集成测试说明:
+// 需要可用的 PostgreSQL（默认使用 docker-compose 配置），运行方式:
+//   go test -tags integration ./internal/knowledge/data/ -run TestFullTextSearchMigration
+
+const fullTextSearchMigration = "../../../migrations/00012_ensure_chunks_fulltext_search.sql"
+
+// setupSchemaDB 在独立 schema 中创建测试数据库连接（单连接，保证 search_path 生效）
+func setupSchemaDB(t *testing.T) (*database.DB, func()) {
+	cfg := database.DefaultConfig()
+	cfg.Host = getEnv("TEST_DB_HOST", "localhost")
+	cfg.User = getEnv("TEST_DB_USER", "postgres")
+	cfg.Password = getEnv("TEST_DB_PASSWORD", "postgres")
+	cfg.DBName = getEnv("TEST_DB_NAME", "aiwriter")
+	cfg.SSLMode = "disable"
+	cfg.MaxOpenConns = 1
+	cfg.MaxIdleConns = 1
+	cfg.PrepareStmt = false
+
+	log, err := logger.Development()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	db, err := database.New(cfg, log)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+
+	schema := fmt.Sprintf("fts_migration_test_%d", time.Now().UnixNano())
+	if err := db.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema)).Error; err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	if err := db.Exec(fmt.Sprintf("SET search_path TO %s", schema)).Error; err != nil {
+		t.Fatalf("Failed to set search_path: %v", err)
+	}
+
+	cleanup := func() {
+		db.Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", schema))
+		db.Close()
+	}
+	return db, cleanup
+}
+
+// applyUpMigration 执行 goose 迁移文件的 Up 部分
+func applyUpMigration(t *testing.T, db *database.DB, path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read migration: %v", err)
+	}
+
+	up := string(content)
+	if idx := strings.Index(up, "-- +goose Down"); idx >= 0 {
+		up = up[:idx]
+	}
+
+	if err := db.Exec(up).Error; err != nil {
+		t.Fatalf("Failed to apply migration: %v", err)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestFullTextSearchMigration(t *testing.T) {
+	db, cleanup := setupSchemaDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	kbID := uuid.New().String()
+	docID := uuid.New().String()
+
+	// 全新的 chunks 表（不含全文搜索列、触发器和函数）
+	err := db.Exec(`
+		CREATE TABLE chunks (
+			id UUID PRIMARY KEY,
+			document_id UUID NOT NULL,
+			knowledge_base_id UUID NOT NULL,
+			chunk_index INTEGER NOT NULL,
+			content TEXT NOT NULL,
+			token_count INTEGER NOT NULL,
+			milvus_id VARCHAR(100) NOT NULL UNIQUE,
+			metadata JSONB,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`).Error
+	if err != nil {
+		t.Fatalf("Failed to create chunks table: %v", err)
+	}
+
+	// 迁移前已存在的数据（需回填）
+	existingID := uuid.New().String()
+	err = db.Exec(`INSERT INTO chunks (id, document_id, knowledge_base_id, chunk_index, content, token_count, milvus_id, metadata)
+		VALUES (?, ?, ?, 0, 'legacy widget manual', 3, ?, '{}')`, existingID, docID, kbID, docID+"_0").Error
+	if err != nil {
+		t.Fatalf("Failed to insert existing chunk: %v", err)
+	}
+
+	// 迁移可重复执行
+	applyUpMigration(t, db, fullTextSearchMigration)
+	applyUpMigration(t, db, fullTextSearchMigration)
+
+	// 迁移后写入的数据由触发器维护
+	repo := NewChunkRepo(db)
+	newID := uuid.New().String()
+	err = repo.BatchCreate(ctx, []*biz.Chunk{
+		{ID: newID, DocumentID: docID, KnowledgeBaseID: kbID, Position: 1, Content: "new widget guide", TokenCount: 3, CreatedAt: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+
+	chunks, err := repo.KeywordSearch(ctx, kbID, "widget", 10)
+	if err != nil {
+		t.Fatalf("KeywordSearch failed after migration: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, chunk := range chunks {
+		found[chunk.ID] = true
+		if _, ok := chunk.Metadata["bm25_score"]; !ok {
+			t.Errorf("Expected bm25_score in metadata for chunk %s", chunk.ID)
+		}
+	}
+	if !found[existingID] || !found[newID] {
+		t.Errorf("Expected backfilled and new chunks to match, got %v", found)
+	}
+
+	chunks, err = repo.KeywordSearch(ctx, kbID, "legacy", 10)
+	if err != nil {
+		t.Fatalf("KeywordSearch failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].ID != existingID {
+		t.Errorf("Expected only the backfilled chunk for 'legacy', got %d results", len(chunks))
+	}
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -18,6 +19,7 @@ type KnowledgeBasePO struct {
 	RerankModelID    *string   `gorm:"type:uuid;index:idx_kb_rerank_model"`
 	ChunkSize        int       `gorm:"not null;default:512"`
 	ChunkOverlap     int       `gorm:"not null;default:50"`
+	ChunkOverlapUnit string    `gorm:"size:20;not null;default:'tokens'"`
 	ChunkStrategy    string    `gorm:"size:50;not null;default:'recursive'"`
 	MilvusCollection string    `gorm:"size:255;not null"`
 	DocumentCount    int64     `gorm:"not null;default:0"`
@@ -26,6 +28,9 @@ type KnowledgeBasePO struct {
 	Threshold           float32 `gorm:"type:real;not null;default:0.0"`
 	TopK                int     `gorm:"not null;default:5"`
 	EnableHybridSearch  bool    `gorm:"not null;default:false"`
+	KeywordStopwords    string  `gorm:"column:keyword_stopwords;type:jsonb;not null;default:'[]'"` // 关键词检索停用词（JSON 数组）
+	EmbeddingOverrides  string  `gorm:"column:embedding_overrides;type:jsonb;not null;default:'[]'"` // 按文件类型覆盖的 Embedding 模型（JSON 数组）
+	KnowledgeTemplate   *string `gorm:"column:knowledge_template;type:text"`                       // 检索结果注入模板（为空时使用全局模板）
 
 	CreatedAt        time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt        time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
@@ -55,12 +60,16 @@ func (r *KnowledgeBaseRepo) Create(ctx context.Context, kb *biz.KnowledgeBase) e
 		RerankModelID:    kb.RerankModelID,
 		ChunkSize:        kb.ChunkSize,
 		ChunkOverlap:     kb.ChunkOverlap,
+		ChunkOverlapUnit: kb.ChunkOverlapUnit,
 		ChunkStrategy:    kb.ChunkStrategy,
 		MilvusCollection: kb.MilvusCollection,
 		DocumentCount:    kb.DocumentCount,
 		Threshold:        kb.Threshold,
 		TopK:             kb.TopK,
 		EnableHybridSearch: kb.EnableHybridSearch,
+		KeywordStopwords: marshalStopwords(kb.KeywordStopwords),
+		EmbeddingOverrides: marshalEmbeddingOverrides(kb.EmbeddingOverrides),
+		KnowledgeTemplate: kb.KnowledgeTemplate,
 		CreatedAt:        kb.CreatedAt,
 		UpdatedAt:        kb.UpdatedAt,
 	}
@@ -135,6 +144,8 @@ func (r *KnowledgeBaseRepo) Update(ctx context.Context, kb *biz.KnowledgeBase) e
 		"threshold":            kb.Threshold,
 		"top_k":                kb.TopK,
 		"enable_hybrid_search": kb.EnableHybridSearch,
+		"keyword_stopwords":    marshalStopwords(kb.KeywordStopwords),
+		"knowledge_template":   kb.KnowledgeTemplate,
 		"updated_at":           kb.UpdatedAt,
 	}
 
@@ -215,6 +226,19 @@ func (r *KnowledgeBaseRepo) BatchUpdateDocumentCounts(ctx context.Context, delta
 	return nil
 }
 
+// ListIDs 列出所有知识库 ID（按创建时间排序）
+func (r *KnowledgeBaseRepo) ListIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).GetDB().
+		Model(&KnowledgeBasePO{}).
+		Order("created_at ASC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge base ids: %w", err)
+	}
+	return ids, nil
+}
+
 // toKnowledgeBase 转换 PO 到业务对象
 func (r *KnowledgeBaseRepo) toKnowledgeBase(po *KnowledgeBasePO) *biz.KnowledgeBase {
 	return &biz.KnowledgeBase{
@@ -225,13 +249,61 @@ func (r *KnowledgeBaseRepo) toKnowledgeBase(po *KnowledgeBasePO) *biz.KnowledgeB
 		RerankModelID:    po.RerankModelID,
 		ChunkSize:        po.ChunkSize,
 		ChunkOverlap:     po.ChunkOverlap,
+		ChunkOverlapUnit: po.ChunkOverlapUnit,
 		ChunkStrategy:    po.ChunkStrategy,
 		MilvusCollection: po.MilvusCollection,
 		DocumentCount:    po.DocumentCount,
 		Threshold:        po.Threshold,
 		TopK:             po.TopK,
 		EnableHybridSearch: po.EnableHybridSearch,
+		KeywordStopwords: unmarshalStopwords(po.KeywordStopwords),
+		EmbeddingOverrides: unmarshalEmbeddingOverrides(po.EmbeddingOverrides),
+		KnowledgeTemplate: po.KnowledgeTemplate,
 		CreatedAt:        po.CreatedAt,
 		UpdatedAt:        po.UpdatedAt,
 	}
 }
+
+// marshalStopwords 序列化停用词列表为 JSON 数组
+func marshalStopwords(stopwords []string) string {
+	if len(stopwords) == 0 {
+		return "[]"
+	}
+	bytes, err := json.Marshal(stopwords)
+	if err != nil {
+		return "[]"
+	}
+	return string(bytes)
+}
+
+// unmarshalStopwords 反序列化停用词列表
+func unmarshalStopwords(value string) []string {
+	if value == "" || value == "[]" {
+		return nil
+	}
+	var stopwords []string
+	_ = json.Unmarshal([]byte(value), &stopwords)
+	return stopwords
+}
+
+// marshalEmbeddingOverrides 序列化 Embedding 覆盖配置为 JSON 数组
+func marshalEmbeddingOverrides(overrides []biz.EmbeddingOverride) string {
+	if len(overrides) == 0 {
+		return "[]"
+	}
+	bytes, err := json.Marshal(overrides)
+	if err != nil {
+		return "[]"
+	}
+	return string(bytes)
+}
+
+// unmarshalEmbeddingOverrides 反序列化 Embedding 覆盖配置
+func unmarshalEmbeddingOverrides(value string) []biz.EmbeddingOverride {
+	if value == "" || value == "[]" {
+		return nil
+	}
+	var overrides []biz.EmbeddingOverride
+	_ = json.Unmarshal([]byte(value), &overrides)
+	return overrides
+}
@@ -0,0 +1,80 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/database"
+	"gorm.io/gorm/clause"
+)
+
+// KnowledgeBaseDefaultsPO 用户知识库默认设置数据库模型（NULL 表示未设置）
+type KnowledgeBaseDefaultsPO struct {
+	UserID           string  `gorm:"type:uuid;primarykey"`
+	EmbeddingModelID *string `gorm:"type:uuid"`
+	ChunkSize        *int
+	ChunkOverlap     *int
+	ChunkStrategy    *string `gorm:"size:50"`
+	TopK             *int
+	Threshold        *float32  `gorm:"type:real"`
+	UpdatedAt        time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (KnowledgeBaseDefaultsPO) TableName() string {
+	return "user_knowledge_base_defaults"
+}
+
+// KnowledgeBaseDefaultsRepo 用户知识库默认设置仓储实现
+type KnowledgeBaseDefaultsRepo struct {
+	db *database.DB
+}
+
+// NewKnowledgeBaseDefaultsRepo 创建用户知识库默认设置仓储
+func NewKnowledgeBaseDefaultsRepo(db *database.DB) biz.KnowledgeBaseDefaultsRepo {
+	return &KnowledgeBaseDefaultsRepo{db: db}
+}
+
+// Get 获取用户的默认设置（未设置时返回 nil）
+func (r *KnowledgeBaseDefaultsRepo) Get(ctx context.Context, userID string) (*biz.KnowledgeBaseDefaults, error) {
+	var po KnowledgeBaseDefaultsPO
+	err := r.db.WithContext(ctx).GetDB().Where("user_id = ?", userID).First(&po).Error
+	if err != nil {
+		if database.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &biz.KnowledgeBaseDefaults{
+		UserID:           po.UserID,
+		EmbeddingModelID: po.EmbeddingModelID,
+		ChunkSize:        po.ChunkSize,
+		ChunkOverlap:     po.ChunkOverlap,
+		ChunkStrategy:    po.ChunkStrategy,
+		TopK:             po.TopK,
+		Threshold:        po.Threshold,
+		UpdatedAt:        po.UpdatedAt,
+	}, nil
+}
+
+// Upsert 创建或整体替换用户的默认设置
+func (r *KnowledgeBaseDefaultsRepo) Upsert(ctx context.Context, defaults *biz.KnowledgeBaseDefaults) error {
+	po := &KnowledgeBaseDefaultsPO{
+		UserID:           defaults.UserID,
+		EmbeddingModelID: defaults.EmbeddingModelID,
+		ChunkSize:        defaults.ChunkSize,
+		ChunkOverlap:     defaults.ChunkOverlap,
+		ChunkStrategy:    defaults.ChunkStrategy,
+		TopK:             defaults.TopK,
+		Threshold:        defaults.Threshold,
+		UpdatedAt:        defaults.UpdatedAt,
+	}
+
+	return r.db.WithContext(ctx).GetDB().
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			UpdateAll: true,
+		}).
+		Create(po).Error
+}
@@ -74,3 +74,20 @@ func (s *MinIOStorageService) DeleteFile(ctx context.Context, bucket, objectName
 
 	return nil
 }
+
+// ObjectExists 检查对象是否存在
+func (s *MinIOStorageService) ObjectExists(ctx context.Context, bucket, objectName string) (bool, error) {
+	if bucket == "" {
+		bucket = s.bucket
+	}
+
+	_, err := s.client.StatObject(ctx, bucket, objectName, pkgminio.StatObjectOptions{})
+	if err != nil {
+		if pkgminio.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	return true, nil
+}
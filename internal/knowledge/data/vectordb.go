@@ -3,6 +3,7 @@ package data
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/milvus"
@@ -13,26 +14,38 @@ import (
 )
 
 // MilvusVectorDBService 实现 biz.VectorDBService 接口
+// CreateCollection、HasCollection、InsertVectors、Search、DeleteByDocumentID 的每一步 Milvus 调用在遇到瞬时错误时按 retry 配置退避重试
 type MilvusVectorDBService struct {
-	client *milvus.Client
+	ops         milvusOperations
+	retry       VectorRetryConfig
+	autoFlush   bool                     // 插入后不显式 Flush
+	consistency *entity.ConsistencyLevel // 搜索一致性级别（nil 使用 collection 默认级别）
+}
+
+// NewMilvusVectorDBService 创建 Milvus 向量数据库服务（consistency 需先通过 Validate 校验，无效的一致性级别被忽略）
+func NewMilvusVectorDBService(client *milvus.Client, retry VectorRetryConfig, consistency VectorConsistencyConfig) *MilvusVectorDBService {
+	return newMilvusVectorDBService(&sdkMilvusOperations{client: client}, retry, consistency)
 }
 
-// NewMilvusVectorDBService 创建 Milvus 向量数据库服务
-func NewMilvusVectorDBService(client *milvus.Client) *MilvusVectorDBService {
+func newMilvusVectorDBService(ops milvusOperations, retry VectorRetryConfig, consistency VectorConsistencyConfig) *MilvusVectorDBService {
+	level, _ := consistency.searchConsistency()
 	return &MilvusVectorDBService{
-		client: client,
+		ops:         ops,
+		retry:       retry.withDefaults(),
+		autoFlush:   consistency.AutoFlush,
+		consistency: level,
 	}
 }
 
 // CreateCollection 创建向量 collection
 func (s *MilvusVectorDBService) CreateCollection(ctx context.Context, collectionName string, dimension int) error {
-	cli := s.client.GetClient()
-	if cli == nil {
-		return fmt.Errorf("milvus client is not available")
-	}
-
 	// 检查 collection 是否已存在
-	has, err := cli.HasCollection(ctx, milvusclient.NewHasCollectionOption(collectionName))
+	var has bool
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		has, err = s.ops.HasCollection(ctx, collectionName)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to check collection: %w", err)
 	}
@@ -47,35 +60,49 @@ func (s *MilvusVectorDBService) CreateCollection(ctx context.Context, collection
 		WithField(entity.NewField().WithName("id").WithDataType(entity.FieldTypeVarChar).WithMaxLength(64).WithIsPrimaryKey(true)).
 		WithField(entity.NewField().WithName("document_id").WithDataType(entity.FieldTypeVarChar).WithMaxLength(64)).
 		WithField(entity.NewField().WithName("chunk_id").WithDataType(entity.FieldTypeVarChar).WithMaxLength(64)).
-		WithField(entity.NewField().WithName("content").WithDataType(entity.FieldTypeVarChar).WithMaxLength(65535)).
+		WithField(entity.NewField().WithName("content").WithDataType(entity.FieldTypeVarChar).WithMaxLength(biz.MilvusContentMaxBytes)).
 		WithField(entity.NewField().WithName("embedding").WithDataType(entity.FieldTypeFloatVector).WithDim(int64(dimension)))
 
 	// 创建 collection
-	err = cli.CreateCollection(ctx, milvusclient.NewCreateCollectionOption(collectionName, schema))
+	err = s.withRetry(ctx, func(ctx context.Context) error {
+		return s.ops.CreateCollection(ctx, collectionName, schema)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
 
 	// 创建向量索引
 	idx := index.NewAutoIndex(entity.COSINE)
-	_, err = cli.CreateIndex(ctx, milvusclient.NewCreateIndexOption(collectionName, "embedding", idx))
+	err = s.withRetry(ctx, func(ctx context.Context) error {
+		return s.ops.CreateIndex(ctx, collectionName, "embedding", idx)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
-	// 加载 collection
-	loadTask, err := cli.LoadCollection(ctx, milvusclient.NewLoadCollectionOption(collectionName))
+	// 加载 collection 并等待加载完成
+	err = s.withRetry(ctx, func(ctx context.Context) error {
+		return s.ops.LoadCollection(ctx, collectionName)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to load collection: %w", err)
 	}
 
-	// 等待加载完成
-	err = loadTask.Await(ctx)
+	return nil
+}
+
+// HasCollection 检查 collection 是否存在
+func (s *MilvusVectorDBService) HasCollection(ctx context.Context, collectionName string) (bool, error) {
+	var has bool
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		has, err = s.ops.HasCollection(ctx, collectionName)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to wait for collection load: %w", err)
+		return false, fmt.Errorf("failed to check collection: %w", err)
 	}
-
-	return nil
+	return has, nil
 }
 
 // InsertVectors 批量插入向量
@@ -84,11 +111,6 @@ func (s *MilvusVectorDBService) InsertVectors(ctx context.Context, collectionNam
 		return nil
 	}
 
-	cli := s.client.GetClient()
-	if cli == nil {
-		return fmt.Errorf("milvus client is not available")
-	}
-
 	// 准备数据列
 	ids := make([]string, len(chunks))
 	documentIDs := make([]string, len(chunks))
@@ -111,23 +133,25 @@ func (s *MilvusVectorDBService) InsertVectors(ctx context.Context, collectionNam
 	contentColumn := column.NewColumnVarChar("content", contents)
 	embeddingColumn := column.NewColumnFloatVector("embedding", len(embeddings[0]), embeddings)
 
-	// 插入数据
-	_, err := cli.Insert(ctx, milvusclient.NewColumnBasedInsertOption(collectionName).
-		WithColumns(idColumn, documentIDColumn, chunkIDColumn, contentColumn, embeddingColumn))
-
+	// 按分块 ID（主键）upsert：请求实际已写入但响应失败时重试不会产生重复向量
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		return s.ops.Upsert(ctx, collectionName, idColumn, documentIDColumn, chunkIDColumn, contentColumn, embeddingColumn)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to insert vectors: %w", err)
 	}
 
-	// 刷新 collection 以确保数据持久化
-	flushTask, err := cli.Flush(ctx, milvusclient.NewFlushOption(collectionName))
-	if err != nil {
-		return fmt.Errorf("failed to flush: %w", err)
+	// 依赖 Milvus 自动刷新时直接返回（新向量在一致性窗口内可能搜索不到）
+	if s.autoFlush {
+		return nil
 	}
 
-	err = flushTask.Await(ctx)
+	// 刷新 collection 以确保数据持久化
+	err = s.withRetry(ctx, func(ctx context.Context) error {
+		return s.ops.Flush(ctx, collectionName)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to wait for flush: %w", err)
+		return fmt.Errorf("failed to flush: %w", err)
 	}
 
 	return nil
@@ -140,18 +164,13 @@ func (s *MilvusVectorDBService) Search(ctx context.Context, collectionName strin
 
 // SearchWithThreshold 向量搜索（带阈值过滤）
 func (s *MilvusVectorDBService) SearchWithThreshold(ctx context.Context, collectionName string, vector []float32, topK int, minScore float32) ([]*biz.SearchResult, error) {
-	cli := s.client.GetClient()
-	if cli == nil {
-		return nil, fmt.Errorf("milvus client is not available")
-	}
-
 	// 执行搜索
-	searchResult, err := cli.Search(ctx, milvusclient.NewSearchOption(
-		collectionName,
-		topK,
-		[]entity.Vector{entity.FloatVector(vector)},
-	).WithOutputFields("document_id", "chunk_id", "content"))
-
+	var searchResult []milvusclient.ResultSet
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		searchResult, err = s.ops.Search(ctx, collectionName, topK, vector, s.consistency, "document_id", "chunk_id", "content")
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
@@ -189,46 +208,208 @@ func (s *MilvusVectorDBService) SearchWithThreshold(ctx context.Context, collect
 
 // DeleteByDocumentID 根据文档 ID 删除向量（完整实现）
 func (s *MilvusVectorDBService) DeleteByDocumentID(ctx context.Context, collectionName, documentID string) error {
-	cli := s.client.GetClient()
-	if cli == nil {
-		return fmt.Errorf("milvus client is not available")
-	}
-
 	// 使用表达式删除所有匹配的向量
 	expr := fmt.Sprintf("document_id == '%s'", documentID)
-	deleteOpt := milvusclient.NewDeleteOption(collectionName)
-	deleteOpt.WithExpr(expr)
-	
-	_, err := cli.Delete(ctx, deleteOpt)
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		return s.ops.Delete(ctx, collectionName, expr)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete by document_id: %w", err)
 	}
 
 	// 刷新以确保删除立即生效
-	flushTask, err := cli.Flush(ctx, milvusclient.NewFlushOption(collectionName))
+	err = s.withRetry(ctx, func(ctx context.Context) error {
+		return s.ops.Flush(ctx, collectionName)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to flush after delete: %w", err)
 	}
 
-	err = flushTask.Await(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to wait for flush after delete: %w", err)
-	}
-
 	return nil
 }
 
 // DropCollection 删除 collection
 func (s *MilvusVectorDBService) DropCollection(ctx context.Context, collectionName string) error {
-	cli := s.client.GetClient()
+	if err := s.ops.DropCollection(ctx, collectionName); err != nil {
+		return fmt.Errorf("failed to drop collection: %w", err)
+	}
+
+	return nil
+}
+
+// compactionPollInterval 等待 Milvus 压缩完成时的轮询间隔
+const compactionPollInterval = time.Second
+
+// CompactCollection 触发 collection 压缩（清理已删除的实体）并等待完成
+func (s *MilvusVectorDBService) CompactCollection(ctx context.Context, collectionName string) error {
+	compactionID, err := s.ops.Compact(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("failed to compact collection: %w", err)
+	}
+
+	ticker := time.NewTicker(compactionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		state, err := s.ops.GetCompactionState(ctx, compactionID)
+		if err != nil {
+			return fmt.Errorf("failed to get compaction state: %w", err)
+		}
+		if state == entity.CompactionStateCompleted {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for compaction: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// milvusOperations MilvusVectorDBService 依赖的 Milvus 调用，异步任务（建索引、加载、刷新）在内部等待完成
+type milvusOperations interface {
+	HasCollection(ctx context.Context, collectionName string) (bool, error)
+	CreateCollection(ctx context.Context, collectionName string, schema *entity.Schema) error
+	CreateIndex(ctx context.Context, collectionName, fieldName string, idx index.Index) error
+	LoadCollection(ctx context.Context, collectionName string) error
+	Upsert(ctx context.Context, collectionName string, columns ...column.Column) error
+	Flush(ctx context.Context, collectionName string) error
+	Search(ctx context.Context, collectionName string, topK int, vector []float32, consistency *entity.ConsistencyLevel, outputFields ...string) ([]milvusclient.ResultSet, error) // consistency 为 nil 时使用 collection 默认级别
+	Delete(ctx context.Context, collectionName, expr string) error
+	DropCollection(ctx context.Context, collectionName string) error
+	Compact(ctx context.Context, collectionName string) (int64, error)
+	GetCompactionState(ctx context.Context, compactionID int64) (entity.CompactionState, error)
+}
+
+// errMilvusUnavailable Milvus 客户端未初始化
+var errMilvusUnavailable = fmt.Errorf("milvus client is not available")
+
+// sdkMilvusOperations 基于 Milvus SDK 客户端的 milvusOperations 实现
+type sdkMilvusOperations struct {
+	client *milvus.Client
+}
+
+func (o *sdkMilvusOperations) cli() (*milvusclient.Client, error) {
+	cli := o.client.GetClient()
 	if cli == nil {
-		return fmt.Errorf("milvus client is not available")
+		return nil, errMilvusUnavailable
 	}
+	return cli, nil
+}
 
-	err := cli.DropCollection(ctx, milvusclient.NewDropCollectionOption(collectionName))
+func (o *sdkMilvusOperations) HasCollection(ctx context.Context, collectionName string) (bool, error) {
+	cli, err := o.cli()
 	if err != nil {
-		return fmt.Errorf("failed to drop collection: %w", err)
+		return false, err
+	}
+	return cli.HasCollection(ctx, milvusclient.NewHasCollectionOption(collectionName))
+}
+
+func (o *sdkMilvusOperations) CreateCollection(ctx context.Context, collectionName string, schema *entity.Schema) error {
+	cli, err := o.cli()
+	if err != nil {
+		return err
 	}
+	return cli.CreateCollection(ctx, milvusclient.NewCreateCollectionOption(collectionName, schema))
+}
+
+func (o *sdkMilvusOperations) CreateIndex(ctx context.Context, collectionName, fieldName string, idx index.Index) error {
+	cli, err := o.cli()
+	if err != nil {
+		return err
+	}
+	task, err := cli.CreateIndex(ctx, milvusclient.NewCreateIndexOption(collectionName, fieldName, idx))
+	if err != nil {
+		return err
+	}
+	return task.Await(ctx)
+}
 
+func (o *sdkMilvusOperations) LoadCollection(ctx context.Context, collectionName string) error {
+	cli, err := o.cli()
+	if err != nil {
+		return err
+	}
+	task, err := cli.LoadCollection(ctx, milvusclient.NewLoadCollectionOption(collectionName))
+	if err != nil {
+		return err
+	}
+	if err := task.Await(ctx); err != nil {
+		return fmt.Errorf("failed to wait for collection load: %w", err)
+	}
+	return nil
+}
+
+func (o *sdkMilvusOperations) Upsert(ctx context.Context, collectionName string, columns ...column.Column) error {
+	cli, err := o.cli()
+	if err != nil {
+		return err
+	}
+	_, err = cli.Upsert(ctx, milvusclient.NewColumnBasedInsertOption(collectionName).WithColumns(columns...))
+	return err
+}
+
+func (o *sdkMilvusOperations) Flush(ctx context.Context, collectionName string) error {
+	cli, err := o.cli()
+	if err != nil {
+		return err
+	}
+	task, err := cli.Flush(ctx, milvusclient.NewFlushOption(collectionName))
+	if err != nil {
+		return err
+	}
+	if err := task.Await(ctx); err != nil {
+		return fmt.Errorf("failed to wait for flush: %w", err)
+	}
 	return nil
 }
+
+func (o *sdkMilvusOperations) Search(ctx context.Context, collectionName string, topK int, vector []float32, consistency *entity.ConsistencyLevel, outputFields ...string) ([]milvusclient.ResultSet, error) {
+	cli, err := o.cli()
+	if err != nil {
+		return nil, err
+	}
+	opt := milvusclient.NewSearchOption(
+		collectionName,
+		topK,
+		[]entity.Vector{entity.FloatVector(vector)},
+	).WithOutputFields(outputFields...)
+	if consistency != nil {
+		opt = opt.WithConsistencyLevel(*consistency)
+	}
+	return cli.Search(ctx, opt)
+}
+
+func (o *sdkMilvusOperations) Delete(ctx context.Context, collectionName, expr string) error {
+	cli, err := o.cli()
+	if err != nil {
+		return err
+	}
+	_, err = cli.Delete(ctx, milvusclient.NewDeleteOption(collectionName).WithExpr(expr))
+	return err
+}
+
+func (o *sdkMilvusOperations) DropCollection(ctx context.Context, collectionName string) error {
+	cli, err := o.cli()
+	if err != nil {
+		return err
+	}
+	return cli.DropCollection(ctx, milvusclient.NewDropCollectionOption(collectionName))
+}
+
+func (o *sdkMilvusOperations) Compact(ctx context.Context, collectionName string) (int64, error) {
+	cli, err := o.cli()
+	if err != nil {
+		return 0, err
+	}
+	return cli.Compact(ctx, milvusclient.NewCompactOption(collectionName))
+}
+
+func (o *sdkMilvusOperations) GetCompactionState(ctx context.Context, compactionID int64) (entity.CompactionState, error) {
+	cli, err := o.cli()
+	if err != nil {
+		return 0, err
+	}
+	return cli.GetCompactionState(ctx, milvusclient.NewGetCompactionStateOption(compactionID))
+}
@@ -0,0 +1,85 @@
+// +build integration
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/milvus"
+)
+
+// 集成测试说明:
+// 需要可用的 Milvus（默认 localhost:19530，可通过 TEST_MILVUS_ADDR 指定），运行方式:
+//   go test -tags integration ./internal/knowledge/data/ -run TestCompactCollection
+
+func TestCompactCollection_KeepsRemainingVectors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	log, err := logger.Development()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	client, err := milvus.New(ctx, &milvus.Config{Address: getEnv("TEST_MILVUS_ADDR", "localhost:19530")}, log)
+	if err != nil {
+		t.Skipf("Milvus not available: %v", err)
+	}
+	defer client.Close(ctx)
+
+	svc := NewMilvusVectorDBService(client, DefaultVectorRetryConfig(), VectorConsistencyConfig{})
+	collection := fmt.Sprintf("compaction_test_%d", time.Now().UnixNano())
+	const dims = 4
+	if err := svc.CreateCollection(ctx, collection, dims); err != nil {
+		t.Fatalf("CreateCollection failed: %v", err)
+	}
+	defer svc.DropCollection(ctx, collection)
+
+	// 10 个文档各 1 个向量
+	chunks := make([]*biz.Chunk, 10)
+	for i := range chunks {
+		chunks[i] = &biz.Chunk{
+			ID:         fmt.Sprintf("chunk-%d", i),
+			DocumentID: fmt.Sprintf("doc-%d", i),
+			Content:    fmt.Sprintf("content %d", i),
+			Embedding:  []float32{1, float32(i), 0.5, 0.25},
+		}
+	}
+	if err := svc.InsertVectors(ctx, collection, chunks); err != nil {
+		t.Fatalf("InsertVectors failed: %v", err)
+	}
+
+	deleted := map[string]bool{"doc-1": true, "doc-4": true, "doc-7": true}
+	for docID := range deleted {
+		if err := svc.DeleteByDocumentID(ctx, collection, docID); err != nil {
+			t.Fatalf("DeleteByDocumentID(%s) failed: %v", docID, err)
+		}
+	}
+
+	if err := svc.CompactCollection(ctx, collection); err != nil {
+		t.Fatalf("CompactCollection failed: %v", err)
+	}
+
+	results, err := svc.Search(ctx, collection, []float32{1, 0, 0.5, 0.25}, 100)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, result := range results {
+		found[result.DocumentID] = true
+	}
+	for _, chunk := range chunks {
+		if deleted[chunk.DocumentID] && found[chunk.DocumentID] {
+			t.Errorf("Deleted document %s still present after compaction", chunk.DocumentID)
+		}
+		if !deleted[chunk.DocumentID] && !found[chunk.DocumentID] {
+			t.Errorf("Document %s lost after compaction", chunk.DocumentID)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/milvus-io/milvus/client/v2/entity"
+)
+
+// 向量搜索一致性级别（与 Milvus 一致性级别对应）
+const (
+	SearchConsistencyStrong     = "Strong"     // 读取搜索发起前的所有写入（延迟最高）
+	SearchConsistencyBounded    = "Bounded"    // 允许一定时间窗口内的陈旧数据（collection 默认级别）
+	SearchConsistencySession    = "Session"    // 同一客户端的写入对自身可见
+	SearchConsistencyEventually = "Eventually" // 最终一致（延迟最低）
+)
+
+// VectorConsistencyConfig 向量写入刷新与搜索一致性配置
+//
+// 默认在插入后显式 Flush 并等待完成，文档标记为 completed 时向量即可被搜索到。
+// AutoFlush 为 true 时不显式 Flush，由 Milvus 按段大小/时间自动刷新以提高写入吞吐；
+// 此时新写入的向量在 Bounded 一致性下通常需要数秒（Milvus 默认的 graceful time 窗口）才能被搜索到，
+// 需要上传后立即可搜索时应将 SearchConsistencyLevel 设为 Strong（或同一客户端内使用 Session）。
+type VectorConsistencyConfig struct {
+	AutoFlush              bool   // 插入后不显式 Flush，依赖 Milvus 自动刷新
+	SearchConsistencyLevel string // Strong, Bounded, Session, Eventually（为空使用 collection 默认级别）
+}
+
+// Validate 校验搜索一致性级别
+func (c VectorConsistencyConfig) Validate() error {
+	_, err := c.searchConsistency()
+	return err
+}
+
+// searchConsistency 解析搜索一致性级别（未配置时返回 nil，使用 collection 默认级别）
+func (c VectorConsistencyConfig) searchConsistency() (*entity.ConsistencyLevel, error) {
+	var level entity.ConsistencyLevel
+	switch c.SearchConsistencyLevel {
+	case "":
+		return nil, nil
+	case SearchConsistencyStrong:
+		level = entity.ClStrong
+	case SearchConsistencyBounded:
+		level = entity.ClBounded
+	case SearchConsistencySession:
+		level = entity.ClSession
+	case SearchConsistencyEventually:
+		level = entity.ClEventually
+	default:
+		return nil, fmt.Errorf("invalid search consistency level %q (expected Strong, Bounded, Session or Eventually)", c.SearchConsistencyLevel)
+	}
+	return &level, nil
+}
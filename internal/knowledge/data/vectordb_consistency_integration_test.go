@@ -0,0 +1,59 @@
+// +build integration
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/milvus"
+)
+
+// 集成测试说明:
+// 需要可用的 Milvus（默认 localhost:19530，可通过 TEST_MILVUS_ADDR 指定），运行方式:
+//   go test -tags integration ./internal/knowledge/data/ -run TestSearchAfterInsert
+
+func TestSearchAfterInsert_FlushEnabled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	log, err := logger.Development()
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	client, err := milvus.New(ctx, &milvus.Config{Address: getEnv("TEST_MILVUS_ADDR", "localhost:19530")}, log)
+	if err != nil {
+		t.Skipf("Milvus not available: %v", err)
+	}
+	defer client.Close(ctx)
+
+	// 显式 Flush（默认配置），插入返回后立即搜索
+	svc := NewMilvusVectorDBService(client, DefaultVectorRetryConfig(), VectorConsistencyConfig{})
+	collection := fmt.Sprintf("flush_test_%d", time.Now().UnixNano())
+	const dims = 4
+	if err := svc.CreateCollection(ctx, collection, dims); err != nil {
+		t.Fatalf("CreateCollection failed: %v", err)
+	}
+	defer svc.DropCollection(ctx, collection)
+
+	chunks := []*biz.Chunk{
+		{ID: "chunk-0", DocumentID: "doc-0", Content: "first", Embedding: []float32{1, 0, 0, 0}},
+		{ID: "chunk-1", DocumentID: "doc-1", Content: "second", Embedding: []float32{0, 1, 0, 0}},
+	}
+	if err := svc.InsertVectors(ctx, collection, chunks); err != nil {
+		t.Fatalf("InsertVectors failed: %v", err)
+	}
+
+	results, err := svc.Search(ctx, collection, []float32{1, 0, 0, 0}, 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) == 0 || results[0].ChunkID != "chunk-0" {
+		t.Fatalf("Expected inserted vectors to be searchable right after insert, got %+v", results)
+	}
+}
@@ -0,0 +1,77 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/milvus-io/milvus/client/v2/entity"
+)
+
+func TestMilvusVectorDBService_FlushAfterInsert(t *testing.T) {
+	chunks := []*biz.Chunk{{ID: "chunk-1", DocumentID: "doc-1", Content: "hello", Embedding: []float32{1, 0, 0, 0}}}
+
+	tests := []struct {
+		name       string
+		autoFlush  bool
+		wantFlushes int
+	}{
+		{name: "explicit flush by default", autoFlush: false, wantFlushes: 1},
+		{name: "auto flush skips explicit flush", autoFlush: true, wantFlushes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := newFlakyMilvusOperations(nil, nil)
+			svc := newMilvusVectorDBService(ops, testRetryConfig(), VectorConsistencyConfig{AutoFlush: tt.autoFlush})
+
+			if err := svc.InsertVectors(context.Background(), "kb_test", chunks); err != nil {
+				t.Fatalf("InsertVectors failed: %v", err)
+			}
+			if got := ops.callCount("Flush"); got != tt.wantFlushes {
+				t.Errorf("Expected %d flush calls, got %d", tt.wantFlushes, got)
+			}
+		})
+	}
+}
+
+func TestMilvusVectorDBService_SearchConsistencyLevel(t *testing.T) {
+	tests := []struct {
+		level string
+		want  *entity.ConsistencyLevel
+	}{
+		{level: "", want: nil},
+		{level: SearchConsistencyStrong, want: consistencyLevel(entity.ClStrong)},
+		{level: SearchConsistencyBounded, want: consistencyLevel(entity.ClBounded)},
+		{level: SearchConsistencySession, want: consistencyLevel(entity.ClSession)},
+		{level: SearchConsistencyEventually, want: consistencyLevel(entity.ClEventually)},
+	}
+
+	for _, tt := range tests {
+		t.Run("level "+tt.level, func(t *testing.T) {
+			cfg := VectorConsistencyConfig{SearchConsistencyLevel: tt.level}
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+
+			ops := newFlakyMilvusOperations(nil, nil)
+			svc := newMilvusVectorDBService(ops, testRetryConfig(), cfg)
+			if _, err := svc.Search(context.Background(), "kb_test", []float32{1, 0, 0, 0}, 5); err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			got := ops.searchConsistency
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Expected consistency %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if err := (VectorConsistencyConfig{SearchConsistencyLevel: "strong-ish"}).Validate(); err == nil {
+		t.Error("Expected invalid consistency level to be rejected")
+	}
+}
+
+func consistencyLevel(level entity.ConsistencyLevel) *entity.ConsistencyLevel {
+	return &level
+}
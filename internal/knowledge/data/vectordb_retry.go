@@ -0,0 +1,112 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 向量操作重试的默认参数
+const (
+	DefaultVectorRetryMaxAttempts    = 3
+	DefaultVectorRetryInitialBackoff = 200 * time.Millisecond
+	DefaultVectorRetryMaxBackoff     = 2 * time.Second
+)
+
+// VectorRetryConfig 向量数据库操作的重试配置
+type VectorRetryConfig struct {
+	MaxAttempts    int           // 最大尝试次数（含首次），1 表示不重试
+	InitialBackoff time.Duration // 首次重试前的等待时间，之后每次翻倍
+	MaxBackoff     time.Duration // 单次等待时间上限
+}
+
+// DefaultVectorRetryConfig 默认重试配置
+func DefaultVectorRetryConfig() VectorRetryConfig {
+	return VectorRetryConfig{
+		MaxAttempts:    DefaultVectorRetryMaxAttempts,
+		InitialBackoff: DefaultVectorRetryInitialBackoff,
+		MaxBackoff:     DefaultVectorRetryMaxBackoff,
+	}
+}
+
+// withDefaults 未配置（零值或负值）的字段使用默认值
+func (c VectorRetryConfig) withDefaults() VectorRetryConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultVectorRetryMaxAttempts
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultVectorRetryInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultVectorRetryMaxBackoff
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		c.MaxBackoff = c.InitialBackoff
+	}
+	return c
+}
+
+// backoff 第 attempt 次重试（从 1 开始）前的等待时间
+func (c VectorRetryConfig) backoff(attempt int) time.Duration {
+	d := c.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= c.MaxBackoff {
+			return c.MaxBackoff
+		}
+	}
+	return d
+}
+
+// withRetry 执行 fn，遇到可重试错误时按指数退避重试，直到成功、遇到不可重试错误、次数用尽或 ctx 结束
+func (s *MilvusVectorDBService) withRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; attempt <= s.retry.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(s.retry.backoff(attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%w (retry aborted: %v)", err, ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		err = fn(ctx)
+		if err == nil || !isRetriableVectorError(err) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w (gave up after %d attempts)", err, s.retry.MaxAttempts)
+}
+
+// isRetriableVectorError 判断 Milvus 错误是否可重试
+// 仅服务暂不可用、限流等瞬时错误可重试；参数、schema、维度不匹配等错误以及 ctx 取消/超时都不重试
+func isRetriableVectorError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// 服务端返回的状态码转换为 merr 错误，可能被多层包装
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if merr.IsRetryableErr(e) {
+			return true
+		}
+	}
+
+	// 连接层错误（gRPC 状态码）
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"github.com/milvus-io/milvus/client/v2/column"
+	"github.com/milvus-io/milvus/client/v2/entity"
+	"github.com/milvus-io/milvus/client/v2/index"
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyMilvusOperations 前 failures[op] 次调用返回 err，之后成功
+type flakyMilvusOperations struct {
+	mu       sync.Mutex
+	err      error
+	failures map[string]int
+	calls    map[string]int
+
+	searchConsistency *entity.ConsistencyLevel // 最近一次搜索的一致性级别
+}
+
+func newFlakyMilvusOperations(err error, failures map[string]int) *flakyMilvusOperations {
+	return &flakyMilvusOperations{err: err, failures: failures, calls: make(map[string]int)}
+}
+
+func (o *flakyMilvusOperations) call(op string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls[op]++
+	if o.calls[op] <= o.failures[op] {
+		return fmt.Errorf("%s: %w", op, o.err)
+	}
+	return nil
+}
+
+func (o *flakyMilvusOperations) callCount(op string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.calls[op]
+}
+
+func (o *flakyMilvusOperations) HasCollection(ctx context.Context, collectionName string) (bool, error) {
+	return false, o.call("HasCollection")
+}
+
+func (o *flakyMilvusOperations) CreateCollection(ctx context.Context, collectionName string, schema *entity.Schema) error {
+	return o.call("CreateCollection")
+}
+
+func (o *flakyMilvusOperations) CreateIndex(ctx context.Context, collectionName, fieldName string, idx index.Index) error {
+	return o.call("CreateIndex")
+}
+
+func (o *flakyMilvusOperations) LoadCollection(ctx context.Context, collectionName string) error {
+	return o.call("LoadCollection")
+}
+
+func (o *flakyMilvusOperations) Upsert(ctx context.Context, collectionName string, columns ...column.Column) error {
+	return o.call("Upsert")
+}
+
+func (o *flakyMilvusOperations) Flush(ctx context.Context, collectionName string) error {
+	return o.call("Flush")
+}
+
+func (o *flakyMilvusOperations) Search(ctx context.Context, collectionName string, topK int, vector []float32, consistency *entity.ConsistencyLevel, outputFields ...string) ([]milvusclient.ResultSet, error) {
+	o.mu.Lock()
+	o.searchConsistency = consistency
+	o.mu.Unlock()
+	if err := o.call("Search"); err != nil {
+		return nil, err
+	}
+	return []milvusclient.ResultSet{{
+		ResultCount: 1,
+		Scores:      []float32{0.9},
+		Fields: milvusclient.DataSet{
+			column.NewColumnVarChar("document_id", []string{"doc-1"}),
+			column.NewColumnVarChar("chunk_id", []string{"chunk-1"}),
+			column.NewColumnVarChar("content", []string{"hello"}),
+		},
+	}}, nil
+}
+
+func (o *flakyMilvusOperations) Delete(ctx context.Context, collectionName, expr string) error {
+	return o.call("Delete")
+}
+
+func (o *flakyMilvusOperations) DropCollection(ctx context.Context, collectionName string) error {
+	return o.call("DropCollection")
+}
+
+func (o *flakyMilvusOperations) Compact(ctx context.Context, collectionName string) (int64, error) {
+	return 1, o.call("Compact")
+}
+
+func (o *flakyMilvusOperations) GetCompactionState(ctx context.Context, compactionID int64) (entity.CompactionState, error) {
+	return entity.CompactionStateCompleted, o.call("GetCompactionState")
+}
+
+func testRetryConfig() VectorRetryConfig {
+	return VectorRetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestMilvusVectorDBService_RetriesTransientErrors(t *testing.T) {
+	ctx := context.Background()
+	transient := []struct {
+		name string
+		err  error
+	}{
+		{name: "milvus service unavailable", err: merr.ErrServiceUnavailable},
+		{name: "milvus rate limit", err: merr.ErrServiceRateLimit},
+		{name: "grpc unavailable", err: status.Error(codes.Unavailable, "connection refused")},
+	}
+
+	for _, tt := range transient {
+		t.Run(tt.name, func(t *testing.T) {
+			// 每个操作前两次失败、第三次成功，恰好用完 3 次尝试的预算
+			ops := newFlakyMilvusOperations(tt.err, map[string]int{
+				"HasCollection": 2, "CreateCollection": 2, "CreateIndex": 2, "LoadCollection": 2,
+				"Upsert": 2, "Flush": 2, "Search": 2, "Delete": 2,
+			})
+			svc := newMilvusVectorDBService(ops, testRetryConfig(), VectorConsistencyConfig{})
+
+			if err := svc.CreateCollection(ctx, "kb_test", 4); err != nil {
+				t.Fatalf("CreateCollection failed: %v", err)
+			}
+			chunks := []*biz.Chunk{{ID: "chunk-1", DocumentID: "doc-1", Content: "hello", Embedding: []float32{1, 0, 0, 0}}}
+			if err := svc.InsertVectors(ctx, "kb_test", chunks); err != nil {
+				t.Fatalf("InsertVectors failed: %v", err)
+			}
+			results, err := svc.Search(ctx, "kb_test", []float32{1, 0, 0, 0}, 5)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(results) != 1 || results[0].ChunkID != "chunk-1" {
+				t.Errorf("Expected chunk-1 in search results, got %+v", results)
+			}
+			if err := svc.DeleteByDocumentID(ctx, "kb_test", "doc-1"); err != nil {
+				t.Fatalf("DeleteByDocumentID failed: %v", err)
+			}
+
+			for _, op := range []string{"HasCollection", "CreateCollection", "CreateIndex", "LoadCollection", "Upsert", "Search", "Delete"} {
+				if got := ops.callCount(op); got != 3 {
+					t.Errorf("Expected %s to be called 3 times, got %d", op, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMilvusVectorDBService_GivesUpAfterRetryBudget(t *testing.T) {
+	ops := newFlakyMilvusOperations(merr.ErrServiceUnavailable, map[string]int{"Upsert": 10})
+	svc := newMilvusVectorDBService(ops, testRetryConfig(), VectorConsistencyConfig{})
+
+	chunks := []*biz.Chunk{{ID: "chunk-1", DocumentID: "doc-1", Embedding: []float32{1}}}
+	err := svc.InsertVectors(context.Background(), "kb_test", chunks)
+	if err == nil {
+		t.Fatal("Expected InsertVectors to fail after exhausting retries")
+	}
+	if !errors.Is(err, merr.ErrServiceUnavailable) {
+		t.Errorf("Expected error to wrap ErrServiceUnavailable, got %v", err)
+	}
+	if got := ops.callCount("Upsert"); got != 3 {
+		t.Errorf("Expected 3 insert attempts, got %d", got)
+	}
+}
+
+func TestMilvusVectorDBService_DoesNotRetryPermanentErrors(t *testing.T) {
+	permanent := []struct {
+		name string
+		err  error
+	}{
+		{name: "dimension mismatch", err: merr.WrapErrParameterInvalid(4, 8, "vector dimension mismatch")},
+		{name: "collection not found", err: merr.WrapErrCollectionNotFound("kb_test")},
+		{name: "grpc invalid argument", err: status.Error(codes.InvalidArgument, "invalid schema")},
+	}
+
+	for _, tt := range permanent {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := newFlakyMilvusOperations(tt.err, map[string]int{"Search": 10})
+			svc := newMilvusVectorDBService(ops, testRetryConfig(), VectorConsistencyConfig{})
+
+			if _, err := svc.Search(context.Background(), "kb_test", []float32{1, 0, 0, 0}, 5); err == nil {
+				t.Fatal("Expected Search to fail")
+			}
+			if got := ops.callCount("Search"); got != 1 {
+				t.Errorf("Expected a single search attempt, got %d", got)
+			}
+		})
+	}
+}
+
+func TestMilvusVectorDBService_RetryStopsWhenContextDone(t *testing.T) {
+	ops := newFlakyMilvusOperations(merr.ErrServiceNotReady, map[string]int{"Delete": 10})
+	svc := newMilvusVectorDBService(ops, VectorRetryConfig{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}, VectorConsistencyConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := svc.DeleteByDocumentID(ctx, "kb_test", "doc-1")
+	if err == nil {
+		t.Fatal("Expected DeleteByDocumentID to fail once the context is done")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected retry to stop with the context, took %v", elapsed)
+	}
+	if got := ops.callCount("Delete"); got != 1 {
+		t.Errorf("Expected a single delete attempt before the context expired, got %d", got)
+	}
+}
@@ -3,19 +3,25 @@ package embedding
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
-	"github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
 )
 
 // EmbeddingService Embedding 生成服务
-type EmbeddingService struct{}
+type EmbeddingService struct {
+	httpClient     *http.Client      // 不设置固定超时，由请求 ctx 控制
+	payloadFormats map[string]string // provider_type -> Embedding 请求格式（覆盖默认映射）
+}
 
 // NewEmbeddingService 创建 Embedding 服务
-func NewEmbeddingService() *EmbeddingService {
-	return &EmbeddingService{}
+func NewEmbeddingService(payloadFormats map[string]string) *EmbeddingService {
+	return &EmbeddingService{
+		httpClient:     &http.Client{},
+		payloadFormats: payloadFormats,
+	}
 }
 
 // GenerateEmbeddings 批量生成 Embeddings
@@ -36,27 +42,32 @@ func (s *EmbeddingService) GenerateEmbeddings(ctx context.Context, texts []strin
 		return nil, fmt.Errorf("API key is empty for provider %s", provider.ProviderType)
 	}
 
-	apiBaseURL := ""
-
-	// 根据 provider type 设置不同的 base URL
+	// 服务商未配置 Base URL 时使用默认地址
+	apiBaseURL := provider.APIBaseURL
 	switch provider.ProviderType {
 	case "siliconflow":
-		apiBaseURL = "https://api.siliconflow.cn/v1"
+		if apiBaseURL == "" {
+			apiBaseURL = "https://api.siliconflow.cn/v1"
+		}
 	case "openai":
-		apiBaseURL = "https://api.openai.com/v1"
+		if apiBaseURL == "" {
+			apiBaseURL = "https://api.openai.com/v1"
+		}
 	case "anthropic":
 		// Anthropic 不支持 Embedding，应该在验证阶段就拦截
 		return nil, fmt.Errorf("anthropic does not support embeddings")
 	}
+	if apiBaseURL == "" {
+		return nil, fmt.Errorf("API base URL is empty for provider %s", provider.ProviderType)
+	}
 
-	// 创建 OpenAI 客户端
-	clientConfig := openai.DefaultConfig(apiKey)
-	if apiBaseURL != "" {
-		clientConfig.BaseURL = apiBaseURL
+	// 按服务商类型选择请求/响应格式
+	adapter, err := biz.EmbeddingPayloadAdapterFor(provider.ProviderType, s.payloadFormats)
+	if err != nil {
+		return nil, err
 	}
-	client := openai.NewClientWithConfig(clientConfig)
 
-	// 批量处理（OpenAI API 限制单次请求数量）
+	// 批量处理（服务商限制单次请求数量）
 	batchSize := 100
 	var allEmbeddings [][]float32
 
@@ -66,25 +77,11 @@ func (s *EmbeddingService) GenerateEmbeddings(ctx context.Context, texts []strin
 			end = len(texts)
 		}
 
-		batch := texts[i:end]
-		req := openai.EmbeddingRequest{
-			Input: batch,
-			Model: openai.EmbeddingModel(model.ModelName),
-		}
-
-		resp, err := client.CreateEmbeddings(ctx, req)
+		embeddings, err := biz.RequestEmbeddings(ctx, s.httpClient, adapter, apiBaseURL, apiKey, model.ModelName, texts[i:end])
 		if err != nil {
 			return nil, fmt.Errorf("failed to create embeddings: %w", err)
 		}
-
-		// 提取 embedding 向量
-		for _, data := range resp.Data {
-			embedding := make([]float32, len(data.Embedding))
-			for j, val := range data.Embedding {
-				embedding[j] = float32(val)
-			}
-			allEmbeddings = append(allEmbeddings, embedding)
-		}
+		allEmbeddings = append(allEmbeddings, embeddings...)
 	}
 
 	// 记录向量化完成
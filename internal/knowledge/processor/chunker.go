@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"unicode"
@@ -8,20 +9,36 @@ import (
 	"github.com/pkoukk/tiktoken-go"
 )
 
-// ChunkText 将文本分块
-func (p *DocumentProcessor) ChunkText(text string, chunkSize, chunkOverlap int, strategy string) ([]string, error) {
+// 分块重叠单位（chunkSize 始终按 token 计算）
+const (
+	OverlapUnitTokens     = "tokens"     // 按 token 重叠（默认）
+	OverlapUnitCharacters = "characters" // 按字符重叠
+	OverlapUnitSentences  = "sentences"  // 按完整句子重叠，保证带入下一块的上下文语义完整
+)
+
+// 分块策略
+const (
+	ChunkStrategyRecursive = "recursive" // 按段落、句子递归分块（默认）
+	ChunkStrategyFixed     = "fixed"     // 按固定 token 数分块
+)
+
+// ErrUnsupportedChunkStrategy 不支持的分块策略
+var ErrUnsupportedChunkStrategy = errors.New("unsupported chunk strategy")
+
+// ChunkText 将文本分块（overlapUnit 为空时按 token 重叠，strategy 为空时递归分块）
+func (p *DocumentProcessor) ChunkText(text string, chunkSize, chunkOverlap int, overlapUnit, strategy string) ([]string, error) {
 	switch strategy {
-	case "recursive":
-		return p.recursiveChunk(text, chunkSize, chunkOverlap)
-	case "fixed":
-		return p.fixedChunk(text, chunkSize, chunkOverlap)
+	case ChunkStrategyRecursive, "":
+		return p.recursiveChunk(text, chunkSize, chunkOverlap, overlapUnit)
+	case ChunkStrategyFixed:
+		return p.fixedChunk(text, chunkSize, chunkOverlap, overlapUnit)
 	default:
-		return p.recursiveChunk(text, chunkSize, chunkOverlap)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedChunkStrategy, strategy)
 	}
 }
 
 // recursiveChunk 递归分块（按段落、句子）
-func (p *DocumentProcessor) recursiveChunk(text string, chunkSize, chunkOverlap int) ([]string, error) {
+func (p *DocumentProcessor) recursiveChunk(text string, chunkSize, chunkOverlap int, overlapUnit string) ([]string, error) {
 	// 初始化 tiktoken 编码器
 	encoding, err := tiktoken.GetEncoding("cl100k_base")
 	if err != nil {
@@ -49,7 +66,7 @@ func (p *DocumentProcessor) recursiveChunk(text string, chunkSize, chunkOverlap
 
 			// 处理 overlap
 			if chunkOverlap > 0 {
-				overlapText := p.getOverlapText(currentChunk.String(), chunkOverlap, encoding)
+				overlapText := p.getOverlapText(currentChunk.String(), chunkOverlap, overlapUnit, encoding)
 				currentChunk.Reset()
 				currentChunk.WriteString(overlapText)
 				currentTokens = len(encoding.Encode(overlapText, nil, nil))
@@ -67,7 +84,7 @@ func (p *DocumentProcessor) recursiveChunk(text string, chunkSize, chunkOverlap
 				if currentTokens+sentTokens > chunkSize && currentChunk.Len() > 0 {
 					chunks = append(chunks, currentChunk.String())
 					if chunkOverlap > 0 {
-						overlapText := p.getOverlapText(currentChunk.String(), chunkOverlap, encoding)
+						overlapText := p.getOverlapText(currentChunk.String(), chunkOverlap, overlapUnit, encoding)
 						currentChunk.Reset()
 						currentChunk.WriteString(overlapText)
 						currentTokens = len(encoding.Encode(overlapText, nil, nil))
@@ -101,7 +118,7 @@ func (p *DocumentProcessor) recursiveChunk(text string, chunkSize, chunkOverlap
 }
 
 // fixedChunk 固定大小分块
-func (p *DocumentProcessor) fixedChunk(text string, chunkSize, chunkOverlap int) ([]string, error) {
+func (p *DocumentProcessor) fixedChunk(text string, chunkSize, chunkOverlap int, overlapUnit string) ([]string, error) {
 	encoding, err := tiktoken.GetEncoding("cl100k_base")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tiktoken encoding: %w", err)
@@ -110,7 +127,7 @@ func (p *DocumentProcessor) fixedChunk(text string, chunkSize, chunkOverlap int)
 	tokens := encoding.Encode(text, nil, nil)
 	var chunks []string
 
-	for i := 0; i < len(tokens); i += chunkSize - chunkOverlap {
+	for i := 0; i < len(tokens); {
 		end := i + chunkSize
 		if end > len(tokens) {
 			end = len(tokens)
@@ -123,11 +140,34 @@ func (p *DocumentProcessor) fixedChunk(text string, chunkSize, chunkOverlap int)
 		if end >= len(tokens) {
 			break
 		}
+
+		// 下一块从重叠内容的起始 token 开始（至少前进 1 个 token）
+		next := end - p.overlapTokenCount(chunkText, len(chunkTokens), chunkOverlap, overlapUnit, encoding)
+		if next <= i {
+			next = i + 1
+		}
+		i = next
 	}
 
 	return chunks, nil
 }
 
+// overlapTokenCount 计算固定分块时重叠部分占用的 token 数
+func (p *DocumentProcessor) overlapTokenCount(chunkText string, chunkTokens, chunkOverlap int, overlapUnit string, encoding *tiktoken.Tiktoken) int {
+	if chunkOverlap <= 0 {
+		return 0
+	}
+	if overlapUnit == "" || overlapUnit == OverlapUnitTokens {
+		return chunkOverlap
+	}
+
+	count := len(encoding.Encode(p.getOverlapText(chunkText, chunkOverlap, overlapUnit, encoding), nil, nil))
+	if count > chunkTokens {
+		count = chunkTokens
+	}
+	return count
+}
+
 // splitSentences 分割句子
 func (p *DocumentProcessor) splitSentences(text string) []string {
 	var sentences []string
@@ -155,8 +195,51 @@ func (p *DocumentProcessor) splitSentences(text string) []string {
 	return sentences
 }
 
-// getOverlapText 获取重叠文本
-func (p *DocumentProcessor) getOverlapText(text string, overlapTokens int, encoding *tiktoken.Tiktoken) string {
+// getOverlapText 按重叠单位获取文本末尾的重叠内容
+func (p *DocumentProcessor) getOverlapText(text string, overlap int, overlapUnit string, encoding *tiktoken.Tiktoken) string {
+	switch overlapUnit {
+	case OverlapUnitCharacters:
+		return p.getOverlapCharacters(text, overlap)
+	case OverlapUnitSentences:
+		return p.getOverlapSentences(text, overlap)
+	default:
+		return p.getOverlapTokens(text, overlap, encoding)
+	}
+}
+
+// getOverlapCharacters 获取末尾 overlapChars 个字符（最多为文本的一半，保证下一块有新内容）
+func (p *DocumentProcessor) getOverlapCharacters(text string, overlapChars int) string {
+	runes := []rune(text)
+	if maxChars := len(runes) / 2; overlapChars > maxChars {
+		overlapChars = maxChars
+	}
+	if overlapChars <= 0 {
+		return ""
+	}
+	return strings.TrimLeftFunc(string(runes[len(runes)-overlapChars:]), unicode.IsSpace)
+}
+
+// getOverlapSentences 获取末尾 overlapSentences 个完整句子（跨段落，最多为文本句子数的一半，保证下一块有新内容）
+func (p *DocumentProcessor) getOverlapSentences(text string, overlapSentences int) string {
+	var sentences []string
+	for _, para := range strings.Split(text, "\n\n") {
+		for _, sentence := range p.splitSentences(para) {
+			if sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+		}
+	}
+	if maxSentences := len(sentences) / 2; overlapSentences > maxSentences {
+		overlapSentences = maxSentences
+	}
+	if overlapSentences <= 0 {
+		return ""
+	}
+	return strings.Join(sentences[len(sentences)-overlapSentences:], " ")
+}
+
+// getOverlapTokens 获取末尾 overlapTokens 个 token
+func (p *DocumentProcessor) getOverlapTokens(text string, overlapTokens int, encoding *tiktoken.Tiktoken) string {
 	tokens := encoding.Encode(text, nil, nil)
 	if len(tokens) <= overlapTokens {
 		return text
@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+const overlapTestText = "The first sentence is here. The second one follows.\n\nA new paragraph starts. It ends with a final sentence."
+
+func TestGetOverlapText_Units(t *testing.T) {
+	p := &DocumentProcessor{}
+
+	tests := []struct {
+		name    string
+		unit    string
+		overlap int
+		text    string // 为空时使用 overlapTestText
+		want    string
+	}{
+		{name: "characters", unit: OverlapUnitCharacters, overlap: 15, want: "final sentence."},
+		{name: "characters trims leading space", unit: OverlapUnitCharacters, overlap: 22, want: "with a final sentence."},
+		{name: "one sentence", unit: OverlapUnitSentences, overlap: 1, want: "It ends with a final sentence."},
+		{name: "sentences across paragraphs", unit: OverlapUnitSentences, overlap: 2, want: "A new paragraph starts. It ends with a final sentence."},
+		{name: "sentences capped at half the chunk", unit: OverlapUnitSentences, overlap: 10, want: "A new paragraph starts. It ends with a final sentence."},
+		{name: "characters capped at half the chunk", unit: OverlapUnitCharacters, overlap: 1000, want: "new paragraph starts. It ends with a final sentence."},
+		{name: "single sentence has no overlap", unit: OverlapUnitSentences, overlap: 1, text: "Only one sentence.", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.text
+			if text == "" {
+				text = overlapTestText
+			}
+			if got := p.getOverlapText(text, tt.overlap, tt.unit, nil); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestChunkText_OverlapUnits(t *testing.T) {
+	encoding, err := tiktoken.GetEncoding("cl100k_base")
+	if err != nil {
+		t.Skipf("tiktoken encoding unavailable: %v", err)
+	}
+
+	p := &DocumentProcessor{}
+	sentences := []string{
+		"Alpha beta gamma delta epsilon.",
+		"Zeta eta theta iota kappa.",
+		"Lambda mu nu xi omicron.",
+		"Pi rho sigma tau upsilon.",
+		"Phi chi psi omega end.",
+	}
+	text := strings.Join(sentences, " ")
+	chunkSize := len(encoding.Encode(sentences[0]+" "+sentences[1], nil, nil))
+
+	tokensChunks, err := p.ChunkText(text, chunkSize, 3, OverlapUnitTokens, "recursive")
+	if err != nil {
+		t.Fatalf("ChunkText(tokens) failed: %v", err)
+	}
+	sentenceChunks, err := p.ChunkText(text, chunkSize, 1, OverlapUnitSentences, "recursive")
+	if err != nil {
+		t.Fatalf("ChunkText(sentences) failed: %v", err)
+	}
+	charChunks, err := p.ChunkText(text, chunkSize, 10, OverlapUnitCharacters, "recursive")
+	if err != nil {
+		t.Fatalf("ChunkText(characters) failed: %v", err)
+	}
+
+	if len(tokensChunks) < 2 || len(sentenceChunks) < 2 || len(charChunks) < 2 {
+		t.Fatalf("Expected multiple chunks, got tokens=%d sentences=%d characters=%d",
+			len(tokensChunks), len(sentenceChunks), len(charChunks))
+	}
+
+	// 句子重叠：下一块以上一块的最后一个完整句子开头
+	for i := 1; i < len(sentenceChunks); i++ {
+		prev := p.splitSentences(sentenceChunks[i-1])
+		lastSentence := prev[len(prev)-1]
+		if !strings.HasPrefix(sentenceChunks[i], lastSentence) {
+			t.Errorf("Chunk %d: expected to start with %q, got %q", i, lastSentence, sentenceChunks[i])
+		}
+	}
+
+	// 字符重叠：下一块以上一块末尾 10 个字符开头
+	for i := 1; i < len(charChunks); i++ {
+		want := p.getOverlapCharacters(charChunks[i-1], 10)
+		if !strings.HasPrefix(charChunks[i], want) {
+			t.Errorf("Chunk %d: expected to start with %q, got %q", i, want, charChunks[i])
+		}
+	}
+
+	// Token 重叠：下一块以上一块末尾 3 个 token 开头（通常截断在句子中间）
+	want := p.getOverlapTokens(tokensChunks[0], 3, encoding)
+	if !strings.HasPrefix(tokensChunks[1], want) {
+		t.Errorf("Expected token overlap %q at start of %q", want, tokensChunks[1])
+	}
+	if want == p.getOverlapSentences(tokensChunks[0], 1) {
+		t.Errorf("Expected token overlap to differ from sentence overlap, both %q", want)
+	}
+}
+
+func TestChunkText_UnsupportedStrategy(t *testing.T) {
+	p := &DocumentProcessor{}
+
+	_, err := p.ChunkText(overlapTestText, 100, 0, OverlapUnitTokens, "semantic")
+	if !errors.Is(err, ErrUnsupportedChunkStrategy) {
+		t.Errorf("Expected ErrUnsupportedChunkStrategy, got %v", err)
+	}
+}
@@ -27,6 +27,22 @@ func NewDocumentProcessor() *DocumentProcessor {
 	return &DocumentProcessor{}
 }
 
+// 文件类型的处理方式
+const (
+	FileProcessorLocal  = "local"  // 本地提取（纯文本解析、go-fitz）
+	FileProcessorMinerU = "mineru" // MinerU 云端文档解析
+)
+
+// SupportedFileTypes 返回本地提取器支持的文件类型（扩展名 -> 处理方式）
+func (p *DocumentProcessor) SupportedFileTypes() map[string]string {
+	return map[string]string{
+		"pdf":  FileProcessorLocal,
+		"txt":  FileProcessorLocal,
+		"md":   FileProcessorLocal,
+		"json": FileProcessorLocal,
+	}
+}
+
 // ExtractText 从文件中提取文本内容
 func (p *DocumentProcessor) ExtractText(ctx context.Context, fileData []byte, fileType string) (string, error) {
 	switch strings.ToLower(fileType) {
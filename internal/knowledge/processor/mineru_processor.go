@@ -31,20 +31,47 @@ func NewMinerUProcessor(client *mineru.Client, log *logger.Logger) *MinerUProces
 	}
 }
 
+// mineruFileTypes 交给 MinerU 解析的复杂文档类型
+var mineruFileTypes = []string{"pdf", "docx", "doc", "ppt", "pptx"}
+
+// SupportedFileTypes 返回支持的文件类型（扩展名 -> 处理方式）
+// 纯文本类文件始终本地处理；未配置 MinerU 客户端时只支持本地提取器能处理的类型
+func (p *MinerUProcessor) SupportedFileTypes() map[string]string {
+	types := p.baseProcessor.SupportedFileTypes()
+	if p.client == nil {
+		return types
+	}
+	for _, fileType := range mineruFileTypes {
+		types[fileType] = FileProcessorMinerU
+	}
+	return types
+}
+
 // ExtractText 使用 MinerU 提取文本内容
 func (p *MinerUProcessor) ExtractText(ctx context.Context, fileData []byte, fileType string) (string, error) {
+	return p.extractText(ctx, fileData, fileType, false)
+}
+
+// ExtractTextWithOCR 强制启用 OCR 重新提取文本（用于首次提取结果为空的文档）
+func (p *MinerUProcessor) ExtractTextWithOCR(ctx context.Context, fileData []byte, fileType string) (string, error) {
+	return p.extractText(ctx, fileData, fileType, true)
+}
+
+// extractText 按文件类型选择本地提取或 MinerU 解析，forceOCR 为 true 时所有 MinerU 文档都启用 OCR
+func (p *MinerUProcessor) extractText(ctx context.Context, fileData []byte, fileType string, forceOCR bool) (string, error) {
 	fileType = strings.ToLower(fileType)
 
-	// 对于简单文本文件，直接使用本地处理（无需调用 MinerU API）
-	if fileType == "txt" || fileType == "md" || fileType == "json" {
-		p.logger.Info("using local processor for simple text file", zap.String("type", fileType))
+	switch p.SupportedFileTypes()[fileType] {
+	case FileProcessorLocal:
+		// 简单文本文件（或未配置 MinerU 时）直接使用本地处理，本地提取器不支持 OCR
+		p.logger.Info("using local processor", zap.String("type", fileType))
 		return p.baseProcessor.ExtractText(ctx, fileData, fileType)
-	}
-
-	// 对于 PDF/DOCX 等复杂文档，使用 MinerU
-	if fileType == "pdf" || fileType == "docx" || fileType == "doc" || fileType == "ppt" || fileType == "pptx" {
-		p.logger.Info("using MinerU for document processing", zap.String("type", fileType))
-		return p.extractWithMinerU(ctx, fileData, fileType)
+	case FileProcessorMinerU:
+		// 对于 PDF/DOCX 等复杂文档，使用 MinerU
+		p.logger.Info("using MinerU for document processing", zap.String("type", fileType), zap.Bool("force_ocr", forceOCR))
+		// 注意：OCR 仅对扫描的 PDF 有用，对于 DOCX/PPTX 等原生文档默认关闭
+		// 免费账户可能有 OCR 配额限制
+		return p.extractWithMinerU(ctx, fileData, fileType, forceOCR || fileType == "pdf")
 	}
 
 	// 不支持的文件类型
@@ -52,7 +79,7 @@ func (p *MinerUProcessor) ExtractText(ctx context.Context, fileData []byte, file
 }
 
 // extractWithMinerU 使用 MinerU 提取文本
-func (p *MinerUProcessor) extractWithMinerU(ctx context.Context, fileData []byte, fileType string) (string, error) {
+func (p *MinerUProcessor) extractWithMinerU(ctx context.Context, fileData []byte, fileType string, isOCR bool) (string, error) {
 	// 1. 创建临时文件
 	tmpFile, err := p.createTempFile(fileData, fileType)
 	if err != nil {
@@ -63,9 +90,6 @@ func (p *MinerUProcessor) extractWithMinerU(ctx context.Context, fileData []byte
 	p.logger.Info("created temp file for MinerU processing", zap.String("path", tmpFile))
 
 	// 2. 创建 MinerU 任务（上传文件）
-	// 注意：OCR 仅对扫描的 PDF 有用，对于 DOCX/PPTX 等原生文档应该关闭
-	// 免费账户可能有 OCR 配额限制
-	isOCR := fileType == "pdf" // 仅对 PDF 启用 OCR
 	req := &mineru.BatchUploadRequest{
 		Language: "ch",
 		Files: []mineru.BatchFileInfo{
@@ -237,6 +261,6 @@ func (p *MinerUProcessor) extractTextFromZip(zipPath string) (string, error) {
 }
 
 // ChunkText 文本分块（复用基础实现）
-func (p *MinerUProcessor) ChunkText(text string, chunkSize, chunkOverlap int, strategy string) ([]string, error) {
-	return p.baseProcessor.ChunkText(text, chunkSize, chunkOverlap, strategy)
+func (p *MinerUProcessor) ChunkText(text string, chunkSize, chunkOverlap int, overlapUnit, strategy string) ([]string, error) {
+	return p.baseProcessor.ChunkText(text, chunkSize, chunkOverlap, overlapUnit, strategy)
 }
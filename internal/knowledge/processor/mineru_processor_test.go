@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/mineru"
+)
+
+func TestMinerUProcessor_SupportedFileTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *mineru.Client
+		want   map[string]string
+	}{
+		{
+			name:   "local extractors only",
+			client: nil,
+			want: map[string]string{
+				"pdf": FileProcessorLocal, "txt": FileProcessorLocal, "md": FileProcessorLocal, "json": FileProcessorLocal,
+			},
+		},
+		{
+			name:   "mineru configured",
+			client: &mineru.Client{},
+			want: map[string]string{
+				"txt": FileProcessorLocal, "md": FileProcessorLocal, "json": FileProcessorLocal,
+				"pdf": FileProcessorMinerU, "docx": FileProcessorMinerU, "doc": FileProcessorMinerU,
+				"ppt": FileProcessorMinerU, "pptx": FileProcessorMinerU,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMinerUProcessor(tt.client, nil).SupportedFileTypes()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d file types, got %d: %v", len(tt.want), len(got), got)
+			}
+			for ext, processor := range tt.want {
+				if got[ext] != processor {
+					t.Errorf("%s: expected processor %q, got %q", ext, processor, got[ext])
+				}
+			}
+		})
+	}
+}
+
+func TestMinerUProcessor_RejectsUnsupportedTypeWithoutMinerU(t *testing.T) {
+	p := NewMinerUProcessor(nil, nil)
+	if _, err := p.ExtractText(context.Background(), []byte("data"), "docx"); err == nil {
+		t.Error("Expected docx to be unsupported without MinerU")
+	}
+}
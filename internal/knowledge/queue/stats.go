@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// QueueStats 文档处理队列深度统计
+type QueueStats struct {
+	Pending                 int64      `json:"pending"`                              // 等待处理的任务数
+	Processing              int64      `json:"processing"`                           // 处理中的文档数
+	DeadLetter              int64      `json:"dead_letter"`                          // 死信队列任务数
+	OldestEnqueuedAt        *time.Time `json:"oldest_enqueued_at,omitempty"`         // 最早入队的待处理任务时间
+	OldestPendingAgeSeconds float64    `json:"oldest_pending_age_seconds,omitempty"` // 最早待处理任务的等待时长
+}
+
+// queueReader 队列统计所需的 Redis 读操作
+type queueReader interface {
+	LLen(ctx context.Context, key string) (int64, error)
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	SCard(ctx context.Context, key string) (int64, error)
+}
+
+// GetQueueStats 获取队列深度统计
+func (w *Worker) GetQueueStats(ctx context.Context) (*QueueStats, error) {
+	return collectQueueStats(ctx, w.redis, time.Now())
+}
+
+// collectQueueStats 统计队列深度（任务 LPush 入队、RPop 出队，列表尾部为最早的任务）
+func collectQueueStats(ctx context.Context, r queueReader, now time.Time) (*QueueStats, error) {
+	pending, err := r.LLen(ctx, DocumentProcessQueue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue length: %w", err)
+	}
+
+	processing, err := r.SCard(ctx, ProcessingSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processing count: %w", err)
+	}
+
+	deadLetter, err := r.LLen(ctx, DocumentDeadLetterQueue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter queue length: %w", err)
+	}
+
+	stats := &QueueStats{
+		Pending:    pending,
+		Processing: processing,
+		DeadLetter: deadLetter,
+	}
+
+	if pending > 0 {
+		oldest, err := r.LRange(ctx, DocumentProcessQueue, -1, -1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read oldest task: %w", err)
+		}
+		if len(oldest) == 1 {
+			var task DocumentTask
+			// 旧版本入队的任务没有 enqueued_at，此时不报告等待时长
+			if err := json.Unmarshal([]byte(oldest[0]), &task); err == nil && !task.EnqueuedAt.IsZero() {
+				enqueuedAt := task.EnqueuedAt
+				stats.OldestEnqueuedAt = &enqueuedAt
+				stats.OldestPendingAgeSeconds = now.Sub(enqueuedAt).Seconds()
+			}
+		}
+	}
+
+	return stats, nil
+}
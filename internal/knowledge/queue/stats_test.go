@@ -0,0 +1,135 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+// fakeQueueReader 内存队列（列表下标 0 为 LPush 端）
+type fakeQueueReader struct {
+	lists map[string][]string
+	sets  map[string]int64
+}
+
+func (r *fakeQueueReader) LLen(ctx context.Context, key string) (int64, error) {
+	return int64(len(r.lists[key])), nil
+}
+
+func (r *fakeQueueReader) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	list := r.lists[key]
+	n := int64(len(list))
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 || start > stop || start >= n {
+		return nil, nil
+	}
+	return list[start : stop+1], nil
+}
+
+func (r *fakeQueueReader) SCard(ctx context.Context, key string) (int64, error) {
+	return r.sets[key], nil
+}
+
+func (r *fakeQueueReader) lpush(t *testing.T, key string, task *DocumentTask) {
+	t.Helper()
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("marshal task: %v", err)
+	}
+	r.lists[key] = append([]string{string(data)}, r.lists[key]...)
+}
+
+func TestCollectQueueStats(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	r := &fakeQueueReader{
+		lists: map[string][]string{},
+		sets:  map[string]int64{ProcessingSet: 2},
+	}
+	r.lpush(t, DocumentProcessQueue, &DocumentTask{DocumentID: "doc-oldest", EnqueuedAt: now.Add(-90 * time.Second)})
+	r.lpush(t, DocumentProcessQueue, &DocumentTask{DocumentID: "doc-2", EnqueuedAt: now.Add(-30 * time.Second)})
+	r.lpush(t, DocumentProcessQueue, &DocumentTask{DocumentID: "doc-3", EnqueuedAt: now.Add(-5 * time.Second)})
+	r.lpush(t, DocumentDeadLetterQueue, &DocumentTask{DocumentID: "doc-dead", RetryCount: 3})
+
+	stats, err := collectQueueStats(context.Background(), r, now)
+	if err != nil {
+		t.Fatalf("collectQueueStats failed: %v", err)
+	}
+
+	if stats.Pending != 3 || stats.Processing != 2 || stats.DeadLetter != 1 {
+		t.Errorf("Expected pending=3 processing=2 dead_letter=1, got %+v", stats)
+	}
+	if stats.OldestEnqueuedAt == nil || !stats.OldestEnqueuedAt.Equal(now.Add(-90*time.Second)) {
+		t.Errorf("Expected oldest task enqueued 90s ago, got %v", stats.OldestEnqueuedAt)
+	}
+	if stats.OldestPendingAgeSeconds != 90 {
+		t.Errorf("Expected oldest pending age 90s, got %v", stats.OldestPendingAgeSeconds)
+	}
+}
+
+func TestCollectQueueStats_EmptyAndLegacyTasks(t *testing.T) {
+	r := &fakeQueueReader{lists: map[string][]string{}, sets: map[string]int64{}}
+
+	stats, err := collectQueueStats(context.Background(), r, time.Now())
+	if err != nil {
+		t.Fatalf("collectQueueStats failed: %v", err)
+	}
+	if stats.Pending != 0 || stats.OldestEnqueuedAt != nil {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	// 旧任务没有 enqueued_at：只报告数量
+	r.lists[DocumentProcessQueue] = []string{`{"document_id":"doc-legacy","retry_count":0}`}
+	stats, err = collectQueueStats(context.Background(), r, time.Now())
+	if err != nil {
+		t.Fatalf("collectQueueStats failed: %v", err)
+	}
+	if stats.Pending != 1 || stats.OldestEnqueuedAt != nil || stats.OldestPendingAgeSeconds != 0 {
+		t.Errorf("Expected legacy task to report depth only, got %+v", stats)
+	}
+}
+
+func TestQueueMetricsCollector(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	r := &fakeQueueReader{
+		lists: map[string][]string{},
+		sets:  map[string]int64{ProcessingSet: 1},
+	}
+	r.lpush(t, DocumentProcessQueue, &DocumentTask{DocumentID: "doc-oldest", EnqueuedAt: now.Add(-45 * time.Second)})
+	r.lpush(t, DocumentProcessQueue, &DocumentTask{DocumentID: "doc-2", EnqueuedAt: now})
+	r.lpush(t, DocumentDeadLetterQueue, &DocumentTask{DocumentID: "doc-dead"})
+
+	collector := NewQueueMetricsCollector(r, zap.NewNop())
+	collector.now = func() time.Time { return now }
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	want := `
+# HELP ai_writer_document_queue_dead_letter Number of tasks in the dead letter queue.
+# TYPE ai_writer_document_queue_dead_letter gauge
+ai_writer_document_queue_dead_letter 1
+# HELP ai_writer_document_queue_oldest_pending_age_seconds Age of the oldest pending task (0 when the queue is empty).
+# TYPE ai_writer_document_queue_oldest_pending_age_seconds gauge
+ai_writer_document_queue_oldest_pending_age_seconds 45
+# HELP ai_writer_document_queue_pending Number of document processing tasks waiting in the queue.
+# TYPE ai_writer_document_queue_pending gauge
+ai_writer_document_queue_pending 2
+# HELP ai_writer_document_queue_processing Number of documents currently being processed.
+# TYPE ai_writer_document_queue_processing gauge
+ai_writer_document_queue_processing 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want)); err != nil {
+		t.Fatalf("Unexpected queue metrics: %v", err)
+	}
+}
@@ -14,14 +14,16 @@ import (
 )
 
 const (
-	DocumentProcessQueue = "queue:document:process"
-	ProcessingSet        = "set:document:processing"
+	DocumentProcessQueue    = "queue:document:process"
+	DocumentDeadLetterQueue = "queue:document:dead" // 超过最大重试次数的任务
+	ProcessingSet           = "set:document:processing"
 )
 
 // DocumentTask 文档处理任务
 type DocumentTask struct {
-	DocumentID string `json:"document_id"`
-	RetryCount int    `json:"retry_count"`
+	DocumentID string    `json:"document_id"`
+	RetryCount int       `json:"retry_count"`
+	EnqueuedAt time.Time `json:"enqueued_at"` // 首次入队时间（重试时保留）
 }
 
 // Worker 任务处理Worker
@@ -97,6 +99,7 @@ func (w *Worker) EnqueueDocument(ctx context.Context, documentID string) error {
 	task := &DocumentTask{
 		DocumentID: documentID,
 		RetryCount: 0,
+		EnqueuedAt: time.Now(),
 	}
 
 	taskJSON, err := json.Marshal(task)
@@ -175,7 +178,7 @@ func (w *Worker) processTask(ctx context.Context, task *DocumentTask, logger *za
 	resources := []string{docResource, kbResource}
 
 	// SSE 广播: 开始处理
-	doc.ProcessStatus = "processing" // 更新状态
+	doc.ProcessStatus = biz.ProcessStatusProcessing // 更新状态
 	event := sse.Event{
 		Type: "status",
 		Data: map[string]interface{}{
@@ -208,8 +211,8 @@ func (w *Worker) processTask(ctx context.Context, task *DocumentTask, logger *za
 			// 获取最新文档信息
 			doc, _ := w.docUseCase.DocumentRepo.GetByID(ctx, task.DocumentID)
 			if doc != nil {
-				doc.ProcessStatus = "retrying" // 更新状态
-				doc.ProcessError = err.Error() // 设置错误信息
+				doc.ProcessStatus = biz.ProcessStatusRetrying // 更新状态
+				doc.ProcessError = err.Error()                // 设置错误信息
 			}
 
 			// SSE 广播: 重试中
@@ -227,11 +230,17 @@ func (w *Worker) processTask(ctx context.Context, task *DocumentTask, logger *za
 		} else {
 			logger.Error("document processing failed after max retries")
 
+			// 移入死信队列，便于排查与人工重试
+			taskJSON, _ := json.Marshal(task)
+			if _, err := w.redis.LPush(ctx, DocumentDeadLetterQueue, string(taskJSON)); err != nil {
+				logger.Error("failed to move task to dead letter queue", zap.Error(err))
+			}
+
 			// 获取最新文档信息
 			doc, _ := w.docUseCase.DocumentRepo.GetByID(ctx, task.DocumentID)
 			if doc != nil {
-				doc.ProcessStatus = "failed"   // 更新状态
-				doc.ProcessError = err.Error() // 设置错误信息
+				doc.ProcessStatus = biz.ProcessStatusFailed // 更新状态
+				doc.ProcessError = err.Error()              // 设置错误信息
 			}
 
 			// SSE 广播: 失败
@@ -261,7 +270,7 @@ func (w *Worker) processTask(ctx context.Context, task *DocumentTask, logger *za
 				Type: "status",
 				Data: map[string]interface{}{
 					"document_id": task.DocumentID,
-					"status":      "completed",
+					"status":      biz.ProcessStatusCompleted,
 					"message":     "Document processing completed successfully",
 				},
 			}
@@ -270,11 +279,15 @@ func (w *Worker) processTask(ctx context.Context, task *DocumentTask, logger *za
 			}
 		} else {
 			// SSE 广播: 完成
+			message := fmt.Sprintf("Document processing completed successfully. Generated %d chunks.", doc.ChunkCount)
+			if doc.ProcessStatus == biz.ProcessStatusEmpty {
+				message = fmt.Sprintf("Document processing finished with no content: %s", doc.ProcessError)
+			}
 			completedEvent := sse.Event{
 				Type: "status",
 				Data: map[string]interface{}{
 					"document": biz.ToDocumentResponse(doc),
-					"message":  fmt.Sprintf("Document processing completed successfully. Generated %d chunks.", doc.ChunkCount),
+					"message":  message,
 				},
 			}
 			for _, resource := range resources {
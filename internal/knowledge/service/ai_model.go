@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -40,9 +41,16 @@ func (s *AIModelService) GetModelByID(ctx context.Context, req *GetModelByIDRequ
 	return toAIModelResponse(model), nil
 }
 
-// ListModelsByProvider 获取服务商的模型列表
+// ListModelsByProvider 分页获取服务商的模型列表
 func (s *AIModelService) ListModelsByProvider(ctx context.Context, req *ListModelsByProviderRequest) (*ListModelsResponse, error) {
-	models, err := s.modelUseCase.ListAIModelsByProviderID(ctx, req.ProviderID)
+	listReq := &biz.ListAIModelsRequest{
+		ProviderID:     req.ProviderID,
+		CapabilityType: req.CapabilityType,
+		IsEnabled:      req.IsEnabled,
+		Page:           req.Page,
+		PageSize:       req.PageSize,
+	}
+	models, total, err := s.modelUseCase.ListAIModelsByProvider(ctx, listReq)
 	if err != nil {
 		return nil, err
 	}
@@ -53,8 +61,10 @@ func (s *AIModelService) ListModelsByProvider(ctx context.Context, req *ListMode
 	}
 
 	return &ListModelsResponse{
-		Items: items,
-		Total: len(items),
+		Items:    items,
+		Total:    int(total),
+		Page:     listReq.Page,
+		PageSize: listReq.PageSize,
 	}, nil
 }
 
@@ -100,6 +110,46 @@ func (s *AIModelService) SyncProviderModels(ctx context.Context, req *SyncProvid
 	}, nil
 }
 
+// GetModelsDiff 预览服务商模型同步差异（dry-run，不写入数据库）
+func (s *AIModelService) GetModelsDiff(ctx context.Context, req *GetModelsDiffRequest) (*ModelsDiffResponse, error) {
+	result, err := s.syncUseCase.SyncProviderModels(ctx, &biz.ModelSyncRequest{
+		ProviderID: req.ProviderID,
+		SyncType:   "manual",
+		DryRun:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated := extractModelNames(result.UpdatedModels)
+	sort.Strings(updated)
+	changes := make([]*ModelChangeResponse, 0, len(updated))
+	for _, name := range updated {
+		fields := make([]*ModelFieldChangeResponse, len(result.Changes[name]))
+		for i, change := range result.Changes[name] {
+			fields[i] = &ModelFieldChangeResponse{
+				Field:    change.Field,
+				OldValue: change.OldValue,
+				NewValue: change.NewValue,
+			}
+		}
+		changes = append(changes, &ModelChangeResponse{ModelName: name, Fields: fields})
+	}
+
+	newModels := extractModelNames(result.NewModels)
+	deprecatedModels := extractModelNames(result.DeprecatedModels)
+	sort.Strings(newModels)
+	sort.Strings(deprecatedModels)
+
+	return &ModelsDiffResponse{
+		ProviderID:       req.ProviderID,
+		NewModels:        newModels,
+		DeprecatedModels: deprecatedModels,
+		UpdatedModels:    updated,
+		Changes:          changes,
+	}, nil
+}
+
 // GetSyncHistory 获取同步历史
 func (s *AIModelService) GetSyncHistory(ctx context.Context, req *GetSyncHistoryRequest) (*SyncHistoryResponse, error) {
 	limit := req.Limit
@@ -148,7 +198,11 @@ type GetModelByIDRequest struct {
 }
 
 type ListModelsByProviderRequest struct {
-	ProviderID string `json:"provider_id" binding:"required"`
+	ProviderID     string `json:"provider_id" binding:"required"`
+	CapabilityType string `form:"capability"`
+	IsEnabled      *bool  `form:"is_enabled"`
+	Page           int    `form:"page" binding:"omitempty,min=1"`
+	PageSize       int    `form:"page_size" binding:"omitempty,min=1"` // 超过上限时截断
 }
 
 type ListModelsByCapabilityRequest struct {
@@ -160,6 +214,10 @@ type SyncProviderModelsRequest struct {
 	SyncedBy   string `json:"synced_by"`
 }
 
+type GetModelsDiffRequest struct {
+	ProviderID string `json:"provider_id" binding:"required"`
+}
+
 type GetSyncHistoryRequest struct {
 	ProviderID string `json:"provider_id" binding:"required"`
 	Limit      int    `json:"limit"`
@@ -186,8 +244,10 @@ type AIModelResponse struct {
 }
 
 type ListModelsResponse struct {
-	Items []*AIModelResponse `json:"items"`
-	Total int                `json:"total"`
+	Items    []*AIModelResponse `json:"items"`
+	Total    int                `json:"total"`
+	Page     int                `json:"page,omitempty"`
+	PageSize int                `json:"page_size,omitempty"`
 }
 
 type SyncResultResponse struct {
@@ -207,6 +267,25 @@ type SyncResultResponse struct {
 	TotalModels   int      `json:"total_models"`
 }
 
+type ModelFieldChangeResponse struct {
+	Field    string `json:"field"` // max_tokens, display_name, capabilities, supports_* 等
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+type ModelChangeResponse struct {
+	ModelName string                      `json:"model_name"`
+	Fields    []*ModelFieldChangeResponse `json:"fields"`
+}
+
+type ModelsDiffResponse struct {
+	ProviderID       string                 `json:"provider_id"`
+	NewModels        []string               `json:"new_models"`
+	DeprecatedModels []string               `json:"deprecated_models"`
+	UpdatedModels    []string               `json:"updated_models"`
+	Changes          []*ModelChangeResponse `json:"changes"` // 更新模型的字段变更
+}
+
 type SyncLogResponse struct {
 	ID                    string    `json:"id"`
 	ProviderID            string    `json:"provider_id"`
@@ -311,6 +390,11 @@ func (s *AIModelService) HandleListModelsByProvider(c *gin.Context) {
 	}
 
 	req := &ListModelsByProviderRequest{ProviderID: providerID}
+	if err := c.ShouldBindQuery(req); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	resp, err := s.ListModelsByProvider(c.Request.Context(), req)
 	if err != nil {
 		s.log.Error("failed to list models by provider", zap.Error(err))
@@ -369,6 +453,42 @@ func (s *AIModelService) HandleSyncProviderModels(c *gin.Context) {
 	response.Success(c, resp)
 }
 
+// HandleGetModelsDiff Gin handler
+func (s *AIModelService) HandleGetModelsDiff(c *gin.Context) {
+	providerID := c.Param("provider_id")
+	if providerID == "" {
+		response.Error(c, http.StatusBadRequest, "provider ID is required")
+		return
+	}
+
+	resp, err := s.GetModelsDiff(c.Request.Context(), &GetModelsDiffRequest{ProviderID: providerID})
+	if err != nil {
+		s.log.Error("failed to diff provider models", zap.String("provider_id", providerID), zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// HandleVerifyProviderModels 批量验证服务商下所有模型
+func (s *AIModelService) HandleVerifyProviderModels(c *gin.Context) {
+	providerID := c.Param("provider_id")
+	if providerID == "" {
+		response.Error(c, http.StatusBadRequest, "provider ID is required")
+		return
+	}
+
+	summary, err := s.syncUseCase.VerifyProviderModels(c.Request.Context(), providerID)
+	if err != nil {
+		s.log.Error("failed to verify provider models", zap.String("provider_id", providerID), zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, summary)
+}
+
 // HandleGetSyncHistory Gin handler
 func (s *AIModelService) HandleGetSyncHistory(c *gin.Context) {
 	providerID := c.Param("provider_id")
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lk2023060901/ai-writer-backend/internal/knowledge/biz"
+	"go.uber.org/zap"
+)
+
+type stubProviderRepo struct {
+	provider *biz.AIProvider
+}
+
+func (r *stubProviderRepo) ListAll(ctx context.Context) ([]*biz.AIProvider, error) {
+	return []*biz.AIProvider{r.provider}, nil
+}
+
+func (r *stubProviderRepo) GetByID(ctx context.Context, id string) (*biz.AIProvider, error) {
+	if id != r.provider.ID {
+		return nil, errors.New("not found")
+	}
+	return r.provider, nil
+}
+
+func (r *stubProviderRepo) GetByType(ctx context.Context, providerType string) (*biz.AIProvider, error) {
+	return r.provider, nil
+}
+
+func (r *stubProviderRepo) UpdateStatus(ctx context.Context, id string, isEnabled bool) error {
+	return nil
+}
+
+func (r *stubProviderRepo) UpdateConfig(ctx context.Context, id string, apiKey, apiBaseURL *string) error {
+	return nil
+}
+
+// recordingModelRepo 记录所有写操作
+type recordingModelRepo struct {
+	models   []*biz.AIModel
+	writes   int
+	lastList *biz.ListAIModelsRequest
+}
+
+func (r *recordingModelRepo) GetByID(ctx context.Context, id string) (*biz.AIModel, error) {
+	return nil, errors.New("not found")
+}
+
+func (r *recordingModelRepo) ListByProviderID(ctx context.Context, providerID string) ([]*biz.AIModel, error) {
+	return r.models, nil
+}
+
+func (r *recordingModelRepo) List(ctx context.Context, req *biz.ListAIModelsRequest) ([]*biz.AIModel, int64, error) {
+	r.lastList = req
+	return r.models, int64(len(r.models)), nil
+}
+
+func (r *recordingModelRepo) ListByCapabilityType(ctx context.Context, capabilityType string) ([]*biz.AIModel, error) {
+	return nil, nil
+}
+
+func (r *recordingModelRepo) ListAll(ctx context.Context) ([]*biz.AIModel, error) {
+	return r.models, nil
+}
+
+func (r *recordingModelRepo) Create(ctx context.Context, model *biz.AIModel) error {
+	r.writes++
+	return nil
+}
+
+func (r *recordingModelRepo) Update(ctx context.Context, model *biz.AIModel) error {
+	r.writes++
+	return nil
+}
+
+func (r *recordingModelRepo) Delete(ctx context.Context, id string) error {
+	r.writes++
+	return nil
+}
+
+type recordingSyncLogRepo struct {
+	logs []*biz.ModelSyncLog
+}
+
+func (r *recordingSyncLogRepo) Create(ctx context.Context, log *biz.ModelSyncLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *recordingSyncLogRepo) ListByProviderID(ctx context.Context, providerID string, limit int) ([]*biz.ModelSyncLog, error) {
+	return r.logs, nil
+}
+
+func (r *recordingSyncLogRepo) GetLatest(ctx context.Context, providerID string) (*biz.ModelSyncLog, error) {
+	return nil, nil
+}
+
+func TestHandleGetModelsDiff_ReturnsChangesWithoutWriting(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"glm-4"},{"id":"glm-4v"}]}`))
+	}))
+	defer upstream.Close()
+
+	provider := &biz.AIProvider{ID: "p-1", ProviderName: "zhipu", ProviderType: "zhipu", APIKey: "key", APIBaseURL: upstream.URL, IsEnabled: true}
+	modelRepo := &recordingModelRepo{models: []*biz.AIModel{
+		{ID: "m-1", ProviderID: "p-1", ModelName: "glm-4", DisplayName: "GLM-4", IsEnabled: true, Capabilities: []string{biz.CapabilityTypeChat}, SupportsStream: true},
+		{ID: "m-2", ProviderID: "p-1", ModelName: "old-model", DisplayName: "old-model", IsEnabled: true, VerificationStatus: "available", Capabilities: []string{biz.CapabilityTypeChat}, SupportsStream: true},
+	}}
+	syncLogRepo := &recordingSyncLogRepo{}
+	svc := NewAIModelService(nil, biz.NewModelSyncUseCase(&stubProviderRepo{provider: provider}, modelRepo, syncLogRepo, nil), zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ai-providers/:provider_id/models/diff", svc.HandleGetModelsDiff)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ai-providers/p-1/models/diff", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Data ModelsDiffResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	diff := body.Data
+	if len(diff.NewModels) != 1 || diff.NewModels[0] != "glm-4v" {
+		t.Errorf("Expected new models [glm-4v], got %v", diff.NewModels)
+	}
+	if len(diff.DeprecatedModels) != 1 || diff.DeprecatedModels[0] != "old-model" {
+		t.Errorf("Expected deprecated models [old-model], got %v", diff.DeprecatedModels)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].ModelName != "glm-4" {
+		t.Fatalf("Expected changes for glm-4, got %+v", diff.Changes)
+	}
+	if fields := diff.Changes[0].Fields; len(fields) != 1 || fields[0].Field != "display_name" ||
+		fields[0].OldValue != "GLM-4" || fields[0].NewValue != "glm-4" {
+		t.Errorf("Expected display_name change GLM-4 -> glm-4, got %+v", fields)
+	}
+
+	if modelRepo.writes != 0 {
+		t.Errorf("Expected no model writes, got %d", modelRepo.writes)
+	}
+	if len(syncLogRepo.logs) != 0 {
+		t.Errorf("Expected no sync log, got %d", len(syncLogRepo.logs))
+	}
+	if old := modelRepo.models[1]; !old.IsEnabled || old.VerificationStatus != "available" {
+		t.Errorf("Expected deprecated model to stay unchanged, got enabled=%v status=%s", old.IsEnabled, old.VerificationStatus)
+	}
+}
+
+func TestHandleListModelsByProvider_PassesPaginationAndFilters(t *testing.T) {
+	modelRepo := &recordingModelRepo{models: []*biz.AIModel{
+		{ID: "m-1", ProviderID: "p-1", ModelName: "glm-4", IsEnabled: true},
+	}}
+	svc := NewAIModelService(biz.NewAIModelUseCase(modelRepo, &biz.AIModelConfig{MaxPageSize: 50}), nil, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ai-providers/:provider_id/models", svc.HandleListModelsByProvider)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ai-providers/p-1/models?page=2&page_size=500&is_enabled=true&capability=chat", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req := modelRepo.lastList
+	if req == nil || req.ProviderID != "p-1" || req.Page != 2 || req.PageSize != 50 ||
+		req.CapabilityType != biz.CapabilityTypeChat || req.IsEnabled == nil || !*req.IsEnabled {
+		t.Fatalf("Unexpected list request %+v", req)
+	}
+
+	var body struct {
+		Data ListModelsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Data.Total != 1 || body.Data.Page != 2 || body.Data.PageSize != 50 {
+		t.Errorf("Expected total=1 page=2 page_size=50, got %+v", body.Data)
+	}
+}
+
+func TestHandleListModelsByProvider_WithoutPaginationReturnsAll(t *testing.T) {
+	modelRepo := &recordingModelRepo{models: []*biz.AIModel{
+		{ID: "m-1", ProviderID: "p-1", ModelName: "glm-4", IsEnabled: true},
+		{ID: "m-2", ProviderID: "p-1", ModelName: "glm-4-air", IsEnabled: true},
+	}}
+	svc := NewAIModelService(biz.NewAIModelUseCase(modelRepo, &biz.AIModelConfig{MaxPageSize: 1}), nil, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ai-providers/:provider_id/models", svc.HandleListModelsByProvider)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ai-providers/p-1/models", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if req := modelRepo.lastList; req == nil || req.Page != 0 || req.PageSize != 0 {
+		t.Fatalf("Expected an unpaged list request, got %+v", req)
+	}
+
+	var body struct {
+		Data ListModelsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Data.Items) != 2 || body.Data.Total != 2 {
+		t.Errorf("Expected all 2 models, got %+v", body.Data)
+	}
+}
@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -42,6 +44,11 @@ func NewDocumentService(
 	}
 }
 
+// Shutdown 等待后台任务（自动压缩）结束，由 HTTP 服务关闭时调用
+func (s *DocumentService) Shutdown(ctx context.Context) error {
+	return s.docUseCase.WaitForCompactions(ctx)
+}
+
 // UploadDocument 单文件上传（返回 JSON）
 func (s *DocumentService) UploadDocument(c *gin.Context) {
 	kbID := c.Param("id")
@@ -72,12 +79,27 @@ func (s *DocumentService) UploadDocument(c *gin.Context) {
 		zap.Int("file_size", len(fileData)))
 
 	// 上传文档
-	doc, err := s.docUseCase.UploadDocument(c.Request.Context(), kbID, userID, fileName, fileData, fileType)
+	outcome, err := s.docUseCase.UploadDocumentWithOutcome(c.Request.Context(), kbID, userID, fileName, fileData, fileType)
 	if err != nil {
 		s.logger.Error("failed to upload document", zap.Error(err))
+		if errors.Is(err, biz.ErrDocumentHashExists) {
+			response.Error(c, http.StatusConflict, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	doc := outcome.Document
+
+	// 已有文档无需重新处理
+	if outcome.Existing {
+		response.Success(c, map[string]interface{}{
+			"document": toDocumentResponse(doc),
+			"existing": true,
+			"message":  fmt.Sprintf("File '%s' already exists in this knowledge base", fileName),
+		})
+		return
+	}
 
 	// 加入处理队列
 	err = s.worker.EnqueueDocument(c.Request.Context(), doc.ID)
@@ -142,7 +164,47 @@ func (s *DocumentService) GetDocument(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, toDocumentResponse(doc))
+	resp := toDocumentResponse(doc)
+	resp.Telemetry = biz.ToProcessingTelemetryResponse(doc.Telemetry)
+
+	// 按需查询原始文件 Content-Type，避免默认多一次查询
+	if c.Query("include_content_type") == "true" {
+		contentType, err := s.docUseCase.ResolveContentType(c.Request.Context(), doc)
+		if err != nil {
+			s.logger.Warn("failed to resolve document content type", zap.String("doc_id", docID), zap.Error(err))
+		}
+		resp.ContentType = contentType
+	}
+
+	response.Success(c, resp)
+}
+
+// UpdateDocumentMetadata 更新文档元数据（不触发重新处理）
+func (s *DocumentService) UpdateDocumentMetadata(c *gin.Context) {
+	docID := c.Param("doc_id")
+	userID := c.GetString("user_id")
+
+	var req struct {
+		Metadata map[string]interface{} `json:"metadata" binding:"required"`
+		Merge    bool                   `json:"merge"` // true: 合并到现有元数据; false: 整体替换
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid parameters: metadata required")
+		return
+	}
+
+	doc, err := s.docUseCase.UpdateDocumentMetadata(c.Request.Context(), docID, userID, req.Metadata, req.Merge)
+	if err != nil {
+		s.logger.Error("failed to update document metadata", zap.String("doc_id", docID), zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"document": toDocumentResponse(doc),
+		"metadata": doc.Metadata,
+	})
 }
 
 // DeleteDocument 删除文档
@@ -213,9 +275,17 @@ func (s *DocumentService) BatchUploadDocuments(c *gin.Context) {
 		return
 	}
 
+	// 批量上传会话 ID（可选）：中断后使用同一 batch_id 重新提交，已创建的文件会被跳过
+	batchID := c.Request.FormValue("batch_id")
+	if len(batchID) > biz.MaxBatchIDLength {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("batch_id too long: maximum %d characters", biz.MaxBatchIDLength))
+		return
+	}
+
 	s.logger.Info("batch upload request",
 		zap.Int("file_count", len(allFileHeaders)),
-		zap.String("kb_id", kbID))
+		zap.String("kb_id", kbID),
+		zap.String("batch_id", batchID))
 
 	// 限制最多上传 50 个文件
 	if len(allFileHeaders) > 50 {
@@ -266,21 +336,30 @@ func (s *DocumentService) BatchUploadDocuments(c *gin.Context) {
 		Build()
 	defer stream.Close()
 
+	// 无需入队的文档：按重复文档策略返回的已有文档、续传时已在处理或已处理完成的文档
+	var skipProcessing sync.Map
+
 	// 使用 BatchUploader 处理批量上传
 	go sse.NewBatchUploader[*biz.UploadFile](stream, len(files)).
 		WithEventPrefix("file"). // 事件类型: file-success, file-failed
 		Process(files, func(ctx context.Context, file *biz.UploadFile) (interface{}, error) {
 			// 上传单个文件
-			doc, err := s.docUseCase.UploadDocument(ctx, kbID, userID, file.FileName, file.FileData, file.FileType)
+			outcome, err := s.docUseCase.UploadBatchDocument(ctx, kbID, userID, batchID, file)
 			if err != nil {
 				return nil, err
 			}
-			return toDocumentResponse(doc), nil
+			if !outcome.NeedsProcessing() {
+				skipProcessing.Store(outcome.Document.ID, true)
+			}
+			return toDocumentResponse(outcome.Document), nil
 		}).
 		WithWorkerPool(s.uploadPool).
 		OnSuccess(func(index int, file *biz.UploadFile, result interface{}) error {
 			// 成功后加入处理队列
 			if doc, ok := result.(*DocumentResponse); ok && doc.ID != "" {
+				if _, skip := skipProcessing.Load(doc.ID); skip {
+					return nil
+				}
 				return s.worker.EnqueueDocument(c.Request.Context(), doc.ID)
 			}
 			return nil
@@ -295,6 +374,28 @@ func (s *DocumentService) ReprocessDocument(c *gin.Context) {
 	docID := c.Param("doc_id")
 	_ = c.GetString("user_id") // userID
 
+	// 文件与分块配置未变化时跳过（force=true 强制重新处理）
+	if c.Query("force") != "true" {
+		unchanged, err := s.docUseCase.IsUnchangedSinceLastRun(c.Request.Context(), docID)
+		if err != nil {
+			s.logger.Error("failed to check document changes", zap.String("document_id", docID), zap.Error(err))
+			if errors.Is(err, biz.ErrDocumentNotFound) {
+				response.Error(c, http.StatusNotFound, "document not found")
+				return
+			}
+			response.Error(c, http.StatusInternalServerError, "failed to check document changes")
+			return
+		}
+		if unchanged {
+			// 跳过不是错误：返回 200 并标明 skipped，客户端可用 force=true 强制重新处理
+			response.Success(c, map[string]string{
+				"status":  "skipped",
+				"message": "document unchanged since last run, reprocessing skipped",
+			})
+			return
+		}
+	}
+
 	// 加入处理队列
 	err := s.worker.EnqueueDocument(c.Request.Context(), docID)
 	if err != nil {
@@ -303,7 +404,133 @@ func (s *DocumentService) ReprocessDocument(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, map[string]string{"message": "document queued for reprocessing"})
+	response.Success(c, map[string]string{
+		"status":  "queued",
+		"message": "document queued for reprocessing",
+	})
+}
+
+// ReindexKeywordSearch 重建知识库全文搜索索引（管理操作）
+func (s *DocumentService) ReindexKeywordSearch(c *gin.Context) {
+	kbID := c.Param("id")
+
+	count, err := s.docUseCase.ReindexKeywordSearch(c.Request.Context(), kbID)
+	if err != nil {
+		s.logger.Error("failed to reindex keyword search", zap.String("kb_id", kbID), zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"knowledge_base_id": kbID,
+		"reindexed_chunks":  count,
+	})
+}
+
+// CompactKnowledgeBase 手动压缩知识库的 Milvus collection（管理接口）
+func (s *DocumentService) CompactKnowledgeBase(c *gin.Context) {
+	kbID := c.Param("id")
+
+	collections, err := s.docUseCase.CompactKnowledgeBase(c.Request.Context(), kbID)
+	if err != nil {
+		s.logger.Error("failed to compact knowledge base", zap.String("kb_id", kbID), zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, map[string]interface{}{
+		"knowledge_base_id": kbID,
+		"collections":       collections,
+	})
+}
+
+// usageDateLayout 用量统计的日期格式
+const usageDateLayout = "2006-01-02"
+
+// defaultUsageRangeDays 未指定起始日期时统计的天数
+const defaultUsageRangeDays = 30
+
+// GetEmbeddingUsage 按日期范围统计各知识库及各所有者的 Embedding token 用量（管理接口）
+// from/to 为 YYYY-MM-DD（按 UTC 整天计算，均包含），默认最近 30 天；owner_id 可选
+func (s *DocumentService) GetEmbeddingUsage(c *gin.Context) {
+	var req struct {
+		From    string `form:"from"`
+		To      string `form:"to"`
+		OwnerID string `form:"owner_id"`
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid parameters")
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if req.To != "" {
+		parsed, err := time.Parse(usageDateLayout, req.To)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "invalid to date, expected YYYY-MM-DD")
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(defaultUsageRangeDays - 1))
+	if req.From != "" {
+		parsed, err := time.Parse(usageDateLayout, req.From)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "invalid from date, expected YYYY-MM-DD")
+			return
+		}
+		from = parsed
+	}
+
+	report, err := s.docUseCase.GetEmbeddingUsageReport(c.Request.Context(), &biz.EmbeddingUsageQuery{
+		From:    from,
+		To:      to.AddDate(0, 0, 1), // 结束日期包含当天
+		OwnerID: req.OwnerID,
+	})
+	if err != nil {
+		if errors.Is(err, biz.ErrInvalidUsageRange) {
+			response.Error(c, http.StatusBadRequest, "from must not be after to")
+			return
+		}
+		s.logger.Error("failed to get embedding usage", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, toEmbeddingUsageResponse(report))
+}
+
+// ListSupportedFileTypes 可上传的文件类型（随 MinerU/本地提取器配置变化，供上传前客户端校验）
+func (s *DocumentService) ListSupportedFileTypes(c *gin.Context) {
+	types := s.docUseCase.ListSupportedFileTypes(c.Request.Context())
+	response.Success(c, map[string]interface{}{
+		"items": types,
+		"total": len(types),
+	})
+}
+
+// GetProcessingQueueStats 文档处理队列深度（Redis 队列、死信队列、上传 Worker Pool，管理接口）
+func (s *DocumentService) GetProcessingQueueStats(c *gin.Context) {
+	stats, err := s.worker.GetQueueStats(c.Request.Context())
+	if err != nil {
+		s.logger.Error("failed to get processing queue stats", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := map[string]interface{}{
+		"queue": stats,
+	}
+	if s.uploadPool != nil {
+		result["upload_pool"] = map[string]interface{}{
+			"queue_length": s.uploadPool.QueueLength(),
+			"running":      s.uploadPool.Running(),
+			"free":         s.uploadPool.Free(),
+		}
+	}
+
+	response.Success(c, result)
 }
 
 // SearchDocuments 向量搜索
@@ -313,7 +540,9 @@ func (s *DocumentService) SearchDocuments(c *gin.Context) {
 	userID := c.GetString("user_id")
 
 	var req struct {
-		Query string `json:"query" binding:"required,min=1,max=1000"`
+		Query                    string `json:"query" binding:"required,min=1,max=1000"`
+		IncludeDocumentMetadata  *bool  `json:"include_document_metadata"`                          // 默认 true
+		PreviewChunksPerDocument int    `json:"preview_chunks_per_document" binding:"min=0,max=20"` // 大于 0 时附带命中文档的分块预览
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -321,15 +550,58 @@ func (s *DocumentService) SearchDocuments(c *gin.Context) {
 		return
 	}
 
+	includeMetadata := true
+	if req.IncludeDocumentMetadata != nil {
+		includeMetadata = *req.IncludeDocumentMetadata
+	}
+
 	// 使用知识库配置的默认 TopK（不允许前端覆盖）
-	results, err := s.docUseCase.SearchDocuments(c.Request.Context(), kbID, userID, req.Query, 0)
+	outcome, err := s.docUseCase.SearchDocumentsWithOptions(c.Request.Context(), kbID, userID, req.Query, &biz.SearchOptions{
+		IncludeDocumentMetadata:  includeMetadata,
+		PreviewChunksPerDocument: req.PreviewChunksPerDocument,
+	})
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"results": toSearchResults(outcome.Results),
+		"partial": outcome.Partial,
+	}
+	if outcome.Partial {
+		data["partial_reason"] = outcome.PartialReason
+	}
+	if outcome.Previews != nil {
+		data["previews"] = toChunkPreviews(outcome.Previews)
+	}
+	response.Success(c, data)
+}
+
+// PreviewDocuments 批量预览多个文档的前 N 个分块（单次查询）
+func (s *DocumentService) PreviewDocuments(c *gin.Context) {
+	kbID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req struct {
+		DocumentIDs []string `json:"document_ids" binding:"required,min=1,max=100"`
+		LimitPerDoc int      `json:"limit_per_doc" binding:"min=0,max=20"` // 默认 biz.MaxPreviewChunksPerDocument
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid parameters: document_ids required (1-100 items)")
+		return
+	}
+
+	previews, err := s.docUseCase.PreviewDocuments(c.Request.Context(), kbID, userID, req.DocumentIDs, req.LimitPerDoc)
 	if err != nil {
+		s.logger.Error("failed to preview documents", zap.String("kb_id", kbID), zap.Error(err))
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response.Success(c, map[string]interface{}{
-		"results": toSearchResults(results),
+		"previews": toChunkPreviews(previews),
 	})
 }
 
@@ -51,10 +51,14 @@ func (s *KnowledgeBaseService) CreateKnowledgeBase(c *gin.Context) {
 		RerankModelID:    req.RerankModelID,
 		ChunkSize:        req.ChunkSize,
 		ChunkOverlap:     req.ChunkOverlap,
+		ChunkOverlapUnit: req.ChunkOverlapUnit,
 		ChunkStrategy:    req.ChunkStrategy,
 		Threshold:        req.Threshold,
 		TopK:             req.TopK,
 		EnableHybridSearch: req.EnableHybridSearch,
+		KeywordStopwords: req.KeywordStopwords,
+		EmbeddingOverrides: toEmbeddingOverrides(req.EmbeddingOverrides),
+		KnowledgeTemplate: req.KnowledgeTemplate,
 	})
 
 	if err != nil {
@@ -158,6 +162,8 @@ func (s *KnowledgeBaseService) UpdateKnowledgeBase(c *gin.Context) {
 		Threshold:          req.Threshold,
 		TopK:               req.TopK,
 		EnableHybridSearch: req.EnableHybridSearch,
+		KeywordStopwords:   req.KeywordStopwords,
+		KnowledgeTemplate:  req.KnowledgeTemplate,
 	})
 
 	if err != nil {
@@ -186,6 +192,53 @@ func (s *KnowledgeBaseService) DeleteKnowledgeBase(c *gin.Context) {
 	response.Success(c, struct{}{})
 }
 
+// GetKnowledgeBaseDefaults 获取当前用户的知识库默认设置
+func (s *KnowledgeBaseService) GetKnowledgeBaseDefaults(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	defaults, err := s.kbUseCase.GetUserDefaults(c.Request.Context(), userID)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	response.Success(c, toKnowledgeBaseDefaultsResponse(defaults))
+}
+
+// UpdateKnowledgeBaseDefaults 设置当前用户的知识库默认设置
+func (s *KnowledgeBaseService) UpdateKnowledgeBaseDefaults(c *gin.Context) {
+	var req KnowledgeBaseDefaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if userID == "" {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	defaults, err := s.kbUseCase.UpdateUserDefaults(c.Request.Context(), userID, &biz.KnowledgeBaseDefaults{
+		EmbeddingModelID: req.EmbeddingModelID,
+		ChunkSize:        req.ChunkSize,
+		ChunkOverlap:     req.ChunkOverlap,
+		ChunkStrategy:    req.ChunkStrategy,
+		TopK:             req.TopK,
+		Threshold:        req.Threshold,
+	})
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	response.Success(c, toKnowledgeBaseDefaultsResponse(defaults))
+}
+
 // handleError 处理错误
 func (s *KnowledgeBaseService) handleError(c *gin.Context, err error) {
 	s.logger.Error("Knowledge base operation failed", zap.Error(err))
@@ -195,7 +248,9 @@ func (s *KnowledgeBaseService) handleError(c *gin.Context, err error) {
 		response.NotFound(c, err.Error())
 	case errors.Is(err, biz.ErrKnowledgeBaseNameRequired),
 		errors.Is(err, biz.ErrKnowledgeBaseInvalidChunkSize),
-		errors.Is(err, biz.ErrKnowledgeBaseInvalidOverlap):
+		errors.Is(err, biz.ErrKnowledgeBaseInvalidOverlap),
+		errors.Is(err, biz.ErrKnowledgeBaseInvalidTemplate),
+		errors.Is(err, biz.ErrInvalidKnowledgeBaseDefaults):
 		response.BadRequest(c, err.Error())
 	case errors.Is(err, biz.ErrUnauthorized):
 		response.Forbidden(c, err.Error())
@@ -209,6 +264,18 @@ func (s *KnowledgeBaseService) handleError(c *gin.Context, err error) {
 	}
 }
 
+// toKnowledgeBaseDefaultsResponse 转换默认设置响应
+func toKnowledgeBaseDefaultsResponse(defaults *biz.KnowledgeBaseDefaults) *KnowledgeBaseDefaultsResponse {
+	return &KnowledgeBaseDefaultsResponse{
+		EmbeddingModelID: defaults.EmbeddingModelID,
+		ChunkSize:        defaults.ChunkSize,
+		ChunkOverlap:     defaults.ChunkOverlap,
+		ChunkStrategy:    defaults.ChunkStrategy,
+		TopK:             defaults.TopK,
+		Threshold:        defaults.Threshold,
+	}
+}
+
 // toKnowledgeBaseResponse 转换为响应对象
 func toKnowledgeBaseResponse(kb *biz.KnowledgeBase, currentUserID string) *KnowledgeBaseResponse {
 	// 官方知识库：仅返回 ID 和名称
@@ -235,12 +302,31 @@ func toKnowledgeBaseResponse(kb *biz.KnowledgeBase, currentUserID string) *Knowl
 		RerankModelID:    kb.RerankModelID,
 		ChunkSize:        &kb.ChunkSize,
 		ChunkOverlap:     &kb.ChunkOverlap,
+		ChunkOverlapUnit: &kb.ChunkOverlapUnit,
 		ChunkStrategy:    &kb.ChunkStrategy,
 		MilvusCollection: &kb.MilvusCollection,
 		Threshold:        &kb.Threshold,
 		TopK:             &kb.TopK,
 		EnableHybridSearch: &kb.EnableHybridSearch,
+		KeywordStopwords: kb.KeywordStopwords,
+		EmbeddingOverrides: kb.EmbeddingOverrides,
+		KnowledgeTemplate: kb.KnowledgeTemplate,
 		CreatedAt:        &createdAt,
 		UpdatedAt:        &updatedAt,
 	}
 }
+
+// toEmbeddingOverrides 转换 Embedding 覆盖请求
+func toEmbeddingOverrides(reqs []EmbeddingOverrideRequest) []biz.EmbeddingOverride {
+	if len(reqs) == 0 {
+		return nil
+	}
+	overrides := make([]biz.EmbeddingOverride, len(reqs))
+	for i, req := range reqs {
+		overrides[i] = biz.EmbeddingOverride{
+			FileTypes:        req.FileTypes,
+			EmbeddingModelID: req.EmbeddingModelID,
+		}
+	}
+	return overrides
+}
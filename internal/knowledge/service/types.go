@@ -9,14 +9,24 @@ import (
 // CreateKnowledgeBaseRequest 创建知识库请求
 type CreateKnowledgeBaseRequest struct {
 	Name             string  `json:"name" binding:"required"`
-	EmbeddingModelID string  `json:"embedding_model_id" binding:"required"` // 必填，Embedding 模型 ID
+	EmbeddingModelID string  `json:"embedding_model_id"`                    // 必填（已设置默认 Embedding 模型时可不传）
 	RerankModelID    *string `json:"rerank_model_id"`                       // 可选，Rerank 模型 ID
 	ChunkSize        *int     `json:"chunk_size"`           // 可选，不传则根据嵌入模型 max_context 自动设置
 	ChunkOverlap     *int     `json:"chunk_overlap"`        // 可选，不传则为 0（不重叠）
+	ChunkOverlapUnit *string  `json:"chunk_overlap_unit"`   // 可选，重叠单位（tokens/characters/sentences），默认 tokens
 	ChunkStrategy    *string  `json:"chunk_strategy"`       // 可选，不传则为 "recursive"
 	Threshold        *float32 `json:"threshold"`            // 可选，相似度阈值（0.0-1.0），默认 0.0
 	TopK             *int     `json:"top_k"`                // 可选，返回文档数量（1-20），默认 5
 	EnableHybridSearch *bool  `json:"enable_hybrid_search"` // 可选，是否启用混合检索，默认 false
+	KeywordStopwords []string `json:"keyword_stopwords"`    // 可选，关键词检索停用词，默认为空
+	EmbeddingOverrides []EmbeddingOverrideRequest `json:"embedding_overrides"` // 可选，按文件类型覆盖 Embedding 模型
+	KnowledgeTemplate *string `json:"knowledge_template"` // 可选，检索结果注入模板（Go text/template），默认使用全局模板
+}
+
+// EmbeddingOverrideRequest 按文件类型覆盖 Embedding 模型
+type EmbeddingOverrideRequest struct {
+	FileTypes        []string `json:"file_types" binding:"required"`         // 文件扩展名（如 go、py）
+	EmbeddingModelID string   `json:"embedding_model_id" binding:"required"` // 覆盖使用的 Embedding 模型 ID
 }
 
 // UpdateKnowledgeBaseRequest 更新知识库请求
@@ -25,6 +35,28 @@ type UpdateKnowledgeBaseRequest struct {
 	Threshold          *float32 `json:"threshold"`            // 相似度阈值（0.0-1.0）
 	TopK               *int     `json:"top_k"`                // 返回文档数量（1-20）
 	EnableHybridSearch *bool    `json:"enable_hybrid_search"` // 是否启用混合检索
+	KeywordStopwords   []string `json:"keyword_stopwords"`    // 关键词检索停用词（传空数组清空）
+	KnowledgeTemplate  *string  `json:"knowledge_template"`   // 检索结果注入模板（传空字符串恢复全局模板）
+}
+
+// KnowledgeBaseDefaultsRequest 用户知识库默认设置请求（整体替换，不传的字段表示清除默认值）
+type KnowledgeBaseDefaultsRequest struct {
+	EmbeddingModelID *string  `json:"embedding_model_id"`
+	ChunkSize        *int     `json:"chunk_size"`     // 100-10000
+	ChunkOverlap     *int     `json:"chunk_overlap"`  // 需小于 chunk_size
+	ChunkStrategy    *string  `json:"chunk_strategy"` // recursive, fixed
+	TopK             *int     `json:"top_k"`          // 1-20
+	Threshold        *float32 `json:"threshold"`      // 0.0-1.0
+}
+
+// KnowledgeBaseDefaultsResponse 用户知识库默认设置响应（未设置的字段为 null）
+type KnowledgeBaseDefaultsResponse struct {
+	EmbeddingModelID *string  `json:"embedding_model_id"`
+	ChunkSize        *int     `json:"chunk_size"`
+	ChunkOverlap     *int     `json:"chunk_overlap"`
+	ChunkStrategy    *string  `json:"chunk_strategy"`
+	TopK             *int     `json:"top_k"`
+	Threshold        *float32 `json:"threshold"`
 }
 
 // KnowledgeBaseResponse 知识库响应
@@ -41,11 +73,15 @@ type KnowledgeBaseResponse struct {
 	RerankModelID    *string  `json:"rerank_model_id,omitempty"`
 	ChunkSize        *int     `json:"chunk_size,omitempty"`
 	ChunkOverlap     *int     `json:"chunk_overlap,omitempty"`
+	ChunkOverlapUnit *string  `json:"chunk_overlap_unit,omitempty"`
 	ChunkStrategy    *string  `json:"chunk_strategy,omitempty"`
 	MilvusCollection *string  `json:"milvus_collection,omitempty"`
 	Threshold        *float32 `json:"threshold,omitempty"`            // 相似度阈值
 	TopK             *int     `json:"top_k,omitempty"`                // 返回文档数量
 	EnableHybridSearch *bool  `json:"enable_hybrid_search,omitempty"` // 是否启用混合检索
+	KeywordStopwords []string `json:"keyword_stopwords,omitempty"`    // 关键词检索停用词
+	EmbeddingOverrides []biz.EmbeddingOverride `json:"embedding_overrides,omitempty"` // 按文件类型覆盖的 Embedding 模型
+	KnowledgeTemplate *string `json:"knowledge_template,omitempty"` // 检索结果注入模板（未设置时使用全局模板）
 	CreatedAt        *string  `json:"created_at,omitempty"`
 	UpdatedAt        *string  `json:"updated_at,omitempty"`
 }
@@ -82,6 +118,14 @@ type SearchResultItem struct {
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// ChunkPreviewItem 文档分块预览项
+type ChunkPreviewItem struct {
+	ID         string `json:"id"`
+	Position   int    `json:"position"`
+	Content    string `json:"content"`
+	TokenCount int    `json:"token_count"`
+}
+
 // toDocumentResponse 使用公共转换函数
 func toDocumentResponse(doc *biz.Document) *DocumentResponse {
 	return biz.ToDocumentResponse(doc)
@@ -99,3 +143,70 @@ func toSearchResults(results []*biz.SearchResult) []SearchResultItem {
 	}
 	return items
 }
+
+func toChunkPreviews(previews map[string][]*biz.Chunk) map[string][]ChunkPreviewItem {
+	items := make(map[string][]ChunkPreviewItem, len(previews))
+	for docID, chunks := range previews {
+		docItems := make([]ChunkPreviewItem, len(chunks))
+		for i, chunk := range chunks {
+			docItems[i] = ChunkPreviewItem{
+				ID:         chunk.ID,
+				Position:   chunk.Position,
+				Content:    chunk.Content,
+				TokenCount: chunk.TokenCount,
+			}
+		}
+		items[docID] = docItems
+	}
+	return items
+}
+
+// EmbeddingUsageResponse Embedding 用量统计响应
+type EmbeddingUsageResponse struct {
+	From           string                   `json:"from"`
+	To             string                   `json:"to"`
+	TotalTokens    int64                    `json:"total_tokens"`
+	KnowledgeBases []KnowledgeBaseUsageItem `json:"knowledge_bases"`
+	Owners         []OwnerUsageItem         `json:"owners"`
+}
+
+// KnowledgeBaseUsageItem 单个知识库的 Embedding 用量
+type KnowledgeBaseUsageItem struct {
+	KnowledgeBaseID string `json:"knowledge_base_id"`
+	OwnerID         string `json:"owner_id"`
+	Tokens          int64  `json:"tokens"`
+	Runs            int64  `json:"runs"`
+}
+
+// OwnerUsageItem 单个所有者的 Embedding 用量
+type OwnerUsageItem struct {
+	OwnerID string `json:"owner_id"`
+	Tokens  int64  `json:"tokens"`
+	Runs    int64  `json:"runs"`
+}
+
+func toEmbeddingUsageResponse(report *biz.EmbeddingUsageReport) *EmbeddingUsageResponse {
+	resp := &EmbeddingUsageResponse{
+		From:           report.From.Format(usageDateLayout),
+		To:             report.To.AddDate(0, 0, -1).Format(usageDateLayout),
+		TotalTokens:    report.TotalTokens,
+		KnowledgeBases: make([]KnowledgeBaseUsageItem, len(report.KnowledgeBases)),
+		Owners:         make([]OwnerUsageItem, len(report.Owners)),
+	}
+	for i, usage := range report.KnowledgeBases {
+		resp.KnowledgeBases[i] = KnowledgeBaseUsageItem{
+			KnowledgeBaseID: usage.KnowledgeBaseID,
+			OwnerID:         usage.OwnerID,
+			Tokens:          usage.Tokens,
+			Runs:            usage.Runs,
+		}
+	}
+	for i, usage := range report.Owners {
+		resp.Owners[i] = OwnerUsageItem{
+			OwnerID: usage.OwnerID,
+			Tokens:  usage.Tokens,
+			Runs:    usage.Runs,
+		}
+	}
+	return resp
+}
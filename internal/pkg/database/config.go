@@ -33,6 +33,7 @@ type Config struct {
 	Timezone        string `mapstructure:"timezone"`        // Database timezone
 	AutoMigrate     bool   `mapstructure:"automigrate"`     // Enable auto migration
 	PreferSimpleProtocol bool `mapstructure:"prefersimpleprotocol"` // Prefer simple protocol
+	SearchPath      string `mapstructure:"searchpath"`      // Schema search path for every connection (optional)
 }
 
 // DefaultConfig returns the default database configuration
@@ -140,5 +141,9 @@ func (c *Config) DSN() string {
 		dsn += " prefer_simple_protocol=true"
 	}
 
+	if c.SearchPath != "" {
+		dsn += " search_path=" + c.SearchPath
+	}
+
 	return dsn
 }
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
@@ -189,10 +190,11 @@ func IsDuplicateKeyError(err error) bool {
 	if err == nil {
 		return false
 	}
-	// PostgreSQL duplicate key error code: 23505
-	return err.Error() == "ERROR: duplicate key value violates unique constraint (SQLSTATE 23505)" ||
-		err.Error() == "UNIQUE constraint failed" ||
-		err.Error() == "Duplicate entry"
+	// PostgreSQL duplicate key error code: 23505 (the message also names the constraint, and callers may wrap it)
+	msg := err.Error()
+	return strings.Contains(msg, "(SQLSTATE 23505)") ||
+		strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "Duplicate entry")
 }
 
 // GetDB returns the underlying gorm.DB instance
@@ -310,6 +310,7 @@ func TestConfigDSN(t *testing.T) {
 		SSLMode:              "disable",
 		Timezone:             "UTC",
 		PreferSimpleProtocol: true,
+		SearchPath:           "test_schema",
 	}
 
 	dsn := cfg.DSN()
@@ -318,7 +319,7 @@ func TestConfigDSN(t *testing.T) {
 	}
 
 	// Check if DSN contains expected parts
-	expectedParts := []string{"host=", "user=", "password=", "dbname=", "sslmode=", "TimeZone="}
+	expectedParts := []string{"host=", "user=", "password=", "dbname=", "sslmode=", "TimeZone=", "search_path=test_schema"}
 	for _, part := range expectedParts {
 		if !contains(dsn, part) {
 			t.Errorf("DSN missing expected part: %s", part)
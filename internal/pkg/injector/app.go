@@ -4,6 +4,7 @@ import (
 	"github.com/lk2023060901/ai-writer-backend/internal/conf"
 	kbqueue "github.com/lk2023060901/ai-writer-backend/internal/knowledge/queue"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+	"github.com/lk2023060901/ai-writer-backend/internal/seed"
 	"github.com/lk2023060901/ai-writer-backend/internal/server"
 )
 
@@ -14,6 +15,7 @@ type App struct {
 	HTTPServer     *server.HTTPServer
 	GRPCServer     *server.GRPCServer
 	DocumentWorker *kbqueue.Worker
+	Seeder         *seed.Seeder
 	cleanup        func()
 }
 
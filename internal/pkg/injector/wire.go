@@ -37,6 +37,7 @@ import (
 	pkgredis "github.com/lk2023060901/ai-writer-backend/internal/pkg/redis"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/sse"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/workerpool"
+	"github.com/lk2023060901/ai-writer-backend/internal/seed"
 	"github.com/lk2023060901/ai-writer-backend/internal/server"
 	userbiz "github.com/lk2023060901/ai-writer-backend/internal/user/biz"
 	userdata "github.com/lk2023060901/ai-writer-backend/internal/user/data"
@@ -83,6 +84,8 @@ var repositoryProviderSet = wire.NewSet(
 	provideModelSyncLogRepo,
 	provideDocumentProviderRepo,
 	provideKnowledgeBaseRepo,
+	provideKnowledgeBaseDefaultsRepo,
+	provideEmbeddingUsageRepo,
 	provideDocumentRepo,
 	provideChunkRepo,
 	provideFileStorageRepo,
@@ -90,6 +93,7 @@ var repositoryProviderSet = wire.NewSet(
 	provideTopicRepo,
 	provideMessageRepo,
 	provideFavoriteRepo,
+	provideModelAliasRepo,
 )
 
 // Use case providers
@@ -108,6 +112,7 @@ var useCaseProviderSet = wire.NewSet(
 	assistantbiz.NewTopicUseCase,
 	assistantbiz.NewMessageUseCase,
 	assistantbiz.NewFavoriteUseCase,
+	assistantbiz.NewModelAliasUseCase,
 )
 
 // Service providers
@@ -117,6 +122,10 @@ var serviceProviderSet = wire.NewSet(
 	provideEmbeddingService,
 	provideMinerUClient,
 	provideDocumentProcessor,
+	provideDocumentConfig,
+	provideSystemKnowledgeBaseConfig,
+	provideModelSyncConfig,
+	provideAIModelConfig,
 	provideEmailConfig,
 	provideOAuth2Config,
 	provideTokenStore,
@@ -124,6 +133,11 @@ var serviceProviderSet = wire.NewSet(
 	provideSSEHub,
 	provideProviderFactory,
 	provideOrchestrator,
+	provideOrchestratorConfig,
+	provideMetricsCollector,
+	provideContextManager,
+	provideFileProcessor,
+	provideErrorHandler,
 	provideUploadWorkerPool,
 )
 
@@ -142,6 +156,7 @@ var httpServiceProviderSet = wire.NewSet(
 	assistantservice.NewTopicService,
 	assistantservice.NewMessageService,
 	assistantservice.NewFavoriteService,
+	assistantservice.NewModelAliasService,
 	provideEmailService,
 	emailhandler.NewEmailHandler,
 	emailhandler.NewOAuth2Handler,
@@ -152,6 +167,7 @@ var serverProviderSet = wire.NewSet(
 	server.NewHTTPServer,
 	server.NewGRPCServer,
 	provideDocumentWorkerWithStart,
+	provideSeederWithRun,
 )
 
 // InitializeApp initializes the application with Wire
@@ -160,6 +176,12 @@ func InitializeApp(config *conf.Config, log *logger.Logger) (*App, func(), error
 	return nil, nil, nil
 }
 
+// InitializeDocumentUseCase initializes the document use case for command-line tools
+func InitializeDocumentUseCase(config *conf.Config, log *logger.Logger) (*kbbiz.DocumentUseCase, func(), error) {
+	wire.Build(ProviderSet)
+	return nil, nil, nil
+}
+
 // Provider functions for complex dependencies
 
 func provideAuthUseCase(
@@ -182,8 +204,20 @@ func provideStorageService(
 	return kbdata.NewMinIOStorageService(d.MinIOClient, config.MinIO.Bucket)
 }
 
-func provideVectorDBService(d *data.Data) kbbiz.VectorDBService {
-	return kbdata.NewMilvusVectorDBService(d.MilvusClient)
+func provideVectorDBService(d *data.Data, config *conf.Config) (kbbiz.VectorDBService, error) {
+	retry := config.Milvus.Retry
+	consistency := kbdata.VectorConsistencyConfig{
+		AutoFlush:              config.Milvus.AutoFlush,
+		SearchConsistencyLevel: config.Milvus.SearchConsistencyLevel,
+	}
+	if err := consistency.Validate(); err != nil {
+		return nil, err
+	}
+	return kbdata.NewMilvusVectorDBService(d.MilvusClient, kbdata.VectorRetryConfig{
+		MaxAttempts:    retry.MaxAttempts,
+		InitialBackoff: retry.InitialBackoff,
+		MaxBackoff:     retry.MaxBackoff,
+	}, consistency), nil
 }
 
 func provideSSEHub() *sse.Hub {
@@ -197,12 +231,38 @@ func provideDocumentWorkerWithStart(
 	log *logger.Logger,
 ) (*kbqueue.Worker, error) {
 	worker := kbqueue.NewWorker(d.RedisClient, docUseCase, sseHub, log.Logger, 5)
+	// 队列深度指标注册到默认注册表，由内部 metrics 监听端口暴露
+	if err := worker.RegisterMetrics(nil); err != nil {
+		return nil, err
+	}
 	if err := worker.Start(context.Background()); err != nil {
 		return nil, err
 	}
 	return worker, nil
 }
 
+// provideSeederWithRun 启动时写入官方智能体与助手（按稳定 Key 幂等）
+func provideSeederWithRun(
+	config *conf.Config,
+	agentUseCase *agentbiz.AgentUseCase,
+	assistantUseCase *assistantbiz.AssistantUseCase,
+	log *logger.Logger,
+) (*seed.Seeder, error) {
+	seeder := seed.NewSeeder(agentUseCase, assistantUseCase, log.Logger)
+	if config.Seed.Disabled {
+		return seeder, nil
+	}
+
+	defs, err := seed.LoadDefinitions(config.Seed.File)
+	if err != nil {
+		return nil, err
+	}
+	if err := seeder.Run(context.Background(), defs); err != nil {
+		return nil, err
+	}
+	return seeder, nil
+}
+
 func provideGRPCAuthService(
 	authUC *authbiz.AuthUseCase,
 	log *logger.Logger,
@@ -231,10 +291,12 @@ func provideDocumentUseCase(
 	aiModelRepo kbbiz.AIModelRepo,
 	aiProviderRepo kbbiz.AIProviderRepo,
 	fileStorageRepo kbbiz.FileStorageRepo,
+	embeddingUsageRepo kbbiz.EmbeddingUsageRepo,
 	storage kbbiz.StorageService,
 	vectorDB kbbiz.VectorDBService,
 	embedder kbbiz.EmbeddingService,
 	processor kbbiz.DocumentProcessor,
+	cfg *kbbiz.DocumentConfig,
 	log *logger.Logger,
 ) *kbbiz.DocumentUseCase {
 	return kbbiz.NewDocumentUseCase(
@@ -244,14 +306,80 @@ func provideDocumentUseCase(
 		aiModelRepo,
 		aiProviderRepo,
 		fileStorageRepo,
+		embeddingUsageRepo,
 		storage,
 		vectorDB,
 		embedder,
 		processor,
+		cfg,
 		log,
 	)
 }
 
+// provideSystemKnowledgeBaseConfig 提供系统知识库服务商绑定配置
+func provideSystemKnowledgeBaseConfig(config *conf.Config) *kbbiz.SystemKnowledgeBaseConfig {
+	return &kbbiz.SystemKnowledgeBaseConfig{
+		ProviderIDs:      config.Knowledge.SystemProviderIDs,
+		EmbeddingModelID: config.Knowledge.SystemEmbeddingModelID,
+	}
+}
+
+// provideAIModelConfig 提供AI模型配置
+func provideAIModelConfig(config *conf.Config) *kbbiz.AIModelConfig {
+	return &kbbiz.AIModelConfig{
+		MaxPageSize: config.Knowledge.ModelListMaxPageSize,
+	}
+}
+
+// provideModelSyncConfig 提供模型同步配置
+func provideModelSyncConfig(config *conf.Config) *kbbiz.ModelSyncConfig {
+	return &kbbiz.ModelSyncConfig{
+		Timeout:                   config.Knowledge.ModelSyncTimeout,
+		DimensionProbeConcurrency: config.Knowledge.ModelProbeConcurrency,
+		EmbeddingPayloadFormats:   config.Knowledge.EmbeddingPayloadFormats,
+	}
+}
+
+// provideDocumentConfig 提供文档处理配置
+func provideDocumentConfig(config *conf.Config) *kbbiz.DocumentConfig {
+	cfg := kbbiz.DefaultDocumentConfig()
+	if config.Knowledge.EmptyContentPolicy != "" {
+		cfg.EmptyContentPolicy = config.Knowledge.EmptyContentPolicy
+	}
+	if config.Knowledge.VectorSearchTimeout > 0 {
+		cfg.VectorSearchTimeout = config.Knowledge.VectorSearchTimeout
+	}
+	if config.Knowledge.DuplicateDocumentPolicy != "" {
+		cfg.DuplicateDocumentPolicy = config.Knowledge.DuplicateDocumentPolicy
+	}
+	if config.Knowledge.CompactAfterDeletes > 0 {
+		cfg.CompactAfterDeletes = config.Knowledge.CompactAfterDeletes
+	}
+	if config.Knowledge.CompactionTimeout > 0 {
+		cfg.CompactionTimeout = config.Knowledge.CompactionTimeout
+	}
+	if config.Knowledge.VectorContentMaxBytes > 0 {
+		cfg.VectorContentMaxBytes = config.Knowledge.VectorContentMaxBytes
+	}
+	if config.Knowledge.VectorContentPolicy != "" {
+		cfg.VectorContentPolicy = config.Knowledge.VectorContentPolicy
+	}
+	if config.Knowledge.EmbeddingCostPer1KTokens > 0 {
+		cfg.EmbeddingCostPer1KTokens = config.Knowledge.EmbeddingCostPer1KTokens
+	}
+	cfg.StrictChunkStrategy = config.Knowledge.StrictChunkStrategy
+	cfg.BackupEmbeddingModelID = config.Knowledge.BackupEmbeddingModelID
+	if config.Knowledge.MaxSearchTopK > 0 {
+		cfg.MaxSearchTopK = config.Knowledge.MaxSearchTopK
+	}
+	cfg.MinResultContentLength = config.Knowledge.MinResultContentLength
+	if config.Knowledge.MissingObjectPolicy != "" {
+		cfg.MissingObjectPolicy = config.Knowledge.MissingObjectPolicy
+	}
+	cfg.SkipUnchangedReprocess = config.Knowledge.SkipUnchangedReprocess
+	return cfg
+}
+
 // Repository providers
 
 func provideUserRepo(d *data.Data) userbiz.UserRepo {
@@ -290,6 +418,14 @@ func provideKnowledgeBaseRepo(d *data.Data) kbbiz.KnowledgeBaseRepo {
 	return kbdata.NewKnowledgeBaseRepo(d.DBWrapper)
 }
 
+func provideKnowledgeBaseDefaultsRepo(d *data.Data) kbbiz.KnowledgeBaseDefaultsRepo {
+	return kbdata.NewKnowledgeBaseDefaultsRepo(d.DBWrapper)
+}
+
+func provideEmbeddingUsageRepo(d *data.Data) kbbiz.EmbeddingUsageRepo {
+	return kbdata.NewEmbeddingUsageRepo(d.DBWrapper)
+}
+
 func provideDocumentRepo(d *data.Data) kbbiz.DocumentRepo {
 	return kbdata.NewDocumentRepo(d.DBWrapper)
 }
@@ -319,17 +455,26 @@ func provideFavoriteRepo(d *data.Data) assistantbiz.FavoriteRepo {
 	return assistantdata.NewFavoriteRepo(d.DBWrapper)
 }
 
+func provideModelAliasRepo(d *data.Data) assistantbiz.ModelAliasRepo {
+	return assistantdata.NewModelAliasRepo(d.DBWrapper)
+}
+
 func provideModelSyncLogRepo(d *data.Data) kbbiz.ModelSyncLogRepo {
 	return kbdata.NewModelSyncLogRepo(d.DBWrapper)
 }
 
 // Service providers
 
-func provideEmbeddingService() kbbiz.EmbeddingService {
-	return kbembedding.NewEmbeddingService()
+func provideEmbeddingService(config *conf.Config) kbbiz.EmbeddingService {
+	return kbembedding.NewEmbeddingService(config.Knowledge.EmbeddingPayloadFormats)
 }
 
+// provideMinerUClient 未配置 MinerU API Key 时返回 nil，文档处理仅使用本地提取器
 func provideMinerUClient(config *conf.Config, log *logger.Logger) (*mineru.Client, error) {
+	if config.MinerU.APIKey == "" {
+		log.Warn("MinerU is not configured, only local extractors are available")
+		return nil, nil
+	}
 	cfg := &mineru.Config{
 		BaseURL:         config.MinerU.BaseURL,
 		APIKey:          config.MinerU.APIKey,
@@ -404,6 +549,13 @@ func provideProviderFactory(
 func provideOrchestrator(
 	providerFactory llm.ProviderFactory,
 	docUseCase *kbbiz.DocumentUseCase,
+	modelAliasUseCase *assistantbiz.ModelAliasUseCase,
+	aiModelUseCase *kbbiz.AIModelUseCase,
+	cfg *llm.OrchestratorConfig,
+	contextManager llm.ContextManager,
+	fileProcessor llm.FileProcessor,
+	errorHandler llm.ErrorHandler,
+	metricsCollector llm.MetricsCollector,
 	zapLogger *zap.Logger,
 ) llm.MultiProviderOrchestrator {
 	// 创建知识库适配器
@@ -412,16 +564,93 @@ func provideOrchestrator(
 	// 创建 Orchestrator
 	return llm.NewOrchestrator(
 		providerFactory,
-		nil, // contextManager
+		contextManager,
 		nil, // webSearch
-		nil, // fileProcessor
-		nil, // errorHandler
-		nil, // metricsCollector
+		fileProcessor,
+		errorHandler,
+		metricsCollector,
 		knowledgeSearcher,
+		modelAliasUseCase, // modelResolver
+		llm.NewModelCapabilityAdapter(aiModelUseCase), // modelCapabilities
+		cfg,
 		zapLogger,
 	)
 }
 
+// provideContextManager 提供基于消息仓储的会话上下文管理器
+func provideContextManager(messageRepo assistantbiz.MessageRepo, topicRepo assistantbiz.TopicRepo, cfg *llm.OrchestratorConfig) llm.ContextManager {
+	return llm.NewMessageContextManager(messageRepo, topicRepo, cfg)
+}
+
+// provideFileProcessor 提供聊天附件处理器（复用知识库的文档处理器提取文本）
+func provideFileProcessor(processor kbbiz.DocumentProcessor, cfg *llm.OrchestratorConfig) llm.FileProcessor {
+	return llm.NewDocumentFileProcessor(processor, cfg)
+}
+
+// provideErrorHandler 提供服务商错误处理器（错误分类、重试判断与对客户端安全的错误信息）
+func provideErrorHandler() llm.ErrorHandler {
+	return llm.NewErrorHandler()
+}
+
+// provideMetricsCollector 提供 LLM 指标收集器（注册到 Prometheus 默认注册表，由 /metrics 暴露）
+func provideMetricsCollector() (llm.MetricsCollector, error) {
+	collector, err := llm.NewPrometheusMetricsCollector(nil)
+	if err != nil {
+		return nil, err
+	}
+	return collector, nil
+}
+
+// provideOrchestratorConfig 提供编排器配置
+func provideOrchestratorConfig(config *conf.Config) *llm.OrchestratorConfig {
+	cfg := llm.DefaultOrchestratorConfig()
+	if config.LLM.ProviderOptionPolicy != "" {
+		cfg.ProviderOptionPolicy = config.LLM.ProviderOptionPolicy
+	}
+	if config.LLM.KnowledgeSearchErrorPolicy != "" {
+		cfg.KnowledgeSearchErrorPolicy = config.LLM.KnowledgeSearchErrorPolicy
+	}
+	if config.LLM.KnowledgeTemplate != "" {
+		cfg.KnowledgeTemplate = config.LLM.KnowledgeTemplate
+	}
+	if config.LLM.KnowledgeContextRole != "" {
+		cfg.KnowledgeContextRole = config.LLM.KnowledgeContextRole
+	}
+	if config.LLM.NonStreamChunkSize > 0 {
+		cfg.NonStreamChunkSize = config.LLM.NonStreamChunkSize
+	}
+	if config.LLM.MaxProvidersPerRequest > 0 {
+		cfg.MaxProvidersPerRequest = config.LLM.MaxProvidersPerRequest
+	}
+	if config.LLM.StructuredOutputMaxRepairs != 0 {
+		cfg.StructuredOutputMaxRepairs = config.LLM.StructuredOutputMaxRepairs
+	}
+	if config.LLM.HistoryMaxMessages != 0 {
+		cfg.HistoryMaxMessages = config.LLM.HistoryMaxMessages
+	}
+	if config.LLM.HistoryTokenBudget != 0 {
+		cfg.HistoryTokenBudget = config.LLM.HistoryTokenBudget
+	}
+	if config.LLM.FileMaxSize != 0 {
+		cfg.FileMaxSize = config.LLM.FileMaxSize
+	}
+	if config.LLM.FileContentMaxChars != 0 {
+		cfg.FileContentMaxChars = config.LLM.FileContentMaxChars
+	}
+	cfg.FileAllowedHosts = config.LLM.FileAllowedHosts
+	if len(cfg.FileAllowedHosts) == 0 && config.MinIO.Endpoint != "" {
+		// 未配置时只允许下载 MinIO 上的附件
+		cfg.FileAllowedHosts = []string{config.MinIO.Endpoint}
+	}
+	if config.LLM.ProviderMaxRetries != 0 {
+		cfg.ProviderMaxRetries = config.LLM.ProviderMaxRetries
+	}
+	if config.LLM.ProviderRetryBackoff > 0 {
+		cfg.ProviderRetryBackoff = config.LLM.ProviderRetryBackoff
+	}
+	return cfg
+}
+
 // provideUploadWorkerPool 提供上传文件 Worker Pool
 func provideUploadWorkerPool(
 	config *conf.Config,
@@ -457,6 +686,7 @@ func newApp(
 	grpcServer *server.GRPCServer,
 	documentWorker *kbqueue.Worker,
 	uploadPool *workerpool.Pool,
+	seeder *seed.Seeder,
 ) (*App, func()) {
 	// Cleanup function combines worker and data cleanup
 	cleanup := func() {
@@ -474,6 +704,7 @@ func newApp(
 		HTTPServer:     httpServer,
 		GRPCServer:     grpcServer,
 		DocumentWorker: documentWorker,
+		Seeder:         seeder,
 		cleanup:        cleanup,
 	}, cleanup
 }
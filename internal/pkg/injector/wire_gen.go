@@ -38,6 +38,7 @@ import (
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/redis"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/sse"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/workerpool"
+	"github.com/lk2023060901/ai-writer-backend/internal/seed"
 	"github.com/lk2023060901/ai-writer-backend/internal/server"
 	"github.com/lk2023060901/ai-writer-backend/internal/user/biz"
 	data3 "github.com/lk2023060901/ai-writer-backend/internal/user/data"
@@ -69,30 +70,40 @@ func InitializeApp(config *conf.Config, log *logger.Logger) (*App, func(), error
 	aiProviderRepo := provideAIProviderRepo(data)
 	aiProviderUseCase := biz3.NewAIProviderUseCase(aiProviderRepo)
 	aiModelRepo := provideAIModelRepo(data)
-	aiModelUseCase := biz3.NewAIModelUseCase(aiModelRepo)
+	aiModelConfig := provideAIModelConfig(config)
+	aiModelUseCase := biz3.NewAIModelUseCase(aiModelRepo, aiModelConfig)
 	aiProviderService := service4.NewAIProviderService(aiProviderUseCase, aiModelUseCase, log)
 	modelSyncLogRepo := provideModelSyncLogRepo(data)
-	modelSyncUseCase := biz3.NewModelSyncUseCase(aiProviderRepo, aiModelRepo, modelSyncLogRepo)
+	modelSyncConfig := provideModelSyncConfig(config)
+	modelSyncUseCase := biz3.NewModelSyncUseCase(aiProviderRepo, aiModelRepo, modelSyncLogRepo, modelSyncConfig)
 	aiModelService := service4.NewAIModelService(aiModelUseCase, modelSyncUseCase, zapLogger)
 	documentProviderRepo := provideDocumentProviderRepo(data)
 	documentProviderUseCase := biz3.NewDocumentProviderUseCase(documentProviderRepo)
 	documentProviderService := service4.NewDocumentProviderService(documentProviderUseCase, log)
 	knowledgeBaseRepo := provideKnowledgeBaseRepo(data)
-	knowledgeBaseUseCase := biz3.NewKnowledgeBaseUseCase(knowledgeBaseRepo, aiModelRepo)
-	knowledgeBaseService := service4.NewKnowledgeBaseService(knowledgeBaseUseCase, aiProviderUseCase, log)
+	knowledgeBaseDefaultsRepo := provideKnowledgeBaseDefaultsRepo(data)
+	systemKnowledgeBaseConfig := provideSystemKnowledgeBaseConfig(config)
 	documentRepo := provideDocumentRepo(data)
 	chunkRepo := provideChunkRepo(data)
 	fileStorageRepo := provideFileStorageRepo(data)
+	embeddingUsageRepo := provideEmbeddingUsageRepo(data)
 	storageService := provideStorageService(data, config)
-	vectorDBService := provideVectorDBService(data)
-	embeddingService := provideEmbeddingService()
+	vectorDBService, err := provideVectorDBService(data, config)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	knowledgeBaseUseCase := biz3.NewKnowledgeBaseUseCase(knowledgeBaseRepo, aiModelRepo, knowledgeBaseDefaultsRepo, systemKnowledgeBaseConfig, vectorDBService)
+	knowledgeBaseService := service4.NewKnowledgeBaseService(knowledgeBaseUseCase, aiProviderUseCase, log)
+	embeddingService := provideEmbeddingService(config)
 	client, err := provideMinerUClient(config, log)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
 	documentProcessor := provideDocumentProcessor(client, log)
-	documentUseCase := provideDocumentUseCase(documentRepo, chunkRepo, knowledgeBaseRepo, aiModelRepo, aiProviderRepo, fileStorageRepo, storageService, vectorDBService, embeddingService, documentProcessor, log)
+	documentConfig := provideDocumentConfig(config)
+	documentUseCase := provideDocumentUseCase(documentRepo, chunkRepo, knowledgeBaseRepo, aiModelRepo, aiProviderRepo, fileStorageRepo, embeddingUsageRepo, storageService, vectorDBService, embeddingService, documentProcessor, documentConfig, log)
 	hub := provideSSEHub()
 	worker, err := provideDocumentWorkerWithStart(data, documentUseCase, hub, log)
 	if err != nil {
@@ -108,17 +119,34 @@ func InitializeApp(config *conf.Config, log *logger.Logger) (*App, func(), error
 	assistantRepo := provideAssistantRepo(data)
 	topicRepo := provideTopicRepo(data)
 	assistantUseCase := biz4.NewAssistantUseCase(assistantRepo, topicRepo)
+	seeder, err := provideSeederWithRun(config, agentUseCase, assistantUseCase, log)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	topicUseCase := biz4.NewTopicUseCase(topicRepo)
 	messageRepo := provideMessageRepo(data)
 	messageUseCase := biz4.NewMessageUseCase(messageRepo, topicRepo)
 	providerFactory := provideProviderFactory(aiProviderUseCase, zapLogger)
-	multiProviderOrchestrator := provideOrchestrator(providerFactory, documentUseCase, zapLogger)
+	orchestratorConfig := provideOrchestratorConfig(config)
+	modelAliasRepo := provideModelAliasRepo(data)
+	modelAliasUseCase := biz4.NewModelAliasUseCase(modelAliasRepo)
+	contextManager := provideContextManager(messageRepo, topicRepo, orchestratorConfig)
+	fileProcessor := provideFileProcessor(documentProcessor, orchestratorConfig)
+	errorHandler := provideErrorHandler()
+	metricsCollector, err := provideMetricsCollector()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	multiProviderOrchestrator := provideOrchestrator(providerFactory, documentUseCase, modelAliasUseCase, aiModelUseCase, orchestratorConfig, contextManager, fileProcessor, errorHandler, metricsCollector, zapLogger)
 	assistantService := service5.NewAssistantService(assistantUseCase, topicUseCase, messageUseCase, hub, multiProviderOrchestrator)
 	topicService := service5.NewTopicService(topicUseCase)
 	messageService := service5.NewMessageService(messageUseCase)
 	favoriteRepo := provideFavoriteRepo(data)
 	favoriteUseCase := biz4.NewFavoriteUseCase(favoriteRepo)
 	favoriteService := service5.NewFavoriteService(favoriteUseCase)
+	modelAliasService := service5.NewModelAliasService(modelAliasUseCase)
 	emailConfig := provideEmailConfig(config)
 	oauth2Config := provideOAuth2Config(config)
 	tokenStore, err := provideTokenStore(data)
@@ -139,16 +167,49 @@ func InitializeApp(config *conf.Config, log *logger.Logger) (*App, func(), error
 	emailHandler := handler.NewEmailHandler(emailService)
 	redisClient := provideRedisClient(data)
 	oAuth2Handler := handler.NewOAuth2Handler(emailService, redisClient)
-	httpServer := server.NewHTTPServer(config, log, userService, authService, agentService, aiProviderService, aiModelService, documentProviderService, knowledgeBaseService, documentService, assistantService, topicService, messageService, favoriteService, emailHandler, oAuth2Handler, redisClient)
+	httpServer := server.NewHTTPServer(config, log, userService, authService, agentService, aiProviderService, aiModelService, documentProviderService, knowledgeBaseService, documentService, assistantService, topicService, messageService, favoriteService, modelAliasService, emailHandler, oAuth2Handler, redisClient)
 	authServiceServer := provideGRPCAuthService(authUseCase, log)
 	grpcServer := server.NewGRPCServer(config, log, authServiceServer)
-	app, cleanup2 := newApp(config, log, httpServer, grpcServer, worker, pool)
+	app, cleanup2 := newApp(config, log, httpServer, grpcServer, worker, pool, seeder)
 	return app, func() {
 		cleanup2()
 		cleanup()
 	}, nil
 }
 
+// InitializeDocumentUseCase initializes the document use case for command-line tools
+func InitializeDocumentUseCase(config *conf.Config, log *logger.Logger) (*biz3.DocumentUseCase, func(), error) {
+	data, cleanup, err := provideData(config, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	documentRepo := provideDocumentRepo(data)
+	chunkRepo := provideChunkRepo(data)
+	knowledgeBaseRepo := provideKnowledgeBaseRepo(data)
+	aiModelRepo := provideAIModelRepo(data)
+	aiProviderRepo := provideAIProviderRepo(data)
+	fileStorageRepo := provideFileStorageRepo(data)
+	embeddingUsageRepo := provideEmbeddingUsageRepo(data)
+	storageService := provideStorageService(data, config)
+	vectorDBService, err := provideVectorDBService(data, config)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	embeddingService := provideEmbeddingService(config)
+	client, err := provideMinerUClient(config, log)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	documentProcessor := provideDocumentProcessor(client, log)
+	documentConfig := provideDocumentConfig(config)
+	documentUseCase := provideDocumentUseCase(documentRepo, chunkRepo, knowledgeBaseRepo, aiModelRepo, aiProviderRepo, fileStorageRepo, embeddingUsageRepo, storageService, vectorDBService, embeddingService, documentProcessor, documentConfig, log)
+	return documentUseCase, func() {
+		cleanup()
+	}, nil
+}
+
 // wire.go:
 
 // ProviderSet is the Wire provider set for all dependencies
@@ -185,6 +246,8 @@ var repositoryProviderSet = wire.NewSet(
 	provideModelSyncLogRepo,
 	provideDocumentProviderRepo,
 	provideKnowledgeBaseRepo,
+	provideKnowledgeBaseDefaultsRepo,
+	provideEmbeddingUsageRepo,
 	provideDocumentRepo,
 	provideChunkRepo,
 	provideFileStorageRepo,
@@ -192,11 +255,12 @@ var repositoryProviderSet = wire.NewSet(
 	provideTopicRepo,
 	provideMessageRepo,
 	provideFavoriteRepo,
+	provideModelAliasRepo,
 )
 
 // Use case providers
 var useCaseProviderSet = wire.NewSet(
-	provideZapLogger, biz.NewUserUseCase, provideAuthUseCase, biz2.NewAgentUseCase, biz3.NewAIProviderUseCase, biz3.NewAIModelUseCase, biz3.NewModelSyncUseCase, biz3.NewDocumentProviderUseCase, biz3.NewKnowledgeBaseUseCase, provideDocumentUseCase, biz4.NewAssistantUseCase, biz4.NewTopicUseCase, biz4.NewMessageUseCase, biz4.NewFavoriteUseCase,
+	provideZapLogger, biz.NewUserUseCase, provideAuthUseCase, biz2.NewAgentUseCase, biz3.NewAIProviderUseCase, biz3.NewAIModelUseCase, biz3.NewModelSyncUseCase, biz3.NewDocumentProviderUseCase, biz3.NewKnowledgeBaseUseCase, provideDocumentUseCase, biz4.NewAssistantUseCase, biz4.NewTopicUseCase, biz4.NewMessageUseCase, biz4.NewFavoriteUseCase, biz4.NewModelAliasUseCase,
 )
 
 // Service providers
@@ -206,6 +270,10 @@ var serviceProviderSet = wire.NewSet(
 	provideEmbeddingService,
 	provideMinerUClient,
 	provideDocumentProcessor,
+	provideDocumentConfig,
+	provideSystemKnowledgeBaseConfig,
+	provideModelSyncConfig,
+	provideAIModelConfig,
 	provideEmailConfig,
 	provideOAuth2Config,
 	provideTokenStore,
@@ -213,14 +281,19 @@ var serviceProviderSet = wire.NewSet(
 	provideSSEHub,
 	provideProviderFactory,
 	provideOrchestrator,
+	provideOrchestratorConfig,
+	provideMetricsCollector,
+	provideContextManager,
+	provideFileProcessor,
+	provideErrorHandler,
 	provideUploadWorkerPool,
 )
 
 // HTTP/gRPC service providers
-var httpServiceProviderSet = wire.NewSet(service.NewUserService, service2.NewAuthService, provideGRPCAuthService, service3.NewAgentService, service4.NewAIProviderService, service4.NewAIModelService, service4.NewDocumentProviderService, service4.NewKnowledgeBaseService, service4.NewDocumentService, service5.NewAssistantService, service5.NewTopicService, service5.NewMessageService, service5.NewFavoriteService, provideEmailService, handler.NewEmailHandler, handler.NewOAuth2Handler)
+var httpServiceProviderSet = wire.NewSet(service.NewUserService, service2.NewAuthService, provideGRPCAuthService, service3.NewAgentService, service4.NewAIProviderService, service4.NewAIModelService, service4.NewDocumentProviderService, service4.NewKnowledgeBaseService, service4.NewDocumentService, service5.NewAssistantService, service5.NewTopicService, service5.NewMessageService, service5.NewFavoriteService, service5.NewModelAliasService, provideEmailService, handler.NewEmailHandler, handler.NewOAuth2Handler)
 
 // Server providers
-var serverProviderSet = wire.NewSet(server.NewHTTPServer, server.NewGRPCServer, provideDocumentWorkerWithStart)
+var serverProviderSet = wire.NewSet(server.NewHTTPServer, server.NewGRPCServer, provideDocumentWorkerWithStart, provideSeederWithRun)
 
 func provideAuthUseCase(
 	userRepo biz5.UserRepo,
@@ -242,8 +315,20 @@ func provideStorageService(
 	return data2.NewMinIOStorageService(d.MinIOClient, config.MinIO.Bucket)
 }
 
-func provideVectorDBService(d *data.Data) biz3.VectorDBService {
-	return data2.NewMilvusVectorDBService(d.MilvusClient)
+func provideVectorDBService(d *data.Data, config *conf.Config) (biz3.VectorDBService, error) {
+	retry := config.Milvus.Retry
+	consistency := data2.VectorConsistencyConfig{
+		AutoFlush:              config.Milvus.AutoFlush,
+		SearchConsistencyLevel: config.Milvus.SearchConsistencyLevel,
+	}
+	if err := consistency.Validate(); err != nil {
+		return nil, err
+	}
+	return data2.NewMilvusVectorDBService(d.MilvusClient, data2.VectorRetryConfig{
+		MaxAttempts:    retry.MaxAttempts,
+		InitialBackoff: retry.InitialBackoff,
+		MaxBackoff:     retry.MaxBackoff,
+	}, consistency), nil
 }
 
 func provideSSEHub() *sse.Hub {
@@ -257,12 +342,38 @@ func provideDocumentWorkerWithStart(
 	log *logger.Logger,
 ) (*queue.Worker, error) {
 	worker := queue.NewWorker(d.RedisClient, docUseCase, sseHub, log.Logger, 5)
+	// 队列深度指标注册到默认注册表，由内部 metrics 监听端口暴露
+	if err := worker.RegisterMetrics(nil); err != nil {
+		return nil, err
+	}
 	if err := worker.Start(context.Background()); err != nil {
 		return nil, err
 	}
 	return worker, nil
 }
 
+// provideSeederWithRun 启动时写入官方智能体与助手（按稳定 Key 幂等）
+func provideSeederWithRun(
+	config *conf.Config,
+	agentUseCase *biz2.AgentUseCase,
+	assistantUseCase *biz4.AssistantUseCase,
+	log *logger.Logger,
+) (*seed.Seeder, error) {
+	seeder := seed.NewSeeder(agentUseCase, assistantUseCase, log.Logger)
+	if config.Seed.Disabled {
+		return seeder, nil
+	}
+
+	defs, err := seed.LoadDefinitions(config.Seed.File)
+	if err != nil {
+		return nil, err
+	}
+	if err := seeder.Run(context.Background(), defs); err != nil {
+		return nil, err
+	}
+	return seeder, nil
+}
+
 func provideGRPCAuthService(
 	authUC *biz5.AuthUseCase,
 	log *logger.Logger,
@@ -289,10 +400,12 @@ func provideDocumentUseCase(
 	aiModelRepo biz3.AIModelRepo,
 	aiProviderRepo biz3.AIProviderRepo,
 	fileStorageRepo biz3.FileStorageRepo,
+	embeddingUsageRepo biz3.EmbeddingUsageRepo,
 	storage biz3.StorageService,
 	vectorDB biz3.VectorDBService,
 	embedder biz3.EmbeddingService,
 	processor biz3.DocumentProcessor,
+	cfg *biz3.DocumentConfig,
 	log *logger.Logger,
 ) *biz3.DocumentUseCase {
 	return biz3.NewDocumentUseCase(
@@ -302,14 +415,80 @@ func provideDocumentUseCase(
 		aiModelRepo,
 		aiProviderRepo,
 		fileStorageRepo,
+		embeddingUsageRepo,
 		storage,
 		vectorDB,
 		embedder,
 		processor,
+		cfg,
 		log,
 	)
 }
 
+// provideSystemKnowledgeBaseConfig 提供系统知识库服务商绑定配置
+func provideSystemKnowledgeBaseConfig(config *conf.Config) *biz3.SystemKnowledgeBaseConfig {
+	return &biz3.SystemKnowledgeBaseConfig{
+		ProviderIDs:      config.Knowledge.SystemProviderIDs,
+		EmbeddingModelID: config.Knowledge.SystemEmbeddingModelID,
+	}
+}
+
+// provideAIModelConfig 提供AI模型配置
+func provideAIModelConfig(config *conf.Config) *biz3.AIModelConfig {
+	return &biz3.AIModelConfig{
+		MaxPageSize: config.Knowledge.ModelListMaxPageSize,
+	}
+}
+
+// provideModelSyncConfig 提供模型同步配置
+func provideModelSyncConfig(config *conf.Config) *biz3.ModelSyncConfig {
+	return &biz3.ModelSyncConfig{
+		Timeout:                   config.Knowledge.ModelSyncTimeout,
+		DimensionProbeConcurrency: config.Knowledge.ModelProbeConcurrency,
+		EmbeddingPayloadFormats:   config.Knowledge.EmbeddingPayloadFormats,
+	}
+}
+
+// provideDocumentConfig 提供文档处理配置
+func provideDocumentConfig(config *conf.Config) *biz3.DocumentConfig {
+	cfg := biz3.DefaultDocumentConfig()
+	if config.Knowledge.EmptyContentPolicy != "" {
+		cfg.EmptyContentPolicy = config.Knowledge.EmptyContentPolicy
+	}
+	if config.Knowledge.VectorSearchTimeout > 0 {
+		cfg.VectorSearchTimeout = config.Knowledge.VectorSearchTimeout
+	}
+	if config.Knowledge.DuplicateDocumentPolicy != "" {
+		cfg.DuplicateDocumentPolicy = config.Knowledge.DuplicateDocumentPolicy
+	}
+	if config.Knowledge.CompactAfterDeletes > 0 {
+		cfg.CompactAfterDeletes = config.Knowledge.CompactAfterDeletes
+	}
+	if config.Knowledge.CompactionTimeout > 0 {
+		cfg.CompactionTimeout = config.Knowledge.CompactionTimeout
+	}
+	if config.Knowledge.VectorContentMaxBytes > 0 {
+		cfg.VectorContentMaxBytes = config.Knowledge.VectorContentMaxBytes
+	}
+	if config.Knowledge.VectorContentPolicy != "" {
+		cfg.VectorContentPolicy = config.Knowledge.VectorContentPolicy
+	}
+	if config.Knowledge.EmbeddingCostPer1KTokens > 0 {
+		cfg.EmbeddingCostPer1KTokens = config.Knowledge.EmbeddingCostPer1KTokens
+	}
+	cfg.StrictChunkStrategy = config.Knowledge.StrictChunkStrategy
+	cfg.BackupEmbeddingModelID = config.Knowledge.BackupEmbeddingModelID
+	if config.Knowledge.MaxSearchTopK > 0 {
+		cfg.MaxSearchTopK = config.Knowledge.MaxSearchTopK
+	}
+	cfg.MinResultContentLength = config.Knowledge.MinResultContentLength
+	if config.Knowledge.MissingObjectPolicy != "" {
+		cfg.MissingObjectPolicy = config.Knowledge.MissingObjectPolicy
+	}
+	cfg.SkipUnchangedReprocess = config.Knowledge.SkipUnchangedReprocess
+	return cfg
+}
+
 func provideUserRepo(d *data.Data) biz.UserRepo {
 	return data3.NewUserRepo(d.DB)
 }
@@ -346,6 +525,14 @@ func provideKnowledgeBaseRepo(d *data.Data) biz3.KnowledgeBaseRepo {
 	return data2.NewKnowledgeBaseRepo(d.DBWrapper)
 }
 
+func provideKnowledgeBaseDefaultsRepo(d *data.Data) biz3.KnowledgeBaseDefaultsRepo {
+	return data2.NewKnowledgeBaseDefaultsRepo(d.DBWrapper)
+}
+
+func provideEmbeddingUsageRepo(d *data.Data) biz3.EmbeddingUsageRepo {
+	return data2.NewEmbeddingUsageRepo(d.DBWrapper)
+}
+
 func provideDocumentRepo(d *data.Data) biz3.DocumentRepo {
 	return data2.NewDocumentRepo(d.DBWrapper)
 }
@@ -375,15 +562,24 @@ func provideFavoriteRepo(d *data.Data) biz4.FavoriteRepo {
 	return data6.NewFavoriteRepo(d.DBWrapper)
 }
 
+func provideModelAliasRepo(d *data.Data) biz4.ModelAliasRepo {
+	return data6.NewModelAliasRepo(d.DBWrapper)
+}
+
 func provideModelSyncLogRepo(d *data.Data) biz3.ModelSyncLogRepo {
 	return data2.NewModelSyncLogRepo(d.DBWrapper)
 }
 
-func provideEmbeddingService() biz3.EmbeddingService {
-	return embedding.NewEmbeddingService()
+func provideEmbeddingService(config *conf.Config) biz3.EmbeddingService {
+	return embedding.NewEmbeddingService(config.Knowledge.EmbeddingPayloadFormats)
 }
 
+// provideMinerUClient 未配置 MinerU API Key 时返回 nil，文档处理仅使用本地提取器
 func provideMinerUClient(config *conf.Config, log *logger.Logger) (*mineru.Client, error) {
+	if config.MinerU.APIKey == "" {
+		log.Warn("MinerU is not configured, only local extractors are available")
+		return nil, nil
+	}
 	cfg := &mineru.Config{
 		BaseURL:         config.MinerU.BaseURL,
 		APIKey:          config.MinerU.APIKey,
@@ -456,6 +652,13 @@ func provideProviderFactory(
 func provideOrchestrator(
 	providerFactory llm.ProviderFactory,
 	docUseCase *biz3.DocumentUseCase,
+	modelAliasUseCase *biz4.ModelAliasUseCase,
+	aiModelUseCase *biz3.AIModelUseCase,
+	cfg *llm.OrchestratorConfig,
+	contextManager llm.ContextManager,
+	fileProcessor llm.FileProcessor,
+	errorHandler llm.ErrorHandler,
+	metricsCollector llm.MetricsCollector,
 	zapLogger *zap.Logger,
 ) llm.MultiProviderOrchestrator {
 
@@ -463,16 +666,93 @@ func provideOrchestrator(
 
 	return llm.NewOrchestrator(
 		providerFactory,
+		contextManager,
 		nil,
-		nil,
-		nil,
-		nil,
-		nil,
+		fileProcessor,
+		errorHandler,
+		metricsCollector,
 		knowledgeSearcher,
+		modelAliasUseCase,
+		llm.NewModelCapabilityAdapter(aiModelUseCase),
+		cfg,
 		zapLogger,
 	)
 }
 
+// provideContextManager 提供基于消息仓储的会话上下文管理器
+func provideContextManager(messageRepo biz4.MessageRepo, topicRepo biz4.TopicRepo, cfg *llm.OrchestratorConfig) llm.ContextManager {
+	return llm.NewMessageContextManager(messageRepo, topicRepo, cfg)
+}
+
+// provideFileProcessor 提供聊天附件处理器（复用知识库的文档处理器提取文本）
+func provideFileProcessor(processor biz3.DocumentProcessor, cfg *llm.OrchestratorConfig) llm.FileProcessor {
+	return llm.NewDocumentFileProcessor(processor, cfg)
+}
+
+// provideErrorHandler 提供服务商错误处理器（错误分类、重试判断与对客户端安全的错误信息）
+func provideErrorHandler() llm.ErrorHandler {
+	return llm.NewErrorHandler()
+}
+
+// provideMetricsCollector 提供 LLM 指标收集器（注册到 Prometheus 默认注册表，由 /metrics 暴露）
+func provideMetricsCollector() (llm.MetricsCollector, error) {
+	collector, err := llm.NewPrometheusMetricsCollector(nil)
+	if err != nil {
+		return nil, err
+	}
+	return collector, nil
+}
+
+// provideOrchestratorConfig 提供编排器配置
+func provideOrchestratorConfig(config *conf.Config) *llm.OrchestratorConfig {
+	cfg := llm.DefaultOrchestratorConfig()
+	if config.LLM.ProviderOptionPolicy != "" {
+		cfg.ProviderOptionPolicy = config.LLM.ProviderOptionPolicy
+	}
+	if config.LLM.KnowledgeSearchErrorPolicy != "" {
+		cfg.KnowledgeSearchErrorPolicy = config.LLM.KnowledgeSearchErrorPolicy
+	}
+	if config.LLM.KnowledgeTemplate != "" {
+		cfg.KnowledgeTemplate = config.LLM.KnowledgeTemplate
+	}
+	if config.LLM.KnowledgeContextRole != "" {
+		cfg.KnowledgeContextRole = config.LLM.KnowledgeContextRole
+	}
+	if config.LLM.NonStreamChunkSize > 0 {
+		cfg.NonStreamChunkSize = config.LLM.NonStreamChunkSize
+	}
+	if config.LLM.MaxProvidersPerRequest > 0 {
+		cfg.MaxProvidersPerRequest = config.LLM.MaxProvidersPerRequest
+	}
+	if config.LLM.StructuredOutputMaxRepairs != 0 {
+		cfg.StructuredOutputMaxRepairs = config.LLM.StructuredOutputMaxRepairs
+	}
+	if config.LLM.HistoryMaxMessages != 0 {
+		cfg.HistoryMaxMessages = config.LLM.HistoryMaxMessages
+	}
+	if config.LLM.HistoryTokenBudget != 0 {
+		cfg.HistoryTokenBudget = config.LLM.HistoryTokenBudget
+	}
+	if config.LLM.FileMaxSize != 0 {
+		cfg.FileMaxSize = config.LLM.FileMaxSize
+	}
+	if config.LLM.FileContentMaxChars != 0 {
+		cfg.FileContentMaxChars = config.LLM.FileContentMaxChars
+	}
+	cfg.FileAllowedHosts = config.LLM.FileAllowedHosts
+	if len(cfg.FileAllowedHosts) == 0 && config.MinIO.Endpoint != "" {
+		// 未配置时只允许下载 MinIO 上的附件
+		cfg.FileAllowedHosts = []string{config.MinIO.Endpoint}
+	}
+	if config.LLM.ProviderMaxRetries != 0 {
+		cfg.ProviderMaxRetries = config.LLM.ProviderMaxRetries
+	}
+	if config.LLM.ProviderRetryBackoff > 0 {
+		cfg.ProviderRetryBackoff = config.LLM.ProviderRetryBackoff
+	}
+	return cfg
+}
+
 // provideUploadWorkerPool 提供上传文件 Worker Pool
 func provideUploadWorkerPool(
 	config *conf.Config,
@@ -507,6 +787,7 @@ func newApp(
 	grpcServer *server.GRPCServer,
 	documentWorker *queue.Worker,
 	uploadPool *workerpool.Pool,
+	seeder *seed.Seeder,
 ) (*App, func()) {
 
 	cleanup := func() {
@@ -524,6 +805,7 @@ func newApp(
 		HTTPServer:     httpServer,
 		GRPCServer:     grpcServer,
 		DocumentWorker: documentWorker,
+		Seeder:         seeder,
 		cleanup:        cleanup,
 	}, cleanup
 }
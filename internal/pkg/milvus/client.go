@@ -9,6 +9,8 @@ import (
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Client Milvus 客户端封装
@@ -38,23 +40,9 @@ func New(ctx context.Context, cfg *Config, log *logger.Logger) (*Client, error)
 	cfg.SetDefaults()
 
 	// 构建客户端配置
-	clientCfg := &milvusclient.ClientConfig{
-		Address: cfg.Address,
-	}
-
-	// 设置认证
-	if cfg.Username != "" && cfg.Password != "" {
-		clientCfg.Username = cfg.Username
-		clientCfg.Password = cfg.Password
-	}
-
-	if cfg.APIKey != "" {
-		clientCfg.APIKey = cfg.APIKey
-	}
-
-	// 设置数据库
-	if cfg.Database != "" {
-		clientCfg.DBName = cfg.Database
+	clientCfg, err := buildClientConfig(cfg)
+	if err != nil {
+		return nil, WrapError("New", err, "", "")
 	}
 
 	// 创建带超时的上下文
@@ -83,6 +71,41 @@ func New(ctx context.Context, cfg *Config, log *logger.Logger) (*Client, error)
 	}, nil
 }
 
+// buildClientConfig 构建 SDK 客户端配置（地址、认证、数据库、TLS）
+func buildClientConfig(cfg *Config) (*milvusclient.ClientConfig, error) {
+	clientCfg := &milvusclient.ClientConfig{
+		Address: cfg.Address,
+	}
+
+	// 设置认证
+	if cfg.Username != "" && cfg.Password != "" {
+		clientCfg.Username = cfg.Username
+		clientCfg.Password = cfg.Password
+	}
+
+	if cfg.APIKey != "" {
+		clientCfg.APIKey = cfg.APIKey
+	}
+
+	// 设置数据库
+	if cfg.Database != "" {
+		clientCfg.DBName = cfg.Database
+	}
+
+	// 设置 TLS：SDK 的 EnableTLSAuth 只使用系统根证书，自定义 CA / 双向 TLS 需要通过 DialOptions 传入凭据
+	tlsCfg, err := cfg.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		clientCfg.EnableTLSAuth = true
+		clientCfg.DialOptions = append(append([]grpc.DialOption{}, milvusclient.DefaultGrpcOpts...),
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	}
+
+	return clientCfg, nil
+}
+
 // Close 关闭客户端连接
 func (c *Client) Close(ctx context.Context) error {
 	c.mu.Lock()
@@ -1,8 +1,11 @@
 package milvus
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 )
 
@@ -32,8 +35,13 @@ type Config struct {
 	RetryDelay time.Duration // Delay between retries
 
 	// TLS settings
-	EnableTLS bool   // Enable TLS connection
-	TLSMode   string // TLS mode (optional)
+	EnableTLS          bool   // Enable TLS connection
+	TLSMode            string // TLS mode (optional)
+	CACertFile         string // CA certificate used to verify the server (optional, system roots by default)
+	CertFile           string // Client certificate for mutual TLS (optional, requires KeyFile)
+	KeyFile            string // Client private key for mutual TLS (optional, requires CertFile)
+	ServerName         string // Server name used to verify the certificate (optional)
+	InsecureSkipVerify bool   // Skip server certificate verification (testing only)
 
 	// Other settings
 	EnableTracing bool // Enable request tracing for debugging
@@ -85,9 +93,53 @@ func (c *Config) Validate() error {
 		return errors.New("milvus: retry delay must be non-negative")
 	}
 
+	// Validate TLS settings
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("milvus: client certificate and key must be configured together")
+	}
+
+	if !c.EnableTLS && (c.CACertFile != "" || c.CertFile != "" || c.ServerName != "" || c.InsecureSkipVerify) {
+		return errors.New("milvus: TLS options require EnableTLS")
+	}
+
 	return nil
 }
 
+// TLSConfig builds the TLS configuration for the connection (nil when TLS is disabled)
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	if !c.EnableTLS {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CACertFile != "" {
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("milvus: failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("milvus: no valid certificates in %s", c.CACertFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("milvus: failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
 // SetDefaults sets default values for unspecified configuration fields
 func (c *Config) SetDefaults() {
 	if c.Database == "" {
@@ -139,8 +191,8 @@ func (c *Config) String() string {
 		apiKey = ""
 	}
 
-	return fmt.Sprintf("Config{Address: %s, Username: %s, Password: %s, APIKey: %s, Database: %s, EnableTLS: %v}",
-		c.Address, c.Username, password, apiKey, c.Database, c.EnableTLS)
+	return fmt.Sprintf("Config{Address: %s, Username: %s, Password: %s, APIKey: %s, Database: %s, EnableTLS: %v, MutualTLS: %v}",
+		c.Address, c.Username, password, apiKey, c.Database, c.EnableTLS, c.CertFile != "")
 }
 
 // DefaultConfig returns a configuration with default values
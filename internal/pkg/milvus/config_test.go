@@ -1,10 +1,21 @@
 package milvus
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -166,6 +177,137 @@ func TestConfig_TLS(t *testing.T) {
 	assert.Equal(t, "mutual", cfg.TLSMode)
 }
 
+func TestConfig_ValidateTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{
+			name:    "tls with system roots",
+			cfg:     &Config{Address: "localhost:19530", EnableTLS: true},
+			wantErr: false,
+		},
+		{
+			name:    "client certificate without key",
+			cfg:     &Config{Address: "localhost:19530", EnableTLS: true, CertFile: "client.pem"},
+			wantErr: true,
+		},
+		{
+			name:    "tls options without tls",
+			cfg:     &Config{Address: "localhost:19530", CACertFile: "ca.pem"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBuildClientConfig_Insecure(t *testing.T) {
+	clientCfg, err := buildClientConfig(&Config{Address: "localhost:19530", Database: "default"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost:19530", clientCfg.Address)
+	assert.Equal(t, "default", clientCfg.DBName)
+	assert.False(t, clientCfg.EnableTLSAuth)
+	assert.Empty(t, clientCfg.DialOptions)
+	assert.Empty(t, clientCfg.Username)
+	assert.Empty(t, clientCfg.APIKey)
+}
+
+func TestBuildClientConfig_AuthAndTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+
+	cfg := &Config{
+		Address:    "milvus.example.com:443",
+		Username:   "root",
+		Password:   "secret",
+		EnableTLS:  true,
+		CACertFile: certFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: "milvus.internal",
+	}
+	require.NoError(t, cfg.Validate())
+
+	clientCfg, err := buildClientConfig(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "root", clientCfg.Username)
+	assert.Equal(t, "secret", clientCfg.Password)
+	assert.True(t, clientCfg.EnableTLSAuth)
+	// 默认连接选项 + 自定义 TLS 凭据
+	assert.Len(t, clientCfg.DialOptions, len(milvusclient.DefaultGrpcOpts)+1)
+
+	tlsCfg, err := cfg.TLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "milvus.internal", tlsCfg.ServerName)
+	assert.NotNil(t, tlsCfg.RootCAs)
+	assert.Len(t, tlsCfg.Certificates, 1)
+	assert.False(t, tlsCfg.InsecureSkipVerify)
+}
+
+func TestBuildClientConfig_APIKey(t *testing.T) {
+	clientCfg, err := buildClientConfig(&Config{
+		Address:   "https://in01-xxx.zillizcloud.com:443",
+		APIKey:    "api-key",
+		EnableTLS: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "api-key", clientCfg.APIKey)
+	assert.Empty(t, clientCfg.Username)
+	assert.True(t, clientCfg.EnableTLSAuth)
+}
+
+func TestConfig_TLSConfigInvalidCA(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+	_, err := (&Config{Address: "localhost:19530", EnableTLS: true, CACertFile: caFile}).TLSConfig()
+	assert.Error(t, err)
+}
+
+// writeTestCertificate 生成自签名证书和私钥文件
+func writeTestCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "milvus.internal"},
+		DNSNames:              []string{"milvus.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
 func TestConfig_ValidateEdgeCases(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -2,6 +2,7 @@ package sse
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 )
@@ -11,6 +12,12 @@ type WorkerPool interface {
 	Submit(task func()) error
 }
 
+// StagedError 带失败阶段的错误(失败事件附带 stage 字段)
+type StagedError interface {
+	error
+	FailedStage() string
+}
+
 // ItemNamer 可命名的项目(用于获取项目名称)
 type ItemNamer interface {
 	GetName() string
@@ -191,15 +198,23 @@ func (u *BatchUploader[T]) sendFailureEvent(index int, itemName string, err erro
 	u.tracker.failedCount.Add(1)
 	completed := u.tracker.completed.Add(1)
 
-	eventType := fmt.Sprintf("%s-failed", u.eventPrefix)
-	return u.stream.Send(eventType, map[string]interface{}{
+	eventData := map[string]interface{}{
 		"index":     index + 1,
 		"total":     u.tracker.total,
 		"completed": int(completed),
 		"item_name": itemName,
 		"error":     err.Error(),
 		"message":   fmt.Sprintf("Item '%s' processing failed: %s", itemName, err.Error()),
-	})
+	}
+
+	// 标注失败阶段(如上传 MinIO、创建文档记录)
+	var stagedErr StagedError
+	if errors.As(err, &stagedErr) {
+		eventData["stage"] = stagedErr.FailedStage()
+	}
+
+	eventType := fmt.Sprintf("%s-failed", u.eventPrefix)
+	return u.stream.Send(eventType, eventData)
 }
 
 // defaultItemNamer 默认的项目名称提取器
@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// inlinePool 在调用方 goroutine 中直接执行任务
+type inlinePool struct{}
+
+func (inlinePool) Submit(task func()) error {
+	task()
+	return nil
+}
+
+// stageError 测试用的带阶段错误
+type stageError struct {
+	stage string
+	err   error
+}
+
+func (e *stageError) Error() string       { return e.stage + ": " + e.err.Error() }
+func (e *stageError) Unwrap() error       { return e.err }
+func (e *stageError) FailedStage() string { return e.stage }
+
+func TestBatchUploader_FailureEventIncludesStage(t *testing.T) {
+	stream := NewStream(nil, nil).WithBufferSize(10).Build()
+	items := []string{"ok.txt", "broken.txt", "lost.txt"}
+
+	err := NewBatchUploader[string](stream, len(items)).
+		WithEventPrefix("file").
+		WithItemNamer(func(item string) string { return item }).
+		Process(items, func(ctx context.Context, item string) (interface{}, error) {
+			switch item {
+			case "broken.txt":
+				return nil, fmt.Errorf("upload failed: %w", &stageError{stage: "storage_upload", err: errors.New("connection refused")})
+			case "lost.txt":
+				return nil, errors.New("unknown failure")
+			}
+			return nil, nil
+		}).
+		WithWorkerPool(inlinePool{}).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	stages := make(map[string]interface{})
+	for len(stream.client.Channel) > 0 {
+		event := <-stream.client.Channel
+		if event.Type != "file-failed" {
+			continue
+		}
+		data := event.Data.(map[string]interface{})
+		stages[data["item_name"].(string)] = data["stage"]
+	}
+
+	if stages["broken.txt"] != "storage_upload" {
+		t.Errorf("Expected stage storage_upload for broken.txt, got %v", stages["broken.txt"])
+	}
+	if stage, ok := stages["lost.txt"]; !ok || stage != nil {
+		t.Errorf("Expected failure event without stage for lost.txt, got %v (present=%v)", stage, ok)
+	}
+}
@@ -0,0 +1,122 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+
+	agentbiz "github.com/lk2023060901/ai-writer-backend/internal/agent/biz"
+	assistantbiz "github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+//go:embed defaults.yaml
+var defaultDefinitions []byte
+
+// Definitions 种子数据定义
+type Definitions struct {
+	OfficialAgents []AgentDefinition     `mapstructure:"official_agents"`
+	Assistants     []AssistantDefinition `mapstructure:"assistants"`
+}
+
+// AgentDefinition 官方智能体定义
+type AgentDefinition struct {
+	Key              string   `mapstructure:"key"` // 稳定标识，决定智能体 ID
+	Name             string   `mapstructure:"name"`
+	Emoji            string   `mapstructure:"emoji"`
+	Prompt           string   `mapstructure:"prompt"`
+	Tags             []string `mapstructure:"tags"`
+	KnowledgeBaseIDs []string `mapstructure:"knowledge_base_ids"`
+}
+
+// AssistantDefinition 官方助手定义
+type AssistantDefinition struct {
+	Key              string   `mapstructure:"key"` // 稳定标识，决定助手 ID
+	Name             string   `mapstructure:"name"`
+	Emoji            string   `mapstructure:"emoji"`
+	Prompt           string   `mapstructure:"prompt"`
+	Type             string   `mapstructure:"type"` // assistant, translate
+	Tags             []string `mapstructure:"tags"`
+	KnowledgeBaseIDs []string `mapstructure:"knowledge_base_ids"`
+}
+
+// LoadDefinitions 加载种子定义（path 为空时使用内置默认值）
+func LoadDefinitions(path string) (*Definitions, error) {
+	v := viper.New()
+	if path == "" {
+		v.SetConfigType("yaml")
+		if err := v.ReadConfig(bytes.NewReader(defaultDefinitions)); err != nil {
+			return nil, fmt.Errorf("failed to read default seed definitions: %w", err)
+		}
+	} else {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read seed file %s: %w", path, err)
+		}
+	}
+
+	var defs Definitions
+	if err := v.Unmarshal(&defs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal seed definitions: %w", err)
+	}
+	return &defs, nil
+}
+
+// Seeder 启动时写入官方智能体与助手（按 Key 幂等，已存在的记录不做修改）
+type Seeder struct {
+	agentUseCase     *agentbiz.AgentUseCase
+	assistantUseCase *assistantbiz.AssistantUseCase
+	logger           *zap.Logger
+}
+
+// NewSeeder 创建种子数据写入器
+func NewSeeder(agentUseCase *agentbiz.AgentUseCase, assistantUseCase *assistantbiz.AssistantUseCase, logger *zap.Logger) *Seeder {
+	return &Seeder{
+		agentUseCase:     agentUseCase,
+		assistantUseCase: assistantUseCase,
+		logger:           logger,
+	}
+}
+
+// Run 创建缺失的官方智能体与助手
+func (s *Seeder) Run(ctx context.Context, defs *Definitions) error {
+	agentSeeds := make([]agentbiz.OfficialAgentSeed, len(defs.OfficialAgents))
+	for i, d := range defs.OfficialAgents {
+		agentSeeds[i] = agentbiz.OfficialAgentSeed{
+			Key:              d.Key,
+			Name:             d.Name,
+			Emoji:            d.Emoji,
+			Prompt:           d.Prompt,
+			Tags:             d.Tags,
+			KnowledgeBaseIDs: d.KnowledgeBaseIDs,
+		}
+	}
+	agentsCreated, err := s.agentUseCase.SeedOfficialAgents(ctx, agentSeeds)
+	if err != nil {
+		return err
+	}
+
+	assistantSeeds := make([]assistantbiz.AssistantSeed, len(defs.Assistants))
+	for i, d := range defs.Assistants {
+		assistantSeeds[i] = assistantbiz.AssistantSeed{
+			Key:              d.Key,
+			Name:             d.Name,
+			Emoji:            d.Emoji,
+			Prompt:           d.Prompt,
+			Type:             d.Type,
+			Tags:             d.Tags,
+			KnowledgeBaseIDs: d.KnowledgeBaseIDs,
+		}
+	}
+	assistantsCreated, err := s.assistantUseCase.SeedOfficialAssistants(ctx, assistantSeeds)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("official agents and assistants seeded",
+		zap.Int("agents_created", agentsCreated),
+		zap.Int("assistants_created", assistantsCreated))
+	return nil
+}
@@ -0,0 +1,228 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	agentbiz "github.com/lk2023060901/ai-writer-backend/internal/agent/biz"
+	assistantbiz "github.com/lk2023060901/ai-writer-backend/internal/assistant/biz"
+	"github.com/lk2023060901/ai-writer-backend/internal/assistant/types"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeOfficialAgentRepo deleted 中的 ID 模拟软删除的行：GetByID 不可见，但仍占用主键
+type fakeOfficialAgentRepo struct {
+	agents  map[string]*agentbiz.Agent
+	deleted map[string]bool
+	creates int
+}
+
+func (r *fakeOfficialAgentRepo) GetByID(ctx context.Context, id string) (*agentbiz.Agent, error) {
+	if agent, ok := r.agents[id]; ok {
+		return agent, nil
+	}
+	return nil, agentbiz.ErrAgentNotFound
+}
+
+func (r *fakeOfficialAgentRepo) List(ctx context.Context, req *agentbiz.ListAgentsRequest) ([]*agentbiz.Agent, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeOfficialAgentRepo) CreateIfAbsent(ctx context.Context, agent *agentbiz.Agent) (bool, error) {
+	if r.agents[agent.ID] != nil || r.deleted[agent.ID] {
+		return false, nil
+	}
+	r.agents[agent.ID] = agent
+	r.creates++
+	return true, nil
+}
+
+// fakeAssistantRepo deleted 中的 ID 模拟软删除的行：List 不可见，但仍占用主键
+type fakeAssistantRepo struct {
+	assistants map[string]*types.Assistant
+	deleted    map[string]bool
+	creates    int
+}
+
+func (r *fakeAssistantRepo) Create(ctx context.Context, assistant *types.Assistant) error {
+	if r.assistants[assistant.ID] != nil || r.deleted[assistant.ID] {
+		return errors.New("duplicate key value violates unique constraint")
+	}
+	r.assistants[assistant.ID] = assistant
+	r.creates++
+	return nil
+}
+
+func (r *fakeAssistantRepo) CreateIfAbsent(ctx context.Context, assistant *types.Assistant) (bool, error) {
+	if r.assistants[assistant.ID] != nil || r.deleted[assistant.ID] {
+		return false, nil
+	}
+	return true, r.Create(ctx, assistant)
+}
+
+func (r *fakeAssistantRepo) GetByID(ctx context.Context, id, userID string) (*types.Assistant, error) {
+	return r.assistants[id], nil
+}
+
+func (r *fakeAssistantRepo) List(ctx context.Context, userID string, filter *types.AssistantFilter) ([]*types.Assistant, error) {
+	var assistants []*types.Assistant
+	for _, assistant := range r.assistants {
+		if assistant.UserID == userID {
+			assistants = append(assistants, assistant)
+		}
+	}
+	return assistants, nil
+}
+
+func (r *fakeAssistantRepo) Update(ctx context.Context, assistant *types.Assistant) error {
+	return nil
+}
+
+func (r *fakeAssistantRepo) Delete(ctx context.Context, id, userID string) error {
+	return nil
+}
+
+func TestSeeder_CreatesDefaultsOnce(t *testing.T) {
+	agentRepo := &fakeOfficialAgentRepo{agents: map[string]*agentbiz.Agent{}}
+	assistantRepo := &fakeAssistantRepo{assistants: map[string]*types.Assistant{}}
+	seeder := NewSeeder(
+		agentbiz.NewAgentUseCase(nil, agentRepo),
+		assistantbiz.NewAssistantUseCase(assistantRepo, nil),
+		zap.NewNop(),
+	)
+
+	defs, err := LoadDefinitions("")
+	if err != nil {
+		t.Fatalf("LoadDefinitions failed: %v", err)
+	}
+	if len(defs.OfficialAgents) == 0 || len(defs.Assistants) == 0 {
+		t.Fatalf("Expected embedded defaults, got %+v", defs)
+	}
+
+	if err := seeder.Run(context.Background(), defs); err != nil {
+		t.Fatalf("first Run failed: %v", err)
+	}
+	if agentRepo.creates != len(defs.OfficialAgents) || assistantRepo.creates != len(defs.Assistants) {
+		t.Fatalf("Expected %d agents and %d assistants, got %d and %d",
+			len(defs.OfficialAgents), len(defs.Assistants), agentRepo.creates, assistantRepo.creates)
+	}
+
+	first := defs.OfficialAgents[0]
+	agent := agentRepo.agents[agentbiz.OfficialAgentSeedID(first.Key)]
+	if agent == nil || agent.Name != first.Name || !agent.IsOfficial() || !agent.IsEnabled {
+		t.Errorf("Expected enabled official agent %q keyed by %s, got %+v", first.Name, first.Key, agent)
+	}
+	for _, assistant := range assistantRepo.assistants {
+		if assistant.UserID != agentbiz.SystemOwnerID {
+			t.Errorf("Expected assistant %s to be owned by the system user, got %s", assistant.Name, assistant.UserID)
+		}
+	}
+
+	// 第二次运行不产生任何写入
+	if err := seeder.Run(context.Background(), defs); err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	if agentRepo.creates != len(defs.OfficialAgents) || assistantRepo.creates != len(defs.Assistants) {
+		t.Errorf("Expected second run to be a no-op, got %d agent and %d assistant creates", agentRepo.creates, assistantRepo.creates)
+	}
+}
+
+func TestSeeder_SkipsDeletedOfficialAssistants(t *testing.T) {
+	defs, err := LoadDefinitions("")
+	if err != nil {
+		t.Fatalf("LoadDefinitions failed: %v", err)
+	}
+	deletedID := assistantbiz.AssistantSeedID(defs.Assistants[0].Key)
+	assistantRepo := &fakeAssistantRepo{
+		assistants: map[string]*types.Assistant{},
+		deleted:    map[string]bool{deletedID: true},
+	}
+	assistants := assistantbiz.NewAssistantUseCase(assistantRepo, nil)
+	seeder := NewSeeder(
+		agentbiz.NewAgentUseCase(nil, &fakeOfficialAgentRepo{agents: map[string]*agentbiz.Agent{}}),
+		assistants,
+		zap.NewNop(),
+	)
+
+	if err := seeder.Run(context.Background(), defs); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if assistantRepo.assistants[deletedID] != nil || assistantRepo.creates != len(defs.Assistants)-1 {
+		t.Errorf("Expected deleted assistant to stay deleted, got %d creates", assistantRepo.creates)
+	}
+
+	// 官方助手对普通用户可见
+	listed, err := assistants.ListAssistants(context.Background(), "user-1", nil)
+	if err != nil {
+		t.Fatalf("ListAssistants failed: %v", err)
+	}
+	if len(listed) != len(defs.Assistants)-1 {
+		t.Errorf("Expected %d official assistants in the user's list, got %d", len(defs.Assistants)-1, len(listed))
+	}
+}
+
+func TestSeeder_SkipsDeletedOfficialAgents(t *testing.T) {
+	defs, err := LoadDefinitions("")
+	if err != nil {
+		t.Fatalf("LoadDefinitions failed: %v", err)
+	}
+	deletedID := agentbiz.OfficialAgentSeedID(defs.OfficialAgents[0].Key)
+	agentRepo := &fakeOfficialAgentRepo{
+		agents:  map[string]*agentbiz.Agent{},
+		deleted: map[string]bool{deletedID: true},
+	}
+	core, logs := observer.New(zap.InfoLevel)
+	seeder := NewSeeder(
+		agentbiz.NewAgentUseCase(nil, agentRepo),
+		assistantbiz.NewAssistantUseCase(&fakeAssistantRepo{assistants: map[string]*types.Assistant{}}, nil),
+		zap.New(core),
+	)
+
+	// 第二次运行时已删除的智能体也不应计为新建
+	wantCreated := []int64{int64(len(defs.OfficialAgents) - 1), 0}
+	for run, want := range wantCreated {
+		if err := seeder.Run(context.Background(), defs); err != nil {
+			t.Fatalf("Run %d failed: %v", run+1, err)
+		}
+		entries := logs.TakeAll()
+		if len(entries) != 1 {
+			t.Fatalf("Expected one seed log entry, got %d", len(entries))
+		}
+		if got := entries[0].ContextMap()["agents_created"]; got != want {
+			t.Errorf("Run %d: expected agents_created %d, got %v", run+1, want, got)
+		}
+	}
+	if agentRepo.agents[deletedID] != nil || agentRepo.creates != len(defs.OfficialAgents)-1 {
+		t.Errorf("Expected deleted agent to stay deleted, got %d creates", agentRepo.creates)
+	}
+}
+
+func TestLoadDefinitions_FromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	content := `official_agents:
+  - key: reviewer
+    name: 审稿人
+    prompt: 你是一名严格的审稿人，指出文章中的逻辑与表达问题。
+    tags: [审稿]
+    knowledge_base_ids: [kb-1]
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write seed file: %v", err)
+	}
+
+	defs, err := LoadDefinitions(path)
+	if err != nil {
+		t.Fatalf("LoadDefinitions failed: %v", err)
+	}
+	if len(defs.OfficialAgents) != 1 || len(defs.Assistants) != 0 {
+		t.Fatalf("Expected 1 agent and no assistants, got %+v", defs)
+	}
+	agent := defs.OfficialAgents[0]
+	if agent.Key != "reviewer" || len(agent.KnowledgeBaseIDs) != 1 || agent.KnowledgeBaseIDs[0] != "kb-1" {
+		t.Errorf("Unexpected agent definition %+v", agent)
+	}
+}
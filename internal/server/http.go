@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	agentservice "github.com/lk2023060901/ai-writer-backend/internal/agent/service"
+	"github.com/lk2023060901/ai-writer-backend/internal/auth"
 	assistantservice "github.com/lk2023060901/ai-writer-backend/internal/assistant/service"
 	"github.com/lk2023060901/ai-writer-backend/internal/auth/middleware"
 	authservice "github.com/lk2023060901/ai-writer-backend/internal/auth/service"
@@ -17,11 +18,13 @@ import (
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
 	"github.com/lk2023060901/ai-writer-backend/internal/pkg/redis"
 	"github.com/lk2023060901/ai-writer-backend/internal/user/service"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
 type HTTPServer struct {
 	server                  *http.Server
+	metricsServer           *http.Server // 内部指标监听，未配置端口时为 nil
 	logger                  *logger.Logger
 	userService             *service.UserService
 	authService             *authservice.AuthService
@@ -35,6 +38,7 @@ type HTTPServer struct {
 	topicService            *assistantservice.TopicService
 	messageService          *assistantservice.MessageService
 	favoriteService         *assistantservice.FavoriteService
+	modelAliasService       *assistantservice.ModelAliasService
 	emailHandler            *emailhandler.EmailHandler
 	oauth2Handler           *emailhandler.OAuth2Handler
 }
@@ -54,6 +58,7 @@ func NewHTTPServer(
 	topicService *assistantservice.TopicService,
 	messageService *assistantservice.MessageService,
 	favoriteService *assistantservice.FavoriteService,
+	modelAliasService *assistantservice.ModelAliasService,
 	emailHandler *emailhandler.EmailHandler,
 	oauth2Handler *emailhandler.OAuth2Handler,
 	redisClient *redis.Client,
@@ -140,7 +145,9 @@ func NewHTTPServer(
 			// AI Models routes (nested under providers)
 			aiProviders.GET("/:provider_id/models", aiModelService.HandleListModelsByProvider)
 			aiProviders.POST("/:provider_id/models/sync", aiModelService.HandleSyncProviderModels)
+			aiProviders.GET("/:provider_id/models/diff", aiModelService.HandleGetModelsDiff) // 预览同步差异（不写入）
 			aiProviders.GET("/:provider_id/models/sync-history", aiModelService.HandleGetSyncHistory)
+			aiProviders.POST("/:provider_id/models/verify", aiModelService.HandleVerifyProviderModels) // 批量验证模型可用性
 		}
 
 		// AI Models routes (global)
@@ -162,6 +169,9 @@ func NewHTTPServer(
 		{
 			kbs.GET("", kbService.ListKnowledgeBases)
 			kbs.POST("", kbService.CreateKnowledgeBase)
+			kbs.GET("/defaults", kbService.GetKnowledgeBaseDefaults)                 // 当前用户的知识库默认设置
+			kbs.PUT("/defaults", kbService.UpdateKnowledgeBaseDefaults)              // 设置知识库默认设置（创建时填充未传的字段）
+			kbs.GET("/supported-file-types", documentService.ListSupportedFileTypes) // 可上传的文件类型及处理方式
 			kbs.GET("/:id", kbService.GetKnowledgeBase)
 			kbs.PUT("/:id", kbService.UpdateKnowledgeBase)
 			kbs.DELETE("/:id", kbService.DeleteKnowledgeBase)
@@ -171,13 +181,27 @@ func NewHTTPServer(
 			kbs.POST("/:id/documents/batch-upload", documentService.BatchUploadDocuments) // 批量上传文档（返回 SSE）
 			kbs.GET("/:id/documents", documentService.ListDocuments)
 			kbs.POST("/:id/documents/batch-delete", documentService.BatchDeleteDocuments)  // 批量删除文档
+			kbs.POST("/:id/documents/preview", documentService.PreviewDocuments)           // 批量预览多个文档的分块
 			kbs.GET("/:id/document-stream/:doc_id", documentService.StreamDocumentStatus)  // SSE (独立路径避免冲突)
 			kbs.GET("/:id/documents/:doc_id", documentService.GetDocument)
 			kbs.DELETE("/:id/documents/:doc_id", documentService.DeleteDocument)
+			kbs.PATCH("/:id/documents/:doc_id/metadata", documentService.UpdateDocumentMetadata) // 更新文档元数据
 			kbs.POST("/:id/documents/:doc_id/reprocess", documentService.ReprocessDocument)
 			kbs.POST("/:id/search", documentService.SearchDocuments)
 		}
 
+		// Admin routes (protected, admin role required)
+		admin := newAdminGroup(protectedAPI)
+		{
+			admin.POST("/knowledge-bases/:id/reindex-keyword-search", documentService.ReindexKeywordSearch) // 重建全文搜索索引
+			admin.POST("/knowledge-bases/:id/compact", documentService.CompactKnowledgeBase)                // 压缩 Milvus collection
+			admin.GET("/documents/processing-queue", documentService.GetProcessingQueueStats)               // 文档处理队列深度
+			admin.GET("/knowledge-bases/embedding-usage", documentService.GetEmbeddingUsage)                // 按知识库/所有者统计 Embedding token 用量
+
+			// Model alias routes
+			modelAliasService.RegisterRoutes(admin)
+		}
+
 		// Topic routes (protected)
 		topicService.RegisterRoutes(protectedAPI)
 
@@ -210,6 +234,7 @@ func NewHTTPServer(
 			Addr:    addr,
 			Handler: router,
 		},
+		metricsServer:           newMetricsServer(config.Server),
 		logger:                  log,
 		userService:             userService,
 		authService:             authService,
@@ -223,12 +248,47 @@ func NewHTTPServer(
 		topicService:            topicService,
 		messageService:          messageService,
 		favoriteService:         favoriteService,
+		modelAliasService:       modelAliasService,
 		emailHandler:            emailHandler,
 		oauth2Handler:           oauth2Handler,
 	}
 }
 
+// newAdminGroup 创建管理员路由组（需要 access token 中的角色为 admin）
+func newAdminGroup(protectedAPI *gin.RouterGroup) *gin.RouterGroup {
+	admin := protectedAPI.Group("/admin")
+	admin.Use(middleware.RequireRole(auth.RoleAdmin))
+	return admin
+}
+
+// newMetricsServer 创建内部指标监听（与对外 API 分开，只提供 Prometheus /metrics）
+func newMetricsServer(config conf.ServerConfig) *http.Server {
+	if config.MetricsPort <= 0 {
+		return nil
+	}
+	host := config.MetricsHost
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", host, config.MetricsPort),
+		Handler: mux,
+	}
+}
+
 func (s *HTTPServer) Start() error {
+	if s.metricsServer != nil {
+		go func() {
+			s.logger.Info("starting metrics server", zap.String("addr", s.metricsServer.Addr))
+			if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				s.logger.Error("metrics server failed", zap.Error(err))
+			}
+		}()
+	}
+
 	s.logger.Info("starting HTTP server", zap.String("addr", s.server.Addr))
 
 	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -240,7 +300,16 @@ func (s *HTTPServer) Start() error {
 
 func (s *HTTPServer) Stop(ctx context.Context) error {
 	s.logger.Info("stopping HTTP server")
-	return s.server.Shutdown(ctx)
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(ctx); err != nil {
+			s.logger.Warn("failed to stop metrics server", zap.Error(err))
+		}
+	}
+	if err := s.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	// 请求处理完后再等待其触发的后台压缩
+	return s.documentService.Shutdown(ctx)
 }
 
 func LoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
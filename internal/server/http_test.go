@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lk2023060901/ai-writer-backend/internal/auth"
+	"github.com/lk2023060901/ai-writer-backend/internal/auth/middleware"
+	"github.com/lk2023060901/ai-writer-backend/internal/conf"
+	"github.com/lk2023060901/ai-writer-backend/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const testJWTSecret = "test-secret"
+
+func TestAdminRoutes_RequireAdminRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	protectedAPI := router.Group("/api/v1")
+	protectedAPI.Use(middleware.JWTAuth(testJWTSecret, &logger.Logger{Logger: zap.NewNop()}))
+	newAdminGroup(protectedAPI).GET("/documents/processing-queue", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	jwtManager := auth.NewJWTManager(testJWTSecret)
+	tests := []struct {
+		name       string
+		role       string
+		wantStatus int
+	}{
+		{name: "admin", role: auth.RoleAdmin, wantStatus: http.StatusOK},
+		{name: "regular user", role: auth.RoleUser, wantStatus: http.StatusForbidden},
+		{name: "token without role", role: "", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwtManager.GenerateAccessToken("u1", "u1@example.com", tt.role)
+			if err != nil {
+				t.Fatalf("GenerateAccessToken failed: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/documents/processing-queue", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewMetricsServer_InternalListenerOnly(t *testing.T) {
+	if srv := newMetricsServer(conf.ServerConfig{Host: "0.0.0.0", Port: 8080}); srv != nil {
+		t.Fatalf("Expected no metrics listener without metrics_port, got %s", srv.Addr)
+	}
+
+	srv := newMetricsServer(conf.ServerConfig{Host: "0.0.0.0", Port: 8080, MetricsPort: 9100})
+	if srv == nil || srv.Addr != "127.0.0.1:9100" {
+		t.Fatalf("Expected metrics listener on loopback by default, got %+v", srv)
+	}
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected /metrics to be served, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/login", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected only /metrics on the metrics listener, got %d", w.Code)
+	}
+}
@@ -39,6 +39,7 @@ type UserPO struct {
 	Name      string         `gorm:"size:100;not null"`
 	Email     string         `gorm:"size:255;not null;uniqueIndex:idx_users_email,where:deleted_at IS NULL"`
 	EmailVerified bool       `gorm:"not null;default:false"`
+	Role          string     `gorm:"size:20;not null;default:user"`
 
 	// 认证信息
 	PasswordHash string `gorm:"size:255;not null"`
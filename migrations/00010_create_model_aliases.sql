@@ -0,0 +1,20 @@
+-- +goose Up
+-- 创建模型别名表
+-- Migration: 00010_create_model_aliases
+-- Date: 2026-10-14
+
+-- 模型别名表（稳定别名 -> 当前具体模型 ID）
+CREATE TABLE IF NOT EXISTS model_aliases (
+    alias VARCHAR(100) PRIMARY KEY,              -- 稳定别名，如 claude-sonnet-latest
+    model VARCHAR(200) NOT NULL DEFAULT '',      -- 当前指向的具体模型 ID（空表示未映射）
+    description TEXT,                            -- 说明
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 注释
+COMMENT ON TABLE model_aliases IS '模型别名表，聊天请求中的别名在编排器中解析为具体模型 ID';
+COMMENT ON COLUMN model_aliases.model IS '具体模型 ID，为空时请求该别名会返回未映射错误';
+
+-- +goose Down
+DROP TABLE IF EXISTS model_aliases;
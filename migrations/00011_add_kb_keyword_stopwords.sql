@@ -0,0 +1,13 @@
+-- +goose Up
+-- 知识库关键词检索停用词
+-- Migration: 00011_add_kb_keyword_stopwords
+-- Date: 2026-10-14
+
+-- 停用词在构建 tsquery 前从查询中过滤，默认为空
+ALTER TABLE knowledge_bases
+ADD COLUMN IF NOT EXISTS keyword_stopwords JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN knowledge_bases.keyword_stopwords IS '关键词检索停用词（JSON 字符串数组），构建全文搜索查询前过滤，不区分大小写';
+
+-- +goose Down
+ALTER TABLE knowledge_bases DROP COLUMN IF EXISTS keyword_stopwords;
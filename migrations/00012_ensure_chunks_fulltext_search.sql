@@ -0,0 +1,80 @@
+-- +goose Up
+-- 确保 chunks 全文搜索依赖存在（可重复执行）
+-- Migration: 00012_ensure_chunks_fulltext_search
+-- Date: 2026-10-14
+--
+-- KeywordSearch 依赖 content_tsv 列、维护触发器和 bm25_score 函数。
+-- 通过其他方式建表（如 AutoMigrate）的数据库可能缺少这些对象，本迁移在已存在时跳过或覆盖为相同定义。
+
+-- 1. content_tsv 列与 GIN 索引
+ALTER TABLE chunks ADD COLUMN IF NOT EXISTS content_tsv tsvector;
+
+CREATE INDEX IF NOT EXISTS idx_chunks_content_tsv
+ON chunks USING GIN(content_tsv);
+
+-- 2. 维护触发器（'simple' 配置需与 data.ftsConfig 保持一致）
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chunks_content_tsv_trigger() RETURNS trigger AS $$
+BEGIN
+  NEW.content_tsv := to_tsvector('simple', COALESCE(NEW.content, ''));
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_trigger
+        WHERE tgname = 'tsvector_update'
+          AND tgrelid = 'chunks'::regclass
+    ) THEN
+        CREATE TRIGGER tsvector_update
+        BEFORE INSERT OR UPDATE ON chunks
+        FOR EACH ROW EXECUTE FUNCTION chunks_content_tsv_trigger();
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- 3. BM25 评分函数（与 00008 定义一致）
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION bm25_score(
+    content_tsv tsvector,
+    query_tsv tsquery,
+    k1 float DEFAULT 1.2,
+    b float DEFAULT 0.75
+) RETURNS float AS $$
+DECLARE
+    doc_length int;
+    avg_doc_length float;
+    score float := 0;
+    norm_length float;
+BEGIN
+    doc_length := array_length(tsvector_to_array(content_tsv), 1);
+    IF doc_length IS NULL THEN
+        RETURN 0;
+    END IF;
+
+    avg_doc_length := 100.0;
+    norm_length := 1 - b + b * (doc_length::float / avg_doc_length);
+
+    score := ts_rank_cd(content_tsv, query_tsv);
+    score := score * (k1 + 1) / (score + k1 * norm_length);
+
+    RETURN score;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+-- 4. 回填缺失的 tsvector（仅处理未索引的行，重复执行无副作用）
+UPDATE chunks
+SET content_tsv = to_tsvector('simple', COALESCE(content, ''))
+WHERE content_tsv IS NULL;
+
+COMMENT ON COLUMN chunks.content_tsv IS '全文搜索向量（自动维护）';
+
+-- +goose Down
+-- 全文搜索对象由 00007/00008 引入，此处不删除，避免破坏已有检索
+SELECT 1;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- 知识库按文件类型覆盖 Embedding 模型
+-- Migration: 00013_add_kb_embedding_overrides
+-- Date: 2026-10-14
+
+-- 每个覆盖包含 file_types、embedding_model_id 和独立的 milvus_collection，默认为空
+ALTER TABLE knowledge_bases
+ADD COLUMN IF NOT EXISTS embedding_overrides JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN knowledge_bases.embedding_overrides IS '按文件类型覆盖的 Embedding 模型（JSON 数组：file_types、embedding_model_id、milvus_collection），检索时多路召回融合';
+
+-- +goose Down
+ALTER TABLE knowledge_bases DROP COLUMN IF EXISTS embedding_overrides;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- 知识库分块重叠单位
+-- Migration: 00014_add_kb_chunk_overlap_unit
+-- Date: 2026-10-14
+
+-- chunk_overlap 的单位：tokens（默认，与历史行为一致）、characters、sentences
+ALTER TABLE knowledge_bases
+ADD COLUMN IF NOT EXISTS chunk_overlap_unit VARCHAR(20) NOT NULL DEFAULT 'tokens';
+
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_knowledge_bases_chunk_overlap_unit'
+    ) THEN
+        ALTER TABLE knowledge_bases
+        ADD CONSTRAINT chk_knowledge_bases_chunk_overlap_unit
+        CHECK (chunk_overlap_unit IN ('tokens', 'characters', 'sentences'));
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+COMMENT ON COLUMN knowledge_bases.chunk_overlap_unit IS '分块重叠单位：tokens、characters、sentences（chunk_size 始终按 token 计算）';
+
+-- +goose Down
+ALTER TABLE knowledge_bases DROP CONSTRAINT IF EXISTS chk_knowledge_bases_chunk_overlap_unit;
+ALTER TABLE knowledge_bases DROP COLUMN IF EXISTS chunk_overlap_unit;
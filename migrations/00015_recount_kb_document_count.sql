@@ -0,0 +1,15 @@
+-- +goose Up
+-- 重新计算知识库文档计数
+-- Migration: 00015_recount_kb_document_count
+-- Date: 2026-10-14
+
+-- document_count 改为在文档创建/删除的同一事务中维护（统计知识库内的全部文档，不再只统计处理完成的文档）
+-- 历史计数可能因并发更新、批量删除等原因漂移，这里按 documents 表重新计算一次
+UPDATE knowledge_bases kb
+SET document_count = (
+    SELECT COUNT(*) FROM documents d WHERE d.knowledge_base_id = kb.id
+);
+
+-- +goose Down
+-- 计数语义变更不可逆，无需回滚
+SELECT 1;
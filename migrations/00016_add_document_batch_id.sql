@@ -0,0 +1,18 @@
+-- +goose Up
+-- 批量上传会话
+-- Migration: 00016_add_document_batch_id
+-- Date: 2026-10-14
+
+-- 客户端提供的批量上传会话 ID，中断后使用同一 batch_id 续传时按 (batch_id, filename) 跳过已创建的文档
+ALTER TABLE documents
+ADD COLUMN IF NOT EXISTS batch_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_doc_batch_file
+ON documents (knowledge_base_id, batch_id, filename)
+WHERE batch_id <> '';
+
+COMMENT ON COLUMN documents.batch_id IS '批量上传会话 ID（为空表示非批量上传）';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_doc_batch_file;
+ALTER TABLE documents DROP COLUMN IF EXISTS batch_id;
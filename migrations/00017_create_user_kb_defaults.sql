@@ -0,0 +1,26 @@
+-- +goose Up
+-- 用户级知识库默认设置
+-- Migration: 00017_create_user_kb_defaults
+-- Date: 2026-10-14
+
+-- 创建知识库时请求未传的字段使用用户默认值（NULL 表示未设置，回退到系统默认值）
+CREATE TABLE IF NOT EXISTS user_knowledge_base_defaults (
+    user_id UUID PRIMARY KEY,
+    embedding_model_id UUID,
+    chunk_size INTEGER,
+    chunk_overlap INTEGER,
+    chunk_strategy VARCHAR(50),
+    top_k INTEGER,
+    threshold REAL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_user_kb_defaults_chunk_size CHECK (chunk_size IS NULL OR chunk_size BETWEEN 100 AND 10000),
+    CONSTRAINT chk_user_kb_defaults_chunk_overlap CHECK (chunk_overlap IS NULL OR chunk_overlap >= 0),
+    CONSTRAINT chk_user_kb_defaults_chunk_strategy CHECK (chunk_strategy IS NULL OR chunk_strategy IN ('recursive', 'fixed')),
+    CONSTRAINT chk_user_kb_defaults_top_k CHECK (top_k IS NULL OR top_k BETWEEN 1 AND 20),
+    CONSTRAINT chk_user_kb_defaults_threshold CHECK (threshold IS NULL OR threshold BETWEEN 0 AND 1)
+);
+
+COMMENT ON TABLE user_knowledge_base_defaults IS '用户级知识库默认设置（创建知识库时填充未传的字段）';
+
+-- +goose Down
+DROP TABLE IF EXISTS user_knowledge_base_defaults;
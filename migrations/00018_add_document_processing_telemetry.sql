@@ -0,0 +1,26 @@
+-- +goose Up
+-- 文档处理统计
+-- Migration: 00018_add_document_processing_telemetry
+-- Date: 2026-10-14
+
+-- 最近一次成功处理的耗时与 Embedding 用量（NULL 表示尚未处理完成），用于定位高成本文档和预算 Embedding 开销
+ALTER TABLE documents
+ADD COLUMN IF NOT EXISTS extraction_duration_ms BIGINT,
+ADD COLUMN IF NOT EXISTS embedding_duration_ms BIGINT,
+ADD COLUMN IF NOT EXISTS embedded_tokens BIGINT,
+ADD COLUMN IF NOT EXISTS estimated_embedding_cost NUMERIC(14, 6),
+ADD COLUMN IF NOT EXISTS processed_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN documents.extraction_duration_ms IS '文本提取耗时（毫秒，含 OCR 重试）';
+COMMENT ON COLUMN documents.embedding_duration_ms IS '生成 Embedding 耗时（毫秒）';
+COMMENT ON COLUMN documents.embedded_tokens IS '送入 Embedding 的 token 总数';
+COMMENT ON COLUMN documents.estimated_embedding_cost IS '估算的 Embedding 成本（按配置的每千 token 单价）';
+COMMENT ON COLUMN documents.processed_at IS '最近一次成功处理完成时间';
+
+-- +goose Down
+ALTER TABLE documents
+DROP COLUMN IF EXISTS processed_at,
+DROP COLUMN IF EXISTS estimated_embedding_cost,
+DROP COLUMN IF EXISTS embedded_tokens,
+DROP COLUMN IF EXISTS embedding_duration_ms,
+DROP COLUMN IF EXISTS extraction_duration_ms;
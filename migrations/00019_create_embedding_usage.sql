@@ -0,0 +1,27 @@
+-- +goose Up
+-- Embedding 用量记录
+-- Migration: 00019_create_embedding_usage
+-- Date: 2026-10-15
+
+-- 每次文档处理成功后追加一条记录（重新处理会再次计入），按知识库/所有者和时间范围汇总 Embedding 成本
+-- 不设外键：知识库或文档删除后仍保留历史用量
+CREATE TABLE IF NOT EXISTS embedding_usage (
+    id UUID PRIMARY KEY,
+    knowledge_base_id UUID NOT NULL,
+    owner_id UUID NOT NULL,
+    document_id UUID NOT NULL,
+    model_id UUID NOT NULL,
+    tokens BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_embedding_usage_tokens CHECK (tokens >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_usage_kb_created ON embedding_usage (knowledge_base_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_embedding_usage_owner_created ON embedding_usage (owner_id, created_at);
+
+COMMENT ON TABLE embedding_usage IS '文档处理的 Embedding token 用量（按处理次数记录）';
+COMMENT ON COLUMN embedding_usage.owner_id IS '记录时知识库的所有者';
+COMMENT ON COLUMN embedding_usage.model_id IS '实际生成 Embedding 的模型（可能是备用模型）';
+
+-- +goose Down
+DROP TABLE IF EXISTS embedding_usage;
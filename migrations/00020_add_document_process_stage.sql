@@ -0,0 +1,35 @@
+-- +goose Up
+-- 文档处理状态规范化与处理子阶段
+-- Migration: 00020_add_document_process_stage
+-- Date: 2026-10-15
+
+-- 处理子阶段：processing 时为当前阶段，failed 时保留失败所在阶段，其余状态为空
+ALTER TABLE documents
+ADD COLUMN IF NOT EXISTS process_stage VARCHAR(20) NOT NULL DEFAULT '';
+
+-- 规范化历史状态值：统一小写，无法识别的状态（如 partial）标记为失败并保留原值便于排查
+UPDATE documents
+SET status = LOWER(TRIM(status))
+WHERE status <> LOWER(TRIM(status));
+
+UPDATE documents
+SET status = 'failed',
+    error_message = COALESCE(NULLIF(error_message, ''), 'unknown legacy status: ' || status)
+WHERE status NOT IN ('pending', 'processing', 'retrying', 'completed', 'failed', 'empty');
+
+ALTER TABLE documents
+ADD CONSTRAINT chk_doc_status
+CHECK (status IN ('pending', 'processing', 'retrying', 'completed', 'failed', 'empty'));
+
+ALTER TABLE documents
+ADD CONSTRAINT chk_doc_process_stage
+CHECK (process_stage IN ('', 'extracting', 'chunking', 'embedding', 'inserting'));
+
+COMMENT ON COLUMN documents.status IS '处理状态：pending、processing、retrying、completed、failed、empty';
+COMMENT ON COLUMN documents.process_stage IS '处理子阶段：extracting、chunking、embedding、inserting（为空表示不在处理中）';
+
+-- +goose Down
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS chk_doc_process_stage;
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS chk_doc_status;
+ALTER TABLE documents DROP COLUMN IF EXISTS process_stage;
+COMMENT ON COLUMN documents.status IS '处理状态：pending、processing、completed、failed';
@@ -0,0 +1,13 @@
+-- +goose Up
+-- 文档处理输入指纹
+-- Migration: 00021_add_document_process_fingerprint
+-- Date: 2026-10-15
+
+-- 最近一次成功处理时的输入指纹（文件哈希 + 分块配置 + Embedding 模型），开启 skip_unchanged_reprocess 时用于跳过无变化的重新处理
+ALTER TABLE documents
+ADD COLUMN IF NOT EXISTS process_fingerprint VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN documents.process_fingerprint IS '最近一次成功处理的输入指纹（为空表示尚未记录，下次处理不会被跳过）';
+
+-- +goose Down
+ALTER TABLE documents DROP COLUMN IF EXISTS process_fingerprint;
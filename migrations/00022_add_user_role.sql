@@ -0,0 +1,13 @@
+-- +goose Up
+-- 用户角色
+-- Migration: 00022_add_user_role
+-- Date: 2026-10-15
+
+-- 用户角色（user / admin），登录时写入 access token，管理员接口（/api/v1/admin）按此校验
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+COMMENT ON COLUMN users.role IS '用户角色：user（普通用户）、admin（管理员，可访问 /api/v1/admin 接口）';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS role;
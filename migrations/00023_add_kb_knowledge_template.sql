@@ -0,0 +1,13 @@
+-- +goose Up
+-- 知识库自定义注入模板
+-- Migration: 00023_add_kb_knowledge_template
+-- Date: 2026-10-15
+
+-- 为空时使用全局配置 llm.knowledge_template
+ALTER TABLE knowledge_bases
+ADD COLUMN IF NOT EXISTS knowledge_template TEXT;
+
+COMMENT ON COLUMN knowledge_bases.knowledge_template IS '知识库检索结果注入模板（Go text/template），为空时使用全局模板';
+
+-- +goose Down
+ALTER TABLE knowledge_bases DROP COLUMN IF EXISTS knowledge_template;
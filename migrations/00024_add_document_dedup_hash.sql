@@ -0,0 +1,19 @@
+-- +goose Up
+-- 知识库内文档去重约束
+-- Migration: 00024_add_document_dedup_hash
+-- Date: 2026-10-15
+
+-- 重复文档策略为 reject / return-existing 时写入文件哈希，allow 策略下为 NULL（允许重复）
+-- 唯一索引保证并发上传相同文件时只会创建一条文档记录
+ALTER TABLE documents
+ADD COLUMN IF NOT EXISTS dedup_hash VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_doc_kb_dedup_hash
+ON documents (knowledge_base_id, dedup_hash)
+WHERE dedup_hash IS NOT NULL;
+
+COMMENT ON COLUMN documents.dedup_hash IS '知识库内去重哈希（重复文档策略为 reject/return-existing 时等于 file_hash，否则为 NULL）';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_doc_kb_dedup_hash;
+ALTER TABLE documents DROP COLUMN IF EXISTS dedup_hash;